@@ -13,9 +13,13 @@ import (
 	"github.com/sean/apollo/api/internal/config"
 	"github.com/sean/apollo/api/internal/database"
 	"github.com/sean/apollo/api/internal/logging"
+	"github.com/sean/apollo/api/internal/maintenance"
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
 	"github.com/sean/apollo/api/internal/research"
+	"github.com/sean/apollo/api/internal/scheduler"
 	"github.com/sean/apollo/api/internal/server"
+	"github.com/sean/apollo/api/internal/staleness"
 )
 
 const (
@@ -24,6 +28,24 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == migrateCommand {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "apollo migrate failed: %v\n", err)
+			os.Exit(exitCodeFailure)
+		}
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == reviewCommand {
+		if err := runReview(os.Args[2:]); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "apollo review failed: %v\n", err)
+			os.Exit(exitCodeFailure)
+		}
+
+		return
+	}
+
 	if err := run(); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "apollo startup failed: %v\n", err)
 		os.Exit(exitCodeFailure)
@@ -52,21 +74,81 @@ func run() error {
 		_ = handle.Close()
 	}()
 
+	handle.SetReadPoolSize(cfg.DatabaseReadConns)
+
+	reviewRepo := repository.NewReviewRepository(handle.ReadDB, handle.DB)
+
+	// Question cards follow the lessons and modules they come from; syncing
+	// at startup covers rows written before they existed.
+	questions, err := reviewRepo.SyncQuestions(ctx)
+	if err != nil {
+		return fmt.Errorf("sync question cards: %w", err)
+	}
+
+	logger.Debug().Int("questions", questions).Msg("synced question cards")
+
+	// Generated cards likewise follow their concepts and topics.
+	cards, err := reviewRepo.SyncCards(ctx)
+	if err != nil {
+		return fmt.Errorf("sync generated cards: %w", err)
+	}
+
+	logger.Debug().Int("cards", cards).Msg("synced generated cards")
+
+	if err := rederiveReviewState(ctx, reviewRepo, cfg, logger); err != nil {
+		return fmt.Errorf("review scheduler: %w", err)
+	}
+
 	srv := server.New(handle, logger)
+	srv.SetAPITokens(cfg.APITokens)
+	srv.SetMasteryThreshold(cfg.MasteryThreshold)
+	srv.SetReviewScheduler(cfg.ReviewScheduler)
+	srv.SetEnrollment(models.EnrollmentOptions{
+		IncludeReferenced: cfg.EnrollReferenced,
+		UnenrollOnReset:   cfg.UnenrollOnReset,
+	})
+	srv.SetSessionLimits(models.SessionLimits{
+		NewPerDay:     cfg.ReviewNewPerDay,
+		ReviewsPerDay: cfg.ReviewsPerDay,
+	})
+	srv.SetLeechThreshold(cfg.LeechThreshold)
 
 	// Create and wire the research orchestrator.
-	researchRepo := repository.NewResearchJobRepository(handle.DB)
-	poolBuilder := research.NewPoolSummaryBuilder(handle.DB)
+	researchRepo := repository.NewResearchJobRepository(handle.ReadDB, handle.DB)
+	poolBuilder := research.NewPoolSummaryBuilder(handle.ReadDB)
 	ingester := research.NewCurriculumIngester(handle.DB)
 	cliSession := research.NewCLISession(cfg.ClaudeCodePath)
 
 	orch := research.NewOrchestrator(
 		cliSession, poolBuilder, ingester, researchRepo, logger, cfg,
-	)
+	).WithCardProposer(repository.NewLeechRepository(handle.ReadDB, handle.DB))
 	srv.SetCancelResearchFunc(orch.Cancel)
 
 	go orch.Start(ctx)
 
+	sweeper := staleness.NewSweeper(
+		repository.NewStalenessRepository(handle.ReadDB, handle.DB),
+		researchRepo, logger, cfg,
+	)
+
+	sched := scheduler.New(repository.NewTaskRunRepository(handle.ReadDB, handle.DB), logger)
+
+	tasks := maintenance.Tasks(cfg, maintenance.Deps{
+		Repo:    repository.NewMaintenanceRepository(handle.ReadDB, handle.DB),
+		Jobs:    researchRepo,
+		Sweeper: sweeper,
+		Logger:  logger,
+	})
+	for _, task := range tasks {
+		if err := sched.Register(task); err != nil {
+			return fmt.Errorf("schedule maintenance: %w", err)
+		}
+	}
+
+	srv.SetTaskScheduler(sched)
+
+	go sched.Start(ctx)
+
 	httpServer := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.ServerPort),
 		Handler:           srv.Router(),
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/logging"
+	"github.com/sean/apollo/api/migrations"
+)
+
+const migrateCommand = "migrate"
+
+const migrateUsage = `usage: apollo migrate <command>
+
+commands:
+  status    list applied, pending, modified, and missing migrations
+  up        apply all pending migrations
+  down N    roll back the N most recently applied migrations`
+
+// runMigrate handles `apollo migrate <status|up|down N>`. Logs go to stderr so
+// that status output on stdout stays machine-readable.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand\n%s", migrateUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("create logger: %w", err)
+	}
+
+	switch args[0] {
+	case "status":
+		handle, err := database.Connect(ctx, cfg.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+		defer func() { _ = handle.Close() }()
+
+		statuses, err := database.MigrationStatuses(ctx, handle.DB, migrations.Files)
+		if err != nil {
+			return err
+		}
+
+		return printMigrationStatuses(os.Stdout, statuses)
+
+	case "up":
+		handle, err := database.Open(ctx, cfg.DatabasePath, logger)
+		if err != nil {
+			return fmt.Errorf("apply migrations: %w", err)
+		}
+
+		return handle.Close()
+
+	case "down":
+		if len(args) != 2 {
+			return fmt.Errorf("down requires a step count\n%s", migrateUsage)
+		}
+
+		steps, err := strconv.Atoi(args[1])
+		if err != nil || steps < 1 {
+			return fmt.Errorf("invalid step count %q: must be a positive integer", args[1])
+		}
+
+		handle, err := database.Connect(ctx, cfg.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+		defer func() { _ = handle.Close() }()
+
+		rolledBack, err := database.RollbackMigrations(ctx, handle.DB, migrations.Files, steps, logger)
+		for _, id := range rolledBack {
+			_, _ = fmt.Fprintf(os.Stdout, "rolled back %s\n", id)
+		}
+
+		return err
+
+	default:
+		return fmt.Errorf("unknown subcommand %q\n%s", args[0], migrateUsage)
+	}
+}
+
+func printMigrationStatuses(out io.Writer, statuses []database.MigrationStatus) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "MIGRATION\tSTATE\tAPPLIED AT\tDOWN")
+
+	for _, st := range statuses {
+		appliedAt := st.AppliedAt
+		if appliedAt == "" {
+			appliedAt = "-"
+		}
+
+		down := "no"
+		if st.HasDown {
+			down = "yes"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", st.ID, st.State, appliedAt, down)
+	}
+
+	return tw.Flush()
+}
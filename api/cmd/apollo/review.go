@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+const reviewCommand = "review"
+
+const defaultSimulationDays = 90
+
+const reviewUsage = `usage: apollo review <command>
+
+commands:
+  simulate [DAYS]  project the reviews due each day for DAYS days (default 90)
+                   under every scheduler, rating cards in the mix of past reviews`
+
+// runReview handles `apollo review simulate [DAYS]`. It only reads the
+// database, so it does not apply pending migrations.
+func runReview(args []string) error {
+	if len(args) == 0 || args[0] != "simulate" {
+		return fmt.Errorf("unknown or missing subcommand\n%s", reviewUsage)
+	}
+
+	days := defaultSimulationDays
+
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid day count %q: must be a positive integer", args[1])
+		}
+
+		days = n
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	handle, err := database.Connect(ctx, cfg.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer func() { _ = handle.Close() }()
+
+	repo := repository.NewReviewRepository(handle.DB, handle.DB)
+
+	states, err := repo.ListRetention(ctx)
+	if err != nil {
+		return err
+	}
+
+	history, err := repo.ListHistory(ctx)
+	if err != nil {
+		return err
+	}
+
+	mix := review.MixFromHistory(history)
+	start := time.Now()
+	names := review.Names()
+	workloads := make([][]int, len(names))
+
+	for i, name := range names {
+		scheduler, err := review.New(name, cfg.MasteryThreshold)
+		if err != nil {
+			return err
+		}
+
+		cards := make([]models.ConceptRetention, 0, len(states))
+
+		for _, state := range states {
+			if state.Scheduler != name {
+				if state, err = review.Replay(scheduler, state, history[state.ConceptID]); err != nil {
+					return err
+				}
+			}
+
+			cards = append(cards, state)
+		}
+
+		workloads[i] = review.Simulate(scheduler, cards, mix, start, days)
+	}
+
+	return printWorkloads(os.Stdout, names, workloads, review.DueCutoff(start).AddDate(0, 0, -1))
+}
+
+func printWorkloads(out io.Writer, names []string, workloads [][]int, firstDay time.Time) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	_, _ = fmt.Fprintf(tw, "DAY\tDATE\t%s\t\n", strings.ToUpper(strings.Join(names, "\t")))
+
+	totals := make([]int, len(names))
+
+	for day := range workloads[0] {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t", day, firstDay.AddDate(0, 0, day).Format(time.DateOnly))
+
+		for i, workload := range workloads {
+			totals[i] += workload[day]
+			_, _ = fmt.Fprintf(tw, "%d\t", workload[day])
+		}
+
+		_, _ = fmt.Fprintln(tw)
+	}
+
+	_, _ = fmt.Fprint(tw, "TOTAL\t\t")
+
+	for _, total := range totals {
+		_, _ = fmt.Fprintf(tw, "%d\t", total)
+	}
+
+	_, _ = fmt.Fprintln(tw)
+
+	return tw.Flush()
+}
+
+// rederiveReviewState brings every concept's review state under the active
+// scheduler, the one chosen through the API or else REVIEW_SCHEDULER, by
+// replaying its review history. It runs at startup so a scheduler change in
+// the config takes effect on the whole queue at once.
+func rederiveReviewState(ctx context.Context, repo repository.ReviewRepository, cfg config.Config, logger zerolog.Logger) error {
+	if _, err := review.New(cfg.ReviewScheduler, cfg.MasteryThreshold); err != nil {
+		return err
+	}
+
+	name, err := repo.GetSchedulerPreference(ctx)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = cfg.ReviewScheduler
+	}
+
+	scheduler, err := review.New(name, cfg.MasteryThreshold)
+	if err != nil {
+		return err
+	}
+
+	n, err := repo.Rederive(ctx, name, func(current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error) {
+		return review.Replay(scheduler, current, history)
+	})
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		logger.Info().Str("scheduler", name).Int("concepts", n).Msg("re-derived review state")
+	}
+
+	return nil
+}
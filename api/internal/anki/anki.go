@@ -0,0 +1,76 @@
+// Package anki writes concept flashcards as Anki decks (.apkg) and as CSV
+// or TSV for other spaced repetition tools. Notes carry stable GUIDs
+// derived from concept IDs, so importing a re-exported deck updates the
+// notes an earlier import created instead of duplicating them.
+package anki
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// RootDeck is the deck every exported note goes under, in a subdeck per
+// topic.
+const RootDeck = "Apollo"
+
+// guidPrefix marks the GUIDs of notes exported from Apollo.
+const guidPrefix = "apollo:"
+
+// Note is one exported flashcard.
+type Note struct {
+	GUID  string
+	Deck  string
+	Front string
+	Back  string
+	Tags  []string
+}
+
+// NoteGUID returns the GUID of the note exported for a concept.
+func NoteGUID(conceptID string) string {
+	return guidPrefix + conceptID
+}
+
+// Notes converts flashcards to notes: each goes in its topic's subdeck of
+// RootDeck, tagged with its topic's tags and module::<module ID>.
+func Notes(cards []models.Flashcard) []Note {
+	notes := make([]Note, 0, len(cards))
+
+	for _, c := range cards {
+		deck := RootDeck
+		if c.TopicTitle != "" {
+			deck += "::" + c.TopicTitle
+		}
+
+		var tags []string
+		for _, t := range c.TopicTags {
+			if t = tag(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+
+		if c.ModuleID != "" {
+			tags = append(tags, "module::"+tag(c.ModuleID))
+		}
+
+		slices.Sort(tags)
+
+		notes = append(notes, Note{
+			GUID:  NoteGUID(c.ConceptID),
+			Deck:  deck,
+			Front: c.Front,
+			Back:  c.Back,
+			Tags:  slices.Compact(tags),
+		})
+	}
+
+	return notes
+}
+
+// tag makes s a valid Anki tag. Anki separates tags with spaces, so runs of
+// whitespace inside a tag become underscores.
+func tag(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), "_")
+}
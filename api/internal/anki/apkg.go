@@ -0,0 +1,344 @@
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// collectionFile and mediaFile are the entries of an .apkg zip:
+// collection.anki2, an SQLite database in Anki's schema version 11, and
+// media, a JSON map of the media files it carries. Every Anki client,
+// AnkiDroid included, imports this format.
+const (
+	collectionFile = "collection.anki2"
+	mediaFile      = "media"
+)
+
+// schemaVersion is the collection schema written to col.ver.
+const schemaVersion = 11
+
+// modelID is the ID of the note type every exported note uses. Anki only
+// updates a note on import when its note type matches, so it never changes.
+const modelID = 1718300000000
+
+// defaultDeckID is the ID of Anki's built-in Default deck and options group.
+const defaultDeckID = 1
+
+// collectionSchema creates the tables of a schema 11 collection.
+const collectionSchema = `
+CREATE TABLE col (
+  id integer PRIMARY KEY, crt integer NOT NULL, mod integer NOT NULL, scm integer NOT NULL,
+  ver integer NOT NULL, dty integer NOT NULL, usn integer NOT NULL, ls integer NOT NULL,
+  conf text NOT NULL, models text NOT NULL, decks text NOT NULL, dconf text NOT NULL, tags text NOT NULL
+);
+CREATE TABLE notes (
+  id integer PRIMARY KEY, guid text NOT NULL, mid integer NOT NULL, mod integer NOT NULL,
+  usn integer NOT NULL, tags text NOT NULL, flds text NOT NULL, sfld integer NOT NULL,
+  csum integer NOT NULL, flags integer NOT NULL, data text NOT NULL
+);
+CREATE TABLE cards (
+  id integer PRIMARY KEY, nid integer NOT NULL, did integer NOT NULL, ord integer NOT NULL,
+  mod integer NOT NULL, usn integer NOT NULL, type integer NOT NULL, queue integer NOT NULL,
+  due integer NOT NULL, ivl integer NOT NULL, factor integer NOT NULL, reps integer NOT NULL,
+  lapses integer NOT NULL, left integer NOT NULL, odue integer NOT NULL, odid integer NOT NULL,
+  flags integer NOT NULL, data text NOT NULL
+);
+CREATE TABLE revlog (
+  id integer PRIMARY KEY, cid integer NOT NULL, usn integer NOT NULL, ease integer NOT NULL,
+  ivl integer NOT NULL, lastIvl integer NOT NULL, factor integer NOT NULL, time integer NOT NULL,
+  type integer NOT NULL
+);
+CREATE TABLE graves (usn integer NOT NULL, oid integer NOT NULL, type integer NOT NULL);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+const insertColSQL = `
+INSERT INTO col (id, crt, mod, scm, ver, dty, usn, ls, conf, models, decks, dconf, tags)
+VALUES (1, ?, ?, ?, ?, 0, 0, 0, ?, ?, ?, ?, '{}')
+`
+
+const insertNoteSQL = `
+INSERT INTO notes (id, guid, mid, mod, usn, tags, flds, sfld, csum, flags, data)
+VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')
+`
+
+// insertCardSQL stores a new card, due in export order.
+const insertCardSQL = `
+INSERT INTO cards (id, nid, did, ord, mod, usn, type, queue, due, ivl, factor, reps, lapses, left, odue, odid, flags, data)
+VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')
+`
+
+// WriteAPKG writes the notes to w as an .apkg, each as a new card in its
+// deck. now stamps the notes as modified, so an import replaces the text
+// of notes an earlier export created.
+func WriteAPKG(ctx context.Context, w io.Writer, notes []Note, now time.Time) error {
+	dir, err := os.MkdirTemp("", "apollo-anki-*")
+	if err != nil {
+		return fmt.Errorf("create collection directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, collectionFile)
+
+	if err := writeCollection(ctx, path, notes, now); err != nil {
+		return err
+	}
+
+	collection, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read collection: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{collectionFile, collection},
+		{mediaFile, []byte("{}")},
+	} {
+		f, err := zw.Create(entry.name)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", entry.name, err)
+		}
+
+		if _, err := f.Write(entry.data); err != nil {
+			return fmt.Errorf("write %s: %w", entry.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("close package: %w", err)
+	}
+
+	return nil
+}
+
+// writeCollection creates the collection database at path.
+func writeCollection(ctx context.Context, path string, notes []Note, now time.Time) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("open collection: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, collectionSchema); err != nil {
+		return fmt.Errorf("create collection schema: %w", err)
+	}
+
+	// Each deck comes after its parents, which older clients need to exist.
+	decks := map[string]int64{}
+	var deckNames []string
+
+	for _, n := range notes {
+		parts := strings.Split(n.Deck, "::")
+
+		for i := range parts {
+			name := strings.Join(parts[:i+1], "::")
+
+			if _, ok := decks[name]; !ok {
+				decks[name] = stableID("deck", name)
+				deckNames = append(deckNames, name)
+			}
+		}
+	}
+
+	conf, models, deckJSON, dconf, err := collectionJSON(decks, deckNames, now)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, insertColSQL,
+		dayStart(now).Unix(), now.UnixMilli(), now.UnixMilli(), schemaVersion,
+		conf, models, deckJSON, dconf,
+	); err != nil {
+		return fmt.Errorf("store collection: %w", err)
+	}
+
+	for i, n := range notes {
+		noteID := stableID("note", n.GUID)
+		front := field(n.Front)
+
+		var tags string
+		if len(n.Tags) > 0 {
+			tags = " " + strings.Join(n.Tags, " ") + " "
+		}
+
+		if _, err := tx.ExecContext(ctx, insertNoteSQL,
+			noteID, n.GUID, modelID, now.Unix(), tags,
+			front+"\x1f"+field(n.Back), n.Front, checksum(n.Front),
+		); err != nil {
+			return fmt.Errorf("store note %s: %w", n.GUID, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, insertCardSQL,
+			stableID("card", n.GUID), noteID, decks[n.Deck], now.Unix(), i+1,
+		); err != nil {
+			return fmt.Errorf("store card of note %s: %w", n.GUID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit collection: %w", err)
+	}
+
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("close collection: %w", err)
+	}
+
+	return nil
+}
+
+// collectionJSON returns the col row's conf, models, decks, and dconf
+// columns: the collection settings, the Front/Back note type, the Default
+// deck plus the named decks, and the default deck options.
+func collectionJSON(decks map[string]int64, deckNames []string, now time.Time) (conf, models, deckJSON, dconf string, err error) {
+	mod := now.Unix()
+
+	deckMap := map[string]any{strconv.Itoa(defaultDeckID): deck(defaultDeckID, "Default", mod)}
+	for _, name := range deckNames {
+		deckMap[strconv.FormatInt(decks[name], 10)] = deck(decks[name], name, mod)
+	}
+
+	modelDeck := int64(defaultDeckID)
+	if len(deckNames) > 0 {
+		modelDeck = decks[deckNames[0]]
+	}
+
+	values := []any{
+		map[string]any{
+			"activeDecks": []int{defaultDeckID}, "curDeck": defaultDeckID, "newSpread": 0,
+			"collapseTime": 1200, "timeLim": 0, "estTimes": true, "dueCounts": true,
+			"curModel": strconv.FormatInt(modelID, 10), "nextPos": 1,
+			"sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+		},
+		map[string]any{strconv.FormatInt(modelID, 10): noteType(modelDeck, mod)},
+		deckMap,
+		map[string]any{strconv.Itoa(defaultDeckID): deckOptions()},
+	}
+
+	encoded := make([]string, len(values))
+
+	for i, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", "", "", "", fmt.Errorf("encode collection settings: %w", err)
+		}
+
+		encoded[i] = string(b)
+	}
+
+	return encoded[0], encoded[1], encoded[2], encoded[3], nil
+}
+
+func deck(id int64, name string, mod int64) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "desc": "", "mod": mod, "usn": -1,
+		"collapsed": false, "browserCollapsed": false, "dyn": 0, "conf": defaultDeckID,
+		"extendNew": 10, "extendRev": 50,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+// noteType is the Apollo note type: a Front and a Back field and one card
+// showing the front, then both.
+func noteType(deckID, mod int64) map[string]any {
+	fieldDef := func(name string, ord int) map[string]any {
+		return map[string]any{
+			"name": name, "ord": ord, "sticky": false, "rtl": false,
+			"font": "Arial", "size": 20, "media": []string{},
+		}
+	}
+
+	return map[string]any{
+		"id": modelID, "name": "Apollo Flashcard", "type": 0, "mod": mod, "usn": -1,
+		"sortf": 0, "did": deckID,
+		"flds": []any{fieldDef("Front", 0), fieldDef("Back", 1)},
+		"tmpls": []any{map[string]any{
+			"name": "Card 1", "ord": 0, "did": nil, "bqfmt": "", "bafmt": "",
+			"qfmt": "{{Front}}",
+			"afmt": "{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}",
+		}},
+		"css":       ".card {\n font-family: arial;\n font-size: 20px;\n text-align: center;\n color: black;\n background-color: white;\n}\n",
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"req":       []any{[]any{0, "any", []int{0}}},
+		"tags":      []string{},
+		"vers":      []int{},
+	}
+}
+
+func deckOptions() map[string]any {
+	return map[string]any{
+		"id": defaultDeckID, "name": "Default", "mod": 0, "usn": 0,
+		"maxTaken": 60, "autoplay": true, "timer": 0, "replayq": true, "dyn": false,
+		"new": map[string]any{
+			"bury": true, "delays": []int{1, 10}, "initialFactor": 2500,
+			"ints": []int{1, 4, 7}, "order": 1, "perDay": 20, "separate": true,
+		},
+		"lapse": map[string]any{
+			"delays": []int{10}, "leechAction": 0, "leechFails": 8, "minInt": 1, "mult": 0,
+		},
+		"rev": map[string]any{
+			"bury": true, "ease4": 1.3, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500,
+			"minSpace": 1, "perDay": 100,
+		},
+	}
+}
+
+// field escapes text as an HTML note field, keeping its line breaks.
+func field(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// checksum is Anki's duplicate check on a note's first field: the first
+// eight hex digits of its SHA-1, as an integer.
+func checksum(text string) int64 {
+	sum := sha1.Sum([]byte(text))
+	n, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+
+	return n
+}
+
+// stableID derives a positive ID below 2^53 from its kind and key, so the
+// same note, card, or deck keeps its ID across exports and survives
+// clients that hold IDs in floating point.
+func stableID(kind, key string) int64 {
+	sum := sha256.Sum256([]byte(kind + "\x00" + key))
+
+	return int64(binary.BigEndian.Uint64(sum[:8])>>11) + defaultDeckID + 1
+}
+
+// dayStart is the start of now's UTC day, the collection creation time.
+func dayStart(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour)
+}
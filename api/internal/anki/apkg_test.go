@@ -0,0 +1,210 @@
+package anki_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/anki"
+	"github.com/sean/apollo/api/internal/models"
+)
+
+var testCards = []models.Flashcard{
+	{ConceptID: "goroutine", Front: "What is a goroutine?", Back: "A lightweight thread\nmanaged by <the runtime>", TopicID: "go", TopicTitle: "Go", TopicTags: []string{"languages", "systems programming"}, ModuleID: "go-concurrency"},
+	{ConceptID: "closure", Front: "Closure", Back: "A function with its environment", TopicID: "go", TopicTitle: "Go"},
+	{ConceptID: "orphan", Front: "Orphan", Back: "A concept without a topic"},
+}
+
+func TestNotes(t *testing.T) {
+	notes := anki.Notes(testCards)
+
+	if len(notes) != 3 {
+		t.Fatalf("Notes() returned %d notes, want 3", len(notes))
+	}
+
+	n := notes[0]
+	if n.GUID != "apollo:goroutine" || n.Deck != "Apollo::Go" || n.Front != "What is a goroutine?" {
+		t.Fatalf("Notes()[0] = %+v", n)
+	}
+
+	if want := []string{"languages", "module::go-concurrency", "systems_programming"}; !slices.Equal(n.Tags, want) {
+		t.Fatalf("Notes()[0].Tags = %v, want %v", n.Tags, want)
+	}
+
+	if notes[1].Tags != nil || notes[2].Deck != anki.RootDeck {
+		t.Fatalf("Notes() = %+v, want no tags on closure and orphan in the root deck", notes)
+	}
+}
+
+// openCollection unpacks an .apkg and opens its collection.
+func openCollection(t *testing.T, apkg []byte) *sql.DB {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(apkg), int64(len(apkg)))
+	if err != nil {
+		t.Fatalf("open package: %v", err)
+	}
+
+	entries := map[string][]byte{}
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+
+		entries[f.Name] = data
+	}
+
+	if string(entries["media"]) != "{}" {
+		t.Fatalf("media = %q, want {}", entries["media"])
+	}
+
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	if err := os.WriteFile(path, entries["collection.anki2"], 0o600); err != nil {
+		t.Fatalf("write collection: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open collection: %v", err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestWriteAPKG(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := anki.WriteAPKG(context.Background(), &buf, anki.Notes(testCards), now); err != nil {
+		t.Fatalf("WriteAPKG() error = %v", err)
+	}
+
+	db := openCollection(t, buf.Bytes())
+
+	var ver int
+	var models, decksJSON string
+	if err := db.QueryRow(`SELECT ver, models, decks FROM col`).Scan(&ver, &models, &decksJSON); err != nil {
+		t.Fatalf("read col: %v", err)
+	}
+
+	var decks map[string]struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(decksJSON), &decks); err != nil {
+		t.Fatalf("decode decks: %v", err)
+	}
+
+	deckNames := map[int64]string{}
+	for id, d := range decks {
+		n, _ := strconv.ParseInt(id, 10, 64)
+		deckNames[n] = d.Name
+	}
+
+	if ver != 11 || len(deckNames) != 3 || deckNames[1] != "Default" || !strings.Contains(models, `"Apollo Flashcard"`) {
+		t.Fatalf("col = ver %d, decks %v", ver, deckNames)
+	}
+
+	rows, err := db.Query(`SELECT n.guid, n.tags, n.flds, n.mod, c.did, c.type
+		FROM notes n JOIN cards c ON c.nid = n.id ORDER BY c.due`)
+	if err != nil {
+		t.Fatalf("query notes: %v", err)
+	}
+	defer rows.Close()
+
+	type note struct {
+		guid, tags, flds, deck string
+		mod                    int64
+	}
+
+	var notes []note
+
+	for rows.Next() {
+		var n note
+		var did int64
+		var cardType int
+		if err := rows.Scan(&n.guid, &n.tags, &n.flds, &n.mod, &did, &cardType); err != nil {
+			t.Fatalf("scan note: %v", err)
+		}
+
+		if cardType != 0 {
+			t.Fatalf("note %s card type = %d, want new", n.guid, cardType)
+		}
+
+		n.deck = deckNames[did]
+		notes = append(notes, n)
+	}
+
+	if len(notes) != 3 {
+		t.Fatalf("collection has %d notes, want 3", len(notes))
+	}
+
+	want := note{
+		guid: "apollo:goroutine",
+		tags: " languages module::go-concurrency systems_programming ",
+		flds: "What is a goroutine?\x1fA lightweight thread<br>managed by &lt;the runtime&gt;",
+		deck: "Apollo::Go",
+		mod:  now.Unix(),
+	}
+	if notes[0] != want {
+		t.Fatalf("first note = %+v, want %+v", notes[0], want)
+	}
+
+	if notes[2].deck != "Apollo" || notes[2].tags != "" {
+		t.Fatalf("orphan note = %+v, want the root deck and no tags", notes[2])
+	}
+
+	// A later export keeps every ID, so Anki updates the notes in place.
+	var again bytes.Buffer
+	if err := anki.WriteAPKG(context.Background(), &again, anki.Notes(testCards[:1]), now.Add(time.Hour)); err != nil {
+		t.Fatalf("WriteAPKG() again error = %v", err)
+	}
+
+	var firstID, secondID int64
+	if err := db.QueryRow(`SELECT id FROM notes WHERE guid = 'apollo:goroutine'`).Scan(&firstID); err != nil {
+		t.Fatalf("read note ID: %v", err)
+	}
+
+	if err := openCollection(t, again.Bytes()).QueryRow(`SELECT id FROM notes`).Scan(&secondID); err != nil {
+		t.Fatalf("read re-exported note ID: %v", err)
+	}
+
+	if firstID != secondID {
+		t.Fatalf("note ID changed from %d to %d across exports", firstID, secondID)
+	}
+}
+
+func TestWriteDelimited(t *testing.T) {
+	var buf bytes.Buffer
+	if err := anki.WriteDelimited(&buf, anki.Notes(testCards[:2]), '\t'); err != nil {
+		t.Fatalf("WriteDelimited() error = %v", err)
+	}
+
+	want := "front\tback\ttags\tdeck\tguid\n" +
+		"What is a goroutine?\t\"A lightweight thread\nmanaged by <the runtime>\"\tlanguages module::go-concurrency systems_programming\tApollo::Go\tapollo:goroutine\n" +
+		"Closure\tA function with its environment\t\tApollo::Go\tapollo:closure\n"
+
+	if got := buf.String(); got != want {
+		t.Fatalf("WriteDelimited() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package anki
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// delimitedHeader names the columns of a CSV or TSV export. Front and back
+// come first, which is what most importers map by default.
+var delimitedHeader = []string{"front", "back", "tags", "deck", "guid"}
+
+// WriteDelimited writes the notes to w as plain text, one row each after a
+// header row, with fields separated by comma: ',' for CSV, '\t' for TSV.
+// Tags are separated by spaces, as Anki's text importer expects.
+func WriteDelimited(w io.Writer, notes []Note, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	if err := cw.Write(delimitedHeader); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	for _, n := range notes {
+		if err := cw.Write([]string{n.Front, n.Back, strings.Join(n.Tags, " "), n.Deck, n.GUID}); err != nil {
+			return fmt.Errorf("write note %s: %w", n.GUID, err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flush notes: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// ErrInvalidPackage means a file is not an Anki package or collection.
+var ErrInvalidPackage = errors.New("not an Anki package")
+
+// ErrUnsupportedPackage means a package holds only the zstd-compressed
+// collection newer Anki versions write by default. Exporting with "Support
+// older Anki versions" checked adds a collection this package can read.
+var ErrUnsupportedPackage = errors.New(`collection is compressed; export it with "Support older Anki versions" checked`)
+
+// compressedCollectionFile is the collection Anki 2.1.50 and later write by
+// default. A package holding it also holds a placeholder collection.anki2,
+// which must not be read in its place. legacyCollectionFile is the
+// collection written for older versions, preferred over collection.anki2.
+const (
+	compressedCollectionFile = "collection.anki21b"
+	legacyCollectionFile     = "collection.anki21"
+)
+
+// ankiRatings maps Anki's answer buttons, revlog.ease 1 to 4, to ratings.
+var ankiRatings = map[int]string{
+	1: models.RatingForgot,
+	2: models.RatingHard,
+	3: models.RatingGood,
+	4: models.RatingEasy,
+}
+
+// lastAnsweredRevlogType is the last revlog.type that records an answer:
+// learning, review, relearning, and filtered deck reviews. Later types are
+// manual reschedules.
+const lastAnsweredRevlogType = 3
+
+const listCardsSQL = `
+SELECT c.id, n.guid, n.flds
+FROM cards c
+JOIN notes n ON n.id = c.nid
+ORDER BY c.id
+`
+
+const listRevlogSQL = `SELECT id, cid, ease, time, type FROM revlog ORDER BY id`
+
+// htmlTag matches the tags of an HTML note field.
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// ReadCards reads the cards of an .apkg or .colpkg with their notes and
+// review logs. Reviews that record no answer, such as manual reschedules,
+// are left out.
+func ReadCards(ctx context.Context, r io.ReaderAt, size int64) ([]models.AnkiCard, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPackage, err)
+	}
+
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	entry := entries[legacyCollectionFile]
+
+	switch {
+	case entry != nil:
+	case entries[compressedCollectionFile] != nil:
+		return nil, ErrUnsupportedPackage
+	case entries[collectionFile] != nil:
+		entry = entries[collectionFile]
+	default:
+		return nil, fmt.Errorf("%w: no collection", ErrInvalidPackage)
+	}
+
+	dir, err := os.MkdirTemp("", "apollo-anki-*")
+	if err != nil {
+		return nil, fmt.Errorf("create collection directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, collectionFile)
+
+	if err := extract(entry, path); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open collection: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	return readCards(ctx, db)
+}
+
+func extract(f *zip.File, path string) error {
+	src, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: open %s: %w", ErrInvalidPackage, f.Name, err)
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create collection: %w", err)
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+
+		return fmt.Errorf("%w: extract %s: %w", ErrInvalidPackage, f.Name, err)
+	}
+
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("write collection: %w", err)
+	}
+
+	return nil
+}
+
+func readCards(ctx context.Context, db *sql.DB) ([]models.AnkiCard, error) {
+	rows, err := db.QueryContext(ctx, listCardsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: query cards: %w", ErrInvalidPackage, err)
+	}
+	defer rows.Close()
+
+	var cards []models.AnkiCard
+	index := map[int64]int{}
+
+	for rows.Next() {
+		var c models.AnkiCard
+		var fields string
+		if err := rows.Scan(&c.ID, &c.NoteGUID, &fields); err != nil {
+			return nil, fmt.Errorf("%w: scan card: %w", ErrInvalidPackage, err)
+		}
+
+		front, _, _ := strings.Cut(fields, "\x1f")
+		c.Front = FieldText(front)
+		if id, ok := strings.CutPrefix(c.NoteGUID, guidPrefix); ok {
+			c.ConceptID = id
+		}
+
+		index[c.ID] = len(cards)
+		cards = append(cards, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterate cards: %w", ErrInvalidPackage, err)
+	}
+
+	revlog, err := db.QueryContext(ctx, listRevlogSQL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: query review log: %w", ErrInvalidPackage, err)
+	}
+	defer revlog.Close()
+
+	for revlog.Next() {
+		var id, cardID int64
+		var ease, ms, kind int
+		if err := revlog.Scan(&id, &cardID, &ease, &ms, &kind); err != nil {
+			return nil, fmt.Errorf("%w: scan review: %w", ErrInvalidPackage, err)
+		}
+
+		i, ok := index[cardID]
+		rating := ankiRatings[ease]
+
+		if !ok || rating == "" || kind > lastAnsweredRevlogType {
+			continue
+		}
+
+		cards[i].Reviews = append(cards[i].Reviews, models.AnkiReview{
+			ReviewedAt: time.UnixMilli(id).UTC().Format(time.RFC3339),
+			Rating:     rating,
+			ResponseMS: max(ms, 0),
+		})
+	}
+
+	if err := revlog.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterate review log: %w", ErrInvalidPackage, err)
+	}
+
+	return cards, nil
+}
+
+// FieldText returns a note field as plain text: tags removed, entities
+// decoded, and whitespace collapsed.
+func FieldText(field string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(field, " "))), " ")
+}
@@ -0,0 +1,150 @@
+package anki_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/anki"
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// zipFiles returns a zip holding the files given, by name.
+func zipFiles(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestReadCards(t *testing.T) {
+	var buf bytes.Buffer
+	if err := anki.WriteAPKG(context.Background(), &buf, anki.Notes(testCards), time.Now()); err != nil {
+		t.Fatalf("WriteAPKG() error = %v", err)
+	}
+
+	db := openCollection(t, buf.Bytes())
+
+	var goroutineCard int64
+	if err := db.QueryRow(`SELECT c.id FROM cards c JOIN notes n ON n.id = c.nid
+		WHERE n.guid = 'apollo:goroutine'`).Scan(&goroutineCard); err != nil {
+		t.Fatalf("find card: %v", err)
+	}
+
+	first := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	for _, stmt := range []string{
+		// A note from another deck, matched only by its front.
+		`INSERT INTO notes (id, guid, mid, mod, usn, tags, flds, sfld, csum, flags, data)
+		 VALUES (7, 'xyz', 1, 0, -1, '', '<b>Mutex</b>&nbsp;lock' || char(31) || 'back', 'Mutex', 0, 0, '')`,
+		`INSERT INTO cards (id, nid, did, ord, mod, usn, type, queue, due, ivl, factor, reps, lapses, left, odue, odid, flags, data)
+		 VALUES (70, 7, 1, 0, 0, -1, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, '')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed collection: %v", err)
+		}
+	}
+
+	for _, r := range []struct {
+		at             time.Time
+		card           int64
+		ease, ms, kind int
+	}{
+		{first.Add(24 * time.Hour), goroutineCard, 1, 9000, 1},
+		{first, goroutineCard, 3, 4000, 0},
+		{first.Add(48 * time.Hour), goroutineCard, 3, 0, 4}, // manual reschedule
+		{first.Add(time.Minute), 70, 4, 2000, 0},
+		{first.Add(time.Hour), 999, 3, 2000, 0}, // deleted card
+	} {
+		if _, err := db.Exec(`INSERT INTO revlog (id, cid, usn, ease, ivl, lastIvl, factor, time, type)
+			VALUES (?, ?, -1, ?, 0, 0, 0, ?, ?)`, r.at.UnixMilli(), r.card, r.ease, r.ms, r.kind); err != nil {
+			t.Fatalf("seed revlog: %v", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "copy.anki2")
+	if _, err := db.Exec(`VACUUM INTO ?`, path); err != nil {
+		t.Fatalf("copy collection: %v", err)
+	}
+
+	collection, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read collection: %v", err)
+	}
+
+	pkg := zipFiles(t, map[string][]byte{"collection.anki2": collection, "media": []byte("{}")})
+
+	cards, err := anki.ReadCards(context.Background(), bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		t.Fatalf("ReadCards() error = %v", err)
+	}
+
+	byGUID := map[string]models.AnkiCard{}
+	for _, c := range cards {
+		byGUID[c.NoteGUID] = c
+	}
+
+	if len(cards) != 4 {
+		t.Fatalf("ReadCards() returned %d cards, want 4", len(cards))
+	}
+
+	g := byGUID["apollo:goroutine"]
+	want := []models.AnkiReview{
+		{ReviewedAt: "2026-09-01T08:00:00Z", Rating: models.RatingGood, ResponseMS: 4000},
+		{ReviewedAt: "2026-09-02T08:00:00Z", Rating: models.RatingForgot, ResponseMS: 9000},
+	}
+
+	if g.ConceptID != "goroutine" || g.Front != "What is a goroutine?" || len(g.Reviews) != 2 ||
+		g.Reviews[0] != want[0] || g.Reviews[1] != want[1] {
+		t.Fatalf("goroutine card = %+v", g)
+	}
+
+	m := byGUID["xyz"]
+	if m.ConceptID != "" || m.Front != "Mutex lock" || len(m.Reviews) != 1 || m.Reviews[0].Rating != models.RatingEasy {
+		t.Fatalf("foreign card = %+v", m)
+	}
+}
+
+func TestReadCardsRejectsPackages(t *testing.T) {
+	for name, pkg := range map[string][]byte{
+		"not a zip":     []byte("plain text"),
+		"no collection": zipFiles(t, map[string][]byte{"media": []byte("{}")}),
+		"compressed": zipFiles(t, map[string][]byte{
+			"collection.anki2": []byte("placeholder"), "collection.anki21b": []byte("zstd"),
+		}),
+		"corrupt collection": zipFiles(t, map[string][]byte{"collection.anki2": []byte("not sqlite")}),
+	} {
+		_, err := anki.ReadCards(context.Background(), bytes.NewReader(pkg), int64(len(pkg)))
+
+		want := anki.ErrInvalidPackage
+		if name == "compressed" {
+			want = anki.ErrUnsupportedPackage
+		}
+
+		if !errors.Is(err, want) {
+			t.Errorf("ReadCards(%s) error = %v, want %v", name, err, want)
+		}
+	}
+}
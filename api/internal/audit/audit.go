@@ -0,0 +1,38 @@
+// Package audit carries the identity behind a curriculum change through the
+// request context, so the repository can record who made each mutation
+// without every method growing an actor parameter.
+package audit
+
+import "context"
+
+const (
+	// ActorCLI is recorded for changes made outside the HTTP server and the
+	// research agent, and for any context without an actor.
+	ActorCLI = "cli"
+
+	// ActorAPI is recorded for HTTP requests that carry no API token.
+	ActorAPI = "api"
+
+	researchAgentPrefix = "research-agent:"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context whose changes are attributed to actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored on ctx, or ActorCLI when none is set.
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+
+	return ActorCLI
+}
+
+// ResearchAgent returns the actor for changes ingested by a research job.
+func ResearchAgent(jobID string) string {
+	return researchAgentPrefix + jobID
+}
@@ -0,0 +1,26 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/audit"
+)
+
+func TestActorFromDefaultsToCLI(t *testing.T) {
+	if got := audit.ActorFrom(context.Background()); got != audit.ActorCLI {
+		t.Fatalf("ActorFrom(background) = %q, want %q", got, audit.ActorCLI)
+	}
+
+	if got := audit.ActorFrom(audit.WithActor(context.Background(), "")); got != audit.ActorCLI {
+		t.Fatalf("ActorFrom(empty actor) = %q, want %q", got, audit.ActorCLI)
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	ctx := audit.WithActor(context.Background(), audit.ResearchAgent("job-7"))
+
+	if got := audit.ActorFrom(ctx); got != "research-agent:job-7" {
+		t.Fatalf("ActorFrom() = %q, want %q", got, "research-agent:job-7")
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 const (
 	envDatabasePath        = "DATABASE_PATH"
+	envDatabaseReadConns   = "DATABASE_READ_CONNECTIONS"
 	envServerPort          = "SERVER_PORT"
 	envClaudeCodePath      = "CLAUDE_CODE_PATH"
 	envMaxResearchDepth    = "MAX_RESEARCH_DEPTH"
@@ -15,13 +17,30 @@ const (
 	envTopicSizeLimit      = "TOPIC_SIZE_LIMIT"
 	envAutoExpandPriority  = "AUTO_EXPAND_PRIORITY"
 	envCurriculumStaleDays = "CURRICULUM_STALE_DAYS"
+	envStaleRefreshLimit   = "STALE_REFRESH_DAILY_LIMIT"
+	envBackupDir           = "BACKUP_DIR"
+	envBackupRetain        = "BACKUP_RETAIN"
+	envWorkDirRetention    = "RESEARCH_WORK_DIR_RETENTION_DAYS"
+	envScheduleStaleness   = "SCHEDULE_STALENESS_SWEEP"
+	envScheduleReviewDue   = "SCHEDULE_REVIEW_DUE"
+	envScheduleBackup      = "SCHEDULE_BACKUP"
+	envScheduleCleanup     = "SCHEDULE_WORK_DIR_CLEANUP"
+	envScheduleFTSOptimize = "SCHEDULE_FTS_OPTIMIZE"
 	envMasteryThreshold    = "MASTERY_THRESHOLD_DAYS"
+	envReviewScheduler     = "REVIEW_SCHEDULER"
+	envEnrollReferenced    = "REVIEW_ENROLL_REFERENCED"
+	envUnenrollOnReset     = "REVIEW_UNENROLL_ON_RESET"
+	envReviewNewPerDay     = "REVIEW_NEW_PER_DAY"
+	envReviewsPerDay       = "REVIEW_MAX_PER_DAY"
+	envLeechThreshold      = "REVIEW_LEECH_THRESHOLD"
 	envResearchWorkDir     = "RESEARCH_WORK_DIR"
 	envLogLevel            = "LOG_LEVEL"
+	envAPITokens           = "API_TOKENS"
 )
 
 const (
 	defaultDatabasePath       = "./data/apollo.db"
+	defaultDatabaseReadConns  = 4
 	defaultServerPort         = 8080
 	defaultClaudeCodePath     = "claude"
 	defaultMaxResearchDepth   = DefaultMaxResearchDepth
@@ -29,7 +48,20 @@ const (
 	defaultTopicSizeLimit     = 8
 	defaultAutoExpandPriority = "essential"
 	defaultCurriculumStale    = 180
+	defaultStaleRefreshLimit  = 0
+	defaultBackupDir          = "./data/backups"
+	defaultBackupRetain       = 7
+	defaultWorkDirRetention   = 7
+	defaultScheduleStaleness  = "0 3 * * *"
+	defaultScheduleReviewDue  = "0 7 * * *"
+	defaultScheduleBackup     = "0 2 * * *"
+	defaultScheduleCleanup    = "0 4 * * *"
+	defaultScheduleFTS        = "30 4 * * 0"
 	defaultMasteryThreshold   = 90
+	defaultReviewScheduler    = "sm2"
+	defaultReviewNewPerDay    = 20
+	defaultReviewsPerDay      = 200
+	defaultLeechThreshold     = 8
 	defaultResearchWorkDir    = DefaultResearchWorkDir
 	defaultLogLevel           = "info"
 )
@@ -37,6 +69,7 @@ const (
 // Config holds runtime configuration loaded from environment variables.
 type Config struct {
 	DatabasePath       string
+	DatabaseReadConns  int
 	ServerPort         int
 	ClaudeCodePath     string
 	MaxResearchDepth   int
@@ -44,9 +77,40 @@ type Config struct {
 	TopicSizeLimit     int
 	AutoExpandPriority string
 	CurriculumStale    int
+	StaleRefreshLimit  int
+	BackupDir          string
+	BackupRetain       int
+	WorkDirRetention   int
+	Schedules          Schedules
 	MasteryThreshold   int
+	ReviewScheduler    string
 	ResearchWorkDir    string
 	LogLevel           string
+	// EnrollReferenced also enrolls concepts a completed lesson only
+	// references; UnenrollOnReset drops never-reviewed concepts from review
+	// when their lesson goes back to not_started.
+	EnrollReferenced bool
+	UnenrollOnReset  bool
+	// ReviewNewPerDay and ReviewsPerDay cap the never-reviewed concepts and
+	// the other reviews that review sessions hand out per UTC day.
+	ReviewNewPerDay int
+	ReviewsPerDay   int
+	// LeechThreshold is the lapses after which a concept is suspended from
+	// review as a leech; 0 never suspends.
+	LeechThreshold int
+	// APITokens maps each bearer token to the name recorded as the actor
+	// of changes made with it.
+	APITokens map[string]string
+}
+
+// Schedules holds the cron-like spec for each maintenance task. "off"
+// leaves a task available for manual runs only.
+type Schedules struct {
+	StalenessSweep string
+	ReviewDue      string
+	Backup         string
+	WorkDirCleanup string
+	FTSOptimize    string
 }
 
 // Load reads environment variables and returns an application configuration.
@@ -56,6 +120,11 @@ func Load() (Config, error) {
 		return Config{}, err
 	}
 
+	databaseReadConns, err := intEnv(envDatabaseReadConns, defaultDatabaseReadConns)
+	if err != nil {
+		return Config{}, err
+	}
+
 	maxResearchDepth, err := intEnv(envMaxResearchDepth, defaultMaxResearchDepth)
 	if err != nil {
 		return Config{}, err
@@ -76,13 +145,59 @@ func Load() (Config, error) {
 		return Config{}, err
 	}
 
+	staleRefreshLimit, err := intEnv(envStaleRefreshLimit, defaultStaleRefreshLimit)
+	if err != nil {
+		return Config{}, err
+	}
+
+	backupRetain, err := intEnv(envBackupRetain, defaultBackupRetain)
+	if err != nil {
+		return Config{}, err
+	}
+
+	workDirRetention, err := intEnv(envWorkDirRetention, defaultWorkDirRetention)
+	if err != nil {
+		return Config{}, err
+	}
+
 	masteryThreshold, err := intEnv(envMasteryThreshold, defaultMasteryThreshold)
 	if err != nil {
 		return Config{}, err
 	}
 
+	enrollReferenced, err := boolEnv(envEnrollReferenced, false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	unenrollOnReset, err := boolEnv(envUnenrollOnReset, false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	reviewNewPerDay, err := intEnv(envReviewNewPerDay, defaultReviewNewPerDay)
+	if err != nil {
+		return Config{}, err
+	}
+
+	reviewsPerDay, err := intEnv(envReviewsPerDay, defaultReviewsPerDay)
+	if err != nil {
+		return Config{}, err
+	}
+
+	leechThreshold, err := intEnv(envLeechThreshold, defaultLeechThreshold)
+	if err != nil {
+		return Config{}, err
+	}
+
+	apiTokens, err := tokensEnv(envAPITokens)
+	if err != nil {
+		return Config{}, err
+	}
+
 	return Config{
 		DatabasePath:       stringEnv(envDatabasePath, defaultDatabasePath),
+		DatabaseReadConns:  databaseReadConns,
 		ServerPort:         serverPort,
 		ClaudeCodePath:     stringEnv(envClaudeCodePath, defaultClaudeCodePath),
 		MaxResearchDepth:   maxResearchDepth,
@@ -90,9 +205,27 @@ func Load() (Config, error) {
 		TopicSizeLimit:     topicSizeLimit,
 		AutoExpandPriority: stringEnv(envAutoExpandPriority, defaultAutoExpandPriority),
 		CurriculumStale:    curriculumStale,
-		MasteryThreshold:   masteryThreshold,
-		ResearchWorkDir:    stringEnv(envResearchWorkDir, defaultResearchWorkDir),
-		LogLevel:           stringEnv(envLogLevel, defaultLogLevel),
+		StaleRefreshLimit:  staleRefreshLimit,
+		BackupDir:          stringEnv(envBackupDir, defaultBackupDir),
+		BackupRetain:       backupRetain,
+		WorkDirRetention:   workDirRetention,
+		Schedules: Schedules{
+			StalenessSweep: stringEnv(envScheduleStaleness, defaultScheduleStaleness),
+			ReviewDue:      stringEnv(envScheduleReviewDue, defaultScheduleReviewDue),
+			Backup:         stringEnv(envScheduleBackup, defaultScheduleBackup),
+			WorkDirCleanup: stringEnv(envScheduleCleanup, defaultScheduleCleanup),
+			FTSOptimize:    stringEnv(envScheduleFTSOptimize, defaultScheduleFTS),
+		},
+		MasteryThreshold: masteryThreshold,
+		ReviewScheduler:  stringEnv(envReviewScheduler, defaultReviewScheduler),
+		EnrollReferenced: enrollReferenced,
+		UnenrollOnReset:  unenrollOnReset,
+		ReviewNewPerDay:  reviewNewPerDay,
+		ReviewsPerDay:    reviewsPerDay,
+		LeechThreshold:   leechThreshold,
+		ResearchWorkDir:  stringEnv(envResearchWorkDir, defaultResearchWorkDir),
+		LogLevel:         stringEnv(envLogLevel, defaultLogLevel),
+		APITokens:        apiTokens,
 	}, nil
 }
 
@@ -118,3 +251,43 @@ func intEnv(key string, fallback int) (int, error) {
 
 	return parsedValue, nil
 }
+
+func boolEnv(key string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+
+	parsedValue, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", key, err)
+	}
+
+	return parsedValue, nil
+}
+
+// tokensEnv parses a comma-separated list of name:token pairs into a map from
+// token to name. An unset variable yields a nil map.
+func tokensEnv(key string) (map[string]string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	tokens := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		name, token, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || name == "" || token == "" {
+			return nil, fmt.Errorf("parse %s: entry %q is not name:token", key, pair)
+		}
+
+		if _, dup := tokens[token]; dup {
+			return nil, fmt.Errorf("parse %s: token for %q is already assigned", key, name)
+		}
+
+		tokens[token] = name
+	}
+
+	return tokens, nil
+}
@@ -4,6 +4,7 @@ import "testing"
 
 func TestLoadDefaults(t *testing.T) {
 	t.Setenv(envDatabasePath, "")
+	t.Setenv(envDatabaseReadConns, "")
 	t.Setenv(envServerPort, "")
 	t.Setenv(envClaudeCodePath, "")
 	t.Setenv(envMaxResearchDepth, "")
@@ -11,9 +12,19 @@ func TestLoadDefaults(t *testing.T) {
 	t.Setenv(envTopicSizeLimit, "")
 	t.Setenv(envAutoExpandPriority, "")
 	t.Setenv(envCurriculumStaleDays, "")
+	t.Setenv(envStaleRefreshLimit, "")
+	t.Setenv(envBackupRetain, "")
+	t.Setenv(envScheduleStaleness, "")
 	t.Setenv(envMasteryThreshold, "")
+	t.Setenv(envReviewScheduler, "")
+	t.Setenv(envEnrollReferenced, "")
+	t.Setenv(envUnenrollOnReset, "")
+	t.Setenv(envReviewNewPerDay, "")
+	t.Setenv(envReviewsPerDay, "")
+	t.Setenv(envLeechThreshold, "")
 	t.Setenv(envResearchWorkDir, "")
 	t.Setenv(envLogLevel, "")
+	t.Setenv(envAPITokens, "")
 
 	cfg, err := Load()
 	if err != nil {
@@ -24,6 +35,10 @@ func TestLoadDefaults(t *testing.T) {
 		t.Fatalf("expected DatabasePath %q, got %q", defaultDatabasePath, cfg.DatabasePath)
 	}
 
+	if cfg.DatabaseReadConns != defaultDatabaseReadConns {
+		t.Fatalf("expected DatabaseReadConns %d, got %d", defaultDatabaseReadConns, cfg.DatabaseReadConns)
+	}
+
 	if cfg.ServerPort != defaultServerPort {
 		t.Fatalf("expected ServerPort %d, got %d", defaultServerPort, cfg.ServerPort)
 	}
@@ -52,10 +67,39 @@ func TestLoadDefaults(t *testing.T) {
 		t.Fatalf("expected CurriculumStale %d, got %d", defaultCurriculumStale, cfg.CurriculumStale)
 	}
 
+	if cfg.BackupRetain != defaultBackupRetain {
+		t.Fatalf("expected BackupRetain %d, got %d", defaultBackupRetain, cfg.BackupRetain)
+	}
+
+	if cfg.Schedules.StalenessSweep != defaultScheduleStaleness {
+		t.Fatalf("expected staleness schedule %q, got %q", defaultScheduleStaleness, cfg.Schedules.StalenessSweep)
+	}
+
+	if cfg.StaleRefreshLimit != defaultStaleRefreshLimit {
+		t.Fatalf("expected StaleRefreshLimit %d, got %d", defaultStaleRefreshLimit, cfg.StaleRefreshLimit)
+	}
+
 	if cfg.MasteryThreshold != defaultMasteryThreshold {
 		t.Fatalf("expected MasteryThreshold %d, got %d", defaultMasteryThreshold, cfg.MasteryThreshold)
 	}
 
+	if cfg.ReviewScheduler != defaultReviewScheduler {
+		t.Fatalf("expected ReviewScheduler %q, got %q", defaultReviewScheduler, cfg.ReviewScheduler)
+	}
+
+	if cfg.EnrollReferenced || cfg.UnenrollOnReset {
+		t.Fatalf("expected enrollment options off, got %v %v", cfg.EnrollReferenced, cfg.UnenrollOnReset)
+	}
+
+	if cfg.ReviewNewPerDay != defaultReviewNewPerDay || cfg.ReviewsPerDay != defaultReviewsPerDay {
+		t.Fatalf("expected review caps %d and %d, got %d and %d",
+			defaultReviewNewPerDay, defaultReviewsPerDay, cfg.ReviewNewPerDay, cfg.ReviewsPerDay)
+	}
+
+	if cfg.LeechThreshold != defaultLeechThreshold {
+		t.Fatalf("expected LeechThreshold %d, got %d", defaultLeechThreshold, cfg.LeechThreshold)
+	}
+
 	if cfg.ResearchWorkDir != defaultResearchWorkDir {
 		t.Fatalf("expected ResearchWorkDir %q, got %q", defaultResearchWorkDir, cfg.ResearchWorkDir)
 	}
@@ -63,10 +107,15 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.LogLevel != defaultLogLevel {
 		t.Fatalf("expected LogLevel %q, got %q", defaultLogLevel, cfg.LogLevel)
 	}
+
+	if cfg.APITokens != nil {
+		t.Fatalf("expected no API tokens, got %v", cfg.APITokens)
+	}
 }
 
 func TestLoadOverrides(t *testing.T) {
 	t.Setenv(envDatabasePath, "/tmp/test.db")
+	t.Setenv(envDatabaseReadConns, "8")
 	t.Setenv(envServerPort, "18080")
 	t.Setenv(envClaudeCodePath, "/usr/local/bin/claude")
 	t.Setenv(envMaxResearchDepth, "5")
@@ -74,7 +123,16 @@ func TestLoadOverrides(t *testing.T) {
 	t.Setenv(envTopicSizeLimit, "11")
 	t.Setenv(envAutoExpandPriority, "helpful")
 	t.Setenv(envCurriculumStaleDays, "120")
+	t.Setenv(envStaleRefreshLimit, "2")
+	t.Setenv(envBackupRetain, "3")
+	t.Setenv(envScheduleStaleness, "@hourly")
 	t.Setenv(envMasteryThreshold, "30")
+	t.Setenv(envReviewScheduler, "fsrs")
+	t.Setenv(envEnrollReferenced, "true")
+	t.Setenv(envUnenrollOnReset, "1")
+	t.Setenv(envReviewNewPerDay, "5")
+	t.Setenv(envReviewsPerDay, "50")
+	t.Setenv(envLeechThreshold, "0")
 	t.Setenv(envResearchWorkDir, "/tmp/research")
 	t.Setenv(envLogLevel, "debug")
 
@@ -87,6 +145,10 @@ func TestLoadOverrides(t *testing.T) {
 		t.Fatalf("expected DatabasePath override, got %q", cfg.DatabasePath)
 	}
 
+	if cfg.DatabaseReadConns != 8 {
+		t.Fatalf("expected DatabaseReadConns override, got %d", cfg.DatabaseReadConns)
+	}
+
 	if cfg.ServerPort != 18080 {
 		t.Fatalf("expected ServerPort override, got %d", cfg.ServerPort)
 	}
@@ -115,10 +177,38 @@ func TestLoadOverrides(t *testing.T) {
 		t.Fatalf("expected CurriculumStale override, got %d", cfg.CurriculumStale)
 	}
 
+	if cfg.BackupRetain != 3 {
+		t.Fatalf("expected BackupRetain override, got %d", cfg.BackupRetain)
+	}
+
+	if cfg.Schedules.StalenessSweep != "@hourly" {
+		t.Fatalf("expected staleness schedule override, got %q", cfg.Schedules.StalenessSweep)
+	}
+
+	if cfg.StaleRefreshLimit != 2 {
+		t.Fatalf("expected StaleRefreshLimit override, got %d", cfg.StaleRefreshLimit)
+	}
+
 	if cfg.MasteryThreshold != 30 {
 		t.Fatalf("expected MasteryThreshold override, got %d", cfg.MasteryThreshold)
 	}
 
+	if cfg.ReviewScheduler != "fsrs" {
+		t.Fatalf("expected ReviewScheduler override, got %q", cfg.ReviewScheduler)
+	}
+
+	if !cfg.EnrollReferenced || !cfg.UnenrollOnReset {
+		t.Fatalf("expected enrollment overrides, got %v %v", cfg.EnrollReferenced, cfg.UnenrollOnReset)
+	}
+
+	if cfg.ReviewNewPerDay != 5 || cfg.ReviewsPerDay != 50 {
+		t.Fatalf("expected review cap overrides, got %d and %d", cfg.ReviewNewPerDay, cfg.ReviewsPerDay)
+	}
+
+	if cfg.LeechThreshold != 0 {
+		t.Fatalf("expected LeechThreshold override, got %d", cfg.LeechThreshold)
+	}
+
 	if cfg.ResearchWorkDir != "/tmp/research" {
 		t.Fatalf("expected ResearchWorkDir override, got %q", cfg.ResearchWorkDir)
 	}
@@ -136,3 +226,35 @@ func TestLoadInvalidInteger(t *testing.T) {
 		t.Fatalf("expected Load() to fail for invalid integer environment value")
 	}
 }
+
+func TestLoadInvalidBool(t *testing.T) {
+	t.Setenv(envEnrollReferenced, "sometimes")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatalf("expected Load() to fail for invalid boolean environment value")
+	}
+}
+
+func TestLoadAPITokens(t *testing.T) {
+	t.Setenv(envAPITokens, "alice:s3cret, ci:tok-2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if cfg.APITokens["s3cret"] != "alice" || cfg.APITokens["tok-2"] != "ci" || len(cfg.APITokens) != 2 {
+		t.Fatalf("expected two named tokens, got %v", cfg.APITokens)
+	}
+}
+
+func TestLoadInvalidAPITokens(t *testing.T) {
+	for _, value := range []string{"no-separator", "alice:", ":token", "a:same,b:same"} {
+		t.Setenv(envAPITokens, value)
+
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected Load() to fail for API_TOKENS=%q", value)
+		}
+	}
+}
@@ -23,15 +23,54 @@ const (
 	// DSN pragma parameters ensure foreign keys and busy timeout are applied to
 	// every connection created by database/sql, surviving pool recycling.
 	dsnPragmas = "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	// readOnlyPragma rejects writes on read pool connections so a repository
+	// wired to the wrong handle fails loudly instead of racing the writer.
+	readOnlyPragma = "&_pragma=query_only(1)"
 )
 
-// Handle wraps a database connection for Apollo services.
+// DefaultReadConnections is the read pool size used unless overridden with
+// SetReadPoolSize.
+const DefaultReadConnections = 4
+
+// Handle wraps the database connections for Apollo services.
+//
+// DB is the single-connection write handle: every INSERT/UPDATE/DELETE and
+// every write transaction goes through it, which serializes writers the way
+// SQLite requires. ReadDB is a pool of query_only connections that WAL mode
+// lets run concurrently with the writer.
 type Handle struct {
-	DB *sql.DB
+	DB     *sql.DB
+	ReadDB *sql.DB
 }
 
 // Open creates the SQLite database connection, runs migrations, and performs a health check.
 func Open(ctx context.Context, databasePath string, logger zerolog.Logger) (*Handle, error) {
+	handle, err := Connect(ctx, databasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyMigrations(ctx, handle.DB, migrations.Files, logger); err != nil {
+		_ = handle.Close()
+		return nil, err
+	}
+
+	if err := HealthCheck(ctx, handle.DB); err != nil {
+		_ = handle.Close()
+		return nil, err
+	}
+
+	if err := HealthCheck(ctx, handle.ReadDB); err != nil {
+		_ = handle.Close()
+		return nil, err
+	}
+
+	return handle, nil
+}
+
+// Connect creates the SQLite database connection without running migrations.
+// Used by maintenance commands that inspect or roll back the schema.
+func Connect(ctx context.Context, databasePath string) (*Handle, error) {
 	if err := os.MkdirAll(filepath.Dir(databasePath), databaseDirPerms); err != nil {
 		return nil, fmt.Errorf("create database directory: %w", err)
 	}
@@ -45,32 +84,56 @@ func Open(ctx context.Context, databasePath string, logger zerolog.Logger) (*Han
 	db.SetMaxIdleConns(maxIdleConnections)
 
 	// journal_mode=WAL is persistent (written to the DB file) so it only
-	// needs to be set once rather than on every new connection.
+	// needs to be set once rather than on every new connection. It must be
+	// set before any read connection opens the file.
 	if err := applyPersistentPragmas(ctx, db); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
 
-	if err := applyMigrations(ctx, db, migrations.Files, logger); err != nil {
+	readDB, err := sql.Open(sqliteDriverName, databasePath+dsnPragmas+readOnlyPragma)
+	if err != nil {
 		_ = db.Close()
-		return nil, err
+		return nil, fmt.Errorf("open sqlite read pool: %w", err)
 	}
 
-	if err := HealthCheck(ctx, db); err != nil {
-		_ = db.Close()
-		return nil, err
+	readDB.SetMaxOpenConns(DefaultReadConnections)
+	readDB.SetMaxIdleConns(DefaultReadConnections)
+
+	return &Handle{DB: db, ReadDB: readDB}, nil
+}
+
+// SetReadPoolSize changes the number of connections in the read pool.
+// Values below 1 are ignored.
+func (h *Handle) SetReadPoolSize(n int) {
+	if n < 1 {
+		return
 	}
 
-	return &Handle{DB: db}, nil
+	h.ReadDB.SetMaxOpenConns(n)
+	h.ReadDB.SetMaxIdleConns(n)
 }
 
 // Close releases database resources.
 func (h *Handle) Close() error {
-	if h == nil || h.DB == nil {
+	if h == nil {
 		return nil
 	}
 
-	return h.DB.Close()
+	var readErr error
+	if h.ReadDB != nil {
+		readErr = h.ReadDB.Close()
+	}
+
+	if h.DB == nil {
+		return readErr
+	}
+
+	if err := h.DB.Close(); err != nil {
+		return err
+	}
+
+	return readErr
 }
 
 // HealthCheck validates the database connection.
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"io"
 	"os"
 	"path/filepath"
@@ -205,6 +206,98 @@ func TestSearchIndexIsFTS5(t *testing.T) {
 	}
 }
 
+func TestReadPoolIsQueryOnly(t *testing.T) {
+	ctx := context.Background()
+	databasePath := filepath.Join(t.TempDir(), "read.db")
+
+	logger, err := logging.New(io.Discard, "info")
+	if err != nil {
+		t.Fatalf("create logger: %v", err)
+	}
+
+	handle, err := Open(ctx, databasePath, logger)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = handle.Close()
+	})
+
+	if _, err := handle.ReadDB.ExecContext(ctx, `
+		INSERT INTO topics (id, title, status) VALUES ('t1', 'Topic', 'draft')
+	`); err == nil {
+		t.Fatal("expected write through read pool to fail")
+	}
+
+	if _, err := handle.DB.ExecContext(ctx, `
+		INSERT INTO topics (id, title, status) VALUES ('t1', 'Topic', 'draft')
+	`); err != nil {
+		t.Fatalf("insert through write handle: %v", err)
+	}
+
+	var count int
+	if err := handle.ReadDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM topics`).Scan(&count); err != nil {
+		t.Fatalf("read through read pool: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected read pool to see committed write, got %d rows", count)
+	}
+}
+
+func TestReadPoolServesReadersDuringWriteTransaction(t *testing.T) {
+	ctx := context.Background()
+	databasePath := filepath.Join(t.TempDir(), "concurrent.db")
+
+	logger, err := logging.New(io.Discard, "info")
+	if err != nil {
+		t.Fatalf("create logger: %v", err)
+	}
+
+	handle, err := Open(ctx, databasePath, logger)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = handle.Close()
+	})
+
+	// Hold the only write connection in an open transaction.
+	tx, err := handle.DB.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin write transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO topics (id, title, status) VALUES ('t1', 'Topic', 'draft')`); err != nil {
+		t.Fatalf("insert in write transaction: %v", err)
+	}
+
+	// Readers must neither block nor see the uncommitted row, and several
+	// must be able to hold connections at the same time.
+	conns := make([]*sql.Conn, 0, DefaultReadConnections)
+	for i := 0; i < DefaultReadConnections; i++ {
+		conn, err := handle.ReadDB.Conn(ctx)
+		if err != nil {
+			t.Fatalf("acquire read connection %d: %v", i, err)
+		}
+		conns = append(conns, conn)
+
+		var count int
+		if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM topics`).Scan(&count); err != nil {
+			t.Fatalf("read on connection %d: %v", i, err)
+		}
+
+		if count != 0 {
+			t.Fatalf("expected uncommitted row to be invisible, got %d rows", count)
+		}
+	}
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
+
 func assertTableExists(t *testing.T, ctx context.Context, handle *Handle, tableName string) {
 	t.Helper()
 
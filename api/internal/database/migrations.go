@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
 	"sort"
@@ -11,47 +14,144 @@ import (
 	"github.com/rs/zerolog"
 )
 
+const (
+	upMigrationSuffix   = ".sql"
+	downMigrationSuffix = ".down.sql"
+)
+
 const (
 	createMigrationsTableSQL = `
 CREATE TABLE IF NOT EXISTS schema_migrations (
   id TEXT PRIMARY KEY,
-  applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+  applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  checksum TEXT
 );`
-	insertMigrationSQL = `INSERT INTO schema_migrations(id) VALUES (?);`
-	hasMigrationSQL    = `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE id = ?);`
+	// Databases created before checksums were tracked have no checksum column.
+	hasChecksumColumnSQL = `SELECT EXISTS(SELECT 1 FROM pragma_table_info('schema_migrations') WHERE name = 'checksum');`
+	addChecksumColumnSQL = `ALTER TABLE schema_migrations ADD COLUMN checksum TEXT;`
+	insertMigrationSQL   = `INSERT INTO schema_migrations(id, checksum) VALUES (?, ?);`
+	deleteMigrationSQL   = `DELETE FROM schema_migrations WHERE id = ?;`
+	backfillChecksumSQL  = `UPDATE schema_migrations SET checksum = ? WHERE id = ? AND checksum IS NULL;`
+	listAppliedSQL       = `SELECT id, applied_at, COALESCE(checksum, '') FROM schema_migrations ORDER BY id;`
+)
+
+// Migration errors.
+var (
+	// ErrMigrationChecksumMismatch indicates an applied migration file was edited after it ran.
+	ErrMigrationChecksumMismatch = errors.New("migration checksum mismatch")
+
+	// ErrMissingDownMigration indicates a rollback was requested for a migration without a .down.sql pair.
+	ErrMissingDownMigration = errors.New("missing down migration")
+)
+
+// MigrationState describes how a migration file relates to the tracking table.
+type MigrationState string
+
+// Migration states reported by MigrationStatuses.
+const (
+	MigrationApplied  MigrationState = "applied"
+	MigrationPending  MigrationState = "pending"
+	MigrationModified MigrationState = "modified"
+	MigrationMissing  MigrationState = "missing"
 )
 
+// MigrationStatus reports the state of a single migration.
+type MigrationStatus struct {
+	ID        string
+	State     MigrationState
+	AppliedAt string
+	HasDown   bool
+}
+
+// migration is an up script with its optional paired down script.
+type migration struct {
+	id       string
+	upSQL    string
+	downSQL  string
+	checksum string
+}
+
+// appliedMigration is a row from the tracking table.
+type appliedMigration struct {
+	appliedAt string
+	checksum  string
+}
+
 func applyMigrations(ctx context.Context, db *sql.DB, migrationFiles fs.FS, logger zerolog.Logger) error {
-	if _, err := db.ExecContext(ctx, createMigrationsTableSQL); err != nil {
-		return fmt.Errorf("create migration tracking table: %w", err)
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return err
 	}
 
-	fileNames, err := migrationFileNames(migrationFiles)
+	migs, err := loadMigrations(migrationFiles)
 	if err != nil {
 		return err
 	}
 
-	for _, fileName := range fileNames {
-		applied, err := migrationAlreadyApplied(ctx, db, fileName)
-		if err != nil {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyChecksums(ctx, db, migs, applied, logger); err != nil {
+		return err
+	}
+
+	for _, m := range migs {
+		if _, ok := applied[m.id]; ok {
+			logger.Debug().Str("migration", m.id).Msg("migration already applied")
+			continue
+		}
+
+		if err := runSingleMigration(ctx, db, m); err != nil {
 			return err
 		}
 
-		if applied {
-			logger.Debug().Str("migration", fileName).Msg("migration already applied")
+		logger.Info().Str("migration", m.id).Msg("migration applied")
+	}
+
+	return nil
+}
+
+// verifyChecksums fails when any applied migration no longer matches the file
+// on disk. Rows recorded before checksums existed are backfilled with the
+// current file checksum.
+func verifyChecksums(ctx context.Context, db *sql.DB, migs []migration, applied map[string]appliedMigration, logger zerolog.Logger) error {
+	var modified []string
+
+	for _, m := range migs {
+		row, ok := applied[m.id]
+		if !ok {
 			continue
 		}
 
-		migrationSQL, err := fs.ReadFile(migrationFiles, fileName)
-		if err != nil {
-			return fmt.Errorf("read migration %s: %w", fileName, err)
+		if row.checksum == "" {
+			if _, err := db.ExecContext(ctx, backfillChecksumSQL, m.checksum, m.id); err != nil {
+				return fmt.Errorf("backfill checksum for migration %s: %w", m.id, err)
+			}
+
+			logger.Info().Str("migration", m.id).Msg("migration checksum recorded")
+
+			continue
 		}
 
-		if err := runSingleMigration(ctx, db, fileName, string(migrationSQL)); err != nil {
-			return err
+		if row.checksum != m.checksum {
+			modified = append(modified, m.id)
 		}
+	}
+
+	if len(modified) > 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationChecksumMismatch, strings.Join(modified, ", "))
+	}
 
-		logger.Info().Str("migration", fileName).Msg("migration applied")
+	known := make(map[string]bool, len(migs))
+	for _, m := range migs {
+		known[m.id] = true
+	}
+
+	for id := range applied {
+		if !known[id] {
+			logger.Warn().Str("migration", id).Msg("applied migration has no matching file")
+		}
 	}
 
 	return nil
@@ -61,38 +161,254 @@ func applyMigrations(ctx context.Context, db *sql.DB, migrationFiles fs.FS, logg
 // a single transaction. The modernc.org/sqlite driver executes multi-statement
 // SQL atomically within one ExecContext call, so all statements in the script
 // are covered by the transaction.
-func runSingleMigration(ctx context.Context, db *sql.DB, migrationID, script string) error {
+func runSingleMigration(ctx context.Context, db *sql.DB, m migration) error {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("begin migration transaction %s: %w", migrationID, err)
+		return fmt.Errorf("begin migration transaction %s: %w", m.id, err)
 	}
 
-	if _, err := tx.ExecContext(ctx, script); err != nil {
+	if _, err := tx.ExecContext(ctx, m.upSQL); err != nil {
 		_ = tx.Rollback()
-		return fmt.Errorf("execute migration %s: %w", migrationID, err)
+		return fmt.Errorf("execute migration %s: %w", m.id, err)
 	}
 
-	if _, err := tx.ExecContext(ctx, insertMigrationSQL, migrationID); err != nil {
+	if _, err := tx.ExecContext(ctx, insertMigrationSQL, m.id, m.checksum); err != nil {
 		_ = tx.Rollback()
-		return fmt.Errorf("insert migration %s into tracking table: %w", migrationID, err)
+		return fmt.Errorf("insert migration %s into tracking table: %w", m.id, err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit migration %s: %w", migrationID, err)
+		return fmt.Errorf("commit migration %s: %w", m.id, err)
 	}
 
 	return nil
 }
 
-func migrationAlreadyApplied(ctx context.Context, db *sql.DB, migrationID string) (bool, error) {
-	var exists bool
-	if err := db.QueryRowContext(ctx, hasMigrationSQL, migrationID).Scan(&exists); err != nil {
-		return false, fmt.Errorf("query applied migration %s: %w", migrationID, err)
+// RollbackMigrations reverts the most recently applied migrations, newest
+// first, using their paired .down.sql scripts. Each rollback runs in its own
+// transaction. It returns the IDs that were rolled back.
+func RollbackMigrations(ctx context.Context, db *sql.DB, migrationFiles fs.FS, steps int, logger zerolog.Logger) ([]string, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("rollback steps must be at least 1, got %d", steps)
+	}
+
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	migs, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]migration, len(migs))
+	for _, m := range migs {
+		byID[m.id] = m
+	}
+
+	appliedIDs := make([]string, 0, len(applied))
+	for id := range applied {
+		appliedIDs = append(appliedIDs, id)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(appliedIDs)))
+
+	if steps > len(appliedIDs) {
+		return nil, fmt.Errorf("cannot roll back %d migrations: only %d applied", steps, len(appliedIDs))
+	}
+
+	targets := appliedIDs[:steps]
+
+	// Validate every target before touching the schema so a missing down
+	// script does not leave a partial rollback behind.
+	for _, id := range targets {
+		m, ok := byID[id]
+		if !ok || m.downSQL == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingDownMigration, id)
+		}
+
+		if sum := applied[id].checksum; sum != "" && sum != m.checksum {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationChecksumMismatch, id)
+		}
+	}
+
+	rolledBack := make([]string, 0, len(targets))
+
+	for _, id := range targets {
+		if err := revertSingleMigration(ctx, db, byID[id]); err != nil {
+			return rolledBack, err
+		}
+
+		logger.Info().Str("migration", id).Msg("migration rolled back")
+		rolledBack = append(rolledBack, id)
 	}
 
-	return exists, nil
+	return rolledBack, nil
 }
 
+func revertSingleMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin rollback transaction %s: %w", m.id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, m.downSQL); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("execute down migration %s: %w", m.id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteMigrationSQL, m.id); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("delete migration %s from tracking table: %w", m.id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit rollback %s: %w", m.id, err)
+	}
+
+	return nil
+}
+
+// MigrationStatuses reports every known migration as applied, pending, or
+// modified, plus any tracked migration whose file no longer exists as missing.
+func MigrationStatuses(ctx context.Context, db *sql.DB, migrationFiles fs.FS) ([]MigrationStatus, error) {
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	migs, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migs))
+	known := make(map[string]bool, len(migs))
+
+	for _, m := range migs {
+		known[m.id] = true
+		status := MigrationStatus{ID: m.id, State: MigrationPending, HasDown: m.downSQL != ""}
+
+		if row, ok := applied[m.id]; ok {
+			status.AppliedAt = row.appliedAt
+			status.State = MigrationApplied
+
+			if row.checksum != "" && row.checksum != m.checksum {
+				status.State = MigrationModified
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for id, row := range applied {
+		if !known[id] {
+			statuses = append(statuses, MigrationStatus{ID: id, State: MigrationMissing, AppliedAt: row.appliedAt})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+
+	return statuses, nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, createMigrationsTableSQL); err != nil {
+		return fmt.Errorf("create migration tracking table: %w", err)
+	}
+
+	var hasChecksum bool
+	if err := db.QueryRowContext(ctx, hasChecksumColumnSQL).Scan(&hasChecksum); err != nil {
+		return fmt.Errorf("inspect migration tracking table: %w", err)
+	}
+
+	if !hasChecksum {
+		if _, err := db.ExecContext(ctx, addChecksumColumnSQL); err != nil {
+			return fmt.Errorf("add checksum column to migration tracking table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[string]appliedMigration, error) {
+	rows, err := db.QueryContext(ctx, listAppliedSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]appliedMigration)
+
+	for rows.Next() {
+		var id string
+		var row appliedMigration
+
+		if err := rows.Scan(&id, &row.appliedAt, &row.checksum); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+
+		applied[id] = row
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// loadMigrations reads up migrations in filename order and pairs each with its
+// NNNN_name.down.sql counterpart when one exists.
+func loadMigrations(migrationFiles fs.FS) ([]migration, error) {
+	fileNames, err := migrationFileNames(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	migs := make([]migration, 0, len(fileNames))
+
+	for _, fileName := range fileNames {
+		upSQL, err := fs.ReadFile(migrationFiles, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", fileName, err)
+		}
+
+		m := migration{
+			id:       fileName,
+			upSQL:    string(upSQL),
+			checksum: migrationChecksum(upSQL),
+		}
+
+		downName := downMigrationName(fileName)
+
+		downSQL, err := fs.ReadFile(migrationFiles, downName)
+		switch {
+		case err == nil:
+			m.downSQL = string(downSQL)
+		case errors.Is(err, fs.ErrNotExist):
+			// Down migrations are optional.
+		default:
+			return nil, fmt.Errorf("read down migration %s: %w", downName, err)
+		}
+
+		migs = append(migs, m)
+	}
+
+	return migs, nil
+}
+
+// migrationFileNames returns the sorted up migration filenames. Paired
+// .down.sql files are excluded.
 func migrationFileNames(migrationFiles fs.FS) ([]string, error) {
 	entries, err := fs.ReadDir(migrationFiles, ".")
 	if err != nil {
@@ -106,7 +422,7 @@ func migrationFileNames(migrationFiles fs.FS) ([]string, error) {
 		}
 
 		name := entry.Name()
-		if strings.HasSuffix(name, ".sql") {
+		if strings.HasSuffix(name, upMigrationSuffix) && !strings.HasSuffix(name, downMigrationSuffix) {
 			fileNames = append(fileNames, name)
 		}
 	}
@@ -119,3 +435,12 @@ func migrationFileNames(migrationFiles fs.FS) ([]string, error) {
 
 	return fileNames, nil
 }
+
+func downMigrationName(upName string) string {
+	return strings.TrimSuffix(upName, upMigrationSuffix) + downMigrationSuffix
+}
+
+func migrationChecksum(script []byte) string {
+	sum := sha256.Sum256(script)
+	return hex.EncodeToString(sum[:])
+}
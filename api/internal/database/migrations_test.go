@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/migrations"
+)
+
+func connectTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	handle, err := Connect(context.Background(), filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = handle.Close()
+	})
+
+	return handle.DB
+}
+
+func testMigrationFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_widgets.sql":      {Data: []byte(`CREATE TABLE widgets (id TEXT PRIMARY KEY);`)},
+		"0001_widgets.down.sql": {Data: []byte(`DROP TABLE widgets;`)},
+		"0002_gadgets.sql":      {Data: []byte(`CREATE TABLE gadgets (id TEXT PRIMARY KEY);`)},
+	}
+}
+
+func TestApplyMigrationsRecordsChecksums(t *testing.T) {
+	ctx := context.Background()
+	db := connectTestDB(t)
+	files := testMigrationFS()
+
+	if err := applyMigrations(ctx, db, files, zerolog.New(io.Discard)); err != nil {
+		t.Fatalf("applyMigrations() returned error: %v", err)
+	}
+
+	var checksum string
+	if err := db.QueryRowContext(ctx, `SELECT checksum FROM schema_migrations WHERE id = ?`, "0001_widgets.sql").Scan(&checksum); err != nil {
+		t.Fatalf("query checksum: %v", err)
+	}
+
+	if checksum != migrationChecksum(files["0001_widgets.sql"].Data) {
+		t.Fatalf("expected stored checksum to match file, got %q", checksum)
+	}
+}
+
+func TestApplyMigrationsFailsOnModifiedFile(t *testing.T) {
+	ctx := context.Background()
+	db := connectTestDB(t)
+	files := testMigrationFS()
+	logger := zerolog.New(io.Discard)
+
+	if err := applyMigrations(ctx, db, files, logger); err != nil {
+		t.Fatalf("first applyMigrations() returned error: %v", err)
+	}
+
+	files["0001_widgets.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE widgets (id TEXT PRIMARY KEY, name TEXT);`)}
+
+	err := applyMigrations(ctx, db, files, logger)
+	if !errors.Is(err, ErrMigrationChecksumMismatch) {
+		t.Fatalf("expected ErrMigrationChecksumMismatch, got %v", err)
+	}
+}
+
+func TestApplyMigrationsBackfillsLegacyTrackingTable(t *testing.T) {
+	ctx := context.Background()
+	db := connectTestDB(t)
+
+	// Simulate a database created before checksums were tracked.
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE schema_migrations (id TEXT PRIMARY KEY, applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE widgets (id TEXT PRIMARY KEY);
+		INSERT INTO schema_migrations (id) VALUES ('0001_widgets.sql');
+	`); err != nil {
+		t.Fatalf("seed legacy tracking table: %v", err)
+	}
+
+	files := testMigrationFS()
+	if err := applyMigrations(ctx, db, files, zerolog.New(io.Discard)); err != nil {
+		t.Fatalf("applyMigrations() returned error: %v", err)
+	}
+
+	var checksum string
+	if err := db.QueryRowContext(ctx, `SELECT checksum FROM schema_migrations WHERE id = ?`, "0001_widgets.sql").Scan(&checksum); err != nil {
+		t.Fatalf("query backfilled checksum: %v", err)
+	}
+
+	if checksum != migrationChecksum(files["0001_widgets.sql"].Data) {
+		t.Fatalf("expected backfilled checksum, got %q", checksum)
+	}
+}
+
+func TestMigrationStatuses(t *testing.T) {
+	ctx := context.Background()
+	db := connectTestDB(t)
+	files := testMigrationFS()
+
+	if err := applyMigrations(ctx, db, fstest.MapFS{"0001_widgets.sql": files["0001_widgets.sql"]}, zerolog.New(io.Discard)); err != nil {
+		t.Fatalf("applyMigrations() returned error: %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO schema_migrations (id, checksum) VALUES ('0000_removed.sql', 'abc')`); err != nil {
+		t.Fatalf("insert orphan tracking row: %v", err)
+	}
+
+	statuses, err := MigrationStatuses(ctx, db, files)
+	if err != nil {
+		t.Fatalf("MigrationStatuses() returned error: %v", err)
+	}
+
+	want := map[string]MigrationState{
+		"0000_removed.sql": MigrationMissing,
+		"0001_widgets.sql": MigrationApplied,
+		"0002_gadgets.sql": MigrationPending,
+	}
+
+	if len(statuses) != len(want) {
+		t.Fatalf("expected %d statuses, got %d", len(want), len(statuses))
+	}
+
+	for _, st := range statuses {
+		if st.State != want[st.ID] {
+			t.Fatalf("migration %s: expected state %s, got %s", st.ID, want[st.ID], st.State)
+		}
+	}
+
+	if !statuses[1].HasDown || statuses[2].HasDown {
+		t.Fatalf("expected only 0001_widgets.sql to have a down migration")
+	}
+
+	files["0001_widgets.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE widgets (id INTEGER);`)}
+
+	statuses, err = MigrationStatuses(ctx, db, files)
+	if err != nil {
+		t.Fatalf("MigrationStatuses() returned error: %v", err)
+	}
+
+	if statuses[1].State != MigrationModified {
+		t.Fatalf("expected modified state for edited migration, got %s", statuses[1].State)
+	}
+}
+
+func TestRollbackMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := connectTestDB(t)
+	logger := zerolog.New(io.Discard)
+	files := testMigrationFS()
+
+	if err := applyMigrations(ctx, db, files, logger); err != nil {
+		t.Fatalf("applyMigrations() returned error: %v", err)
+	}
+
+	// 0002 has no down script, so rolling back two steps must fail before
+	// either migration is touched.
+	if _, err := RollbackMigrations(ctx, db, files, 2, logger); !errors.Is(err, ErrMissingDownMigration) {
+		t.Fatalf("expected ErrMissingDownMigration, got %v", err)
+	}
+
+	files["0002_gadgets.down.sql"] = &fstest.MapFile{Data: []byte(`DROP TABLE gadgets;`)}
+
+	rolledBack, err := RollbackMigrations(ctx, db, files, 2, logger)
+	if err != nil {
+		t.Fatalf("RollbackMigrations() returned error: %v", err)
+	}
+
+	if len(rolledBack) != 2 || rolledBack[0] != "0002_gadgets.sql" || rolledBack[1] != "0001_widgets.sql" {
+		t.Fatalf("expected newest-first rollback, got %v", rolledBack)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count); err != nil {
+		t.Fatalf("count tracking rows: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected empty tracking table after rollback, got %d rows", count)
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE name = 'widgets')`).Scan(&exists); err != nil {
+		t.Fatalf("query sqlite_master: %v", err)
+	}
+
+	if exists {
+		t.Fatal("expected widgets table to be dropped")
+	}
+}
+
+func TestEmbeddedMigrationsRollBackCleanly(t *testing.T) {
+	ctx := context.Background()
+	databasePath := filepath.Join(t.TempDir(), "apollo.db")
+	logger := zerolog.New(io.Discard)
+
+	handle, err := Open(ctx, databasePath, logger)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = handle.Close()
+	})
+
+	statuses, err := MigrationStatuses(ctx, handle.DB, migrations.Files)
+	if err != nil {
+		t.Fatalf("MigrationStatuses() returned error: %v", err)
+	}
+
+	if _, err := RollbackMigrations(ctx, handle.DB, migrations.Files, len(statuses), logger); err != nil {
+		t.Fatalf("RollbackMigrations() returned error: %v", err)
+	}
+
+	var tables int
+	if err := handle.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'
+	`).Scan(&tables); err != nil {
+		t.Fatalf("count remaining tables: %v", err)
+	}
+
+	if tables != 0 {
+		t.Fatalf("expected all tables dropped after full rollback, %d remain", tables)
+	}
+}
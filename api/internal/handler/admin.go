@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+	"github.com/sean/apollo/api/internal/scheduler"
+)
+
+// TaskScheduler lists and triggers scheduled maintenance tasks.
+type TaskScheduler interface {
+	Tasks(ctx context.Context) ([]models.TaskStatus, error)
+	Trigger(name string) (*models.TaskRun, error)
+}
+
+// AdminHandler serves maintenance task endpoints.
+type AdminHandler struct {
+	sched TaskScheduler
+	runs  repository.TaskRunRepository
+}
+
+// NewAdminHandler creates an AdminHandler. A nil scheduler makes the task
+// endpoints answer 503; run history stays readable.
+func NewAdminHandler(sched TaskScheduler, runs repository.TaskRunRepository) *AdminHandler {
+	return &AdminHandler{sched: sched, runs: runs}
+}
+
+// RegisterRoutes mounts admin routes on the given router.
+func (h *AdminHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/admin/tasks", h.listTasks)
+	r.Get("/api/admin/tasks/{name}/runs", h.listRuns)
+	r.Post("/api/admin/tasks/{name}/run", h.runTask)
+}
+
+func (h *AdminHandler) listTasks(w http.ResponseWriter, r *http.Request) {
+	if h.sched == nil {
+		respond.Error(w, http.StatusServiceUnavailable, "scheduler is not running")
+
+		return
+	}
+
+	tasks, err := h.sched.Tasks(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list tasks")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, tasks)
+}
+
+func (h *AdminHandler) listRuns(w http.ResponseWriter, r *http.Request) {
+	params := models.ParsePagination(r)
+
+	list, err := h.runs.ListRuns(r.Context(), chi.URLParam(r, "name"), params)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list task runs")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, list)
+}
+
+func (h *AdminHandler) runTask(w http.ResponseWriter, r *http.Request) {
+	if h.sched == nil {
+		respond.Error(w, http.StatusServiceUnavailable, "scheduler is not running")
+
+		return
+	}
+
+	run, err := h.sched.Trigger(chi.URLParam(r, "name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownTask):
+			respond.Error(w, http.StatusNotFound, "task not found")
+		case errors.Is(err, scheduler.ErrTaskRunning):
+			respond.Error(w, http.StatusConflict, "task is already running")
+		default:
+			respond.Error(w, http.StatusInternalServerError, "failed to start task")
+		}
+
+		return
+	}
+
+	respond.JSON(w, http.StatusAccepted, run)
+}
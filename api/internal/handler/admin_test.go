@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/scheduler"
+)
+
+// mockScheduler is a test double for handler.TaskScheduler.
+type mockScheduler struct {
+	tasks      []models.TaskStatus
+	triggerErr error
+	triggered  string
+}
+
+func (m *mockScheduler) Tasks(_ context.Context) ([]models.TaskStatus, error) {
+	return m.tasks, nil
+}
+
+func (m *mockScheduler) Trigger(name string) (*models.TaskRun, error) {
+	if m.triggerErr != nil {
+		return nil, m.triggerErr
+	}
+
+	m.triggered = name
+
+	return &models.TaskRun{ID: 1, Task: name, Trigger: models.TaskTriggerManual, Status: models.TaskRunStatusRunning}, nil
+}
+
+// mockTaskRunRepo is a test double for repository.TaskRunRepository.
+type mockTaskRunRepo struct {
+	repository.TaskRunRepository
+	listedTask string
+}
+
+func (m *mockTaskRunRepo) ListRuns(_ context.Context, task string, params models.PaginationParams) (*models.PaginatedResponse[models.TaskRun], error) {
+	m.listedTask = task
+
+	return &models.PaginatedResponse[models.TaskRun]{Items: []models.TaskRun{}, Page: params.Page, PerPage: params.PerPage}, nil
+}
+
+func setupAdminRouter(sched handler.TaskScheduler, runs repository.TaskRunRepository) chi.Router {
+	r := chi.NewRouter()
+	handler.NewAdminHandler(sched, runs).RegisterRoutes(r)
+
+	return r
+}
+
+func TestListTasksHandler(t *testing.T) {
+	sched := &mockScheduler{tasks: []models.TaskStatus{{Name: "backup", Spec: "0 2 * * *"}}}
+	r := setupAdminRouter(sched, &mockTaskRunRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/tasks", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var tasks []map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&tasks); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(tasks) != 1 || tasks[0]["name"] != "backup" {
+		t.Fatalf("unexpected tasks %v", tasks)
+	}
+}
+
+func TestListTaskRunsHandler(t *testing.T) {
+	runs := &mockTaskRunRepo{}
+	r := setupAdminRouter(&mockScheduler{}, runs)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/tasks/backup/runs", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || runs.listedTask != "backup" {
+		t.Fatalf("expected 200 for backup runs, got %d (task %q)", rec.Code, runs.listedTask)
+	}
+}
+
+func TestRunTaskHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"started", nil, http.StatusAccepted},
+		{"unknown", fmt.Errorf("task x: %w", scheduler.ErrUnknownTask), http.StatusNotFound},
+		{"running", fmt.Errorf("task x: %w", scheduler.ErrTaskRunning), http.StatusConflict},
+		{"failure", fmt.Errorf("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched := &mockScheduler{triggerErr: tt.err}
+			r := setupAdminRouter(sched, &mockTaskRunRepo{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/tasks/backup/run", nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+
+			if tt.err == nil && sched.triggered != "backup" {
+				t.Fatalf("expected backup to be triggered, got %q", sched.triggered)
+			}
+		})
+	}
+}
+
+func TestAdminHandlerWithoutScheduler(t *testing.T) {
+	r := setupAdminRouter(nil, &mockTaskRunRepo{})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/admin/tasks", nil),
+		httptest.NewRequest(http.MethodPost, "/api/admin/tasks/backup/run", nil),
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s %s: expected 503, got %d", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// redirectToCanonical answers a lookup made by alias or unnormalized slug
+// with a 301 to the canonical URL: prefix, the escaped canonical ID, and
+// suffix, keeping the query string.
+func redirectToCanonical(w http.ResponseWriter, r *http.Request, requestedID, canonicalID, prefix, suffix string) {
+	location := prefix + url.PathEscape(canonicalID) + suffix
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+
+	w.Header().Set("Location", location)
+	respond.JSON(w, http.StatusMovedPermanently, models.AliasRedirect{
+		RequestedID: requestedID,
+		ID:          canonicalID,
+		Location:    location,
+	})
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/anki"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// maxImportSize caps the Anki package an import reads. Packages carry their
+// media, so they are far larger than other request bodies.
+const maxImportSize = 512 * 1024 * 1024 // 512 MB
+
+// exportFormat is a flashcard file format: its extension and how notes are
+// written in it.
+type exportFormat struct {
+	ext         string
+	contentType string
+	write       func(ctx context.Context, w io.Writer, notes []anki.Note) error
+}
+
+var (
+	formatAPKG = exportFormat{"apkg", "application/octet-stream", func(ctx context.Context, w io.Writer, notes []anki.Note) error {
+		return anki.WriteAPKG(ctx, w, notes, time.Now())
+	}}
+	formatCSV = exportFormat{"csv", "text/csv; charset=utf-8", func(_ context.Context, w io.Writer, notes []anki.Note) error {
+		return anki.WriteDelimited(w, notes, ',')
+	}}
+	formatTSV = exportFormat{"tsv", "text/tab-separated-values; charset=utf-8", func(_ context.Context, w io.Writer, notes []anki.Note) error {
+		return anki.WriteDelimited(w, notes, '\t')
+	}}
+)
+
+// AnkiHandler serves flashcard exports, as Anki decks and CSV or TSV files
+// of one topic or of the concepts a filter selects, and the import of Anki
+// review history.
+type AnkiHandler struct {
+	repo             repository.AnkiRepository
+	defaultScheduler string
+	masteryDays      int
+}
+
+// NewAnkiHandler creates an AnkiHandler. Imports replay reviews with the
+// scheduler chosen through PUT /api/review/scheduler, or defaultScheduler
+// until one is.
+func NewAnkiHandler(repo repository.AnkiRepository, defaultScheduler string, masteryDays int) *AnkiHandler {
+	return &AnkiHandler{repo: repo, defaultScheduler: defaultScheduler, masteryDays: masteryDays}
+}
+
+// RegisterRoutes mounts export routes on the given router.
+func (h *AnkiHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/topics/{id}/anki.apkg", h.exportTopic(formatAPKG))
+	r.Get("/api/topics/{id}/flashcards.csv", h.exportTopic(formatCSV))
+	r.Get("/api/topics/{id}/flashcards.tsv", h.exportTopic(formatTSV))
+	r.Get("/api/export/anki.apkg", h.exportFiltered(formatAPKG))
+	r.Get("/api/export/flashcards.csv", h.exportFiltered(formatCSV))
+	r.Get("/api/export/flashcards.tsv", h.exportFiltered(formatTSV))
+	r.Post("/api/anki/import", h.importReviews)
+}
+
+// exportTopic exports the concepts a topic defines, in a file named after
+// the topic. An unknown topic returns 404.
+func (h *AnkiHandler) exportTopic(format exportFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		h.export(w, r, format, models.FlashcardFilter{TopicIDs: []string{id}}, id)
+	}
+}
+
+// exportFiltered exports the concepts selected by the repeatable or
+// comma-separated topic, concept, and tag query parameters, or every
+// concept without them. An unknown topic or concept returns 404.
+func (h *AnkiHandler) exportFiltered(format exportFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := models.FlashcardFilter{
+			TopicIDs:   queryList(r, "topic"),
+			ConceptIDs: queryList(r, "concept"),
+			Tags:       queryList(r, "tag"),
+		}
+
+		h.export(w, r, format, filter, "apollo")
+	}
+}
+
+func (h *AnkiHandler) export(w http.ResponseWriter, r *http.Request, format exportFormat, filter models.FlashcardFilter, name string) {
+	cards, err := h.repo.ListFlashcards(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := format.write(r.Context(), &buf, anki.Notes(cards)); err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to export flashcards")
+
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + "." + format.ext}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// queryList returns the values of a repeatable query parameter, each of
+// which may also be a comma-separated list, without empty entries.
+func queryList(r *http.Request, key string) []string {
+	var values []string
+
+	for _, v := range r.URL.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+
+	return values
+}
+
+// importReviews replays the review history of the Anki package in the
+// request body into the concepts its cards match and reports the matches.
+// With ?dry_run=true nothing is stored. A body that is not a readable
+// package returns 400.
+func (h *AnkiHandler) importReviews(w http.ResponseWriter, r *http.Request) {
+	f, err := os.CreateTemp("", "apollo-import-*")
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to import package")
+
+		return
+	}
+
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	size, err := io.Copy(f, http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respond.Error(w, http.StatusRequestEntityTooLarge, "package exceeds 512 MB")
+
+			return
+		}
+
+		respond.Error(w, http.StatusBadRequest, "failed to read package")
+
+		return
+	}
+
+	cards, err := anki.ReadCards(r.Context(), f, size)
+	if errors.Is(err, anki.ErrInvalidPackage) || errors.Is(err, anki.ErrUnsupportedPackage) {
+		respond.Error(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to read package")
+
+		return
+	}
+
+	scheduler, err := h.activeScheduler(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to load scheduler")
+
+		return
+	}
+
+	report, err := h.repo.ImportReviews(r.Context(), cards, scheduler.Review, r.URL.Query().Get("dry_run") == "true")
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, report)
+}
+
+// activeScheduler returns the scheduler reviews currently use.
+func (h *AnkiHandler) activeScheduler(ctx context.Context) (review.Scheduler, error) {
+	name, err := h.repo.GetSchedulerPreference(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = h.defaultScheduler
+	}
+
+	return review.New(name, h.masteryDays)
+}
@@ -0,0 +1,153 @@
+package handler_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/anki"
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+// mockAnkiRepo is a test double for repository.AnkiRepository.
+type mockAnkiRepo struct {
+	filter    models.FlashcardFilter
+	cards     []models.AnkiCard
+	dryRun    bool
+	rated     models.ConceptRetention
+	returnErr error
+}
+
+func (m *mockAnkiRepo) ListFlashcards(_ context.Context, filter models.FlashcardFilter) ([]models.Flashcard, error) {
+	m.filter = filter
+
+	return []models.Flashcard{{ConceptID: "c1", Front: "Channel", Back: "A typed pipe", TopicTitle: "Go"}}, m.returnErr
+}
+
+func (m *mockAnkiRepo) ImportReviews(_ context.Context, cards []models.AnkiCard, rate repository.RateFunc, dryRun bool) (*models.AnkiImportReport, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	m.cards, m.dryRun = cards, dryRun
+
+	rated, err := rate(models.ConceptRetention{EaseFactor: 2.5}, models.RatingGood, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	m.rated = rated
+
+	return &models.AnkiImportReport{DryRun: dryRun, Cards: len(cards)}, nil
+}
+
+func (m *mockAnkiRepo) GetSchedulerPreference(context.Context) (string, error) {
+	return "", nil
+}
+
+func TestExportHandlers(t *testing.T) {
+	repo := &mockAnkiRepo{}
+
+	r := chi.NewRouter()
+	handler.NewAnkiHandler(repo, "sm2", 21).RegisterRoutes(r)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	rec := get("/api/topics/go/anki.apkg")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Disposition") != `attachment; filename=go.apkg` {
+		t.Fatalf("GET anki.apkg = %d %q", rec.Code, rec.Header().Get("Content-Disposition"))
+	}
+
+	if _, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len())); err != nil {
+		t.Fatalf("GET anki.apkg body is not a package: %v", err)
+	}
+
+	if !slices.Equal(repo.filter.TopicIDs, []string{"go"}) {
+		t.Fatalf("topic export filter = %+v, want topic go", repo.filter)
+	}
+
+	rec = get("/api/export/flashcards.csv?topic=go,rust&topic=c&concept=c1&tag=languages")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" ||
+		rec.Body.String() != "front,back,tags,deck,guid\nChannel,A typed pipe,,Apollo::Go,apollo:c1\n" {
+		t.Fatalf("GET flashcards.csv = %d %q %q", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	want := models.FlashcardFilter{TopicIDs: []string{"go", "rust", "c"}, ConceptIDs: []string{"c1"}, Tags: []string{"languages"}}
+	if !slices.Equal(repo.filter.TopicIDs, want.TopicIDs) || !slices.Equal(repo.filter.ConceptIDs, want.ConceptIDs) ||
+		!slices.Equal(repo.filter.Tags, want.Tags) {
+		t.Fatalf("filtered export filter = %+v, want %+v", repo.filter, want)
+	}
+
+	if rec = get("/api/export/flashcards.tsv"); rec.Code != http.StatusOK || !bytes.HasPrefix(rec.Body.Bytes(), []byte("front\tback")) {
+		t.Fatalf("GET flashcards.tsv = %d %q", rec.Code, rec.Body.String())
+	}
+
+	repo.returnErr = fmt.Errorf("topic missing: %w", repository.ErrNotFound)
+
+	if rec = get("/api/topics/missing/flashcards.csv"); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET unknown topic export = %d, want 404", rec.Code)
+	}
+}
+
+func TestImportHandler(t *testing.T) {
+	repo := &mockAnkiRepo{}
+
+	r := chi.NewRouter()
+	handler.NewAnkiHandler(repo, "sm2", 21).RegisterRoutes(r)
+
+	var pkg bytes.Buffer
+	notes := []anki.Note{{GUID: anki.NoteGUID("c1"), Deck: anki.RootDeck, Front: "Channel", Back: "A typed pipe"}}
+
+	if err := anki.WriteAPKG(context.Background(), &pkg, notes, time.Now()); err != nil {
+		t.Fatalf("WriteAPKG: %v", err)
+	}
+
+	post := func(path string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+
+		return rec
+	}
+
+	rec := post("/api/anki/import?dry_run=true", pkg.Bytes())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/anki/import = %d %s", rec.Code, rec.Body.String())
+	}
+
+	if !repo.dryRun || len(repo.cards) != 1 || repo.cards[0].ConceptID != "c1" || repo.cards[0].Front != "Channel" {
+		t.Fatalf("import got dry run %v, cards %+v", repo.dryRun, repo.cards)
+	}
+
+	if repo.rated.IntervalDays <= 0 {
+		t.Fatalf("import rated with no scheduler: %+v", repo.rated)
+	}
+
+	if rec = post("/api/anki/import", pkg.Bytes()); rec.Code != http.StatusOK || repo.dryRun {
+		t.Fatalf("POST /api/anki/import = %d, dry run %v", rec.Code, repo.dryRun)
+	}
+
+	if rec = post("/api/anki/import", []byte("not a zip")); rec.Code != http.StatusBadRequest {
+		t.Fatalf("POST invalid package = %d, want 400", rec.Code)
+	}
+
+	repo.returnErr = fmt.Errorf("database is locked")
+
+	if rec = post("/api/anki/import", pkg.Bytes()); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("POST failing import = %d, want 500", rec.Code)
+	}
+}
@@ -0,0 +1,434 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// maxBatchOperations caps the number of operations in one POST /api/batch.
+const maxBatchOperations = 1000
+
+// batchStep runs one prepared batch operation against a transaction-bound
+// repository.
+type batchStep func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error)
+
+// batch applies an ordered list of writes in one transaction. Every
+// operation is decoded and validated before the transaction starts; the
+// first failure, during validation or execution, rolls back the whole batch
+// and is reported with its index.
+func (h *WriteHandler) batch(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.Operations) == 0 {
+		respond.Error(w, http.StatusBadRequest, "operations is required")
+
+		return
+	}
+
+	if len(req.Operations) > maxBatchOperations {
+		respond.Error(w, http.StatusBadRequest, fmt.Sprintf("at most %d operations per batch", maxBatchOperations))
+
+		return
+	}
+
+	steps := make([]batchStep, len(req.Operations))
+
+	for i, op := range req.Operations {
+		step, err := prepareBatchOperation(op)
+		if err != nil {
+			writeBatchError(w, i, err)
+
+			return
+		}
+
+		steps[i] = step
+	}
+
+	results := make([]models.BatchResult, 0, len(steps))
+	failedAt := -1
+
+	err := h.repo.Transaction(r.Context(), func(tx repository.WriteRepository) error {
+		for i, step := range steps {
+			result, err := step(r.Context(), tx)
+			if err != nil {
+				failedAt = i
+
+				return err
+			}
+
+			result.Index = i
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		writeBatchError(w, failedAt, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, models.BatchResponse{Results: results})
+}
+
+// writeBatchError reports a failed batch with the status the single-entity
+// endpoint would have returned. index is -1 when the failure happened
+// outside any operation, such as on commit.
+func writeBatchError(w http.ResponseWriter, index int, err error) {
+	status, msg := errorStatus(err)
+	body := models.BatchError{Error: msg, Index: index}
+
+	var ie *inputError
+	if errors.As(err, &ie) {
+		body.Violations = ie.violations
+	}
+
+	respond.JSON(w, status, body)
+}
+
+func prepareBatchOperation(op models.BatchOperation) (batchStep, error) {
+	switch op.Op {
+	case models.BatchOpCreate, models.BatchOpUpdate, models.BatchOpDelete:
+	default:
+		return nil, &inputError{msg: fmt.Sprintf("unknown op %q", op.Op)}
+	}
+
+	switch op.Entity {
+	case models.BatchEntityTopic:
+		return prepareTopicOperation(op)
+	case models.BatchEntityModule:
+		return prepareModuleOperation(op)
+	case models.BatchEntityLesson:
+		return prepareLessonOperation(op)
+	case models.BatchEntityConcept:
+		return prepareConceptOperation(op)
+	case models.BatchEntityConceptReference:
+		return prepareConceptReferenceOperation(op)
+	case models.BatchEntityPrerequisite:
+		return preparePrerequisiteOperation(op)
+	case models.BatchEntityRelation:
+		return prepareRelationOperation(op)
+	}
+
+	return nil, &inputError{msg: fmt.Sprintf("unknown entity %q", op.Entity)}
+}
+
+// decodeBatchData decodes an operation's data into T and validates it.
+func decodeBatchData[T any](op models.BatchOperation, validate func(*T) error) (*T, error) {
+	if len(op.Data) == 0 {
+		return nil, &inputError{msg: "data is required"}
+	}
+
+	var in T
+	if err := json.Unmarshal(op.Data, &in); err != nil {
+		return nil, &inputError{msg: "invalid data: " + err.Error()}
+	}
+
+	if validate != nil {
+		if err := validate(&in); err != nil {
+			return nil, err
+		}
+	}
+
+	return &in, nil
+}
+
+func requireBatchID(op models.BatchOperation) error {
+	if op.ID == "" {
+		return &inputError{msg: "id is required for " + op.Op}
+	}
+
+	return nil
+}
+
+func prepareTopicOperation(op models.BatchOperation) (batchStep, error) {
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, ID: op.ID}
+
+	if op.Op == models.BatchOpCreate {
+		in, err := decodeBatchData(op, validateTopicCreate)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID, result.Data = in.ID, in
+
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			_, err := repo.CreateTopic(ctx, *in)
+
+			return result, err
+		}, nil
+	}
+
+	if err := requireBatchID(op); err != nil {
+		return nil, err
+	}
+
+	if op.Op == models.BatchOpDelete {
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.DeleteTopic(ctx, op.ID, op.Force)
+		}, nil
+	}
+
+	in, err := decodeBatchData(op, validateTopicUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	in.ID = op.ID
+	result.Data = in
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		_, err := repo.UpdateTopic(ctx, op.ID, 0, *in)
+
+		return result, err
+	}, nil
+}
+
+func prepareModuleOperation(op models.BatchOperation) (batchStep, error) {
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, ID: op.ID}
+
+	if op.Op == models.BatchOpCreate {
+		in, err := decodeBatchData(op, validateModuleCreate)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID, result.Data = in.ID, in
+
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			_, err := repo.CreateModule(ctx, *in)
+
+			return result, err
+		}, nil
+	}
+
+	if err := requireBatchID(op); err != nil {
+		return nil, err
+	}
+
+	if op.Op == models.BatchOpDelete {
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.DeleteModule(ctx, op.ID, op.Force)
+		}, nil
+	}
+
+	in, err := decodeBatchData(op, validateModuleUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	in.ID = op.ID
+	result.Data = in
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		_, err := repo.UpdateModule(ctx, op.ID, 0, *in)
+
+		return result, err
+	}, nil
+}
+
+func prepareLessonOperation(op models.BatchOperation) (batchStep, error) {
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, ID: op.ID}
+
+	if op.Op == models.BatchOpCreate {
+		in, err := decodeBatchData(op, validateLessonCreate)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID, result.Data = in.ID, in
+
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			_, err := repo.CreateLesson(ctx, *in)
+
+			return result, err
+		}, nil
+	}
+
+	if err := requireBatchID(op); err != nil {
+		return nil, err
+	}
+
+	if op.Op == models.BatchOpDelete {
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.DeleteLesson(ctx, op.ID, op.Force)
+		}, nil
+	}
+
+	in, err := decodeBatchData(op, validateLessonUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	in.ID = op.ID
+	result.Data = in
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		_, err := repo.UpdateLesson(ctx, op.ID, 0, *in)
+
+		return result, err
+	}, nil
+}
+
+func prepareConceptOperation(op models.BatchOperation) (batchStep, error) {
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, ID: op.ID}
+
+	if op.Op == models.BatchOpCreate {
+		in, err := decodeBatchData(op, validateConceptCreate)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID, result.Data = in.ID, in
+
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			_, err := repo.CreateConcept(ctx, *in)
+
+			return result, err
+		}, nil
+	}
+
+	if err := requireBatchID(op); err != nil {
+		return nil, err
+	}
+
+	if op.Op == models.BatchOpDelete {
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.DeleteConcept(ctx, op.ID, op.Force)
+		}, nil
+	}
+
+	in, err := decodeBatchData(op, validateConceptUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	in.ID = op.ID
+	result.Data = in
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		_, err := repo.UpdateConcept(ctx, op.ID, 0, *in)
+
+		return result, err
+	}, nil
+}
+
+// Concept references are keyed by ID (the concept) and data.lesson_id for
+// every op, so deletes carry data too.
+func prepareConceptReferenceOperation(op models.BatchOperation) (batchStep, error) {
+	if err := requireBatchID(op); err != nil {
+		return nil, err
+	}
+
+	in, err := decodeBatchData(op, validateConceptReferenceCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, ID: op.ID, Data: in}
+
+	switch op.Op {
+	case models.BatchOpCreate:
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.CreateConceptReference(ctx, op.ID, *in)
+		}, nil
+	case models.BatchOpUpdate:
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.UpdateConceptReference(ctx, op.ID, in.LessonID, *in)
+		}, nil
+	}
+
+	result.Data = nil
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		return result, repo.DeleteConceptReference(ctx, op.ID, in.LessonID)
+	}, nil
+}
+
+func validatePrerequisiteKey(in *models.PrerequisiteInput) error {
+	if in.TopicID == "" || in.PrerequisiteTopicID == "" {
+		return &inputError{msg: "topic_id and prerequisite_topic_id are required"}
+	}
+
+	return nil
+}
+
+func preparePrerequisiteOperation(op models.BatchOperation) (batchStep, error) {
+	validate := validatePrerequisiteCreate
+	if op.Op == models.BatchOpDelete {
+		validate = validatePrerequisiteKey
+	}
+
+	in, err := decodeBatchData(op, validate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, Data: in}
+
+	switch op.Op {
+	case models.BatchOpCreate:
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.CreatePrerequisite(ctx, *in)
+		}, nil
+	case models.BatchOpUpdate:
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.UpdatePrerequisite(ctx, *in)
+		}, nil
+	}
+
+	result.Data = nil
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		return result, repo.DeletePrerequisite(ctx, in.TopicID, in.PrerequisiteTopicID)
+	}, nil
+}
+
+func validateRelationKey(in *models.RelationInput) error {
+	if in.TopicA == "" || in.TopicB == "" {
+		return &inputError{msg: "topic_a and topic_b are required"}
+	}
+
+	return nil
+}
+
+func prepareRelationOperation(op models.BatchOperation) (batchStep, error) {
+	validate := validateRelationCreate
+	if op.Op == models.BatchOpDelete {
+		validate = validateRelationKey
+	}
+
+	in, err := decodeBatchData(op, validate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.BatchResult{Op: op.Op, Entity: op.Entity, Data: in}
+
+	switch op.Op {
+	case models.BatchOpCreate:
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.CreateRelation(ctx, *in)
+		}, nil
+	case models.BatchOpUpdate:
+		return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+			return result, repo.UpdateRelation(ctx, *in)
+		}, nil
+	}
+
+	result.Data = nil
+
+	return func(ctx context.Context, repo repository.WriteRepository) (models.BatchResult, error) {
+		return result, repo.DeleteRelation(ctx, in.TopicA, in.TopicB)
+	}, nil
+}
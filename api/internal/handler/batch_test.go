@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func serveBatch(t *testing.T, repo *mockWriteRepo, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := chi.NewRouter()
+	handler.NewWriteHandler(repo).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestBatchHandlerReturnsPerOperationResults(t *testing.T) {
+	rec := serveBatch(t, &mockWriteRepo{}, `{"operations":[
+		{"op":"create","entity":"topic","data":{"id":"t1","title":"T","status":"draft"}},
+		{"op":"update","entity":"module","id":"m1","data":{"topic_id":"t1","title":"M"}},
+		{"op":"create","entity":"concept_reference","id":"c1","data":{"lesson_id":"l1"}},
+		{"op":"delete","entity":"relation","data":{"topic_a":"t1","topic_b":"t2"}}
+	]}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp models.BatchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(resp.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(resp.Results))
+	}
+
+	if resp.Results[0].ID != "t1" || resp.Results[1].ID != "m1" || resp.Results[3].Index != 3 {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+
+	if resp.Results[3].Data != nil {
+		t.Fatalf("expected no data for delete, got %v", resp.Results[3].Data)
+	}
+}
+
+func TestBatchHandlerReportsFailingIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		repo      *mockWriteRepo
+		body      string
+		wantCode  int
+		wantIndex int
+	}{
+		{
+			name:      "unknown entity",
+			repo:      &mockWriteRepo{},
+			body:      `{"operations":[{"op":"delete","entity":"topic","id":"t1"},{"op":"create","entity":"widget","data":{}}]}`,
+			wantCode:  http.StatusBadRequest,
+			wantIndex: 1,
+		},
+		{
+			name:      "missing required field",
+			repo:      &mockWriteRepo{},
+			body:      `{"operations":[{"op":"create","entity":"lesson","data":{"id":"l1","module_id":"m1","title":"L"}}]}`,
+			wantCode:  http.StatusBadRequest,
+			wantIndex: 0,
+		},
+		{
+			name:      "update without id",
+			repo:      &mockWriteRepo{},
+			body:      `{"operations":[{"op":"update","entity":"concept","data":{"name":"N","definition":"D"}}]}`,
+			wantCode:  http.StatusBadRequest,
+			wantIndex: 0,
+		},
+		{
+			name:      "repository error",
+			repo:      &mockWriteRepo{returnErr: repository.ErrFKViolation},
+			body:      `{"operations":[{"op":"create","entity":"module","data":{"id":"m1","topic_id":"nope","title":"M"}}]}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantIndex: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		rec := serveBatch(t, tt.repo, tt.body)
+
+		if rec.Code != tt.wantCode {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.wantCode, rec.Code, rec.Body.String())
+		}
+
+		var resp models.BatchError
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: decode: %v", tt.name, err)
+		}
+
+		if resp.Index != tt.wantIndex || resp.Error == "" {
+			t.Fatalf("%s: expected index %d with message, got %+v", tt.name, tt.wantIndex, resp)
+		}
+	}
+}
+
+func TestBatchHandlerRejectsEmptyBatch(t *testing.T) {
+	rec := serveBatch(t, &mockWriteRepo{}, `{"operations":[]}`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
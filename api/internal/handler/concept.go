@@ -59,6 +59,13 @@ func (h *ConceptHandler) getConceptByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if concept.ID != id {
+		redirectToCanonical(w, r, id, concept.ID, "/api/concepts/", "")
+
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(concept.Revision))
 	respond.JSON(w, http.StatusOK, concept)
 }
 
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// ConflictHandler serves the concept conflict review endpoints.
+type ConflictHandler struct {
+	repo repository.ConceptConflictRepository
+}
+
+// NewConflictHandler creates a ConflictHandler.
+func NewConflictHandler(repo repository.ConceptConflictRepository) *ConflictHandler {
+	return &ConflictHandler{repo: repo}
+}
+
+// RegisterRoutes mounts concept conflict routes on the given router.
+func (h *ConflictHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/concepts/conflicts", h.listConflicts)
+	r.Post("/api/concepts/{id}/resolve", h.resolveConflict)
+}
+
+func (h *ConflictHandler) listConflicts(w http.ResponseWriter, r *http.Request) {
+	conflicts, err := h.repo.ListConflicts(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list concept conflicts")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, conflicts)
+}
+
+// resolveConflict settles a conflicted concept. A concept that is not in
+// conflict returns 409; an unknown concept or candidate returns 404.
+func (h *ConflictHandler) resolveConflict(w http.ResponseWriter, r *http.Request) {
+	var input models.ResolveConflictInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if len(input.CandidateIDs) == 0 && input.Definition == "" {
+		respond.Error(w, http.StatusBadRequest, "candidate_ids or definition is required")
+
+		return
+	}
+
+	resolution, err := h.repo.ResolveConflict(r.Context(), chi.URLParam(r, "id"), input)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, resolution)
+}
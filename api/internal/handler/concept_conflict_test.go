@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+// mockConflictRepo is a test double for repository.ConceptConflictRepository.
+type mockConflictRepo struct {
+	conflicts []models.ConceptConflict
+	returnErr error
+}
+
+func (m *mockConflictRepo) ListConflicts(_ context.Context) ([]models.ConceptConflict, error) {
+	return m.conflicts, m.returnErr
+}
+
+func (m *mockConflictRepo) ResolveConflict(_ context.Context, conceptID string, input models.ResolveConflictInput) (*models.ConceptResolution, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.ConceptResolution{ConceptID: conceptID, Definition: input.Definition}, nil
+}
+
+func TestListConflictsHandler(t *testing.T) {
+	repo := &mockConflictRepo{conflicts: []models.ConceptConflict{{
+		ConceptID:  "c1",
+		Candidates: []models.ConceptCandidate{{ID: 1, SourceLessonID: "l1"}, {ID: 2, SourceLessonID: "l2"}},
+	}}}
+
+	r := chi.NewRouter()
+	handler.NewConflictHandler(repo).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/concepts/conflicts", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var conflicts []models.ConceptConflict
+	if err := json.NewDecoder(rec.Body).Decode(&conflicts); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(conflicts) != 1 || len(conflicts[0].Candidates) != 2 {
+		t.Fatalf("unexpected conflicts %+v", conflicts)
+	}
+}
+
+func TestResolveConflictHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *mockConflictRepo
+		body string
+		want int
+	}{
+		{"pick candidate", &mockConflictRepo{}, `{"candidate_ids":[2]}`, http.StatusOK},
+		{"custom definition", &mockConflictRepo{}, `{"definition":"Custom"}`, http.StatusOK},
+		{"nothing chosen", &mockConflictRepo{}, `{}`, http.StatusBadRequest},
+		{"no conflict", &mockConflictRepo{returnErr: repository.ErrNoConflict}, `{"definition":"x"}`, http.StatusConflict},
+		{"unknown concept", &mockConflictRepo{returnErr: repository.ErrNotFound}, `{"definition":"x"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		r := chi.NewRouter()
+		handler.NewConflictHandler(tt.repo).RegisterRoutes(r)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/concepts/c1/resolve", strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
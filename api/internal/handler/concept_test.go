@@ -252,3 +252,16 @@ func TestGetConceptReferencesErrorHandler(t *testing.T) {
 		t.Fatalf("expected 500, got %d", rec.Code)
 	}
 }
+
+func TestGetConceptByAliasRedirects(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewConceptHandler(&mockConceptRepo{detail: &models.ConceptDetail{ID: "linux-bridge"}}).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/concepts/br0", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/api/concepts/linux-bridge" {
+		t.Fatalf("expected 301 to /api/concepts/linux-bridge, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
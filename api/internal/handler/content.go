@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/schema"
+)
+
+// validateLessonSchema checks a lesson's JSON columns against the
+// curriculum schema definitions before they are written, so the frontend
+// never receives a section type or exercise shape it cannot render. Every
+// field is checked and all violations are reported together, with paths
+// rooted at the request body (e.g. /content/sections/2/type).
+func validateLessonSchema(in *models.LessonInput) error {
+	var violations []schema.Violation
+
+	if err := collectSchemaViolations(&violations, "/content", in.Content, schema.ValidateDefinition, schema.DefLessonContent); err != nil {
+		return err
+	}
+
+	lists := []struct {
+		field string
+		raw   json.RawMessage
+		def   string
+	}{
+		{"/examples", in.Examples, schema.DefExample},
+		{"/exercises", in.Exercises, schema.DefExercise},
+		{"/review_questions", in.ReviewQuestions, schema.DefReviewQuestion},
+	}
+
+	for _, l := range lists {
+		if err := collectSchemaViolations(&violations, l.field, l.raw, schema.ValidateDefinitionList, l.def); err != nil {
+			return err
+		}
+	}
+
+	return violationsError("lesson", violations)
+}
+
+// validateModuleSchema checks a module's assessment the same way.
+func validateModuleSchema(in *models.ModuleInput) error {
+	var violations []schema.Violation
+
+	if err := collectSchemaViolations(&violations, "/assessment", in.Assessment, schema.ValidateDefinition, schema.DefAssessment); err != nil {
+		return err
+	}
+
+	return violationsError("module", violations)
+}
+
+// collectSchemaViolations validates raw when present and appends its
+// violations under field. Errors other than schema violations are returned.
+func collectSchemaViolations(
+	violations *[]schema.Violation, field string, raw json.RawMessage,
+	validate func(def string, data []byte) error, def string,
+) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	err := validate(def, raw)
+	if err == nil {
+		return nil
+	}
+
+	var vErr *schema.ViolationsError
+	if !errors.As(err, &vErr) {
+		return err
+	}
+
+	for _, v := range vErr.Violations {
+		*violations = append(*violations, schema.Violation{Path: field + v.Path, Message: v.Message})
+	}
+
+	return nil
+}
+
+func violationsError(entity string, violations []schema.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &inputError{msg: entity + " does not match the curriculum schema", violations: violations}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// formatETag returns the strong entity tag for a row revision. Topics,
+// modules, lessons, and concepts expose their revision this way, and PUT and
+// PATCH on them require If-Match: 428 when the header is missing and 412
+// when it no longer matches the stored revision.
+func formatETag(revision int) string {
+	return `"` + strconv.Itoa(revision) + `"`
+}
+
+// requireIfMatch parses the If-Match header into the revision the client last
+// saw. "*" matches any revision and yields 0. On failure it writes the error
+// response and returns false.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		respond.Error(w, http.StatusPreconditionRequired, "If-Match header is required")
+
+		return 0, false
+	}
+
+	if header == "*" {
+		return 0, true
+	}
+
+	// If-Match uses strong comparison, so weak tags never match.
+	if strings.HasPrefix(header, "W/") {
+		respond.Error(w, http.StatusPreconditionFailed, "If-Match does not match the current revision")
+
+		return 0, false
+	}
+
+	tag := header
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		respond.Error(w, http.StatusBadRequest, "If-Match must be a single entity tag or *")
+
+		return 0, false
+	}
+
+	revision, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || revision < 1 {
+		// A well-formed tag we never issued cannot match.
+		respond.Error(w, http.StatusPreconditionFailed, "If-Match does not match the current revision")
+
+		return 0, false
+	}
+
+	return revision, true
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// HistoryHandler serves the curriculum audit log.
+type HistoryHandler struct {
+	repo repository.AuditRepository
+}
+
+// NewHistoryHandler creates a HistoryHandler.
+func NewHistoryHandler(repo repository.AuditRepository) *HistoryHandler {
+	return &HistoryHandler{repo: repo}
+}
+
+// RegisterRoutes mounts history routes on the given router.
+func (h *HistoryHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/history", h.listHistory)
+	r.Post("/api/history/{id}/revert", h.revert)
+}
+
+func (h *HistoryHandler) listHistory(w http.ResponseWriter, r *http.Request) {
+	filter := models.HistoryFilter{
+		EntityType: r.URL.Query().Get("entity_type"),
+		EntityID:   r.URL.Query().Get("entity_id"),
+	}
+
+	params := models.ParsePagination(r)
+
+	history, err := h.repo.ListHistory(r.Context(), filter, params)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list history")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, history)
+}
+
+// revert restores the row a history record changed to its state before that
+// change. It returns the new record, or 204 when the row already matched.
+// Deleting a row with progress needs ?force=true, as with DELETE.
+func (h *HistoryHandler) revert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, "history record id must be an integer")
+
+		return
+	}
+
+	record, err := h.repo.Revert(r.Context(), id, forceParam(r))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if record == nil {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, record)
+}
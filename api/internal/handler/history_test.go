@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+// mockAuditRepo is a test double for repository.AuditRepository.
+type mockAuditRepo struct {
+	records    []models.AuditRecord
+	lastFilter models.HistoryFilter
+	lastForce  bool
+	reverted   *models.AuditRecord
+	returnErr  error
+}
+
+func (m *mockAuditRepo) ListHistory(_ context.Context, filter models.HistoryFilter, params models.PaginationParams) (*models.PaginatedResponse[models.AuditRecord], error) {
+	m.lastFilter = filter
+
+	return &models.PaginatedResponse[models.AuditRecord]{
+		Items: m.records, Total: len(m.records), Page: params.Page, PerPage: params.PerPage,
+	}, m.returnErr
+}
+
+func (m *mockAuditRepo) Revert(_ context.Context, _ int64, force bool) (*models.AuditRecord, error) {
+	m.lastForce = force
+
+	return m.reverted, m.returnErr
+}
+
+func TestListHistoryHandlerPassesFilter(t *testing.T) {
+	repo := &mockAuditRepo{records: []models.AuditRecord{{ID: 1, EntityType: "topic", EntityID: "t1", Action: "create", Actor: "cli"}}}
+
+	r := chi.NewRouter()
+	handler.NewHistoryHandler(repo).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/history?entity_type=topic&entity_id=t1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	if repo.lastFilter.EntityType != "topic" || repo.lastFilter.EntityID != "t1" {
+		t.Fatalf("unexpected filter %+v", repo.lastFilter)
+	}
+
+	var page models.PaginatedResponse[models.AuditRecord]
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if page.Total != 1 || page.Items[0].Actor != "cli" {
+		t.Fatalf("unexpected page %+v", page)
+	}
+}
+
+func TestRevertHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		repo       *mockAuditRepo
+		wantStatus int
+		wantForce  bool
+	}{
+		{name: "reverted", path: "/api/history/3/revert", repo: &mockAuditRepo{reverted: &models.AuditRecord{ID: 4}}, wantStatus: http.StatusOK},
+		{name: "already matching", path: "/api/history/3/revert", repo: &mockAuditRepo{}, wantStatus: http.StatusNoContent},
+		{name: "forced", path: "/api/history/3/revert?force=true", repo: &mockAuditRepo{reverted: &models.AuditRecord{ID: 4}}, wantStatus: http.StatusOK, wantForce: true},
+		{name: "bad id", path: "/api/history/abc/revert", repo: &mockAuditRepo{}, wantStatus: http.StatusBadRequest},
+		{name: "unknown record", path: "/api/history/9/revert", repo: &mockAuditRepo{returnErr: fmt.Errorf("x: %w", repository.ErrNotFound)}, wantStatus: http.StatusNotFound},
+		{name: "has progress", path: "/api/history/3/revert", repo: &mockAuditRepo{returnErr: fmt.Errorf("x: %w", repository.ErrHasProgress)}, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			handler.NewHistoryHandler(tt.repo).RegisterRoutes(r)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.repo.lastForce != tt.wantForce {
+				t.Fatalf("expected force=%v, got %v", tt.wantForce, tt.repo.lastForce)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// LeechHandler serves the leech endpoints: concepts suspended from review
+// after too many lapses, and the rewritten cards proposed for them.
+type LeechHandler struct {
+	repo repository.LeechRepository
+}
+
+// NewLeechHandler creates a LeechHandler.
+func NewLeechHandler(repo repository.LeechRepository) *LeechHandler {
+	return &LeechHandler{repo: repo}
+}
+
+// RegisterRoutes mounts leech routes on the given router.
+func (h *LeechHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/review/leeches", h.listLeeches)
+	r.Post("/api/review/leeches/{conceptId}/unsuspend", h.unsuspend)
+	r.Post("/api/review/leeches/{conceptId}/regenerate", h.regenerate)
+	r.Post("/api/review/leeches/{conceptId}/proposal/approve", h.approveProposal)
+	r.Post("/api/review/leeches/{conceptId}/proposal/reject", h.rejectProposal)
+}
+
+func (h *LeechHandler) listLeeches(w http.ResponseWriter, r *http.Request) {
+	leeches, err := h.repo.ListLeeches(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list leeches")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, leeches)
+}
+
+// unsuspend returns a leech to the review queue. A concept that is not
+// suspended returns 404.
+func (h *LeechHandler) unsuspend(w http.ResponseWriter, r *http.Request) {
+	state, err := h.repo.Unsuspend(r.Context(), chi.URLParam(r, "conceptId"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, state)
+}
+
+// regenerate queues a flashcard research job for a leech. It returns 409
+// while an earlier job is unfinished or its proposal awaits a decision.
+func (h *LeechHandler) regenerate(w http.ResponseWriter, r *http.Request) {
+	job, err := h.repo.RegenerateCard(r.Context(), chi.URLParam(r, "conceptId"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, job)
+}
+
+// approveProposal applies the leech's proposed card. Without a pending
+// proposal it returns 404.
+func (h *LeechHandler) approveProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, err := h.repo.ApproveProposal(r.Context(), chi.URLParam(r, "conceptId"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, proposal)
+}
+
+func (h *LeechHandler) rejectProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, err := h.repo.RejectProposal(r.Context(), chi.URLParam(r, "conceptId"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, proposal)
+}
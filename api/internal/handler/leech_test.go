@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+// mockLeechRepo is a test double for repository.LeechRepository.
+type mockLeechRepo struct {
+	leeches   []models.Leech
+	returnErr error
+}
+
+func (m *mockLeechRepo) ListLeeches(_ context.Context) ([]models.Leech, error) {
+	return m.leeches, m.returnErr
+}
+
+func (m *mockLeechRepo) GetLeech(_ context.Context, conceptID string) (*models.Leech, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.Leech{ConceptID: conceptID}, nil
+}
+
+func (m *mockLeechRepo) Unsuspend(_ context.Context, conceptID string) (*models.ConceptRetention, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.ConceptRetention{ConceptID: conceptID, Status: models.RetentionStatusLearning}, nil
+}
+
+func (m *mockLeechRepo) RegenerateCard(_ context.Context, conceptID string) (*models.ResearchJob, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.ResearchJob{ID: "job-1", Kind: models.ResearchKindFlashcard, ConceptID: conceptID, Status: models.ResearchStatusQueued}, nil
+}
+
+func (m *mockLeechRepo) ProposeCard(_ context.Context, conceptID, jobID string, card models.CardProposal) (*models.CardProposal, error) {
+	card.ConceptID, card.JobID = conceptID, jobID
+
+	return &card, m.returnErr
+}
+
+func (m *mockLeechRepo) ApproveProposal(_ context.Context, conceptID string) (*models.CardProposal, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.CardProposal{ID: 1, ConceptID: conceptID, Status: models.ProposalStatusApproved}, nil
+}
+
+func (m *mockLeechRepo) RejectProposal(_ context.Context, conceptID string) (*models.CardProposal, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.CardProposal{ID: 1, ConceptID: conceptID, Status: models.ProposalStatusRejected}, nil
+}
+
+func TestListLeechesHandler(t *testing.T) {
+	repo := &mockLeechRepo{leeches: []models.Leech{{ConceptID: "c1", Lapses: 8, LessonID: "l1"}}}
+
+	r := chi.NewRouter()
+	handler.NewLeechHandler(repo).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/review/leeches", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var leeches []models.Leech
+	if err := json.NewDecoder(rec.Body).Decode(&leeches); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(leeches) != 1 || leeches[0].Lapses != 8 || leeches[0].LessonID != "l1" {
+		t.Fatalf("unexpected leeches %+v", leeches)
+	}
+}
+
+func TestLeechActionHandlers(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		err  error
+		want int
+	}{
+		{"unsuspend", "/api/review/leeches/c1/unsuspend", nil, http.StatusOK},
+		{"unsuspend not a leech", "/api/review/leeches/c1/unsuspend", repository.ErrNotFound, http.StatusNotFound},
+		{"regenerate", "/api/review/leeches/c1/regenerate", nil, http.StatusCreated},
+		{"regenerate in progress", "/api/review/leeches/c1/regenerate", repository.ErrDuplicate, http.StatusConflict},
+		{"approve", "/api/review/leeches/c1/proposal/approve", nil, http.StatusOK},
+		{"approve without proposal", "/api/review/leeches/c1/proposal/approve", repository.ErrNotFound, http.StatusNotFound},
+		{"reject", "/api/review/leeches/c1/proposal/reject", nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			handler.NewLeechHandler(&mockLeechRepo{returnErr: tt.err}).RegisterRoutes(r)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
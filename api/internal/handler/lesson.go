@@ -40,5 +40,6 @@ func (h *LessonHandler) getLessonByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(lesson.Revision))
 	respond.JSON(w, http.StatusOK, lesson)
 }
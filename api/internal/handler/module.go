@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
 	"github.com/sean/apollo/api/internal/respond"
 )
@@ -27,7 +28,7 @@ func (h *ModuleHandler) RegisterRoutes(r chi.Router) {
 func (h *ModuleHandler) getModuleByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	module, err := h.repo.GetModuleByID(r.Context(), id)
+	module, err := h.repo.GetModuleByID(r.Context(), id, models.ParseReadOptions(r))
 	if err != nil {
 		respond.Error(w, http.StatusInternalServerError, "failed to get module")
 
@@ -40,5 +41,6 @@ func (h *ModuleHandler) getModuleByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(module.Revision))
 	respond.JSON(w, http.StatusOK, module)
 }
@@ -19,7 +19,7 @@ type mockModuleRepo struct {
 	returnErr error
 }
 
-func (m *mockModuleRepo) GetModuleByID(_ context.Context, _ string) (*models.ModuleDetail, error) {
+func (m *mockModuleRepo) GetModuleByID(_ context.Context, _ string, _ models.ReadOptions) (*models.ModuleDetail, error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
@@ -31,7 +31,7 @@ func (h *ProgressHandler) RegisterRoutes(r chi.Router) {
 func (h *ProgressHandler) getTopicProgress(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	tp, err := h.repo.GetTopicProgress(r.Context(), id)
+	tp, err := h.repo.GetTopicProgress(r.Context(), id, models.ParseReadOptions(r))
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			respond.Error(w, http.StatusNotFound, "topic not found")
@@ -81,7 +81,7 @@ func (h *ProgressHandler) updateLessonProgress(w http.ResponseWriter, r *http.Re
 }
 
 func (h *ProgressHandler) getProgressSummary(w http.ResponseWriter, r *http.Request) {
-	ps, err := h.repo.GetProgressSummary(r.Context())
+	ps, err := h.repo.GetProgressSummary(r.Context(), models.ParseReadOptions(r))
 	if err != nil {
 		respond.Error(w, http.StatusInternalServerError, "failed to get progress summary")
 
@@ -22,7 +22,7 @@ type mockProgressRepo struct {
 	returnErr       error
 }
 
-func (m *mockProgressRepo) GetTopicProgress(_ context.Context, _ string) (*models.TopicProgress, error) {
+func (m *mockProgressRepo) GetTopicProgress(_ context.Context, _ string, _ models.ReadOptions) (*models.TopicProgress, error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
@@ -38,7 +38,7 @@ func (m *mockProgressRepo) UpdateLessonProgress(_ context.Context, _ string, _ m
 	return m.lessonProgress, nil
 }
 
-func (m *mockProgressRepo) GetProgressSummary(_ context.Context) (*models.ProgressSummary, error) {
+func (m *mockProgressRepo) GetProgressSummary(_ context.Context, _ models.ReadOptions) (*models.ProgressSummary, error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// ReviewHandler serves the spaced repetition review queue.
+type ReviewHandler struct {
+	repo             repository.ReviewRepository
+	defaultScheduler string
+	masteryDays      int
+	limits           models.SessionLimits
+}
+
+// NewReviewHandler creates a ReviewHandler. Reviews use the scheduler chosen
+// through PUT /api/review/scheduler, or defaultScheduler until one is.
+// Sessions use the default daily caps.
+func NewReviewHandler(repo repository.ReviewRepository, defaultScheduler string, masteryDays int) *ReviewHandler {
+	return &ReviewHandler{
+		repo:             repo,
+		defaultScheduler: defaultScheduler,
+		masteryDays:      masteryDays,
+		limits: models.SessionLimits{
+			NewPerDay:     review.DefaultNewPerDay,
+			ReviewsPerDay: review.DefaultReviewsPerDay,
+		},
+	}
+}
+
+// WithSessionLimits sets the daily caps on the cards sessions hand out.
+func (h *ReviewHandler) WithSessionLimits(limits models.SessionLimits) *ReviewHandler {
+	h.limits = limits
+
+	return h
+}
+
+// RegisterRoutes mounts review routes on the given router.
+func (h *ReviewHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/review/due", h.listDue)
+	r.Get("/api/review/stats", h.getStats)
+	r.Get("/api/review/analytics", h.getAnalytics)
+	r.Get("/api/review/scheduler", h.getScheduler)
+	r.Put("/api/review/scheduler", h.switchScheduler)
+	r.Post("/api/review/sessions", h.createSession)
+	r.Get("/api/review/sessions/{id}", h.getSession)
+	r.Get("/api/review/sessions/{id}/next", h.nextSessionCard)
+	r.Post("/api/review/sessions/{id}/answer", h.answerSessionCard)
+	r.Post("/api/review/questions/{questionId}", h.recordQuestionReview)
+	r.Post("/api/review/cards/{cardId}", h.recordCardReview)
+	r.Get("/api/topics/{id}/card-kinds", h.getCardKinds)
+	r.Put("/api/topics/{id}/card-kinds", h.setCardKinds)
+	r.Post("/api/review/{conceptId}", h.recordReview)
+}
+
+// activeScheduler returns the preferred scheduler, or the default.
+func (h *ReviewHandler) activeScheduler(ctx context.Context) (review.Scheduler, error) {
+	name, err := h.repo.GetSchedulerPreference(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = h.defaultScheduler
+	}
+
+	return review.New(name, h.masteryDays)
+}
+
+// listDue returns the concept, question, and generated cards due by the end
+// of today (UTC).
+func (h *ReviewHandler) listDue(w http.ResponseWriter, r *http.Request) {
+	cards, err := h.repo.ListDue(r.Context(), review.DueCutoff(time.Now()))
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list due reviews")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, cards)
+}
+
+func (h *ReviewHandler) getStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.repo.GetStats(r.Context(), review.DueCutoff(time.Now()))
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to get review stats")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, stats)
+}
+
+// recordReview rates a concept and returns its rescheduled retention state.
+// Any concept can be reviewed, including new and mastered ones.
+func (h *ReviewHandler) recordReview(w http.ResponseWriter, r *http.Request) {
+	var input models.ReviewInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if !validReviewInput(w, input.Rating, input.ResponseMS) {
+		return
+	}
+
+	scheduler, err := h.activeScheduler(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to load review scheduler")
+
+		return
+	}
+
+	now := time.Now()
+
+	state, err := h.repo.RecordReview(r.Context(), chi.URLParam(r, "conceptId"), input.ResponseMS,
+		func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return scheduler.Review(current, input.Rating, now)
+		})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, state)
+}
+
+// validReviewInput checks a review's rating and response time, writing a
+// 400 when either is invalid.
+func validReviewInput(w http.ResponseWriter, rating string, responseMS int) bool {
+	if !models.IsRating(rating) {
+		respond.Error(w, http.StatusBadRequest, "rating must be one of forgot, hard, good, easy")
+
+		return false
+	}
+
+	if responseMS < 0 {
+		respond.Error(w, http.StatusBadRequest, "response_ms must not be negative")
+
+		return false
+	}
+
+	return true
+}
+
+// recordQuestionReview rates a question card and every concept in review
+// that it tests, and returns their rescheduled states.
+func (h *ReviewHandler) recordQuestionReview(w http.ResponseWriter, r *http.Request) {
+	var input models.ReviewInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if !validReviewInput(w, input.Rating, input.ResponseMS) {
+		return
+	}
+
+	scheduler, err := h.activeScheduler(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to load review scheduler")
+
+		return
+	}
+
+	now := time.Now()
+
+	answer, err := h.repo.RecordQuestionReview(r.Context(), chi.URLParam(r, "questionId"), input.ResponseMS,
+		func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return scheduler.Review(current, input.Rating, now)
+		})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, answer)
+}
+
+// recordCardReview rates a generated card and returns its rescheduled state.
+func (h *ReviewHandler) recordCardReview(w http.ResponseWriter, r *http.Request) {
+	var input models.ReviewInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if !validReviewInput(w, input.Rating, input.ResponseMS) {
+		return
+	}
+
+	scheduler, err := h.activeScheduler(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to load review scheduler")
+
+		return
+	}
+
+	now := time.Now()
+
+	answer, err := h.repo.RecordCardReview(r.Context(), chi.URLParam(r, "cardId"), input.ResponseMS,
+		func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return scheduler.Review(current, input.Rating, now)
+		})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, answer)
+}
+
+func (h *ReviewHandler) getCardKinds(w http.ResponseWriter, r *http.Request) {
+	kinds, err := h.repo.GetCardKinds(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, kinds)
+}
+
+// setCardKinds replaces the kinds of generated cards a topic enables and
+// regenerates its cards.
+func (h *ReviewHandler) setCardKinds(w http.ResponseWriter, r *http.Request) {
+	var input models.CardKindsInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	for _, kind := range input.Kinds {
+		if !models.IsGeneratedCardKind(kind) {
+			respond.Error(w, http.StatusBadRequest, "kinds must be among reverse, cloze, topic")
+
+			return
+		}
+	}
+
+	kinds, err := h.repo.SetCardKinds(r.Context(), chi.URLParam(r, "id"), input.Kinds)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, kinds)
+}
+
+// Analytics windows, in days, for GET /api/review/analytics?days=N.
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+// getAnalytics reports retention and workload over the last ?days days and
+// forecasts the reviews due over the next ones. A missing or invalid days
+// falls back to the default; larger values are capped.
+func (h *ReviewHandler) getAnalytics(w http.ResponseWriter, r *http.Request) {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 {
+		days = defaultAnalyticsDays
+	}
+
+	analytics, err := h.repo.GetAnalytics(r.Context(), review.DueCutoff(time.Now()), min(days, maxAnalyticsDays))
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to get review analytics")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, analytics)
+}
+
+func (h *ReviewHandler) getScheduler(w http.ResponseWriter, r *http.Request) {
+	scheduler, err := h.activeScheduler(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to load review scheduler")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, models.SchedulerInfo{Scheduler: scheduler.Name(), Available: review.Names()})
+}
+
+// switchScheduler makes the named scheduler the preference and re-derives
+// every concept's state under it from the review history.
+func (h *ReviewHandler) switchScheduler(w http.ResponseWriter, r *http.Request) {
+	var input models.SchedulerInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	scheduler, err := review.New(input.Scheduler, h.masteryDays)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	rederived, err := h.repo.SwitchScheduler(r.Context(), scheduler.Name(),
+		func(current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error) {
+			return review.Replay(scheduler, current, history)
+		})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, models.SchedulerInfo{
+		Scheduler: scheduler.Name(),
+		Available: review.Names(),
+		Rederived: rederived,
+	})
+}
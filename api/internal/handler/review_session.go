@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/respond"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// createSession starts a review session with today's due cards, within the
+// daily caps, interleaved by topic. It abandons any active session. An
+// empty body is allowed.
+func (h *ReviewHandler) createSession(w http.ResponseWriter, r *http.Request) {
+	var input models.SessionInput
+	if r.ContentLength != 0 && !decodeJSON(w, r, &input) {
+		return
+	}
+
+	session, err := h.repo.CreateSession(r.Context(), review.DueCutoff(time.Now()), models.SessionOptions{
+		ActiveTopicsOnly: input.ActiveTopicsOnly,
+		NewLimit:         h.limits.NewPerDay,
+		ReviewLimit:      h.limits.ReviewsPerDay,
+	}, review.Interleave)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to create review session")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, session)
+}
+
+func (h *ReviewHandler) getSession(w http.ResponseWriter, r *http.Request) {
+	session, err := h.repo.GetSession(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, session)
+}
+
+// nextSessionCard returns the session's next card, or 204 when the session
+// has none left.
+func (h *ReviewHandler) nextSessionCard(w http.ResponseWriter, r *http.Request) {
+	card, err := h.repo.NextSessionCard(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if card == nil {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, card)
+}
+
+// answerSessionCard rates the session's next card with the active scheduler.
+// The answer to the last card carries the session summary.
+func (h *ReviewHandler) answerSessionCard(w http.ResponseWriter, r *http.Request) {
+	var input models.SessionAnswerInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if !validReviewInput(w, input.Rating, input.ResponseMS) {
+		return
+	}
+
+	named := 0
+	for _, id := range []string{input.ConceptID, input.QuestionID, input.CardID} {
+		if id != "" {
+			named++
+		}
+	}
+
+	if named > 1 {
+		respond.Error(w, http.StatusBadRequest, "set at most one of concept_id, question_id, card_id")
+
+		return
+	}
+
+	scheduler, err := h.activeScheduler(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to load review scheduler")
+
+		return
+	}
+
+	now := time.Now()
+
+	answer, err := h.repo.AnswerSessionCard(r.Context(), chi.URLParam(r, "id"), input.ConceptID+input.QuestionID+input.CardID, input.ResponseMS,
+		func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return scheduler.Review(current, input.Rating, now)
+		})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, answer)
+}
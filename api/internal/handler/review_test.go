@@ -0,0 +1,637 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// mockReviewRepo is a test double for repository.ReviewRepository. It runs
+// the schedule and replay functions against state and keeps the result.
+type mockReviewRepo struct {
+	state      models.ConceptRetention
+	history    []models.ReviewEvent
+	cards      []models.ReviewCard
+	stats      models.ReviewStats
+	preference string
+	lastCutoff time.Time
+	lastDays   int
+	responseMS int
+	lastOpts   models.SessionOptions
+	sessions   map[string]*models.ReviewSession
+	next       *models.SessionCard
+	kinds      map[string][]string
+	returnErr  error
+}
+
+func (m *mockReviewRepo) ListDue(_ context.Context, dueBefore time.Time) ([]models.ReviewCard, error) {
+	m.lastCutoff = dueBefore
+
+	return m.cards, m.returnErr
+}
+
+func (m *mockReviewRepo) RecordReview(_ context.Context, _ string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.ConceptRetention, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	m.responseMS = responseMS
+
+	next, err := schedule(m.state)
+	if err != nil {
+		return nil, err
+	}
+
+	m.state = next
+
+	return &next, nil
+}
+
+func (m *mockReviewRepo) RecordQuestionReview(_ context.Context, questionID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.QuestionAnswer, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	m.responseMS = responseMS
+
+	next, err := schedule(models.ConceptRetention{})
+	if err != nil {
+		return nil, err
+	}
+
+	concept, err := schedule(m.state)
+	if err != nil {
+		return nil, err
+	}
+
+	m.state = concept
+
+	return &models.QuestionAnswer{QuestionID: questionID, State: next, Concepts: []models.ConceptRetention{concept}}, nil
+}
+
+func (m *mockReviewRepo) SyncQuestions(context.Context) (int, error) {
+	return 0, m.returnErr
+}
+
+func (m *mockReviewRepo) RecordCardReview(_ context.Context, cardID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.CardAnswer, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	m.responseMS = responseMS
+
+	next, err := schedule(models.ConceptRetention{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CardAnswer{CardID: cardID, State: next}, nil
+}
+
+func (m *mockReviewRepo) SyncCards(context.Context) (int, error) {
+	return 0, m.returnErr
+}
+
+func (m *mockReviewRepo) GetCardKinds(_ context.Context, topicID string) (*models.TopicCardKinds, error) {
+	kinds, ok := m.kinds[topicID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return &models.TopicCardKinds{TopicID: topicID, Kinds: kinds}, nil
+}
+
+func (m *mockReviewRepo) SetCardKinds(_ context.Context, topicID string, kinds []string) (*models.TopicCardKinds, error) {
+	if _, ok := m.kinds[topicID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	m.kinds[topicID] = kinds
+
+	return &models.TopicCardKinds{TopicID: topicID, Kinds: kinds, Cards: len(kinds)}, nil
+}
+
+func (m *mockReviewRepo) GetStats(_ context.Context, dayEnd time.Time) (*models.ReviewStats, error) {
+	m.lastCutoff = dayEnd
+
+	return &m.stats, m.returnErr
+}
+
+func (m *mockReviewRepo) GetAnalytics(_ context.Context, dayEnd time.Time, days int) (*models.ReviewAnalytics, error) {
+	m.lastCutoff, m.lastDays = dayEnd, days
+
+	return &models.ReviewAnalytics{Days: days}, m.returnErr
+}
+
+func (m *mockReviewRepo) CreateSession(_ context.Context, dayEnd time.Time, opts models.SessionOptions, arrange repository.ArrangeFunc) (*models.ReviewSession, error) {
+	m.lastCutoff, m.lastOpts = dayEnd, opts
+
+	return &models.ReviewSession{ID: "s1", Status: models.SessionStatusActive, Total: len(arrange(m.cards, nil))}, m.returnErr
+}
+
+func (m *mockReviewRepo) GetSession(_ context.Context, id string) (*models.ReviewSession, error) {
+	if s, ok := m.sessions[id]; ok {
+		return s, nil
+	}
+
+	return nil, repository.ErrNotFound
+}
+
+func (m *mockReviewRepo) NextSessionCard(_ context.Context, id string) (*models.SessionCard, error) {
+	if _, ok := m.sessions[id]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return m.next, nil
+}
+
+func (m *mockReviewRepo) AnswerSessionCard(_ context.Context, id, cardID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.SessionAnswer, error) {
+	if _, ok := m.sessions[id]; !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	if m.next == nil {
+		return nil, repository.ErrSessionClosed
+	}
+
+	if cardID != "" && cardID != m.next.ConceptID+m.next.QuestionID+m.next.CardID {
+		return nil, repository.ErrWrongCard
+	}
+
+	m.responseMS = responseMS
+
+	next, err := schedule(m.next.ConceptRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	m.state, m.next = next, nil
+
+	return &models.SessionAnswer{State: next, Summary: &models.SessionSummary{Reviewed: 1}}, nil
+}
+
+func (m *mockReviewRepo) ListRetention(context.Context) ([]models.ConceptRetention, error) {
+	return []models.ConceptRetention{m.state}, m.returnErr
+}
+
+func (m *mockReviewRepo) ListHistory(context.Context) (map[string][]models.ReviewEvent, error) {
+	return map[string][]models.ReviewEvent{m.state.ConceptID: m.history}, m.returnErr
+}
+
+func (m *mockReviewRepo) GetSchedulerPreference(context.Context) (string, error) {
+	return m.preference, nil
+}
+
+func (m *mockReviewRepo) SwitchScheduler(ctx context.Context, name string, replay repository.ReplayFunc) (int, error) {
+	m.preference = name
+
+	return m.Rederive(ctx, name, replay)
+}
+
+func (m *mockReviewRepo) Rederive(_ context.Context, name string, replay repository.ReplayFunc) (int, error) {
+	if m.returnErr != nil || m.state.Scheduler == name {
+		return 0, m.returnErr
+	}
+
+	next, err := replay(m.state, m.history)
+	if err != nil {
+		return 0, err
+	}
+
+	m.state = next
+
+	return 1, nil
+}
+
+func newReviewRouter(repo repository.ReviewRepository) chi.Router {
+	r := chi.NewRouter()
+	handler.NewReviewHandler(repo, review.NameSM2, 10).RegisterRoutes(r)
+
+	return r
+}
+
+func TestListDueHandlerUsesEndOfDay(t *testing.T) {
+	repo := &mockReviewRepo{cards: []models.ReviewCard{{ConceptRetention: models.ConceptRetention{ConceptID: "c1"}, Name: "Channel"}}}
+
+	rec := httptest.NewRecorder()
+	newReviewRouter(repo).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/review/due", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	if want := review.DueCutoff(time.Now()); !repo.lastCutoff.Equal(want) {
+		t.Fatalf("cutoff = %v, want %v", repo.lastCutoff, want)
+	}
+
+	var cards []models.ReviewCard
+	if err := json.NewDecoder(rec.Body).Decode(&cards); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(cards) != 1 || cards[0].ConceptID != "c1" || cards[0].Name != "Channel" {
+		t.Fatalf("unexpected cards %+v", cards)
+	}
+}
+
+func TestRecordReviewHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		state      models.ConceptRetention
+		repoErr    error
+		wantStatus int
+		wantState  string
+	}{
+		{name: "good", body: `{"rating":"good"}`, wantStatus: http.StatusOK, wantState: models.RetentionStatusReviewing},
+		{name: "forgot", body: `{"rating":"forgot"}`, wantStatus: http.StatusOK, wantState: models.RetentionStatusLearning},
+		{
+			name:       "past mastery threshold",
+			body:       `{"rating":"good"}`,
+			state:      models.ConceptRetention{ReviewCount: 3, IntervalDays: 8, EaseFactor: 2.5},
+			wantStatus: http.StatusOK,
+			wantState:  models.RetentionStatusMastered,
+		},
+		{name: "unknown rating", body: `{"rating":"meh"}`, wantStatus: http.StatusBadRequest},
+		{name: "negative response time", body: `{"rating":"good","response_ms":-1}`, wantStatus: http.StatusBadRequest},
+		{name: "bad json", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "unknown concept", body: `{"rating":"easy"}`, repoErr: repository.ErrNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockReviewRepo{state: tt.state, returnErr: tt.repoErr}
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/review/c1", strings.NewReader(tt.body))
+			newReviewRouter(repo).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.wantState == "" {
+				return
+			}
+
+			var state models.ConceptRetention
+			if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if state.Status != tt.wantState || state.NextReview == "" {
+				t.Fatalf("unexpected state %+v", state)
+			}
+		})
+	}
+}
+
+func TestRecordQuestionReviewHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		repoErr    error
+		wantStatus int
+	}{
+		{name: "good", body: `{"rating":"good","response_ms":3000}`, wantStatus: http.StatusOK},
+		{name: "unknown rating", body: `{"rating":"meh"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown question", body: `{"rating":"good"}`, repoErr: repository.ErrNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockReviewRepo{state: models.ConceptRetention{ConceptID: "c1"}, returnErr: tt.repoErr}
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/review/questions/q-1", strings.NewReader(tt.body))
+			newReviewRouter(repo).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var answer models.QuestionAnswer
+			if err := json.NewDecoder(rec.Body).Decode(&answer); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if answer.QuestionID != "q-1" || answer.State.NextReview == "" || len(answer.Concepts) != 1 || repo.responseMS != 3000 {
+				t.Fatalf("unexpected answer %+v", answer)
+			}
+		})
+	}
+}
+
+func TestRecordCardReviewHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		repoErr    error
+		wantStatus int
+	}{
+		{name: "good", body: `{"rating":"good","response_ms":1200}`, wantStatus: http.StatusOK},
+		{name: "unknown rating", body: `{"rating":"meh"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown card", body: `{"rating":"good"}`, repoErr: repository.ErrNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockReviewRepo{returnErr: tt.repoErr}
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/review/cards/g-1", strings.NewReader(tt.body))
+			newReviewRouter(repo).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var answer models.CardAnswer
+			if err := json.NewDecoder(rec.Body).Decode(&answer); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if answer.CardID != "g-1" || answer.State.NextReview == "" || repo.responseMS != 1200 {
+				t.Fatalf("unexpected answer %+v", answer)
+			}
+		})
+	}
+}
+
+func TestCardKindsHandlers(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantKinds  []string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/topics/t1/card-kinds", wantStatus: http.StatusOK, wantKinds: []string{"cloze"}},
+		{name: "get unknown topic", method: http.MethodGet, path: "/api/topics/t9/card-kinds", wantStatus: http.StatusNotFound},
+		{name: "set", method: http.MethodPut, path: "/api/topics/t1/card-kinds", body: `{"kinds":["reverse","topic"]}`, wantStatus: http.StatusOK, wantKinds: []string{"reverse", "topic"}},
+		{name: "set none", method: http.MethodPut, path: "/api/topics/t1/card-kinds", body: `{"kinds":[]}`, wantStatus: http.StatusOK, wantKinds: []string{}},
+		{name: "set unknown kind", method: http.MethodPut, path: "/api/topics/t1/card-kinds", body: `{"kinds":["reverse","concept"]}`, wantStatus: http.StatusBadRequest},
+		{name: "set unknown topic", method: http.MethodPut, path: "/api/topics/t9/card-kinds", body: `{"kinds":["cloze"]}`, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockReviewRepo{kinds: map[string][]string{"t1": {"cloze"}}}
+
+			rec := httptest.NewRecorder()
+			newReviewRouter(repo).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var kinds models.TopicCardKinds
+			if err := json.NewDecoder(rec.Body).Decode(&kinds); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if kinds.TopicID != "t1" || strings.Join(kinds.Kinds, ",") != strings.Join(tt.wantKinds, ",") {
+				t.Fatalf("unexpected card kinds %+v", kinds)
+			}
+		})
+	}
+}
+
+func TestReviewStatsHandler(t *testing.T) {
+	repo := &mockReviewRepo{stats: models.ReviewStats{DueToday: 3, Mastered: 1}}
+
+	rec := httptest.NewRecorder()
+	newReviewRouter(repo).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/review/stats", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var stats models.ReviewStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if stats.DueToday != 3 || stats.Mastered != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRecordReviewHandlerPassesResponseTime(t *testing.T) {
+	repo := &mockReviewRepo{}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/review/c1", strings.NewReader(`{"rating":"good","response_ms":4200}`))
+	newReviewRouter(repo).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || repo.responseMS != 4200 {
+		t.Fatalf("expected 200 with response time 4200, got %d and %d", rec.Code, repo.responseMS)
+	}
+}
+
+func TestReviewAnalyticsHandlerDays(t *testing.T) {
+	tests := []struct {
+		query    string
+		wantDays int
+	}{
+		{query: "", wantDays: 30},
+		{query: "?days=7", wantDays: 7},
+		{query: "?days=0", wantDays: 30},
+		{query: "?days=week", wantDays: 30},
+		{query: "?days=1000", wantDays: 365},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			repo := &mockReviewRepo{}
+
+			rec := httptest.NewRecorder()
+			newReviewRouter(repo).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/review/analytics"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", rec.Code)
+			}
+
+			if repo.lastDays != tt.wantDays {
+				t.Fatalf("days = %d, want %d", repo.lastDays, tt.wantDays)
+			}
+
+			if want := review.DueCutoff(time.Now()); !repo.lastCutoff.Equal(want) {
+				t.Fatalf("cutoff = %v, want %v", repo.lastCutoff, want)
+			}
+		})
+	}
+}
+
+func TestRecordReviewHandlerUsesPreferredScheduler(t *testing.T) {
+	repo := &mockReviewRepo{preference: review.NameFSRS}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/review/c1", strings.NewReader(`{"rating":"easy"}`))
+	newReviewRouter(repo).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	if repo.state.Scheduler != review.NameFSRS || repo.state.Stability == 0 {
+		t.Fatalf("expected FSRS state, got %+v", repo.state)
+	}
+}
+
+func TestSchedulerHandlers(t *testing.T) {
+	repo := &mockReviewRepo{
+		state: models.ConceptRetention{
+			ConceptID: "c1", Status: models.RetentionStatusReviewing, ReviewCount: 1,
+			IntervalDays: 1, EaseFactor: 2.5, Scheduler: review.NameSM2,
+		},
+		history: []models.ReviewEvent{{Rating: models.RatingGood, ReviewedAt: "2026-03-01T09:00:00Z"}},
+	}
+	router := newReviewRouter(repo)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/review/scheduler", nil))
+
+	var info models.SchedulerInfo
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if rec.Code != http.StatusOK || info.Scheduler != review.NameSM2 || len(info.Available) != 2 {
+		t.Fatalf("GET scheduler = %d %+v", rec.Code, info)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/review/scheduler", strings.NewReader(`{"scheduler":"leitner"}`)))
+
+	if rec.Code != http.StatusBadRequest || repo.preference != "" {
+		t.Fatalf("unknown scheduler: expected 400 and no switch, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/review/scheduler", strings.NewReader(`{"scheduler":"fsrs"}`)))
+
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if rec.Code != http.StatusOK || info.Scheduler != review.NameFSRS || info.Rederived != 1 {
+		t.Fatalf("PUT scheduler = %d %+v", rec.Code, info)
+	}
+
+	// The one logged "good" replays to FSRS's first-review stability.
+	if repo.state.Scheduler != review.NameFSRS || repo.state.Stability != 3.7145 {
+		t.Fatalf("state not re-derived: %+v", repo.state)
+	}
+}
+
+func TestCreateSessionHandler(t *testing.T) {
+	repo := &mockReviewRepo{cards: []models.ReviewCard{{TopicID: "t1"}, {TopicID: "t2"}}}
+
+	r := chi.NewRouter()
+	handler.NewReviewHandler(repo, review.NameSM2, 10).
+		WithSessionLimits(models.SessionLimits{NewPerDay: 5, ReviewsPerDay: 50}).
+		RegisterRoutes(r)
+
+	for _, body := range []string{"", `{"active_topics_only":true}`} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/review/sessions", strings.NewReader(body)))
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("body %q: expected 201, got %d: %s", body, rec.Code, rec.Body.String())
+		}
+
+		want := models.SessionOptions{ActiveTopicsOnly: body != "", NewLimit: 5, ReviewLimit: 50}
+		if repo.lastOpts != want {
+			t.Fatalf("body %q: options = %+v, want %+v", body, repo.lastOpts, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/review/sessions", strings.NewReader("{")))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad json: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestSessionHandlers(t *testing.T) {
+	card := &models.SessionCard{
+		ReviewCard: models.ReviewCard{ConceptRetention: models.ConceptRetention{ConceptID: "c1", Status: models.RetentionStatusLearning}},
+		Remaining:  1,
+	}
+
+	question := &models.SessionCard{
+		ReviewCard: models.ReviewCard{Kind: models.CardKindQuestion, QuestionID: "q-1"},
+		Remaining:  1,
+	}
+
+	generated := &models.SessionCard{
+		ReviewCard: models.ReviewCard{Kind: models.CardKindCloze, CardID: "g-1"},
+		Remaining:  1,
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		next       *models.SessionCard
+		wantStatus int
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/review/sessions/s1", wantStatus: http.StatusOK},
+		{name: "get unknown", method: http.MethodGet, path: "/api/review/sessions/s9", wantStatus: http.StatusNotFound},
+		{name: "next", method: http.MethodGet, path: "/api/review/sessions/s1/next", next: card, wantStatus: http.StatusOK},
+		{name: "next when done", method: http.MethodGet, path: "/api/review/sessions/s1/next", wantStatus: http.StatusNoContent},
+		{name: "answer", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"rating":"good"}`, next: card, wantStatus: http.StatusOK},
+		{name: "answer named card", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"concept_id":"c1","rating":"good"}`, next: card, wantStatus: http.StatusOK},
+		{name: "answer wrong card", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"concept_id":"c2","rating":"good"}`, next: card, wantStatus: http.StatusConflict},
+		{name: "answer named question", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"question_id":"q-1","rating":"good"}`, next: question, wantStatus: http.StatusOK},
+		{name: "answer named generated card", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"card_id":"g-1","rating":"good"}`, next: generated, wantStatus: http.StatusOK},
+		{name: "answer names card and question", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"question_id":"q-1","card_id":"g-1","rating":"good"}`, next: generated, wantStatus: http.StatusBadRequest},
+		{name: "answer names both", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"concept_id":"c1","question_id":"q-1","rating":"good"}`, next: card, wantStatus: http.StatusBadRequest},
+		{name: "answer when done", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"rating":"good"}`, wantStatus: http.StatusConflict},
+		{name: "answer bad rating", method: http.MethodPost, path: "/api/review/sessions/s1/answer", body: `{"rating":"meh"}`, next: card, wantStatus: http.StatusBadRequest},
+		{name: "answer unknown session", method: http.MethodPost, path: "/api/review/sessions/s9/answer", body: `{"rating":"good"}`, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockReviewRepo{
+				sessions: map[string]*models.ReviewSession{"s1": {ID: "s1", Status: models.SessionStatusActive}},
+				next:     tt.next,
+			}
+
+			rec := httptest.NewRecorder()
+			newReviewRouter(repo).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
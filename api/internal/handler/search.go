@@ -36,7 +36,7 @@ func (h *SearchHandler) search(w http.ResponseWriter, r *http.Request) {
 
 	params := models.ParsePagination(r)
 
-	result, err := h.repo.Search(r.Context(), query, params)
+	result, err := h.repo.Search(r.Context(), query, params, models.ParseReadOptions(r))
 	if err != nil {
 		if errors.Is(err, repository.ErrInvalidQuery) {
 			respond.Error(w, http.StatusBadRequest, "invalid search query syntax")
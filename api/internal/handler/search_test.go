@@ -21,7 +21,7 @@ type mockSearchRepo struct {
 	returnErr error
 }
 
-func (m *mockSearchRepo) Search(_ context.Context, _ string, _ models.PaginationParams) (*models.PaginatedResponse[models.SearchResult], error) {
+func (m *mockSearchRepo) Search(_ context.Context, _ string, _ models.PaginationParams, _ models.ReadOptions) (*models.PaginatedResponse[models.SearchResult], error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
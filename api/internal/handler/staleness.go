@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// StalenessHandler serves the staleness sweep history.
+type StalenessHandler struct {
+	repo repository.StalenessRepository
+}
+
+// NewStalenessHandler creates a StalenessHandler.
+func NewStalenessHandler(repo repository.StalenessRepository) *StalenessHandler {
+	return &StalenessHandler{repo: repo}
+}
+
+// RegisterRoutes mounts staleness routes on the given router.
+func (h *StalenessHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/staleness/sweeps", h.listSweeps)
+}
+
+func (h *StalenessHandler) listSweeps(w http.ResponseWriter, r *http.Request) {
+	params := models.ParsePagination(r)
+
+	list, err := h.repo.ListSweeps(r.Context(), params)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, "failed to list staleness sweeps")
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, list)
+}
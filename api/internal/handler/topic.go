@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
 	"github.com/sean/apollo/api/internal/respond"
 )
@@ -29,7 +30,14 @@ func (h *TopicHandler) RegisterRoutes(r chi.Router) {
 }
 
 func (h *TopicHandler) listTopics(w http.ResponseWriter, r *http.Request) {
-	topics, err := h.repo.ListTopics(r.Context())
+	status := r.URL.Query().Get("status")
+	if status != "" && !models.IsTopicStatus(status) {
+		respond.Error(w, http.StatusBadRequest, "status must be researching, draft, published, or outdated")
+
+		return
+	}
+
+	topics, err := h.repo.ListTopics(r.Context(), status)
 	if err != nil {
 		respond.Error(w, http.StatusInternalServerError, "failed to list topics")
 
@@ -42,7 +50,7 @@ func (h *TopicHandler) listTopics(w http.ResponseWriter, r *http.Request) {
 func (h *TopicHandler) getTopicByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	topic, err := h.repo.GetTopicByID(r.Context(), id)
+	topic, err := h.repo.GetTopicByID(r.Context(), id, models.ParseReadOptions(r))
 	if err != nil {
 		respond.Error(w, http.StatusInternalServerError, "failed to get topic")
 
@@ -55,13 +63,20 @@ func (h *TopicHandler) getTopicByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if topic.ID != id {
+		redirectToCanonical(w, r, id, topic.ID, "/api/topics/", "")
+
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(topic.Revision))
 	respond.JSON(w, http.StatusOK, topic)
 }
 
 func (h *TopicHandler) getTopicFull(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	topic, err := h.repo.GetTopicFull(r.Context(), id)
+	topic, err := h.repo.GetTopicFull(r.Context(), id, models.ParseReadOptions(r))
 	if err != nil {
 		respond.Error(w, http.StatusInternalServerError, "failed to get topic")
 
@@ -74,5 +89,11 @@ func (h *TopicHandler) getTopicFull(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if topic.ID != id {
+		redirectToCanonical(w, r, id, topic.ID, "/api/topics/", "/full")
+
+		return
+	}
+
 	respond.JSON(w, http.StatusOK, topic)
 }
@@ -22,7 +22,7 @@ type mockTopicRepo struct {
 	returnErr error
 }
 
-func (m *mockTopicRepo) ListTopics(_ context.Context) ([]models.TopicSummary, error) {
+func (m *mockTopicRepo) ListTopics(_ context.Context, _ string) ([]models.TopicSummary, error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
@@ -30,7 +30,7 @@ func (m *mockTopicRepo) ListTopics(_ context.Context) ([]models.TopicSummary, er
 	return m.topics, nil
 }
 
-func (m *mockTopicRepo) GetTopicByID(_ context.Context, _ string) (*models.TopicDetail, error) {
+func (m *mockTopicRepo) GetTopicByID(_ context.Context, _ string, _ models.ReadOptions) (*models.TopicDetail, error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
@@ -38,7 +38,7 @@ func (m *mockTopicRepo) GetTopicByID(_ context.Context, _ string) (*models.Topic
 	return m.detail, nil
 }
 
-func (m *mockTopicRepo) GetTopicFull(_ context.Context, _ string) (*models.TopicFull, error) {
+func (m *mockTopicRepo) GetTopicFull(_ context.Context, _ string, _ models.ReadOptions) (*models.TopicFull, error) {
 	if m.returnErr != nil {
 		return nil, m.returnErr
 	}
@@ -97,6 +97,18 @@ func TestListTopicsHandlerError(t *testing.T) {
 	}
 }
 
+func TestListTopicsHandlerRejectsUnknownStatus(t *testing.T) {
+	r := setupTopicRouter(&mockTopicRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/topics?status=stale", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
 func TestGetTopicByIDHandler(t *testing.T) {
 	td := &models.TopicDetail{
 		Modules: []models.ModuleSummary{{ID: "m1", Title: "Module 1", SortOrder: 1}},
@@ -202,3 +214,42 @@ func TestGetTopicFullNotFoundHandler(t *testing.T) {
 		t.Fatalf("expected 404, got %d", rec.Code)
 	}
 }
+
+func TestGetTopicByAliasRedirects(t *testing.T) {
+	td := &models.TopicDetail{}
+	td.ID = "linux-administration"
+	tf := &models.TopicFull{}
+	tf.ID = "linux-administration"
+
+	r := setupTopicRouter(&mockTopicRepo{detail: td, full: tf})
+
+	tests := []struct {
+		path, location string
+	}{
+		{"/api/topics/linux-admin", "/api/topics/linux-administration"},
+		{"/api/topics/linux-admin/full?include_archived=true", "/api/topics/linux-administration/full?include_archived=true"},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMovedPermanently {
+			t.Fatalf("%s: expected 301, got %d", tc.path, rec.Code)
+		}
+
+		if got := rec.Header().Get("Location"); got != tc.location {
+			t.Fatalf("%s: expected Location %s, got %s", tc.path, tc.location, got)
+		}
+
+		var body models.AliasRedirect
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+
+		if body.RequestedID != "linux-admin" || body.ID != "linux-administration" {
+			t.Fatalf("unexpected redirect body %+v", body)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
 	"github.com/sean/apollo/api/internal/respond"
+	"github.com/sean/apollo/api/internal/schema"
 )
 
 // WriteHandler serves curriculum write endpoints.
@@ -26,12 +27,48 @@ func NewWriteHandler(repo repository.WriteRepository) *WriteHandler {
 func (h *WriteHandler) RegisterRoutes(r chi.Router) {
 	r.Post("/api/topics", h.createTopic)
 	r.Put("/api/topics/{id}", h.updateTopic)
+	r.Patch("/api/topics/{id}", h.patchTopic)
+	r.Delete("/api/topics/{id}", h.deleteTopic)
+	r.Put("/api/topics/{id}/module-order", h.reorderModules)
+
 	r.Post("/api/modules", h.createModule)
+	r.Put("/api/modules/{id}", h.updateModule)
+	r.Patch("/api/modules/{id}", h.patchModule)
+	r.Delete("/api/modules/{id}", h.deleteModule)
+	r.Put("/api/modules/{id}/lesson-order", h.reorderLessons)
+	r.Post("/api/modules/{id}/archive", h.archiveModule)
+	r.Post("/api/modules/{id}/unarchive", h.unarchiveModule)
+
 	r.Post("/api/lessons", h.createLesson)
+	r.Put("/api/lessons/{id}", h.updateLesson)
+	r.Patch("/api/lessons/{id}", h.patchLesson)
+	r.Delete("/api/lessons/{id}", h.deleteLesson)
+	r.Post("/api/lessons/{id}/move", h.moveLesson)
+	r.Post("/api/lessons/{id}/archive", h.archiveLesson)
+	r.Post("/api/lessons/{id}/unarchive", h.unarchiveLesson)
+
 	r.Post("/api/concepts", h.createConcept)
+	r.Put("/api/concepts/{id}", h.updateConcept)
+	r.Patch("/api/concepts/{id}", h.patchConcept)
+	r.Delete("/api/concepts/{id}", h.deleteConcept)
+	r.Post("/api/concepts/{id}/merge", h.mergeConcept)
+
 	r.Post("/api/concepts/{id}/references", h.createConceptReference)
+	r.Put("/api/concepts/{id}/references/{lessonId}", h.updateConceptReference)
+	r.Patch("/api/concepts/{id}/references/{lessonId}", h.patchConceptReference)
+	r.Delete("/api/concepts/{id}/references/{lessonId}", h.deleteConceptReference)
+
 	r.Post("/api/prerequisites", h.createPrerequisite)
+	r.Put("/api/prerequisites/{topicId}/{prerequisiteId}", h.updatePrerequisite)
+	r.Patch("/api/prerequisites/{topicId}/{prerequisiteId}", h.patchPrerequisite)
+	r.Delete("/api/prerequisites/{topicId}/{prerequisiteId}", h.deletePrerequisite)
+
 	r.Post("/api/relations", h.createRelation)
+	r.Put("/api/relations/{topicA}/{topicB}", h.updateRelation)
+	r.Patch("/api/relations/{topicA}/{topicB}", h.patchRelation)
+	r.Delete("/api/relations/{topicA}/{topicB}", h.deleteRelation)
+
+	r.Post("/api/batch", h.batch)
 }
 
 func (h *WriteHandler) createTopic(w http.ResponseWriter, r *http.Request) {
@@ -40,42 +77,51 @@ func (h *WriteHandler) createTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.ID == "" || input.Title == "" || input.Status == "" {
-		respond.Error(w, http.StatusBadRequest, "id, title, and status are required")
+	if err := validateTopicCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
 
-	if err := h.repo.CreateTopic(r.Context(), input); err != nil {
+	revision, err := h.repo.CreateTopic(r.Context(), input)
+	if err != nil {
 		writeError(w, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(revision))
 	respond.JSON(w, http.StatusCreated, input)
 }
 
 func (h *WriteHandler) updateTopic(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var input models.TopicInput
 	if !decodeJSON(w, r, &input) {
 		return
 	}
 
-	if input.Title == "" || input.Status == "" {
-		respond.Error(w, http.StatusBadRequest, "title and status are required")
+	if err := validateTopicUpdate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
 
-	if err := h.repo.UpdateTopic(r.Context(), id, input); err != nil {
+	revision, err := h.repo.UpdateTopic(r.Context(), id, ifRevision, input)
+	if err != nil {
 		writeError(w, err)
 
 		return
 	}
 
 	input.ID = id
+	w.Header().Set("ETag", formatETag(revision))
 	respond.JSON(w, http.StatusOK, input)
 }
 
@@ -85,18 +131,20 @@ func (h *WriteHandler) createModule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.ID == "" || input.TopicID == "" || input.Title == "" {
-		respond.Error(w, http.StatusBadRequest, "id, topic_id, and title are required")
+	if err := validateModuleCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
 
-	if err := h.repo.CreateModule(r.Context(), input); err != nil {
+	revision, err := h.repo.CreateModule(r.Context(), input)
+	if err != nil {
 		writeError(w, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(revision))
 	respond.JSON(w, http.StatusCreated, input)
 }
 
@@ -106,18 +154,20 @@ func (h *WriteHandler) createLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.ID == "" || input.ModuleID == "" || input.Title == "" || len(input.Content) == 0 {
-		respond.Error(w, http.StatusBadRequest, "id, module_id, title, and content are required")
+	if err := validateLessonCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
 
-	if err := h.repo.CreateLesson(r.Context(), input); err != nil {
+	revision, err := h.repo.CreateLesson(r.Context(), input)
+	if err != nil {
 		writeError(w, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(revision))
 	respond.JSON(w, http.StatusCreated, input)
 }
 
@@ -127,18 +177,20 @@ func (h *WriteHandler) createConcept(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.ID == "" || input.Name == "" || input.Definition == "" {
-		respond.Error(w, http.StatusBadRequest, "id, name, and definition are required")
+	if err := validateConceptCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
 
-	if err := h.repo.CreateConcept(r.Context(), input); err != nil {
+	revision, err := h.repo.CreateConcept(r.Context(), input)
+	if err != nil {
 		writeError(w, err)
 
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(revision))
 	respond.JSON(w, http.StatusCreated, input)
 }
 
@@ -150,8 +202,8 @@ func (h *WriteHandler) createConceptReference(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if input.LessonID == "" {
-		respond.Error(w, http.StatusBadRequest, "lesson_id is required")
+	if err := validateConceptReferenceCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
@@ -171,8 +223,8 @@ func (h *WriteHandler) createPrerequisite(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if input.TopicID == "" || input.PrerequisiteTopicID == "" || input.Priority == "" {
-		respond.Error(w, http.StatusBadRequest, "topic_id, prerequisite_topic_id, and priority are required")
+	if err := validatePrerequisiteCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
@@ -192,8 +244,8 @@ func (h *WriteHandler) createRelation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.TopicA == "" || input.TopicB == "" || input.RelationType == "" {
-		respond.Error(w, http.StatusBadRequest, "topic_a, topic_b, and relation_type are required")
+	if err := validateRelationCreate(&input); err != nil {
+		writeError(w, err)
 
 		return
 	}
@@ -207,6 +259,65 @@ func (h *WriteHandler) createRelation(w http.ResponseWriter, r *http.Request) {
 	respond.JSON(w, http.StatusCreated, input)
 }
 
+// validateTopicCreate rejects a topic missing a required field. It and the
+// other create validators are shared by the single-entity endpoints and
+// POST /api/batch.
+func validateTopicCreate(in *models.TopicInput) error {
+	if in.ID == "" || in.Title == "" || in.Status == "" {
+		return &inputError{msg: "id, title, and status are required"}
+	}
+
+	return nil
+}
+
+func validateModuleCreate(in *models.ModuleInput) error {
+	if in.ID == "" || in.TopicID == "" || in.Title == "" {
+		return &inputError{msg: "id, topic_id, and title are required"}
+	}
+
+	return validateModuleSchema(in)
+}
+
+func validateLessonCreate(in *models.LessonInput) error {
+	if in.ID == "" || in.ModuleID == "" || in.Title == "" || len(in.Content) == 0 {
+		return &inputError{msg: "id, module_id, title, and content are required"}
+	}
+
+	return validateLessonSchema(in)
+}
+
+func validateConceptCreate(in *models.ConceptInput) error {
+	if in.ID == "" || in.Name == "" || in.Definition == "" {
+		return &inputError{msg: "id, name, and definition are required"}
+	}
+
+	return nil
+}
+
+func validateConceptReferenceCreate(in *models.ConceptReferenceInput) error {
+	if in.LessonID == "" {
+		return &inputError{msg: "lesson_id is required"}
+	}
+
+	return nil
+}
+
+func validatePrerequisiteCreate(in *models.PrerequisiteInput) error {
+	if in.TopicID == "" || in.PrerequisiteTopicID == "" || in.Priority == "" {
+		return &inputError{msg: "topic_id, prerequisite_topic_id, and priority are required"}
+	}
+
+	return nil
+}
+
+func validateRelationCreate(in *models.RelationInput) error {
+	if in.TopicA == "" || in.TopicB == "" || in.RelationType == "" {
+		return &inputError{msg: "topic_a, topic_b, and relation_type are required"}
+	}
+
+	return nil
+}
+
 const maxRequestBodySize = 2 * 1024 * 1024 // 2 MB
 
 func decodeJSON(w http.ResponseWriter, r *http.Request, dest any) bool {
@@ -221,30 +332,56 @@ func decodeJSON(w http.ResponseWriter, r *http.Request, dest any) bool {
 	return true
 }
 
-func writeError(w http.ResponseWriter, err error) {
-	if errors.Is(err, repository.ErrDuplicate) {
-		respond.Error(w, http.StatusConflict, err.Error())
+// inputError reports a request validation failure, including ones detected
+// inside a repository callback such as a patch that clears a required field.
+// violations is set when a JSON field failed curriculum schema validation.
+type inputError struct {
+	msg        string
+	violations []schema.Violation
+}
 
-		return
-	}
+// validationErrorBody is the 400 response for schema violations.
+type validationErrorBody struct {
+	Error      string             `json:"error"`
+	Violations []schema.Violation `json:"violations"`
+}
+
+func (e *inputError) Error() string { return e.msg }
 
-	if errors.Is(err, repository.ErrFKViolation) {
-		respond.Error(w, http.StatusUnprocessableEntity, err.Error())
+func writeError(w http.ResponseWriter, err error) {
+	var ie *inputError
+	if errors.As(err, &ie) && len(ie.violations) > 0 {
+		respond.JSON(w, http.StatusBadRequest, validationErrorBody{Error: ie.msg, Violations: ie.violations})
 
 		return
 	}
 
-	if errors.Is(err, repository.ErrCheckViolation) {
-		respond.Error(w, http.StatusBadRequest, err.Error())
+	status, msg := errorStatus(err)
+	respond.Error(w, status, msg)
+}
 
-		return
+// errorStatus maps a write error to its HTTP status and client message.
+func errorStatus(err error) (int, string) {
+	var ie *inputError
+	if errors.As(err, &ie) {
+		return http.StatusBadRequest, ie.msg
 	}
 
-	if errors.Is(err, repository.ErrNotFound) {
-		respond.Error(w, http.StatusNotFound, err.Error())
-
-		return
+	switch {
+	case errors.Is(err, repository.ErrDuplicate), errors.Is(err, repository.ErrHasProgress),
+		errors.Is(err, repository.ErrOrderMismatch), errors.Is(err, repository.ErrArchived),
+		errors.Is(err, repository.ErrNoConflict),
+		errors.Is(err, repository.ErrSessionClosed), errors.Is(err, repository.ErrWrongCard):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, repository.ErrFKViolation):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, repository.ErrCheckViolation):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, repository.ErrStaleRevision):
+		return http.StatusPreconditionFailed, err.Error()
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound, err.Error()
 	}
 
-	respond.Error(w, http.StatusInternalServerError, "internal server error")
+	return http.StatusInternalServerError, "internal server error"
 }
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *WriteHandler) archiveModule(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.repo.ArchiveModule)
+}
+
+func (h *WriteHandler) unarchiveModule(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.repo.UnarchiveModule)
+}
+
+func (h *WriteHandler) archiveLesson(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.repo.ArchiveLesson)
+}
+
+func (h *WriteHandler) unarchiveLesson(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.repo.UnarchiveLesson)
+}
+
+// setArchived serves the archive endpoints, which hide a module or lesson
+// from the default read views without deleting it. Both directions are
+// idempotent and return 204.
+func (h *WriteHandler) setArchived(w http.ResponseWriter, r *http.Request, apply func(ctx context.Context, id string) error) {
+	if err := apply(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
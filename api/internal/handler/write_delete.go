@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// forceParam reports whether a delete carries ?force=true. Without it,
+// topic, module, lesson, and concept deletes return 409 when they would
+// remove learning progress or review state. Deletes respond 204 on success.
+func forceParam(r *http.Request) bool {
+	return r.URL.Query().Get("force") == "true"
+}
+
+func (h *WriteHandler) deleteTopic(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteTopic(r.Context(), chi.URLParam(r, "id"), forceParam(r)); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) deleteModule(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteModule(r.Context(), chi.URLParam(r, "id"), forceParam(r)); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) deleteLesson(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteLesson(r.Context(), chi.URLParam(r, "id"), forceParam(r)); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) deleteConcept(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteConcept(r.Context(), chi.URLParam(r, "id"), forceParam(r)); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) deleteConceptReference(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteConceptReference(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "lessonId")); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) deletePrerequisite(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeletePrerequisite(r.Context(), chi.URLParam(r, "topicId"), chi.URLParam(r, "prerequisiteId")); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WriteHandler) deleteRelation(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.DeleteRelation(r.Context(), chi.URLParam(r, "topicA"), chi.URLParam(r, "topicB")); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// mergeConcept folds the source concept into the one in the path. A missing
+// concept returns 404; merging a concept into itself returns 400.
+func (h *WriteHandler) mergeConcept(w http.ResponseWriter, r *http.Request) {
+	var input models.ConceptMergeInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if input.SourceID == "" {
+		respond.Error(w, http.StatusBadRequest, "source_id is required")
+
+		return
+	}
+
+	merge, err := h.repo.MergeConcepts(r.Context(), chi.URLParam(r, "id"), input.SourceID)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, merge)
+}
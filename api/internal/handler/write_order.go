@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+// reorderModules renumbers a topic's modules atomically. A list that is not
+// a permutation of the topic's current modules returns 409, as it does for
+// reorderLessons.
+func (h *WriteHandler) reorderModules(w http.ResponseWriter, r *http.Request) {
+	var input models.ModuleOrderInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := h.repo.ReorderModules(r.Context(), chi.URLParam(r, "id"), input.ModuleIDs); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) reorderLessons(w http.ResponseWriter, r *http.Request) {
+	var input models.LessonOrderInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := h.repo.ReorderLessons(r.Context(), chi.URLParam(r, "id"), input.LessonIDs); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) moveLesson(w http.ResponseWriter, r *http.Request) {
+	var input models.MoveLessonInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if input.ModuleID == "" {
+		respond.Error(w, http.StatusBadRequest, "module_id is required")
+
+		return
+	}
+
+	if input.Position < 0 {
+		respond.Error(w, http.StatusBadRequest, "position must be positive")
+
+		return
+	}
+
+	if err := h.repo.MoveLesson(r.Context(), chi.URLParam(r, "id"), input.ModuleID, input.Position); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, input)
+}
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -17,40 +18,215 @@ import (
 
 type mockWriteRepo struct {
 	returnErr error
+	lastForce bool
 }
 
-func (m *mockWriteRepo) CreateTopic(_ context.Context, _ models.TopicInput) error {
+func (m *mockWriteRepo) CreateTopic(_ context.Context, _ models.TopicInput) (int, error) {
+	return mockCreated(m.returnErr)
+}
+
+func (m *mockWriteRepo) UpdateTopic(_ context.Context, _ string, ifRevision int, _ models.TopicInput) (int, error) {
+	return mockRevisionedUpdate(m.returnErr, ifRevision)
+}
+
+func (m *mockWriteRepo) CreateModule(_ context.Context, _ models.ModuleInput) (int, error) {
+	return mockCreated(m.returnErr)
+}
+
+func (m *mockWriteRepo) CreateLesson(_ context.Context, _ models.LessonInput) (int, error) {
+	return mockCreated(m.returnErr)
+}
+
+func (m *mockWriteRepo) CreateConcept(_ context.Context, _ models.ConceptInput) (int, error) {
+	return mockCreated(m.returnErr)
+}
+
+func (m *mockWriteRepo) CreateConceptReference(_ context.Context, _ string, _ models.ConceptReferenceInput) error {
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) UpdateTopic(_ context.Context, _ string, _ models.TopicInput) error {
+func (m *mockWriteRepo) CreatePrerequisite(_ context.Context, _ models.PrerequisiteInput) error {
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) CreateModule(_ context.Context, _ models.ModuleInput) error {
+func (m *mockWriteRepo) CreateRelation(_ context.Context, _ models.RelationInput) error {
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) CreateLesson(_ context.Context, _ models.LessonInput) error {
+func (m *mockWriteRepo) UpdateModule(_ context.Context, _ string, ifRevision int, _ models.ModuleInput) (int, error) {
+	return mockRevisionedUpdate(m.returnErr, ifRevision)
+}
+
+func (m *mockWriteRepo) UpdateLesson(_ context.Context, _ string, ifRevision int, _ models.LessonInput) (int, error) {
+	return mockRevisionedUpdate(m.returnErr, ifRevision)
+}
+
+func (m *mockWriteRepo) UpdateConcept(_ context.Context, _ string, ifRevision int, _ models.ConceptInput) (int, error) {
+	return mockRevisionedUpdate(m.returnErr, ifRevision)
+}
+
+func (m *mockWriteRepo) UpdateConceptReference(_ context.Context, _, _ string, _ models.ConceptReferenceInput) error {
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) CreateConcept(_ context.Context, _ models.ConceptInput) error {
+func (m *mockWriteRepo) UpdatePrerequisite(_ context.Context, _ models.PrerequisiteInput) error {
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) CreateConceptReference(_ context.Context, _ string, _ models.ConceptReferenceInput) error {
+func (m *mockWriteRepo) UpdateRelation(_ context.Context, _ models.RelationInput) error {
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) CreatePrerequisite(_ context.Context, _ models.PrerequisiteInput) error {
+// mockRevision is the stored revision of every revisioned mock row.
+const mockRevision = 3
+
+// mockRevisionedPatch is mockPatch with the repository's If-Match check.
+func mockRevisionedPatch[T any](returnErr error, ifRevision int, current *T, apply func(*T) error) (*T, int, error) {
+	if ifRevision != 0 && ifRevision != mockRevision {
+		return nil, 0, repository.ErrStaleRevision
+	}
+
+	patched, err := mockPatch(returnErr, current, apply)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return patched, mockRevision + 1, nil
+}
+
+// mockCreated is the repository's result for a create: a new row starts at
+// revision 1.
+func mockCreated(returnErr error) (int, error) {
+	if returnErr != nil {
+		return 0, returnErr
+	}
+
+	return 1, nil
+}
+
+// mockRevisionedUpdate is the repository's If-Match check for a full update.
+func mockRevisionedUpdate(returnErr error, ifRevision int) (int, error) {
+	if ifRevision != 0 && ifRevision != mockRevision {
+		return 0, repository.ErrStaleRevision
+	}
+
+	return mockRevision + 1, returnErr
+}
+
+// mockPatch applies the handler's callback to a stored value so handler
+// validation of the merged result is exercised.
+func mockPatch[T any](returnErr error, current *T, apply func(*T) error) (*T, error) {
+	if returnErr != nil {
+		return nil, returnErr
+	}
+
+	if err := apply(current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (m *mockWriteRepo) PatchTopic(_ context.Context, id string, ifRevision int, apply func(*models.TopicInput) error) (*models.TopicInput, int, error) {
+	return mockRevisionedPatch(m.returnErr, ifRevision, &models.TopicInput{ID: id, Title: "Existing", Status: "draft"}, apply)
+}
+
+func (m *mockWriteRepo) PatchModule(_ context.Context, id string, ifRevision int, apply func(*models.ModuleInput) error) (*models.ModuleInput, int, error) {
+	return mockRevisionedPatch(m.returnErr, ifRevision, &models.ModuleInput{ID: id, TopicID: "t1", Title: "Existing"}, apply)
+}
+
+func (m *mockWriteRepo) PatchLesson(_ context.Context, id string, ifRevision int, apply func(*models.LessonInput) error) (*models.LessonInput, int, error) {
+	return mockRevisionedPatch(m.returnErr, ifRevision, &models.LessonInput{ID: id, ModuleID: "m1", Title: "Existing", Content: []byte(`{"sections":[{"type":"text","body":"Existing"}]}`)}, apply)
+}
+
+func (m *mockWriteRepo) PatchConcept(_ context.Context, id string, ifRevision int, apply func(*models.ConceptInput) error) (*models.ConceptInput, int, error) {
+	return mockRevisionedPatch(m.returnErr, ifRevision, &models.ConceptInput{ID: id, Name: "Existing", Definition: "Def"}, apply)
+}
+
+func (m *mockWriteRepo) PatchConceptReference(_ context.Context, _, lessonID string, apply func(*models.ConceptReferenceInput) error) (*models.ConceptReferenceInput, error) {
+	return mockPatch(m.returnErr, &models.ConceptReferenceInput{LessonID: lessonID}, apply)
+}
+
+func (m *mockWriteRepo) PatchPrerequisite(_ context.Context, topicID, prerequisiteID string, apply func(*models.PrerequisiteInput) error) (*models.PrerequisiteInput, error) {
+	return mockPatch(m.returnErr, &models.PrerequisiteInput{TopicID: topicID, PrerequisiteTopicID: prerequisiteID, Priority: "helpful"}, apply)
+}
+
+func (m *mockWriteRepo) PatchRelation(_ context.Context, topicA, topicB string, apply func(*models.RelationInput) error) (*models.RelationInput, error) {
+	return mockPatch(m.returnErr, &models.RelationInput{TopicA: topicA, TopicB: topicB, RelationType: "related"}, apply)
+}
+
+func (m *mockWriteRepo) DeleteTopic(_ context.Context, _ string, force bool) error {
+	m.lastForce = force
 	return m.returnErr
 }
 
-func (m *mockWriteRepo) CreateRelation(_ context.Context, _ models.RelationInput) error {
+func (m *mockWriteRepo) DeleteModule(_ context.Context, _ string, force bool) error {
+	m.lastForce = force
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) DeleteLesson(_ context.Context, _ string, force bool) error {
+	m.lastForce = force
 	return m.returnErr
 }
 
+func (m *mockWriteRepo) DeleteConcept(_ context.Context, _ string, force bool) error {
+	m.lastForce = force
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) DeleteConceptReference(_ context.Context, _, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) DeletePrerequisite(_ context.Context, _, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) DeleteRelation(_ context.Context, _, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) ReorderModules(_ context.Context, _ string, _ []string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) ReorderLessons(_ context.Context, _ string, _ []string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) MoveLesson(_ context.Context, _, _ string, _ int) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) MergeConcepts(_ context.Context, targetID, sourceID string) (*models.ConceptMerge, error) {
+	if m.returnErr != nil {
+		return nil, m.returnErr
+	}
+
+	return &models.ConceptMerge{TargetID: targetID, SourceID: sourceID}, nil
+}
+
+func (m *mockWriteRepo) ArchiveModule(_ context.Context, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) UnarchiveModule(_ context.Context, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) ArchiveLesson(_ context.Context, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) UnarchiveLesson(_ context.Context, _ string) error {
+	return m.returnErr
+}
+
+func (m *mockWriteRepo) Transaction(_ context.Context, fn func(tx repository.WriteRepository) error) error {
+	return fn(m)
+}
+
 func TestCreateTopicHandler(t *testing.T) {
 	r := chi.NewRouter()
 	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
@@ -63,6 +239,10 @@ func TestCreateTopicHandler(t *testing.T) {
 	if rec.Code != http.StatusCreated {
 		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
 	}
+
+	if etag := rec.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf("expected ETag \"1\", got %q", etag)
+	}
 }
 
 func TestCreateTopicHandlerMissingFields(t *testing.T) {
@@ -124,7 +304,7 @@ func TestCreateLessonHandler(t *testing.T) {
 	r := chi.NewRouter()
 	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
 
-	body := `{"id":"l1","module_id":"m1","title":"Hello","sort_order":1,"content":[{"type":"text"}]}`
+	body := `{"id":"l1","module_id":"m1","title":"Hello","sort_order":1,"content":{"sections":[{"type":"text","body":"Hello"}]}}`
 	req := httptest.NewRequest(http.MethodPost, "/api/lessons", strings.NewReader(body))
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
@@ -154,12 +334,51 @@ func TestUpdateTopicHandler(t *testing.T) {
 
 	body := `{"title":"Updated Title","status":"published"}`
 	req := httptest.NewRequest(http.MethodPut, "/api/topics/t1", strings.NewReader(body))
+	req.Header.Set("If-Match", `"3"`)
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
 
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
 	}
+
+	if etag := rec.Header().Get("ETag"); etag != `"4"` {
+		t.Fatalf("expected ETag \"4\", got %q", etag)
+	}
+}
+
+func TestUpdateHandlersRequireIfMatch(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	tests := []struct {
+		path    string
+		body    string
+		ifMatch string
+		want    int
+	}{
+		{"/api/topics/t1", `{"title":"T","status":"published"}`, "", http.StatusPreconditionRequired},
+		{"/api/topics/t1", `{"title":"T","status":"published"}`, `"2"`, http.StatusPreconditionFailed},
+		{"/api/modules/m1", `{"topic_id":"t1","title":"Intro"}`, "", http.StatusPreconditionRequired},
+		{"/api/modules/m1", `{"topic_id":"t1","title":"Intro"}`, `"2"`, http.StatusPreconditionFailed},
+		{"/api/lessons/l1", `{"module_id":"m1","title":"L","content":{"sections":[{"type":"text","body":"Body"}]}}`, "", http.StatusPreconditionRequired},
+		{"/api/concepts/c1", `{"name":"N","definition":"D"}`, `"2"`, http.StatusPreconditionFailed},
+		{"/api/concepts/c1", `{"name":"N","definition":"D"}`, "*", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("PUT %s with If-Match %q: expected %d, got %d: %s", tt.path, tt.ifMatch, tt.want, rec.Code, rec.Body.String())
+		}
+	}
 }
 
 func TestUpdateTopicHandlerNotFound(t *testing.T) {
@@ -168,6 +387,7 @@ func TestUpdateTopicHandlerNotFound(t *testing.T) {
 
 	body := `{"title":"Updated Title","status":"published"}`
 	req := httptest.NewRequest(http.MethodPut, "/api/topics/nonexistent", strings.NewReader(body))
+	req.Header.Set("If-Match", "*")
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
 
@@ -259,3 +479,296 @@ func TestCheckViolationHandler(t *testing.T) {
 		t.Fatalf("expected 400, got %d", rec.Code)
 	}
 }
+
+func TestUpdateEndpointsRequireFields(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	tests := []struct {
+		path string
+		body string
+		want int
+	}{
+		{"/api/modules/m1", `{"topic_id":"t1","title":"Intro","sort_order":1}`, http.StatusOK},
+		{"/api/modules/m1", `{"title":"Intro"}`, http.StatusBadRequest},
+		{"/api/lessons/l1", `{"module_id":"m1","title":"L","content":{"sections":[{"type":"text","body":"Body"}]}}`, http.StatusOK},
+		{"/api/lessons/l1", `{"module_id":"m1","title":"L"}`, http.StatusBadRequest},
+		{"/api/concepts/c1", `{"name":"N","definition":"D"}`, http.StatusOK},
+		{"/api/concepts/c1", `{"name":"N"}`, http.StatusBadRequest},
+		{"/api/concepts/c1/references/l1", `{"context":"ctx"}`, http.StatusOK},
+		{"/api/prerequisites/t1/t2", `{"priority":"essential"}`, http.StatusOK},
+		{"/api/prerequisites/t1/t2", `{"reason":"why"}`, http.StatusBadRequest},
+		{"/api/relations/t1/t2", `{"relation_type":"related"}`, http.StatusOK},
+		{"/api/relations/t1/t2", `{}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("If-Match", "*")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("PUT %s %s: expected %d, got %d: %s", tt.path, tt.body, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestUpdateModuleHandlerNotFound(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{returnErr: repository.ErrNotFound}).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/modules/missing",
+		strings.NewReader(`{"topic_id":"t1","title":"Intro"}`))
+	req.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestPatchModuleHandlerMergesFields(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/modules/m1", strings.NewReader(`{"title":"Renamed"}`))
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"title":"Renamed"`) || !strings.Contains(body, `"topic_id":"t1"`) {
+		t.Fatalf("expected merged module in response, got %s", body)
+	}
+
+	if etag := rec.Header().Get("ETag"); etag != `"4"` {
+		t.Fatalf("expected ETag \"4\", got %q", etag)
+	}
+}
+
+func TestPatchTopicHandlerPreconditions(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"missing If-Match", "", "", `{"title":"X"}`, http.StatusPreconditionRequired},
+		{"stale revision", `"2"`, "", `{"title":"X"}`, http.StatusPreconditionFailed},
+		{"weak tag", `W/"3"`, "", `{"title":"X"}`, http.StatusPreconditionFailed},
+		{"malformed tag", `3`, "", `{"title":"X"}`, http.StatusBadRequest},
+		{"wrong content type", `"3"`, "text/plain", `{"title":"X"}`, http.StatusUnsupportedMediaType},
+		{"non-object patch", `"3"`, "", `["title"]`, http.StatusBadRequest},
+		{"null clears required field", `"3"`, "", `{"status":null}`, http.StatusBadRequest},
+		{"merge patch content type", `"3"`, "application/merge-patch+json", `{"description":null}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPatch, "/api/topics/t1", strings.NewReader(tt.body))
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestPatchModuleHandlerRejectsClearedRequiredField(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/modules/m1", strings.NewReader(`{"title":""}`))
+	req.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeleteLessonHandler(t *testing.T) {
+	repo := &mockWriteRepo{}
+	r := chi.NewRouter()
+	handler.NewWriteHandler(repo).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/lessons/l1?force=true", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+
+	if !repo.lastForce {
+		t.Fatal("expected force=true to be passed to repository")
+	}
+}
+
+func TestDeleteLessonHandlerWithProgress(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{returnErr: repository.ErrHasProgress}).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/lessons/l1", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestCreateLessonHandlerReportsSchemaViolations(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	body := `{"id":"l1","module_id":"m1","title":"Hello","sort_order":1,
+		"content":{"sections":[{"type":"video","url":"x"},{"type":"text","body":"ok"}]},
+		"exercises":[{"type":"hands_on","title":"T"}],
+		"review_questions":[{"question":"Q","answer":"A","concepts_tested":[]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/lessons", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Violations []struct {
+			Path string `json:"path"`
+		} `json:"violations"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	paths := make(map[string]bool)
+	for _, v := range resp.Violations {
+		paths[v.Path] = true
+	}
+
+	for _, want := range []string{"/content/sections/0/type", "/exercises/0", "/exercises/0/type"} {
+		if !paths[want] {
+			t.Fatalf("expected violation at %s, got %+v", want, resp.Violations)
+		}
+	}
+}
+
+func TestPatchModuleHandlerValidatesAssessment(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewWriteHandler(&mockWriteRepo{}).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/modules/m1",
+		strings.NewReader(`{"assessment":{"questions":[{"type":"essay","question":"Q"}]}}`))
+	req.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "/assessment/questions/0/type") {
+		t.Fatalf("expected 400 with assessment violation, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestReorderHandlers(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   *mockWriteRepo
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"module order", &mockWriteRepo{}, http.MethodPut, "/api/topics/t1/module-order", `{"module_ids":["m2","m1"]}`, http.StatusOK},
+		{"stale lesson order", &mockWriteRepo{returnErr: repository.ErrOrderMismatch}, http.MethodPut, "/api/modules/m1/lesson-order", `{"lesson_ids":["l1"]}`, http.StatusConflict},
+		{"move lesson", &mockWriteRepo{}, http.MethodPost, "/api/lessons/l1/move", `{"module_id":"m2","position":1}`, http.StatusOK},
+		{"move into archived module", &mockWriteRepo{returnErr: repository.ErrArchived}, http.MethodPost, "/api/lessons/l1/move", `{"module_id":"m2","position":1}`, http.StatusConflict},
+		{"move without module", &mockWriteRepo{}, http.MethodPost, "/api/lessons/l1/move", `{"position":1}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		r := chi.NewRouter()
+		handler.NewWriteHandler(tt.repo).RegisterRoutes(r)
+
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestArchiveHandlers(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *mockWriteRepo
+		path string
+		want int
+	}{
+		{"archive module", &mockWriteRepo{}, "/api/modules/m1/archive", http.StatusNoContent},
+		{"unarchive module", &mockWriteRepo{}, "/api/modules/m1/unarchive", http.StatusNoContent},
+		{"archive lesson", &mockWriteRepo{}, "/api/lessons/l1/archive", http.StatusNoContent},
+		{"unarchive missing lesson", &mockWriteRepo{returnErr: repository.ErrNotFound}, "/api/lessons/missing/unarchive", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		r := chi.NewRouter()
+		handler.NewWriteHandler(tt.repo).RegisterRoutes(r)
+
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMergeConceptHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *mockWriteRepo
+		body string
+		want int
+	}{
+		{"merge", &mockWriteRepo{}, `{"source_id":"c2"}`, http.StatusOK},
+		{"missing source id", &mockWriteRepo{}, `{}`, http.StatusBadRequest},
+		{"unknown concept", &mockWriteRepo{returnErr: repository.ErrNotFound}, `{"source_id":"nope"}`, http.StatusNotFound},
+		{"self merge", &mockWriteRepo{returnErr: repository.ErrCheckViolation}, `{"source_id":"c1"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		r := chi.NewRouter()
+		handler.NewWriteHandler(tt.repo).RegisterRoutes(r)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/concepts/c1/merge", strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
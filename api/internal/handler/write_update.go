@@ -0,0 +1,414 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sean/apollo/api/internal/mergepatch"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/respond"
+)
+
+func validateTopicUpdate(in *models.TopicInput) error {
+	if in.Title == "" || in.Status == "" {
+		return &inputError{msg: "title and status are required"}
+	}
+
+	return nil
+}
+
+func validateModuleUpdate(in *models.ModuleInput) error {
+	if in.TopicID == "" || in.Title == "" {
+		return &inputError{msg: "topic_id and title are required"}
+	}
+
+	return validateModuleSchema(in)
+}
+
+func validateLessonUpdate(in *models.LessonInput) error {
+	if in.ModuleID == "" || in.Title == "" || len(in.Content) == 0 {
+		return &inputError{msg: "module_id, title, and content are required"}
+	}
+
+	return validateLessonSchema(in)
+}
+
+func validateConceptUpdate(in *models.ConceptInput) error {
+	if in.Name == "" || in.Definition == "" {
+		return &inputError{msg: "name and definition are required"}
+	}
+
+	return nil
+}
+
+func validatePrerequisiteUpdate(in *models.PrerequisiteInput) error {
+	if in.Priority == "" {
+		return &inputError{msg: "priority is required"}
+	}
+
+	return nil
+}
+
+func validateRelationUpdate(in *models.RelationInput) error {
+	if in.RelationType == "" {
+		return &inputError{msg: "relation_type is required"}
+	}
+
+	return nil
+}
+
+// decodeMergePatch reads a merge patch body. Both application/merge-patch+json
+// and application/json are accepted.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request) (json.RawMessage, bool) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != mergepatch.ContentType && mediaType != "application/json") {
+			respond.Error(w, http.StatusUnsupportedMediaType, "PATCH requires "+mergepatch.ContentType)
+
+			return nil, false
+		}
+	}
+
+	var body json.RawMessage
+	if !decodeJSON(w, r, &body) {
+		return nil, false
+	}
+
+	return body, true
+}
+
+// mergePatchInto returns a patch callback that applies body to the current
+// value as a JSON Merge Patch (RFC 7396), where omitted fields keep their
+// values and null clears them, and validates the result. PATCH validation
+// is PUT's: the same fields as create, minus the identifiers in the URL.
+func mergePatchInto[T any](body json.RawMessage, validate func(*T) error) func(*T) error {
+	return func(current *T) error {
+		doc, err := json.Marshal(current)
+		if err != nil {
+			return fmt.Errorf("encode current value: %w", err)
+		}
+
+		merged, err := mergepatch.ApplyObject(doc, body)
+		if err != nil {
+			return &inputError{msg: "invalid merge patch: " + err.Error()}
+		}
+
+		var next T
+		if err := json.Unmarshal(merged, &next); err != nil {
+			return &inputError{msg: "invalid merge patch: " + err.Error()}
+		}
+
+		if err := validate(&next); err != nil {
+			return err
+		}
+
+		*current = next
+
+		return nil
+	}
+}
+
+func (h *WriteHandler) patchTopic(w http.ResponseWriter, r *http.Request) {
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	patched, revision, err := h.repo.PatchTopic(r.Context(), chi.URLParam(r, "id"), ifRevision, mergePatchInto(body, validateTopicUpdate))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, patched)
+}
+
+func (h *WriteHandler) updateModule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var input models.ModuleInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := validateModuleUpdate(&input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	revision, err := h.repo.UpdateModule(r.Context(), id, ifRevision, input)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	input.ID = id
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) patchModule(w http.ResponseWriter, r *http.Request) {
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	patched, revision, err := h.repo.PatchModule(r.Context(), chi.URLParam(r, "id"), ifRevision, mergePatchInto(body, validateModuleUpdate))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, patched)
+}
+
+func (h *WriteHandler) updateLesson(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var input models.LessonInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := validateLessonUpdate(&input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	revision, err := h.repo.UpdateLesson(r.Context(), id, ifRevision, input)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	input.ID = id
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) patchLesson(w http.ResponseWriter, r *http.Request) {
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	patched, revision, err := h.repo.PatchLesson(r.Context(), chi.URLParam(r, "id"), ifRevision, mergePatchInto(body, validateLessonUpdate))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, patched)
+}
+
+func (h *WriteHandler) updateConcept(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var input models.ConceptInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := validateConceptUpdate(&input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	revision, err := h.repo.UpdateConcept(r.Context(), id, ifRevision, input)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	input.ID = id
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) patchConcept(w http.ResponseWriter, r *http.Request) {
+	ifRevision, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	patched, revision, err := h.repo.PatchConcept(r.Context(), chi.URLParam(r, "id"), ifRevision, mergePatchInto(body, validateConceptUpdate))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(revision))
+	respond.JSON(w, http.StatusOK, patched)
+}
+
+func (h *WriteHandler) updateConceptReference(w http.ResponseWriter, r *http.Request) {
+	conceptID := chi.URLParam(r, "id")
+	lessonID := chi.URLParam(r, "lessonId")
+
+	var input models.ConceptReferenceInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := h.repo.UpdateConceptReference(r.Context(), conceptID, lessonID, input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	input.LessonID = lessonID
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) patchConceptReference(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	noValidation := func(*models.ConceptReferenceInput) error { return nil }
+
+	patched, err := h.repo.PatchConceptReference(r.Context(),
+		chi.URLParam(r, "id"), chi.URLParam(r, "lessonId"), mergePatchInto(body, noValidation))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, patched)
+}
+
+func (h *WriteHandler) updatePrerequisite(w http.ResponseWriter, r *http.Request) {
+	var input models.PrerequisiteInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	input.TopicID = chi.URLParam(r, "topicId")
+	input.PrerequisiteTopicID = chi.URLParam(r, "prerequisiteId")
+
+	if err := validatePrerequisiteUpdate(&input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if err := h.repo.UpdatePrerequisite(r.Context(), input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) patchPrerequisite(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	patched, err := h.repo.PatchPrerequisite(r.Context(),
+		chi.URLParam(r, "topicId"), chi.URLParam(r, "prerequisiteId"), mergePatchInto(body, validatePrerequisiteUpdate))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, patched)
+}
+
+func (h *WriteHandler) updateRelation(w http.ResponseWriter, r *http.Request) {
+	var input models.RelationInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	input.TopicA = chi.URLParam(r, "topicA")
+	input.TopicB = chi.URLParam(r, "topicB")
+
+	if err := validateRelationUpdate(&input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if err := h.repo.UpdateRelation(r.Context(), input); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, input)
+}
+
+func (h *WriteHandler) patchRelation(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	patched, err := h.repo.PatchRelation(r.Context(),
+		chi.URLParam(r, "topicA"), chi.URLParam(r, "topicB"), mergePatchInto(body, validateRelationUpdate))
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, patched)
+}
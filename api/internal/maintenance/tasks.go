@@ -0,0 +1,214 @@
+// Package maintenance implements the periodic housekeeping tasks run by the
+// scheduler: staleness sweeps, due-review counts, backups, research work
+// directory cleanup, and FTS optimization.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/scheduler"
+	"github.com/sean/apollo/api/internal/staleness"
+)
+
+// Task names, as shown by GET /api/admin/tasks.
+const (
+	TaskStalenessSweep = "staleness-sweep"
+	TaskReviewDue      = "review-due"
+	TaskBackup         = "backup"
+	TaskWorkDirCleanup = "work-dir-cleanup"
+	TaskFTSOptimize    = "fts-optimize"
+)
+
+// backupPrefix and backupSuffix frame the timestamp in backup file names.
+const (
+	backupPrefix = "apollo-"
+	backupSuffix = ".db"
+)
+
+// Deps holds what the maintenance tasks need. Logger receives the due-review
+// notification.
+type Deps struct {
+	Repo    repository.MaintenanceRepository
+	Jobs    repository.ResearchJobRepository
+	Sweeper *staleness.Sweeper
+	Logger  zerolog.Logger
+}
+
+// Tasks returns the maintenance tasks with their schedules from cfg.
+func Tasks(cfg config.Config, deps Deps) []scheduler.Task {
+	stalenessSpec := cfg.Schedules.StalenessSweep
+	if cfg.CurriculumStale <= 0 {
+		stalenessSpec = scheduler.DisabledSpec
+	}
+
+	return []scheduler.Task{
+		{
+			Name: TaskStalenessSweep,
+			Spec: stalenessSpec,
+			Run: func(ctx context.Context) (string, error) {
+				sweep, err := deps.Sweeper.Sweep(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				return fmt.Sprintf("flagged %d topics, queued %d refresh jobs", sweep.TopicsFlagged, sweep.JobsQueued), nil
+			},
+		},
+		{
+			Name: TaskReviewDue,
+			Spec: cfg.Schedules.ReviewDue,
+			Run: func(ctx context.Context) (string, error) {
+				due, err := deps.Repo.CountDueReviews(ctx, time.Now())
+				if err != nil {
+					return "", err
+				}
+
+				if due > 0 {
+					deps.Logger.Warn().Int("due", due).Msg("cards due for review")
+				}
+
+				return fmt.Sprintf("%d cards due for review", due), nil
+			},
+		},
+		{
+			Name: TaskBackup,
+			Spec: cfg.Schedules.Backup,
+			Run: func(ctx context.Context) (string, error) {
+				path, err := Backup(ctx, deps.Repo, cfg.BackupDir, cfg.BackupRetain, time.Now())
+				if err != nil {
+					return "", err
+				}
+
+				return "wrote " + path, nil
+			},
+		},
+		{
+			Name: TaskWorkDirCleanup,
+			Spec: cfg.Schedules.WorkDirCleanup,
+			Run: func(ctx context.Context) (string, error) {
+				retention := time.Duration(cfg.WorkDirRetention) * 24 * time.Hour
+
+				removed, err := CleanWorkDirs(ctx, deps.Jobs, cfg.ResearchWorkDir, retention, time.Now())
+				if err != nil {
+					return "", err
+				}
+
+				return fmt.Sprintf("removed %d work directories", removed), nil
+			},
+		},
+		{
+			Name: TaskFTSOptimize,
+			Spec: cfg.Schedules.FTSOptimize,
+			Run: func(ctx context.Context) (string, error) {
+				if err := deps.Repo.OptimizeSearchIndex(ctx); err != nil {
+					return "", err
+				}
+
+				return "search index optimized", nil
+			},
+		},
+	}
+}
+
+// Backup writes a timestamped copy of the database into dir and deletes the
+// oldest backups beyond retain. A non-positive retain keeps every backup.
+func Backup(ctx context.Context, repo repository.MaintenanceRepository, dir string, retain int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+now.UTC().Format("20060102T150405Z")+backupSuffix)
+	if err := repo.BackupTo(ctx, path); err != nil {
+		return "", err
+	}
+
+	if retain <= 0 {
+		return path, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("list backups: %w", err)
+	}
+
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) && strings.HasSuffix(e.Name(), backupSuffix) {
+			backups = append(backups, e.Name())
+		}
+	}
+
+	// Timestamped names sort chronologically.
+	sort.Strings(backups)
+
+	for len(backups) > retain {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return "", fmt.Errorf("prune backup %s: %w", backups[0], err)
+		}
+
+		backups = backups[1:]
+	}
+
+	return path, nil
+}
+
+// CleanWorkDirs removes research work directories under root that have not
+// been modified within retention and whose job is finished or no longer
+// exists. Directories of queued or running jobs are always kept.
+func CleanWorkDirs(ctx context.Context, jobs repository.ResearchJobRepository, root string, retention time.Duration, now time.Time) (int, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("list work dirs: %w", err)
+	}
+
+	cutoff := now.Add(-retention)
+	removed := 0
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			return removed, fmt.Errorf("stat work dir %s: %w", e.Name(), err)
+		}
+
+		if info.ModTime().After(cutoff) {
+			continue
+		}
+
+		job, err := jobs.GetJobByID(ctx, e.Name())
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return removed, err
+		}
+
+		if job != nil && !models.IsTerminalStatus(job.Status) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			return removed, fmt.Errorf("remove work dir %s: %w", e.Name(), err)
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
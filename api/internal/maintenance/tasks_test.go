@@ -0,0 +1,213 @@
+package maintenance_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/maintenance"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/scheduler"
+)
+
+func openHandle(t *testing.T) *database.Handle {
+	t.Helper()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	handle, err := database.Open(context.Background(), filepath.Join(t.TempDir(), "test.db"), logger)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+
+	t.Cleanup(func() { _ = handle.Close() })
+
+	return handle
+}
+
+func TestBackupPrunesOldest(t *testing.T) {
+	handle := openHandle(t)
+	repo := repository.NewMaintenanceRepository(handle.ReadDB, handle.DB)
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	// Unrelated files in the backup dir are never pruned.
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		if _, err := maintenance.Backup(context.Background(), repo, dir, 2, start.Add(time.Duration(i)*24*time.Hour)); err != nil {
+			t.Fatalf("backup %d: %v", i, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	sort.Strings(names)
+
+	want := []string{"apollo-20260303T020000Z.db", "apollo-20260304T020000Z.db", "notes.txt"}
+	if len(names) != len(want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, names)
+		}
+	}
+}
+
+func TestCleanWorkDirsKeepsActiveAndRecent(t *testing.T) {
+	handle := openHandle(t)
+	jobs := repository.NewResearchJobRepository(handle.ReadDB, handle.DB)
+	root := t.TempDir()
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+
+	mustJob := func(id, status string) {
+		t.Helper()
+
+		if _, err := handle.DB.Exec(`INSERT INTO research_jobs (id, root_topic, status) VALUES (?, 'Topic', ?)`, id, status); err != nil {
+			t.Fatalf("seed job %s: %v", id, err)
+		}
+	}
+
+	mustDir := func(name string, modTime time.Time) {
+		t.Helper()
+
+		path := filepath.Join(root, name)
+		if err := os.Mkdir(path, 0o750); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("chtimes %s: %v", name, err)
+		}
+	}
+
+	mustJob("done", "published")
+	mustJob("active", "researching")
+	mustJob("recent", "failed")
+
+	mustDir("done", old)
+	mustDir("active", old)
+	mustDir("recent", now)
+	mustDir("orphan", old)
+
+	removed, err := maintenance.CleanWorkDirs(context.Background(), jobs, root, 7*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+
+	if removed != 2 {
+		t.Fatalf("expected 2 removed, got %d", removed)
+	}
+
+	for name, keep := range map[string]bool{"done": false, "orphan": false, "active": true, "recent": true} {
+		_, err := os.Stat(filepath.Join(root, name))
+		if exists := err == nil; exists != keep {
+			t.Fatalf("%s: expected kept=%v, got exists=%v", name, keep, exists)
+		}
+	}
+
+	// A missing root is not an error: nothing has run yet.
+	if n, err := maintenance.CleanWorkDirs(context.Background(), jobs, filepath.Join(root, "missing"), time.Hour, now); err != nil || n != 0 {
+		t.Fatalf("missing root: n=%d err=%v", n, err)
+	}
+}
+
+func TestTasksRegister(t *testing.T) {
+	handle := openHandle(t)
+	cfg := config.Config{Schedules: config.Schedules{
+		StalenessSweep: "0 3 * * *",
+		ReviewDue:      "0 7 * * *",
+		Backup:         "0 2 * * *",
+		WorkDirCleanup: "0 4 * * *",
+		FTSOptimize:    "30 4 * * 0",
+	}}
+
+	tasks := maintenance.Tasks(cfg, maintenance.Deps{
+		Repo: repository.NewMaintenanceRepository(handle.ReadDB, handle.DB),
+		Jobs: repository.NewResearchJobRepository(handle.ReadDB, handle.DB),
+	})
+
+	sched := scheduler.New(repository.NewTaskRunRepository(handle.ReadDB, handle.DB), zerolog.Nop())
+	for _, task := range tasks {
+		if err := sched.Register(task); err != nil {
+			t.Fatalf("register %s: %v", task.Name, err)
+		}
+	}
+
+	statuses, err := sched.Tasks(context.Background())
+	if err != nil {
+		t.Fatalf("tasks: %v", err)
+	}
+
+	if len(statuses) != 5 {
+		t.Fatalf("expected 5 tasks, got %d", len(statuses))
+	}
+
+	// CurriculumStale is zero, so the staleness sweep is manual-only.
+	for _, s := range statuses {
+		if s.Name == maintenance.TaskStalenessSweep && s.Spec != "" {
+			t.Fatalf("expected staleness sweep to be unscheduled, got %q", s.Spec)
+		}
+	}
+}
+
+func TestReviewDueNotifies(t *testing.T) {
+	handle := openHandle(t)
+
+	for _, stmt := range []string{
+		`INSERT INTO topics (id, title, status) VALUES ('t1', 'Topic', 'published')`,
+		`INSERT INTO concepts (id, name, definition, defined_in_topic) VALUES ('c1', 'Channel', 'def', 't1')`,
+		`INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c1', 'reviewing', '2026-03-01')`,
+	} {
+		if _, err := handle.DB.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	var logs bytes.Buffer
+	tasks := maintenance.Tasks(config.Config{}, maintenance.Deps{
+		Repo:   repository.NewMaintenanceRepository(handle.ReadDB, handle.DB),
+		Logger: zerolog.New(&logs),
+	})
+
+	for _, task := range tasks {
+		if task.Name != maintenance.TaskReviewDue {
+			continue
+		}
+
+		summary, err := task.Run(context.Background())
+		if err != nil || summary != "1 cards due for review" {
+			t.Fatalf("Run() = %q, %v", summary, err)
+		}
+	}
+
+	if out := logs.String(); !strings.Contains(out, `"level":"warn"`) || !strings.Contains(out, `"due":1`) {
+		t.Fatalf("expected a warn notification with the due count, got %q", out)
+	}
+}
@@ -0,0 +1,92 @@
+// Package mergepatch implements JSON Merge Patch (RFC 7396).
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ContentType is the media type registered for merge patch documents.
+const ContentType = "application/merge-patch+json"
+
+// ErrNotObject is returned by ApplyObject when the patch is not a JSON object.
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Apply applies patch to target and returns the merged document. Members set
+// to null in the patch are removed, objects are merged recursively, and every
+// other value (including arrays) replaces the target value.
+func Apply(target, patch []byte) ([]byte, error) {
+	patchValue, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+
+	var targetValue any
+	if len(bytes.TrimSpace(target)) > 0 {
+		targetValue, err = decode(target)
+		if err != nil {
+			return nil, fmt.Errorf("decode target: %w", err)
+		}
+	}
+
+	merged, err := json.Marshal(merge(targetValue, patchValue))
+	if err != nil {
+		return nil, fmt.Errorf("encode merged document: %w", err)
+	}
+
+	return merged, nil
+}
+
+// ApplyObject is Apply restricted to patches whose top level is an object,
+// which is the only meaningful shape when patching a stored resource.
+func ApplyObject(target, patch []byte) ([]byte, error) {
+	patchValue, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+
+	if _, ok := patchValue.(map[string]any); !ok {
+		return nil, ErrNotObject
+	}
+
+	return Apply(target, patch)
+}
+
+func merge(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+
+			continue
+		}
+
+		targetObj[key] = merge(targetObj[key], value)
+	}
+
+	return targetObj
+}
+
+// decode preserves number literals so merging never changes their precision.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
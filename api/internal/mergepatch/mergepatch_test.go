@@ -0,0 +1,82 @@
+package mergepatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/mergepatch"
+)
+
+// Test cases from RFC 7396 Appendix A.
+func TestApplyRFC7396Examples(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := mergepatch.Apply([]byte(tt.target), []byte(tt.patch))
+		if err != nil {
+			t.Fatalf("Apply(%s, %s) returned error: %v", tt.target, tt.patch, err)
+		}
+
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Fatalf("Apply(%s, %s) = %s, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPreservesNumbers(t *testing.T) {
+	got, err := mergepatch.Apply([]byte(`{"a":12345678901234567890}`), []byte(`{"b":1.50}`))
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	if string(got) != `{"a":12345678901234567890,"b":1.50}` {
+		t.Fatalf("expected number literals to be preserved, got %s", got)
+	}
+}
+
+func TestApplyObjectRejectsNonObjectPatch(t *testing.T) {
+	if _, err := mergepatch.ApplyObject([]byte(`{"a":1}`), []byte(`[1]`)); !errors.Is(err, mergepatch.ErrNotObject) {
+		t.Fatalf("expected ErrNotObject, got %v", err)
+	}
+
+	if _, err := mergepatch.ApplyObject([]byte(`{"a":1}`), []byte(`{"a"`)); err == nil {
+		t.Fatal("expected error for malformed patch")
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("unmarshal %s: %v", a, err)
+	}
+
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("unmarshal %s: %v", b, err)
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "encoding/json"
+
+// Audit actions matching the DB CHECK constraint.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Audited entity types. Relationship rows use "<first>/<second>" as their
+// entity ID, e.g. "concept-id/lesson-id" for a concept reference.
+const (
+	AuditEntityTopic            = "topic"
+	AuditEntityModule           = "module"
+	AuditEntityLesson           = "lesson"
+	AuditEntityConcept          = "concept"
+	AuditEntityConceptReference = "concept_reference"
+	AuditEntityPrerequisite     = "prerequisite"
+	AuditEntityRelation         = "relation"
+)
+
+// AuditRecord is one recorded change to a curriculum row. Before is null for
+// a create and After is null for a delete; otherwise both hold the row's
+// columns as a JSON object.
+type AuditRecord struct {
+	ID           int64           `json:"id"`
+	EntityType   string          `json:"entity_type"`
+	EntityID     string          `json:"entity_id"`
+	Action       string          `json:"action"`
+	Actor        string          `json:"actor"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	RevertedFrom *int64          `json:"reverted_from,omitempty"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// HistoryFilter narrows GET /api/history. Empty fields match everything.
+type HistoryFilter struct {
+	EntityType string
+	EntityID   string
+}
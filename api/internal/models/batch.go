@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/sean/apollo/api/internal/schema"
+)
+
+// Batch operation kinds.
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// Batch entity types, one per WriteRepository entity.
+const (
+	BatchEntityTopic            = "topic"
+	BatchEntityModule           = "module"
+	BatchEntityLesson           = "lesson"
+	BatchEntityConcept          = "concept"
+	BatchEntityConceptReference = "concept_reference"
+	BatchEntityPrerequisite     = "prerequisite"
+	BatchEntityRelation         = "relation"
+)
+
+// BatchRequest is the request body for POST /api/batch.
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is one write in a batch. Data holds the same body the
+// matching single-entity endpoint accepts. ID names the row for topic,
+// module, lesson, and concept updates and deletes, and the concept for
+// concept_reference operations. Composite keys (reference lesson_id,
+// prerequisite and relation topic pairs) are read from Data.
+type BatchOperation struct {
+	Op     string          `json:"op"`
+	Entity string          `json:"entity"`
+	ID     string          `json:"id,omitempty"`
+	Force  bool            `json:"force,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// BatchResult reports the outcome of one operation in a committed batch.
+// Data echoes the written input and is omitted for deletes.
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Entity string `json:"entity"`
+	ID     string `json:"id,omitempty"`
+	Data   any    `json:"data,omitempty"`
+}
+
+// BatchResponse is the response for a committed batch.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchError is the response when a batch is rejected or rolled back.
+// Index identifies the operation that failed. Violations lists schema
+// failures in that operation's data.
+type BatchError struct {
+	Error      string             `json:"error"`
+	Index      int                `json:"index"`
+	Violations []schema.Violation `json:"violations,omitempty"`
+}
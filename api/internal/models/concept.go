@@ -23,6 +23,7 @@ type ConceptDetail struct {
 	FlashcardBack   string             `json:"flashcard_back,omitempty"`
 	Status          string             `json:"status"`
 	Aliases         []string           `json:"aliases,omitempty"`
+	Revision        int                `json:"revision"`
 	References      []ConceptReference `json:"references,omitempty"`
 }
 
@@ -32,3 +33,58 @@ type ConceptReference struct {
 	LessonTitle string `json:"lesson_title"`
 	Context     string `json:"context,omitempty"`
 }
+
+// ConceptMerge reports what POST /api/concepts/{id}/merge changed.
+// RetentionFrom names whose review state the survivor kept: "target",
+// "source", or "" when neither concept had any.
+type ConceptMerge struct {
+	TargetID         string   `json:"target_id"`
+	SourceID         string   `json:"source_id"`
+	ReferencesMoved  int      `json:"references_moved"`
+	RetentionFrom    string   `json:"retention_from,omitempty"`
+	LessonsRewritten []string `json:"lessons_rewritten"`
+	ModulesRewritten []string `json:"modules_rewritten"`
+	Aliases          []string `json:"aliases"`
+}
+
+// Concept status constants matching the DB CHECK constraint.
+const (
+	ConceptStatusActive     = "active"
+	ConceptStatusUnresolved = "unresolved"
+	ConceptStatusConflict   = "conflict"
+)
+
+// ConceptCandidate is one competing definition of a conflicted concept,
+// with the lesson and topic whose curriculum proposed it.
+type ConceptCandidate struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	Definition        string `json:"definition"`
+	FlashcardFront    string `json:"flashcard_front,omitempty"`
+	FlashcardBack     string `json:"flashcard_back,omitempty"`
+	SourceLessonID    string `json:"source_lesson_id,omitempty"`
+	SourceLessonTitle string `json:"source_lesson_title,omitempty"`
+	SourceTopicID     string `json:"source_topic_id,omitempty"`
+	CreatedAt         string `json:"created_at"`
+}
+
+// ConceptConflict is the response item for GET /api/concepts/conflicts: the
+// concept's current definition next to every candidate.
+type ConceptConflict struct {
+	ConceptID  string             `json:"concept_id"`
+	Name       string             `json:"name"`
+	Definition string             `json:"definition"`
+	Candidates []ConceptCandidate `json:"candidates"`
+}
+
+// ConceptResolution reports the canonical definition chosen by
+// POST /api/concepts/{id}/resolve.
+type ConceptResolution struct {
+	ConceptID       string `json:"concept_id"`
+	Definition      string `json:"definition"`
+	FlashcardFront  string `json:"flashcard_front,omitempty"`
+	FlashcardBack   string `json:"flashcard_back,omitempty"`
+	DefinedInLesson string `json:"defined_in_lesson,omitempty"`
+	DefinedInTopic  string `json:"defined_in_topic,omitempty"`
+	RetentionReset  bool   `json:"retention_reset"`
+}
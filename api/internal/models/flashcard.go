@@ -0,0 +1,78 @@
+package models
+
+// Flashcard is a concept's flashcard as the Anki and CSV exports write it.
+// Front and Back fall back to the concept's name and definition when it has
+// no flashcard of its own. The topic and module fields are empty for a
+// concept without a topic or defining lesson.
+type Flashcard struct {
+	ConceptID  string
+	Name       string
+	Front      string
+	Back       string
+	TopicID    string
+	TopicTitle string
+	TopicTags  []string
+	ModuleID   string
+}
+
+// FlashcardFilter selects the concepts a flashcard export covers. Each
+// non-empty list narrows the export: to concepts defined in one of
+// TopicIDs, to the concepts in ConceptIDs, and to concepts whose topic has
+// one of Tags. An empty filter exports every concept.
+type FlashcardFilter struct {
+	TopicIDs   []string
+	ConceptIDs []string
+	Tags       []string
+}
+
+// Ways POST /api/anki/import matches an Anki card to a concept.
+const (
+	AnkiMatchGUID  = "guid"
+	AnkiMatchName  = "name"
+	AnkiMatchAlias = "alias"
+)
+
+// AnkiCard is a card read from an Anki collection: its note's GUID, the
+// concept ID that GUID names when Apollo exported the note, the note's
+// first field as plain text, and the card's reviews, oldest first.
+type AnkiCard struct {
+	ID        int64
+	NoteGUID  string
+	ConceptID string
+	Front     string
+	Reviews   []AnkiReview
+}
+
+// AnkiReview is one answer in an Anki review log.
+type AnkiReview struct {
+	ReviewedAt string
+	Rating     string
+	ResponseMS int
+}
+
+// AnkiImportReport is the response for POST /api/anki/import. Reviews
+// counts the reviews replayed into Concepts, the states the import stored
+// (or would store, on a dry run). SkippedConcepts already had review
+// history in Apollo and were left unchanged.
+type AnkiImportReport struct {
+	DryRun          bool               `json:"dry_run"`
+	Cards           int                `json:"cards"`
+	Reviews         int                `json:"reviews"`
+	Matched         []AnkiCardMatch    `json:"matched"`
+	Ambiguous       []AnkiCardMatch    `json:"ambiguous"`
+	Unmatched       []AnkiCardMatch    `json:"unmatched"`
+	SkippedConcepts []string           `json:"skipped_concepts"`
+	Concepts        []ConceptRetention `json:"concepts"`
+}
+
+// AnkiCardMatch is how the import matched one Anki card: to ConceptID by
+// MatchedBy, to several Candidates when ambiguous, or to nothing.
+type AnkiCardMatch struct {
+	CardID     int64    `json:"card_id"`
+	NoteGUID   string   `json:"note_guid"`
+	Front      string   `json:"front"`
+	Reviews    int      `json:"reviews"`
+	MatchedBy  string   `json:"matched_by,omitempty"`
+	ConceptID  string   `json:"concept_id,omitempty"`
+	Candidates []string `json:"candidates,omitempty"`
+}
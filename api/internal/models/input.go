@@ -16,6 +16,7 @@ type TopicInput struct {
 	GeneratedAt    string   `json:"generated_at,omitempty"`
 	GeneratedBy    string   `json:"generated_by,omitempty"`
 	ParentTopicID  string   `json:"parent_topic_id,omitempty"`
+	Aliases        []string `json:"aliases,omitempty"`
 }
 
 // ModuleInput is the request body for creating a module.
@@ -79,3 +80,37 @@ type RelationInput struct {
 	RelationType string `json:"relation_type"`
 	Description  string `json:"description,omitempty"`
 }
+
+// ModuleOrderInput is the request body for PUT /api/topics/{id}/module-order.
+type ModuleOrderInput struct {
+	ModuleIDs []string `json:"module_ids"`
+}
+
+// LessonOrderInput is the request body for PUT /api/modules/{id}/lesson-order.
+type LessonOrderInput struct {
+	LessonIDs []string `json:"lesson_ids"`
+}
+
+// ConceptMergeInput is the request body for POST /api/concepts/{id}/merge.
+// The concept in the path survives; SourceID is folded into it and deleted.
+type ConceptMergeInput struct {
+	SourceID string `json:"source_id"`
+}
+
+// ResolveConflictInput is the request body for POST /api/concepts/{id}/resolve.
+// One candidate ID picks that candidate; several merge them, joining their
+// definitions in the given order. Definition and the flashcard fields, when
+// set, override whatever the candidates supply.
+type ResolveConflictInput struct {
+	CandidateIDs   []int64 `json:"candidate_ids,omitempty"`
+	Definition     string  `json:"definition,omitempty"`
+	FlashcardFront string  `json:"flashcard_front,omitempty"`
+	FlashcardBack  string  `json:"flashcard_back,omitempty"`
+}
+
+// MoveLessonInput is the request body for POST /api/lessons/{id}/move.
+// Position is 1-based; zero appends to the end of the target module.
+type MoveLessonInput struct {
+	ModuleID string `json:"module_id"`
+	Position int    `json:"position,omitempty"`
+}
@@ -8,6 +8,7 @@ type LessonSummary struct {
 	Title            string `json:"title"`
 	SortOrder        int    `json:"sort_order"`
 	EstimatedMinutes int    `json:"estimated_minutes,omitempty"`
+	ArchivedAt       string `json:"archived_at,omitempty"`
 }
 
 // lessonBase holds fields shared between LessonDetail and LessonFull.
@@ -21,6 +22,8 @@ type lessonBase struct {
 	Examples         json.RawMessage `json:"examples,omitempty"`
 	Exercises        json.RawMessage `json:"exercises,omitempty"`
 	ReviewQuestions  json.RawMessage `json:"review_questions,omitempty"`
+	Revision         int             `json:"revision"`
+	ArchivedAt       string          `json:"archived_at,omitempty"`
 }
 
 // LessonDetail is the full lesson with all content fields.
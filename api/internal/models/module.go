@@ -9,6 +9,7 @@ type ModuleSummary struct {
 	Description      string `json:"description,omitempty"`
 	EstimatedMinutes int    `json:"estimated_minutes,omitempty"`
 	SortOrder        int    `json:"sort_order"`
+	ArchivedAt       string `json:"archived_at,omitempty"`
 }
 
 // moduleBase holds fields shared between ModuleDetail and ModuleFull.
@@ -21,6 +22,8 @@ type moduleBase struct {
 	EstimatedMinutes   int             `json:"estimated_minutes,omitempty"`
 	SortOrder          int             `json:"sort_order"`
 	Assessment         json.RawMessage `json:"assessment,omitempty"`
+	Revision           int             `json:"revision"`
+	ArchivedAt         string          `json:"archived_at,omitempty"`
 }
 
 // ModuleDetail includes the module's lessons.
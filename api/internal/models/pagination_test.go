@@ -77,3 +77,15 @@ func TestPaginationParamsOffset(t *testing.T) {
 		}
 	}
 }
+
+func TestParseReadOptions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/topics/t1?include_archived=true", nil)
+	if !models.ParseReadOptions(req).IncludeArchived {
+		t.Fatal("expected include_archived=true to include archived rows")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/topics/t1?include_archived=1", nil)
+	if models.ParseReadOptions(req).IncludeArchived {
+		t.Fatal("expected only the literal true to include archived rows")
+	}
+}
@@ -15,6 +15,17 @@ type LessonProgress struct {
 	StartedAt   string `json:"started_at,omitempty"`
 	CompletedAt string `json:"completed_at,omitempty"`
 	Notes       string `json:"notes,omitempty"`
+	Archived    bool   `json:"archived,omitempty"`
+	// Enrolled and Unenrolled count the concepts this update moved into or
+	// out of review, EnrolledQuestions and UnenrolledQuestions the question
+	// cards, and EnrolledCards and UnenrolledCards the generated cards of
+	// those concepts. They are only set on the update response.
+	Enrolled            int `json:"enrolled,omitempty"`
+	Unenrolled          int `json:"unenrolled,omitempty"`
+	EnrolledQuestions   int `json:"enrolled_questions,omitempty"`
+	UnenrolledQuestions int `json:"unenrolled_questions,omitempty"`
+	EnrolledCards       int `json:"enrolled_cards,omitempty"`
+	UnenrolledCards     int `json:"unenrolled_cards,omitempty"`
 }
 
 // TopicProgress is the response for GET /api/progress/topics/:id.
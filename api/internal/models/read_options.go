@@ -0,0 +1,15 @@
+package models
+
+import "net/http"
+
+// ReadOptions holds visibility settings shared by the curriculum read
+// endpoints, parsed from query parameters.
+type ReadOptions struct {
+	// IncludeArchived returns archived modules and lessons alongside live ones.
+	IncludeArchived bool
+}
+
+// ParseReadOptions extracts include_archived from an HTTP request's query params.
+func ParseReadOptions(r *http.Request) ReadOptions {
+	return ReadOptions{IncludeArchived: r.URL.Query().Get("include_archived") == "true"}
+}
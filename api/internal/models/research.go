@@ -15,9 +15,19 @@ const (
 	ResearchStatusCancelled   ResearchJobStatus = "cancelled"
 )
 
-// ResearchJob represents a row in the research_jobs table.
+// Research job kind constants matching the DB CHECK constraint. Curriculum
+// jobs research a topic; flashcard jobs rewrite one concept's card.
+const (
+	ResearchKindCurriculum = "curriculum"
+	ResearchKindFlashcard  = "flashcard"
+)
+
+// ResearchJob represents a row in the research_jobs table. ConceptID is
+// the concept a flashcard job rewrites.
 type ResearchJob struct {
 	ID           string          `json:"id"`
+	Kind         string          `json:"kind"`
+	ConceptID    string          `json:"concept_id,omitempty"`
 	RootTopic    string          `json:"root_topic"`
 	CurrentTopic string          `json:"current_topic,omitempty"`
 	Status       string          `json:"status"`
@@ -47,6 +57,7 @@ type CreateResearchJobInput struct {
 // ResearchJobSummary is a subset of ResearchJob for list responses.
 type ResearchJobSummary struct {
 	ID          string `json:"id"`
+	Kind        string `json:"kind"`
 	RootTopic   string `json:"root_topic"`
 	Status      string `json:"status"`
 	StartedAt   string `json:"started_at,omitempty"`
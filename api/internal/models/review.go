@@ -0,0 +1,407 @@
+package models
+
+// Review rating constants matching the concept_retention.last_rating CHECK
+// constraint.
+const (
+	RatingForgot = "forgot"
+	RatingHard   = "hard"
+	RatingGood   = "good"
+	RatingEasy   = "easy"
+)
+
+// IsRating reports whether rating is a valid review rating.
+func IsRating(rating string) bool {
+	switch rating {
+	case RatingForgot, RatingHard, RatingGood, RatingEasy:
+		return true
+	}
+
+	return false
+}
+
+// Retention status constants matching the DB CHECK constraint.
+const (
+	RetentionStatusNew       = "new"
+	RetentionStatusLearning  = "learning"
+	RetentionStatusReviewing = "reviewing"
+	RetentionStatusMastered  = "mastered"
+)
+
+// DefaultEaseFactor is the SM-2 ease factor of a concept never reviewed.
+const DefaultEaseFactor = 2.5
+
+// ConceptRetention is a concept's spaced repetition state. Timestamps are
+// RFC 3339 in UTC. Scheduler names the algorithm that produced the state:
+// SM-2 keeps EaseFactor, FSRS keeps Stability and Difficulty. Lapses counts
+// the times the concept was forgotten; SuspendedAt is set once it became a
+// leech and keeps it out of the review queue.
+type ConceptRetention struct {
+	ConceptID    string  `json:"concept_id"`
+	Status       string  `json:"status"`
+	NextReview   string  `json:"next_review,omitempty"`
+	ReviewCount  int     `json:"review_count"`
+	EaseFactor   float64 `json:"ease_factor"`
+	IntervalDays int     `json:"interval_days"`
+	LastReviewed string  `json:"last_reviewed,omitempty"`
+	LastRating   string  `json:"last_rating,omitempty"`
+	Stability    float64 `json:"stability,omitempty"`
+	Difficulty   float64 `json:"difficulty,omitempty"`
+	Scheduler    string  `json:"scheduler,omitempty"`
+	Lapses       int     `json:"lapses"`
+	SuspendedAt  string  `json:"suspended_at,omitempty"`
+}
+
+// ReviewEvent is one rating from review_log, oldest first when replayed.
+type ReviewEvent struct {
+	Rating     string `json:"rating"`
+	ReviewedAt string `json:"reviewed_at"`
+}
+
+// SchedulerInput is the request body for PUT /api/review/scheduler.
+type SchedulerInput struct {
+	Scheduler string `json:"scheduler"`
+}
+
+// SchedulerInfo is the response for GET and PUT /api/review/scheduler.
+// Rederived counts the concepts whose state a switch re-derived.
+type SchedulerInfo struct {
+	Scheduler string   `json:"scheduler"`
+	Available []string `json:"available"`
+	Rederived int      `json:"rederived,omitempty"`
+}
+
+// Review card kinds. Reverse, cloze and topic cards are generated from a
+// concept's stored data and match the generated_cards.kind CHECK
+// constraint.
+const (
+	CardKindConcept  = "concept"
+	CardKindQuestion = "question"
+	CardKindReverse  = "reverse"
+	CardKindCloze    = "cloze"
+	CardKindTopic    = "topic"
+)
+
+// GeneratedCardKinds lists the kinds of generated cards a topic can enable.
+var GeneratedCardKinds = []string{CardKindReverse, CardKindCloze, CardKindTopic}
+
+// IsGeneratedCardKind reports whether kind is a kind of generated card.
+func IsGeneratedCardKind(kind string) bool {
+	switch kind {
+	case CardKindReverse, CardKindCloze, CardKindTopic:
+		return true
+	}
+
+	return false
+}
+
+// Question card sources matching the question_cards.source CHECK
+// constraint: a lesson's review_questions or a module's assessment.
+const (
+	QuestionSourceLesson     = "lesson"
+	QuestionSourceAssessment = "assessment"
+)
+
+// ReviewCard is a card due for review, the response item for GET
+// /api/review/due. A concept card carries the concept's flashcard, and
+// LessonID is the lesson that defines it, for re-study after a forgotten
+// card. A question card has an empty ConceptID; QuestionID names it, the
+// flashcard is its question and answer, and ConceptIDs lists the concepts
+// it tests. LessonID is its lesson, empty for a module assessment question.
+// A generated card also has an empty ConceptID; CardID names it, the
+// flashcard is its generated front and back, and ConceptIDs holds the
+// concept it was generated from.
+type ReviewCard struct {
+	ConceptRetention
+	Kind           string   `json:"kind"`
+	QuestionID     string   `json:"question_id,omitempty"`
+	CardID         string   `json:"card_id,omitempty"`
+	Name           string   `json:"name"`
+	Definition     string   `json:"definition"`
+	FlashcardFront string   `json:"flashcard_front,omitempty"`
+	FlashcardBack  string   `json:"flashcard_back,omitempty"`
+	ConceptIDs     []string `json:"concept_ids,omitempty"`
+	ModuleID       string   `json:"module_id,omitempty"`
+	LessonID       string   `json:"lesson_id,omitempty"`
+	TopicID        string   `json:"topic_id,omitempty"`
+}
+
+// QuestionAnswer is the response for POST /api/review/questions/:id. State
+// is the question's rescheduled state, with an empty ConceptID; Concepts
+// holds the tested concepts the same rating reviewed.
+type QuestionAnswer struct {
+	QuestionID string             `json:"question_id"`
+	State      ConceptRetention   `json:"state"`
+	Concepts   []ConceptRetention `json:"concepts"`
+}
+
+// CardAnswer is the response for POST /api/review/cards/:id. State is the
+// generated card's rescheduled state, with an empty ConceptID; the concept
+// it was generated from keeps its own.
+type CardAnswer struct {
+	CardID string           `json:"card_id"`
+	State  ConceptRetention `json:"state"`
+}
+
+// TopicCardKinds is the response for GET and PUT
+// /api/topics/:id/card-kinds: the kinds of generated cards the topic has
+// enabled, and how many cards its concepts have of them.
+type TopicCardKinds struct {
+	TopicID string   `json:"topic_id"`
+	Kinds   []string `json:"kinds"`
+	Cards   int      `json:"cards"`
+}
+
+// CardKindsInput is the request body for PUT /api/topics/:id/card-kinds.
+// Kinds replaces the enabled kinds; an empty list disables them all.
+type CardKindsInput struct {
+	Kinds []string `json:"kinds"`
+}
+
+// ReviewInput is the request body for POST /api/review/:conceptId.
+// ResponseMS is how long the answer took, when the client timed it.
+type ReviewInput struct {
+	Rating     string `json:"rating"`
+	ResponseMS int    `json:"response_ms,omitempty"`
+}
+
+// ReviewStats is the response for GET /api/review/stats. DueToday and
+// Upcoming, the reviews due in the seven days after today, include question
+// and generated cards; the status counts cover every concept with review
+// state. Suspended leeches count toward their status but are never due.
+type ReviewStats struct {
+	DueToday      int `json:"due_today"`
+	Upcoming      int `json:"upcoming"`
+	ReviewedToday int `json:"reviewed_today"`
+	New           int `json:"new"`
+	Learning      int `json:"learning"`
+	Reviewing     int `json:"reviewing"`
+	Mastered      int `json:"mastered"`
+	Suspended     int `json:"suspended"`
+}
+
+// ReviewAnalytics is the response for GET /api/review/analytics. Days and
+// RetentionRate cover the window of Days days ending today (UTC); Forecast
+// covers the Days days starting today.
+type ReviewAnalytics struct {
+	Days          int              `json:"days"`
+	Reviews       int              `json:"reviews"`
+	RetentionRate float64          `json:"retention_rate"`
+	Daily         []DailyReviews   `json:"daily"`
+	Forecast      []DueForecast    `json:"forecast"`
+	Hardest       []HardConcept    `json:"hardest"`
+	Topics        []TopicRetention `json:"topics"`
+}
+
+// DailyReviews counts one UTC day's reviews. Recall counts reviews of
+// concepts already past their first review, and Recalled those not
+// forgotten; RetentionRate is Recalled / Recall, the true retention rate,
+// or 0 without any.
+type DailyReviews struct {
+	Date          string  `json:"date"`
+	Reviews       int     `json:"reviews"`
+	Recall        int     `json:"recall"`
+	Recalled      int     `json:"recalled"`
+	RetentionRate float64 `json:"retention_rate"`
+}
+
+// DueForecast is the number of reviews due on a UTC day. Today's count
+// includes overdue reviews.
+type DueForecast struct {
+	Date string `json:"date"`
+	Due  int    `json:"due"`
+}
+
+// HardConcept is a concept forgotten during the window. LapseRate is
+// Lapses / Reviews; EaseFactor and Difficulty are its current state.
+type HardConcept struct {
+	ConceptID  string  `json:"concept_id"`
+	Name       string  `json:"name"`
+	TopicID    string  `json:"topic_id,omitempty"`
+	Reviews    int     `json:"reviews"`
+	Lapses     int     `json:"lapses"`
+	LapseRate  float64 `json:"lapse_rate"`
+	EaseFactor float64 `json:"ease_factor"`
+	Difficulty float64 `json:"difficulty,omitempty"`
+}
+
+// TopicRetention is the true retention rate of the concepts a topic
+// defines, counted as in DailyReviews.
+type TopicRetention struct {
+	TopicID       string  `json:"topic_id"`
+	Title         string  `json:"title"`
+	Concepts      int     `json:"concepts"`
+	Reviews       int     `json:"reviews"`
+	Recall        int     `json:"recall"`
+	Recalled      int     `json:"recalled"`
+	RetentionRate float64 `json:"retention_rate"`
+}
+
+// Review session status constants matching the DB CHECK constraint.
+const (
+	SessionStatusActive    = "active"
+	SessionStatusCompleted = "completed"
+	SessionStatusAbandoned = "abandoned"
+)
+
+// SessionLimits caps the cards review sessions hand out per UTC day. New
+// cards are concepts never reviewed; reviews count as used whether they
+// were answered in a session or not.
+type SessionLimits struct {
+	NewPerDay     int
+	ReviewsPerDay int
+}
+
+// SessionInput is the request body for POST /api/review/sessions.
+// ActiveTopicsOnly limits the session to topics with a lesson in progress.
+type SessionInput struct {
+	ActiveTopicsOnly bool `json:"active_topics_only"`
+}
+
+// SessionOptions selects the cards of a new review session. NewLimit and
+// ReviewLimit are the daily caps; reviews already logged today count
+// against them.
+type SessionOptions struct {
+	ActiveTopicsOnly bool
+	NewLimit         int
+	ReviewLimit      int
+}
+
+// ReviewSession is a review session and the summary of its answered cards.
+type ReviewSession struct {
+	ID               string         `json:"id"`
+	Status           string         `json:"status"`
+	ActiveTopicsOnly bool           `json:"active_topics_only"`
+	Total            int            `json:"total"`
+	Answered         int            `json:"answered"`
+	StartedAt        string         `json:"started_at"`
+	FinishedAt       string         `json:"finished_at,omitempty"`
+	Summary          SessionSummary `json:"summary"`
+}
+
+// SessionCard is the next card of a session, the response for GET
+// /api/review/sessions/:id/next. Position counts from zero; Remaining
+// includes this card. Clients show the front until the user asks for the
+// answer.
+type SessionCard struct {
+	ReviewCard
+	Position  int  `json:"position"`
+	Remaining int  `json:"remaining"`
+	New       bool `json:"new"`
+}
+
+// SessionAnswerInput is the request body for POST
+// /api/review/sessions/:id/answer. ConceptID, QuestionID or CardID, when
+// set, must name the session's next card.
+type SessionAnswerInput struct {
+	ConceptID  string `json:"concept_id,omitempty"`
+	QuestionID string `json:"question_id,omitempty"`
+	CardID     string `json:"card_id,omitempty"`
+	Rating     string `json:"rating"`
+	ResponseMS int    `json:"response_ms,omitempty"`
+}
+
+// SessionAnswer is the response for POST /api/review/sessions/:id/answer.
+// State is the answered card's new state. For a question card QuestionID
+// names it and Concepts holds the tested concepts it also reviewed; for a
+// generated card CardID names it. Summary is set once the answer completes
+// the session.
+type SessionAnswer struct {
+	State      ConceptRetention   `json:"state"`
+	QuestionID string             `json:"question_id,omitempty"`
+	CardID     string             `json:"card_id,omitempty"`
+	Concepts   []ConceptRetention `json:"concepts,omitempty"`
+	Remaining  int                `json:"remaining"`
+	Summary    *SessionSummary    `json:"summary,omitempty"`
+}
+
+// SessionSummary describes a session's answered cards (PRD §11.3): how
+// many were reviewed, when each is next due, and the lessons to re-study
+// for forgotten ones. NextDue is the earliest of the next reviews.
+type SessionSummary struct {
+	Reviewed int             `json:"reviewed"`
+	New      int             `json:"new"`
+	Ratings  map[string]int  `json:"ratings"`
+	NextDue  string          `json:"next_due,omitempty"`
+	Cards    []SessionResult `json:"cards"`
+	Restudy  []RestudyLink   `json:"restudy"`
+}
+
+// SessionResult is one answered card of a session. A question card has
+// an empty ConceptID, and its question as Name; a generated card has its
+// concept's ConceptID and name, and CardID.
+type SessionResult struct {
+	ConceptID    string `json:"concept_id"`
+	QuestionID   string `json:"question_id,omitempty"`
+	CardID       string `json:"card_id,omitempty"`
+	Name         string `json:"name"`
+	Rating       string `json:"rating"`
+	NextReview   string `json:"next_review"`
+	IntervalDays int    `json:"interval_days"`
+}
+
+// RestudyLink points a forgotten concept, lesson question or generated
+// card at the lesson that teaches it.
+type RestudyLink struct {
+	ConceptID   string `json:"concept_id"`
+	QuestionID  string `json:"question_id,omitempty"`
+	CardID      string `json:"card_id,omitempty"`
+	Name        string `json:"name"`
+	LessonID    string `json:"lesson_id"`
+	LessonTitle string `json:"lesson_title"`
+	TopicID     string `json:"topic_id,omitempty"`
+}
+
+// EnrollmentOptions controls which concepts lesson progress moves into and
+// out of review.
+type EnrollmentOptions struct {
+	// IncludeReferenced also enrolls concepts the lesson only references,
+	// not just those it defines.
+	IncludeReferenced bool
+	// UnenrollOnReset returns never-reviewed concepts to new when their
+	// lesson goes back to not_started.
+	UnenrollOnReset bool
+}
+
+// Card proposal status constants matching the DB CHECK constraint.
+const (
+	ProposalStatusProposed = "proposed"
+	ProposalStatusApproved = "approved"
+	ProposalStatusRejected = "rejected"
+)
+
+// Leech is a concept suspended after too many lapses, the response item for
+// GET /api/review/leeches. LessonID and LessonTitle name the lesson that
+// defines it, for re-study. JobID and JobStatus describe its latest
+// flashcard research job, and Proposal is the card that job proposed while
+// it awaits approval.
+type Leech struct {
+	ConceptID      string        `json:"concept_id"`
+	Name           string        `json:"name"`
+	Definition     string        `json:"definition"`
+	FlashcardFront string        `json:"flashcard_front,omitempty"`
+	FlashcardBack  string        `json:"flashcard_back,omitempty"`
+	Lapses         int           `json:"lapses"`
+	ReviewCount    int           `json:"review_count"`
+	SuspendedAt    string        `json:"suspended_at"`
+	LessonID       string        `json:"lesson_id,omitempty"`
+	LessonTitle    string        `json:"lesson_title,omitempty"`
+	TopicID        string        `json:"topic_id,omitempty"`
+	TopicTitle     string        `json:"topic_title,omitempty"`
+	JobID          string        `json:"job_id,omitempty"`
+	JobStatus      string        `json:"job_status,omitempty"`
+	Proposal       *CardProposal `json:"proposal,omitempty"`
+}
+
+// CardProposal is a definition and flashcard a flashcard research job
+// wrote for a leech. It replaces the concept's own only once approved.
+type CardProposal struct {
+	ID             int64  `json:"id"`
+	ConceptID      string `json:"concept_id"`
+	JobID          string `json:"job_id,omitempty"`
+	Status         string `json:"status"`
+	Definition     string `json:"definition"`
+	FlashcardFront string `json:"flashcard_front"`
+	FlashcardBack  string `json:"flashcard_back"`
+	CreatedAt      string `json:"created_at"`
+	ResolvedAt     string `json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "strings"
+
+// NormalizeSlug folds an ID into the canonical slug form used for topic and
+// concept IDs: lower case, with every run of characters other than letters,
+// digits, and "/" collapsed into a single hyphen, and no hyphen at either end
+// of the ID or of a "/" segment. "Linux_Admin " becomes "linux-admin".
+func NormalizeSlug(id string) string {
+	var b strings.Builder
+
+	b.Grow(len(id))
+
+	pendingHyphen := false
+	last := rune(0)
+
+	for _, r := range strings.ToLower(id) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '/':
+			if pendingHyphen && last != 0 && last != '/' && r != '/' {
+				b.WriteByte('-')
+			}
+
+			pendingHyphen = false
+			last = r
+
+			b.WriteRune(r)
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return b.String()
+}
+
+// AliasRedirect is the body of a 301 response to a lookup by an alias or an
+// unnormalized ID. Location repeats the Location header.
+type AliasRedirect struct {
+	RequestedID string `json:"requested_id"`
+	ID          string `json:"id"`
+	Location    string `json:"location"`
+}
@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+func TestNormalizeSlug(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"linux-administration", "linux-administration"},
+		{"Linux_Admin ", "linux-admin"},
+		{"  TCP/IP  Basics", "tcp/ip-basics"},
+		{"go-concurrency / Goroutines", "go-concurrency/goroutines"},
+		{"--C++ Templates--", "c-templates"},
+		{"", ""},
+		{"***", ""},
+	}
+
+	for _, tc := range tests {
+		if got := models.NormalizeSlug(tc.in); got != tc.want {
+			t.Errorf("NormalizeSlug(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
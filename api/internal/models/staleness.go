@@ -0,0 +1,19 @@
+package models
+
+// StalenessSweep records one run of the staleness sweeper.
+type StalenessSweep struct {
+	ID            int64  `json:"id"`
+	StartedAt     string `json:"started_at"`
+	CompletedAt   string `json:"completed_at"`
+	ThresholdDays int    `json:"threshold_days"`
+	TopicsFlagged int    `json:"topics_flagged"`
+	JobsQueued    int    `json:"jobs_queued"`
+	Error         string `json:"error,omitempty"`
+}
+
+// StaleTopic is an outdated topic eligible for an automatic refresh job.
+type StaleTopic struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	GeneratedAt string `json:"generated_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// Task run status constants matching the DB CHECK constraint.
+const (
+	TaskRunStatusRunning   = "running"
+	TaskRunStatusSucceeded = "succeeded"
+	TaskRunStatusFailed    = "failed"
+	TaskRunStatusSkipped   = "skipped"
+)
+
+// Task run triggers matching the DB CHECK constraint.
+const (
+	TaskTriggerSchedule = "schedule"
+	TaskTriggerManual   = "manual"
+)
+
+// TaskRun is one recorded run of a scheduled maintenance task.
+type TaskRun struct {
+	ID         int64  `json:"id"`
+	Task       string `json:"task"`
+	Trigger    string `json:"trigger"`
+	Status     string `json:"status"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TaskStatus is the response item for GET /api/admin/tasks. Spec and NextRun
+// are empty for tasks that only run when triggered manually.
+type TaskStatus struct {
+	Name    string   `json:"name"`
+	Spec    string   `json:"spec,omitempty"`
+	NextRun string   `json:"next_run,omitempty"`
+	Running bool     `json:"running"`
+	LastRun *TaskRun `json:"last_run,omitempty"`
+}
@@ -1,5 +1,23 @@
 package models
 
+// Topic status constants matching the DB CHECK constraint.
+const (
+	TopicStatusResearching = "researching"
+	TopicStatusDraft       = "draft"
+	TopicStatusPublished   = "published"
+	TopicStatusOutdated    = "outdated"
+)
+
+// IsTopicStatus reports whether status is a valid topic status.
+func IsTopicStatus(status string) bool {
+	switch status {
+	case TopicStatusResearching, TopicStatusDraft, TopicStatusPublished, TopicStatusOutdated:
+		return true
+	}
+
+	return false
+}
+
 // TopicSummary is the list-view representation of a topic.
 type TopicSummary struct {
 	ID             string   `json:"id"`
@@ -26,8 +44,10 @@ type topicBase struct {
 	GeneratedAt    string   `json:"generated_at,omitempty"`
 	GeneratedBy    string   `json:"generated_by,omitempty"`
 	ParentTopicID  string   `json:"parent_topic_id,omitempty"`
+	Aliases        []string `json:"aliases,omitempty"`
 	CreatedAt      string   `json:"created_at"`
 	UpdatedAt      string   `json:"updated_at"`
+	Revision       int      `json:"revision"`
 }
 
 // TopicDetail includes the topic's modules without lesson content.
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// RowQueryer is the single-row query method shared by *sql.DB and *sql.Tx,
+// so ID resolution works inside and outside a transaction.
+type RowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const resolveTopicIDSQL = `
+SELECT id FROM (
+  SELECT id, 0 AS rank FROM topics WHERE id = ?1
+  UNION ALL
+  SELECT id, 1 FROM topics WHERE id = ?2
+  UNION ALL
+  SELECT t.id, 2 FROM topics t, json_each(t.aliases) a WHERE a.value IN (?1, ?2)
+)
+ORDER BY rank, id
+LIMIT 1
+`
+
+const resolveConceptIDSQL = `
+SELECT id FROM (
+  SELECT id, 0 AS rank FROM concepts WHERE id = ?1
+  UNION ALL
+  SELECT id, 1 FROM concepts WHERE id = ?2
+  UNION ALL
+  SELECT c.id, 2 FROM concepts c, json_each(c.aliases) a WHERE a.value IN (?1, ?2)
+)
+ORDER BY rank, id
+LIMIT 1
+`
+
+// ResolveTopicID returns the ID of the topic that id names directly, by its
+// normalized slug, or as an alias, or "" when no topic matches.
+func ResolveTopicID(ctx context.Context, q RowQueryer, id string) (string, error) {
+	return resolveID(ctx, q, resolveTopicIDSQL, "topic", id)
+}
+
+// ResolveConceptID returns the ID of the concept that id names directly, by
+// its normalized slug, or as an alias, or "" when no concept matches.
+func ResolveConceptID(ctx context.Context, q RowQueryer, id string) (string, error) {
+	return resolveID(ctx, q, resolveConceptIDSQL, "concept", id)
+}
+
+// resolveID runs one of the resolve queries, which prefer the ID itself,
+// then its normalized slug, then an alias matching either form. Ties
+// between aliases go to the lowest ID.
+func resolveID(ctx context.Context, q RowQueryer, query, entity, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+
+	var canonical string
+
+	err := q.QueryRowContext(ctx, query, id, models.NormalizeSlug(id)).Scan(&canonical)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("resolve %s ID %s: %w", entity, id, err)
+	}
+
+	return canonical, nil
+}
@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func TestResolveTopicID(t *testing.T) {
+	db := setupTestDB(t)
+	seedTopic(t, db, "linux-administration", "Linux Administration", "foundational", "published")
+	seedTopic(t, db, "linux-admin-tools", "Linux Admin Tools", "foundational", "published")
+	mustExec(t, db, `UPDATE topics SET aliases = '["linux-admin","sysadmin"]' WHERE id = 'linux-administration'`)
+	ctx := context.Background()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"linux-administration", "linux-administration"},
+		{"Linux_Administration", "linux-administration"},
+		{"linux-admin", "linux-administration"},
+		{"SysAdmin", "linux-administration"},
+		{"linux-admin-tools", "linux-admin-tools"},
+		{"windows-admin", ""},
+		{"", ""},
+	}
+
+	for _, tc := range tests {
+		got, err := repository.ResolveTopicID(ctx, db, tc.in)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", tc.in, err)
+		}
+
+		if got != tc.want {
+			t.Errorf("ResolveTopicID(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestResolveConceptIDPrefersRealIDOverAlias(t *testing.T) {
+	db := setupTestDB(t)
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "bridge", "Bridge", "A software switch", "t1")
+	seedConcept(t, db, "br0", "br0", "A bridge device", "t1")
+	mustExec(t, db, `UPDATE concepts SET aliases = '["br0","linux-bridge"]' WHERE id = 'bridge'`)
+	ctx := context.Background()
+
+	if got, err := repository.ResolveConceptID(ctx, db, "br0"); err != nil || got != "br0" {
+		t.Fatalf("expected br0 itself, got %q (%v)", got, err)
+	}
+
+	if got, err := repository.ResolveConceptID(ctx, db, "Linux Bridge"); err != nil || got != "bridge" {
+		t.Fatalf("expected normalized alias to resolve to bridge, got %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// RateFunc rates a review state at the time given, as a scheduler's Review
+// does.
+type RateFunc func(current models.ConceptRetention, rating string, at time.Time) (models.ConceptRetention, error)
+
+// AnkiRepository reads the concept flashcards that decks export to Anki and
+// other spaced repetition tools, and seeds review state from Anki's review
+// history.
+type AnkiRepository interface {
+	// ListFlashcards returns the flashcards of the concepts the filter
+	// selects, by topic and then concept ID. Topic and concept IDs resolve
+	// through aliases; one that names nothing is ErrNotFound.
+	ListFlashcards(ctx context.Context, filter models.FlashcardFilter) ([]models.Flashcard, error)
+	// ImportReviews matches Anki cards to concepts and replays their
+	// reviews through rate into the state and review log of each matched
+	// concept that has never been reviewed, in one transaction. A dry run
+	// rolls the transaction back and reports what would have changed.
+	ImportReviews(ctx context.Context, cards []models.AnkiCard, rate RateFunc, dryRun bool) (*models.AnkiImportReport, error)
+	// GetSchedulerPreference returns the scheduler chosen at runtime, or ""
+	// when none has been.
+	GetSchedulerPreference(ctx context.Context) (string, error)
+}
+
+// SQLiteAnkiRepository implements AnkiRepository using SQLite. Reads use
+// the read pool; writes use the write handle.
+type SQLiteAnkiRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewAnkiRepository creates a new SQLiteAnkiRepository.
+func NewAnkiRepository(readDB, writeDB *sql.DB) *SQLiteAnkiRepository {
+	return &SQLiteAnkiRepository{readDB: readDB, db: writeDB}
+}
+
+// listFlashcardsSQL selects the concepts matching the JSON arrays of topic
+// IDs ?1, concept IDs ?2, and topic tags ?3, where an empty array matches
+// everything.
+const listFlashcardsSQL = `
+SELECT c.id, c.name,
+       COALESCE(NULLIF(c.flashcard_front, ''), c.name),
+       COALESCE(NULLIF(c.flashcard_back, ''), c.definition),
+       COALESCE(t.id, ''), COALESCE(t.title, ''), COALESCE(t.tags, ''),
+       COALESCE(l.module_id, '')
+FROM concepts c
+LEFT JOIN topics t ON t.id = c.defined_in_topic
+LEFT JOIN lessons l ON l.id = c.defined_in_lesson
+WHERE (json_array_length(?1) = 0 OR c.defined_in_topic IN (SELECT value FROM json_each(?1)))
+  AND (json_array_length(?2) = 0 OR c.id IN (SELECT value FROM json_each(?2)))
+  AND (json_array_length(?3) = 0 OR EXISTS (
+        SELECT 1 FROM json_each(t.tags) tag WHERE tag.value IN (SELECT value FROM json_each(?3))))
+ORDER BY COALESCE(c.defined_in_topic, ''), c.id
+`
+
+func (r *SQLiteAnkiRepository) ListFlashcards(ctx context.Context, filter models.FlashcardFilter) ([]models.Flashcard, error) {
+	topicIDs, err := resolveIDs(ctx, r.readDB, ResolveTopicID, "topic", filter.TopicIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	conceptIDs, err := resolveIDs(ctx, r.readDB, ResolveConceptID, "concept", filter.ConceptIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]any, 0, 3)
+
+	for _, list := range [][]string{topicIDs, conceptIDs, filter.Tags} {
+		encoded, err := json.Marshal(append([]string{}, list...))
+		if err != nil {
+			return nil, fmt.Errorf("encode flashcard filter: %w", err)
+		}
+
+		args = append(args, string(encoded))
+	}
+
+	rows, err := r.readDB.QueryContext(ctx, listFlashcardsSQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query flashcards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := []models.Flashcard{}
+
+	for rows.Next() {
+		var c models.Flashcard
+		var tags string
+		if err := rows.Scan(&c.ConceptID, &c.Name, &c.Front, &c.Back,
+			&c.TopicID, &c.TopicTitle, &tags, &c.ModuleID); err != nil {
+			return nil, fmt.Errorf("scan flashcard: %w", err)
+		}
+
+		c.TopicTags = models.ParseJSONStringSlice(&tags)
+		cards = append(cards, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate flashcards: %w", err)
+	}
+
+	return cards, nil
+}
+
+func (r *SQLiteAnkiRepository) GetSchedulerPreference(ctx context.Context) (string, error) {
+	return loadSchedulerPreference(ctx, r.readDB)
+}
+
+const matchConceptByNameSQL = `SELECT id FROM concepts WHERE name = ? COLLATE NOCASE ORDER BY id`
+
+// matchConceptByAliasSQL selects the concepts with ?1 or its slug ?2 among
+// their aliases, or with ?2 as their ID.
+const matchConceptByAliasSQL = `
+SELECT id FROM concepts WHERE id = ?2
+UNION
+SELECT c.id FROM concepts c, json_each(c.aliases) a WHERE a.value = ?1 COLLATE NOCASE OR a.value = ?2
+ORDER BY id
+`
+
+const hasReviewHistorySQL = `
+SELECT EXISTS(SELECT 1 FROM review_log WHERE concept_id = ?1)
+    OR EXISTS(SELECT 1 FROM concept_retention WHERE concept_id = ?1 AND review_count > 0)
+`
+
+// ImportReviews replays each concept's reviews from all the cards matched
+// to it, oldest first. A concept already reviewed in Apollo is skipped, so
+// the log stays in order and importing a collection again changes nothing.
+// Reviews count lapses but never suspend a concept.
+func (r *SQLiteAnkiRepository) ImportReviews(ctx context.Context, cards []models.AnkiCard, rate RateFunc, dryRun bool) (*models.AnkiImportReport, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	report := &models.AnkiImportReport{
+		DryRun:          dryRun,
+		Cards:           len(cards),
+		Matched:         []models.AnkiCardMatch{},
+		Ambiguous:       []models.AnkiCardMatch{},
+		Unmatched:       []models.AnkiCardMatch{},
+		SkippedConcepts: []string{},
+		Concepts:        []models.ConceptRetention{},
+	}
+
+	var conceptIDs []string
+	reviews := map[string][]models.AnkiReview{}
+
+	for _, c := range cards {
+		m := models.AnkiCardMatch{CardID: c.ID, NoteGUID: c.NoteGUID, Front: c.Front, Reviews: len(c.Reviews)}
+
+		ids, by, err := matchConcept(ctx, tx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		switch len(ids) {
+		case 0:
+			report.Unmatched = append(report.Unmatched, m)
+		case 1:
+			m.ConceptID, m.MatchedBy = ids[0], by
+			report.Matched = append(report.Matched, m)
+
+			if _, ok := reviews[m.ConceptID]; !ok {
+				conceptIDs = append(conceptIDs, m.ConceptID)
+			}
+
+			reviews[m.ConceptID] = append(reviews[m.ConceptID], c.Reviews...)
+		default:
+			m.MatchedBy, m.Candidates = by, ids
+			report.Ambiguous = append(report.Ambiguous, m)
+		}
+	}
+
+	for _, id := range conceptIDs {
+		events := reviews[id]
+		if len(events) == 0 {
+			continue
+		}
+
+		var reviewed bool
+		if err := tx.QueryRowContext(ctx, hasReviewHistorySQL, id).Scan(&reviewed); err != nil {
+			return nil, fmt.Errorf("check concept %s review history: %w", id, err)
+		}
+
+		if reviewed {
+			report.SkippedConcepts = append(report.SkippedConcepts, id)
+
+			continue
+		}
+
+		slices.SortStableFunc(events, func(a, b models.AnkiReview) int {
+			return cmp.Compare(a.ReviewedAt, b.ReviewedAt)
+		})
+
+		state, err := replayAnkiReviews(ctx, tx, id, events, rate)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Reviews += len(events)
+		report.Concepts = append(report.Concepts, *state)
+	}
+
+	if len(report.Concepts) > 0 {
+		due := time.Now().UTC().AddDate(0, 0, 1).Format(time.RFC3339)
+
+		if _, err := tx.ExecContext(ctx, enrollCardsSQL, due); err != nil {
+			return nil, fmt.Errorf("enroll imported concepts' generated cards: %w", err)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return report, nil
+}
+
+// matchConcept returns the concepts an Anki card names and how: the one its
+// Apollo GUID names, else those whose name is its front, else those with
+// its front as an alias or ID.
+func matchConcept(ctx context.Context, q queryer, c models.AnkiCard) ([]string, string, error) {
+	if c.ConceptID != "" {
+		id, err := ResolveConceptID(ctx, q, c.ConceptID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if id != "" {
+			return []string{id}, models.AnkiMatchGUID, nil
+		}
+	}
+
+	if c.Front == "" {
+		return nil, "", nil
+	}
+
+	ids, err := queryIDs(ctx, q, matchConceptByNameSQL, c.Front)
+	if err != nil || len(ids) > 0 {
+		return ids, models.AnkiMatchName, err
+	}
+
+	ids, err = queryIDs(ctx, q, matchConceptByAliasSQL, c.Front, models.NormalizeSlug(c.Front))
+
+	return ids, models.AnkiMatchAlias, err
+}
+
+// replayAnkiReviews rates a never-reviewed concept once for each review,
+// logging each as RecordReview would, and stores the final state.
+func replayAnkiReviews(ctx context.Context, q queryer, conceptID string, events []models.AnkiReview, rate RateFunc) (*models.ConceptRetention, error) {
+	state, err := loadRetention(ctx, q, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range events {
+		at, err := time.Parse(time.RFC3339, e.ReviewedAt)
+		if err != nil {
+			return nil, fmt.Errorf("replay concept %s review at %q: %w", conceptID, e.ReviewedAt, err)
+		}
+
+		next, err := rate(*state, e.Rating, at)
+		if err != nil {
+			return nil, fmt.Errorf("replay concept %s: %w", conceptID, err)
+		}
+
+		next.ConceptID = conceptID
+		next.Lapses = state.Lapses
+		next.SuspendedAt = state.SuspendedAt
+
+		if next.LastRating == models.RatingForgot {
+			next.Lapses++
+		}
+
+		if _, err := q.ExecContext(ctx, insertReviewLogSQL,
+			conceptID, next.LastRating, e.ReviewedAt,
+			state.IntervalDays, next.IntervalDays, next.EaseFactor, nullIfZero(e.ResponseMS), nil,
+		); err != nil {
+			return nil, classifyError(err, "log concept "+conceptID+" review")
+		}
+
+		state = &next
+	}
+
+	if err := storeRetention(ctx, q, *state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// resolveIDs resolves each of ids with resolve, or returns ErrNotFound for
+// the first that names no entity.
+func resolveIDs(ctx context.Context, q RowQueryer, resolve func(context.Context, RowQueryer, string) (string, error), entity string, ids []string) ([]string, error) {
+	resolved := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		canonical, err := resolve(ctx, q, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if canonical == "" {
+			return nil, fmt.Errorf("%s %s: %w", entity, id, ErrNotFound)
+		}
+
+		resolved = append(resolved, canonical)
+	}
+
+	return resolved, nil
+}
+
+// Verify interface compliance at compile time.
+var _ AnkiRepository = (*SQLiteAnkiRepository)(nil)
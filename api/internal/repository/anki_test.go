@@ -0,0 +1,266 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestListFlashcards(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	seedTopic(t, db, "go", "Go", "foundational", "published")
+	seedTopic(t, db, "rust", "Rust", "foundational", "published")
+	seedModule(t, db, "go-m1", "go", "Concurrency", 1)
+	seedLesson(t, db, "go-l1", "go-m1", "Goroutines", 1)
+	mustExec(t, db, `UPDATE topics SET tags = '["languages", "concurrency"]' WHERE id = 'go'`)
+	mustExec(t, db, `UPDATE topics SET tags = '["languages"]' WHERE id = 'rust'`)
+
+	seedConcept(t, db, "goroutine", "Goroutine", "A lightweight thread.", "go")
+	seedConcept(t, db, "channel", "Channel", "A typed pipe.", "go")
+	seedConcept(t, db, "borrow", "Borrowing", "Taking a reference.", "rust")
+	mustExec(t, db, `UPDATE concepts SET defined_in_lesson = 'go-l1', flashcard_front = 'What is a goroutine?',
+		flashcard_back = 'A thread the Go runtime schedules', aliases = '["gr"]' WHERE id = 'goroutine'`)
+
+	repo := repository.NewAnkiRepository(db, db)
+
+	ids := func(cards []models.Flashcard) []string {
+		var out []string
+		for _, c := range cards {
+			out = append(out, c.ConceptID)
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter models.FlashcardFilter
+		want   []string
+	}{
+		{"everything", models.FlashcardFilter{}, []string{"channel", "goroutine", "borrow"}},
+		{"topic", models.FlashcardFilter{TopicIDs: []string{"rust"}}, []string{"borrow"}},
+		{"concepts by alias", models.FlashcardFilter{ConceptIDs: []string{"gr", "borrow"}}, []string{"goroutine", "borrow"}},
+		{"tag", models.FlashcardFilter{Tags: []string{"concurrency"}}, []string{"channel", "goroutine"}},
+		{"filters combine", models.FlashcardFilter{TopicIDs: []string{"go"}, ConceptIDs: []string{"channel", "borrow"}}, []string{"channel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := repo.ListFlashcards(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListFlashcards() error = %v", err)
+			}
+
+			if got := ids(cards); !slices.Equal(got, tt.want) {
+				t.Fatalf("ListFlashcards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	cards, err := repo.ListFlashcards(ctx, models.FlashcardFilter{TopicIDs: []string{"go"}})
+	if err != nil {
+		t.Fatalf("ListFlashcards(go) error = %v", err)
+	}
+
+	want := models.Flashcard{
+		ConceptID: "goroutine", Name: "Goroutine", Front: "What is a goroutine?", Back: "A thread the Go runtime schedules",
+		TopicID: "go", TopicTitle: "Go", TopicTags: []string{"languages", "concurrency"}, ModuleID: "go-m1",
+	}
+
+	if got := cards[1]; got.ConceptID != want.ConceptID || got.Front != want.Front || got.Back != want.Back ||
+		got.TopicTitle != want.TopicTitle || !slices.Equal(got.TopicTags, want.TopicTags) || got.ModuleID != want.ModuleID {
+		t.Fatalf("goroutine flashcard = %+v, want %+v", got, want)
+	}
+
+	if c := cards[0]; c.Front != "Channel" || c.Back != "A typed pipe." || c.ModuleID != "" {
+		t.Fatalf("channel flashcard = %+v, want its name and definition", c)
+	}
+
+	for _, filter := range []models.FlashcardFilter{{TopicIDs: []string{"missing"}}, {ConceptIDs: []string{"missing"}}} {
+		if _, err := repo.ListFlashcards(ctx, filter); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("ListFlashcards(%+v) error = %v, want ErrNotFound", filter, err)
+		}
+	}
+}
+
+func TestImportReviews(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	seedTopic(t, db, "go", "Go", "foundational", "published")
+	seedConcept(t, db, "goroutine", "Goroutine", "A lightweight thread.", "go")
+	seedConcept(t, db, "channel", "Channel", "A typed pipe.", "go")
+	seedConcept(t, db, "mutex", "Mutual exclusion", "A lock.", "go")
+	seedConcept(t, db, "waitgroup", "WaitGroup", "Waits for goroutines.", "go")
+	seedConcept(t, db, "once", "Once", "Runs a function once.", "go")
+	seedConcept(t, db, "select", "Select", "Waits on channels.", "go")
+	mustExec(t, db, `UPDATE concepts SET aliases = '["go-func"]' WHERE id = 'goroutine'`)
+	mustExec(t, db, `UPDATE concepts SET aliases = '["Lock"]' WHERE id = 'mutex'`)
+	mustExec(t, db, `UPDATE concepts SET aliases = '["sync"]' WHERE id IN ('waitgroup', 'once')`)
+	mustExec(t, db, `INSERT INTO review_log (concept_id, rating, reviewed_at, previous_interval) VALUES ('select', 'good', ?, 0)`,
+		time.Now().UTC().Format(time.RFC3339))
+
+	reviews := func(ratings ...string) []models.AnkiReview {
+		var out []models.AnkiReview
+		for i, r := range ratings {
+			at := time.Date(2026, 9, 1+3*i, 8, 0, 0, 0, time.UTC)
+			out = append(out, models.AnkiReview{ReviewedAt: at.Format(time.RFC3339), Rating: r, ResponseMS: 1500})
+		}
+
+		return out
+	}
+
+	cards := []models.AnkiCard{
+		// The GUID names the concept by an alias; the front no longer matches.
+		{ID: 1, NoteGUID: "apollo:go-func", ConceptID: "go-func", Front: "What runs concurrently?",
+			Reviews: reviews(models.RatingGood, models.RatingForgot, models.RatingGood)},
+		{ID: 2, NoteGUID: "a", Front: "channel", Reviews: reviews(models.RatingGood)},
+		{ID: 3, NoteGUID: "b", Front: "lock", Reviews: reviews(models.RatingEasy)},
+		{ID: 4, NoteGUID: "c", Front: "Sync", Reviews: reviews(models.RatingGood)},
+		{ID: 5, NoteGUID: "d", Front: "Monads", Reviews: reviews(models.RatingGood)},
+		{ID: 6, NoteGUID: "apollo:select", ConceptID: "select", Front: "Select", Reviews: reviews(models.RatingGood)},
+		// A second card of the goroutine note, reviewed between the first's reviews.
+		{ID: 7, NoteGUID: "apollo:goroutine", ConceptID: "goroutine", Front: "Goroutine",
+			Reviews: []models.AnkiReview{{ReviewedAt: "2026-09-02T08:00:00Z", Rating: models.RatingHard}}},
+	}
+
+	repo := repository.NewAnkiRepository(db, db)
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+
+	counts := func() (logged, states int) {
+		t.Helper()
+
+		if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM review_log WHERE concept_id != 'select'),
+			(SELECT COUNT(*) FROM concept_retention WHERE review_count > 0)`).Scan(&logged, &states); err != nil {
+			t.Fatalf("count review state: %v", err)
+		}
+
+		return logged, states
+	}
+
+	report, err := repo.ImportReviews(ctx, cards, sm2.Review, true)
+	if err != nil {
+		t.Fatalf("ImportReviews(dry run) error = %v", err)
+	}
+
+	if logged, states := counts(); logged != 0 || states != 0 {
+		t.Fatalf("dry run stored %d reviews and %d states", logged, states)
+	}
+
+	if !report.DryRun || report.Cards != 7 || report.Reviews != 6 {
+		t.Fatalf("dry run report = %+v", report)
+	}
+
+	matched := map[int64]string{}
+	for _, m := range report.Matched {
+		matched[m.CardID] = m.ConceptID + " by " + m.MatchedBy
+	}
+
+	want := map[int64]string{
+		1: "goroutine by guid", 2: "channel by name", 3: "mutex by alias",
+		6: "select by guid", 7: "goroutine by guid",
+	}
+	for id, m := range want {
+		if matched[id] != m {
+			t.Errorf("card %d matched %q, want %q", id, matched[id], m)
+		}
+	}
+
+	if len(report.Ambiguous) != 1 || report.Ambiguous[0].CardID != 4 ||
+		!slices.Equal(report.Ambiguous[0].Candidates, []string{"once", "waitgroup"}) {
+		t.Errorf("ambiguous = %+v", report.Ambiguous)
+	}
+
+	if len(report.Unmatched) != 1 || report.Unmatched[0].CardID != 5 {
+		t.Errorf("unmatched = %+v", report.Unmatched)
+	}
+
+	if !slices.Equal(report.SkippedConcepts, []string{"select"}) {
+		t.Errorf("skipped = %v, want [select]", report.SkippedConcepts)
+	}
+
+	if _, err := repo.ImportReviews(ctx, cards, sm2.Review, false); err != nil {
+		t.Fatalf("ImportReviews() error = %v", err)
+	}
+
+	if logged, states := counts(); logged != 6 || states != 3 {
+		t.Fatalf("import stored %d reviews and %d states, want 6 and 3", logged, states)
+	}
+
+	var ratings []string
+	rows, err := db.Query(`SELECT rating FROM review_log WHERE concept_id = 'goroutine' ORDER BY id`)
+	if err != nil {
+		t.Fatalf("query goroutine reviews: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r string
+		if err := rows.Scan(&r); err != nil {
+			t.Fatalf("scan rating: %v", err)
+		}
+
+		ratings = append(ratings, r)
+	}
+
+	if want := []string{"good", "hard", "forgot", "good"}; !slices.Equal(ratings, want) {
+		t.Fatalf("goroutine reviews = %v, want %v", ratings, want)
+	}
+
+	var reviewCount, lapses int
+	var last string
+	if err := db.QueryRow(`SELECT review_count, lapses, last_reviewed FROM concept_retention WHERE concept_id = 'goroutine'`).
+		Scan(&reviewCount, &lapses, &last); err != nil {
+		t.Fatalf("read goroutine retention: %v", err)
+	}
+
+	if reviewCount != 4 || lapses != 1 || last != "2026-09-07T08:00:00Z" {
+		t.Fatalf("goroutine retention = %d reviews, %d lapses, last %s", reviewCount, lapses, last)
+	}
+
+	report, err = repo.ImportReviews(ctx, cards, sm2.Review, false)
+	if err != nil {
+		t.Fatalf("ImportReviews(again) error = %v", err)
+	}
+
+	if report.Reviews != 0 || len(report.SkippedConcepts) != 4 {
+		t.Fatalf("re-import report = %+v, want every concept skipped", report)
+	}
+}
+
+func TestImportReviewsRollsBackOnFailure(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	seedTopic(t, db, "go", "Go", "foundational", "published")
+	seedConcept(t, db, "goroutine", "Goroutine", "A lightweight thread.", "go")
+	seedConcept(t, db, "channel", "Channel", "A typed pipe.", "go")
+
+	cards := []models.AnkiCard{
+		{ID: 1, Front: "Goroutine", Reviews: []models.AnkiReview{{ReviewedAt: "2026-09-01T08:00:00Z", Rating: models.RatingGood}}},
+		{ID: 2, Front: "Channel", Reviews: []models.AnkiReview{{ReviewedAt: "2026-09-01T08:00:00Z", Rating: "meh"}}},
+	}
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+
+	if _, err := repository.NewAnkiRepository(db, db).ImportReviews(ctx, cards, sm2.Review, false); err == nil {
+		t.Fatal("ImportReviews() with an invalid rating succeeded")
+	}
+
+	var logged int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM review_log`).Scan(&logged); err != nil {
+		t.Fatalf("count reviews: %v", err)
+	}
+
+	if logged != 0 {
+		t.Fatalf("failed import left %d reviews", logged)
+	}
+}
@@ -0,0 +1,587 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sean/apollo/api/internal/audit"
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// AuditRepository reads the audit log and reverts recorded changes. Only
+// the row a change names is recorded. Rows removed by an ON DELETE cascade
+// are not, so reverting a topic delete restores the topic but not its
+// modules and lessons.
+type AuditRepository interface {
+	ListHistory(ctx context.Context, filter models.HistoryFilter, params models.PaginationParams) (*models.PaginatedResponse[models.AuditRecord], error)
+	// Revert restores the row named by record to its state before that
+	// change and records the revert as a new change. It returns nil when the
+	// row is already in that state. Deleting a row created by the change
+	// refuses with ErrHasProgress unless force is set.
+	Revert(ctx context.Context, recordID int64, force bool) (*models.AuditRecord, error)
+}
+
+// SQLiteAuditRepository implements AuditRepository using SQLite. Reads use
+// the read pool; reverts use the write handle.
+type SQLiteAuditRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewAuditRepository creates a new SQLiteAuditRepository.
+func NewAuditRepository(readDB, writeDB *sql.DB) *SQLiteAuditRepository {
+	return &SQLiteAuditRepository{readDB: readDB, db: writeDB}
+}
+
+// auditTable maps an audited entity type to its table and primary key
+// columns. progressSQL, when set, counts the progress a delete would remove.
+type auditTable struct {
+	name        string
+	keys        []string
+	progressSQL string
+}
+
+var auditTables = map[string]auditTable{
+	models.AuditEntityTopic:            {name: "topics", keys: []string{"id"}, progressSQL: countTopicProgressSQL},
+	models.AuditEntityModule:           {name: "modules", keys: []string{"id"}, progressSQL: countModuleProgressSQL},
+	models.AuditEntityLesson:           {name: "lessons", keys: []string{"id"}, progressSQL: countLessonProgressSQL},
+	models.AuditEntityConcept:          {name: "concepts", keys: []string{"id"}, progressSQL: countConceptRetentionSQL},
+	models.AuditEntityConceptReference: {name: "concept_references", keys: []string{"concept_id", "lesson_id"}},
+	models.AuditEntityPrerequisite:     {name: "topic_prerequisites", keys: []string{"topic_id", "prerequisite_topic_id"}},
+	models.AuditEntityRelation:         {name: "topic_relations", keys: []string{"topic_a", "topic_b"}},
+}
+
+// lookupAuditTable returns the table for entityType and splits entityID into
+// its key values.
+func lookupAuditTable(entityType, entityID string) (auditTable, []any, error) {
+	table, ok := auditTables[entityType]
+	if !ok {
+		return auditTable{}, nil, fmt.Errorf("unknown audit entity type %q: %w", entityType, ErrCheckViolation)
+	}
+
+	parts := strings.SplitN(entityID, "/", len(table.keys))
+	if len(parts) != len(table.keys) {
+		return auditTable{}, nil, fmt.Errorf("%s id %q needs %d parts: %w", entityType, entityID, len(table.keys), ErrCheckViolation)
+	}
+
+	keys := make([]any, len(parts))
+	for i, p := range parts {
+		keys[i] = p
+	}
+
+	return table, keys, nil
+}
+
+func (t auditTable) where() string {
+	conds := make([]string, len(t.keys))
+	for i, k := range t.keys {
+		conds[i] = k + " = ?"
+	}
+
+	return strings.Join(conds, " AND ")
+}
+
+// snapshotRow returns the row as a JSON object, or nil when it does not
+// exist.
+func snapshotRow(ctx context.Context, q queryer, entityType, entityID string) ([]byte, error) {
+	row, err := loadAuditRow(ctx, q, entityType, entityID)
+	if err != nil || row == nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s %s snapshot: %w", entityType, entityID, err)
+	}
+
+	return data, nil
+}
+
+func loadAuditRow(ctx context.Context, q queryer, entityType, entityID string) (map[string]any, error) {
+	table, keys, err := lookupAuditTable(entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT * FROM "+table.name+" WHERE "+table.where(), keys...)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s %s: %w", entityType, entityID, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s %s columns: %w", entityType, entityID, err)
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("snapshot %s %s: %w", entityType, entityID, err)
+		}
+
+		return nil, nil
+	}
+
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, fmt.Errorf("scan %s %s snapshot: %w", entityType, entityID, err)
+	}
+
+	row := make(map[string]any, len(cols))
+	for i, col := range cols {
+		if b, ok := values[i].([]byte); ok {
+			values[i] = string(b)
+		}
+
+		row[col] = values[i]
+	}
+
+	return row, nil
+}
+
+const insertAuditSQL = `
+INSERT INTO audit_log (entity_type, entity_id, action, actor, before, after, reverted_from)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+// trackChange runs fn, recording the named row's before and after snapshots
+// with the context's actor when fn changed it. Every curriculum mutation
+// made through WriteRepository or the curriculum ingester runs inside it.
+// Snapshots are JSON objects of the row's columns, so a record can be
+// reverted knowing only the entity's table and key columns.
+func trackChange(ctx context.Context, q queryer, entityType, entityID string, fn func() error) error {
+	_, err := recordChange(ctx, q, entityType, entityID, nil, fn)
+
+	return err
+}
+
+// TrackChange is trackChange for writers outside this package, such as the
+// curriculum ingester, that hold their own transaction.
+func TrackChange(ctx context.Context, tx *sql.Tx, entityType, entityID string, fn func() error) error {
+	return trackChange(ctx, tx, entityType, entityID, fn)
+}
+
+// recordChange is trackChange that also links the record to the one it
+// reverts. It returns the new record's ID, or 0 when nothing changed.
+func recordChange(ctx context.Context, q queryer, entityType, entityID string, revertedFrom *int64, fn func() error) (int64, error) {
+	before, err := snapshotRow(ctx, q, entityType, entityID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := fn(); err != nil {
+		return 0, err
+	}
+
+	after, err := snapshotRow(ctx, q, entityType, entityID)
+	if err != nil {
+		return 0, err
+	}
+
+	if bytes.Equal(before, after) {
+		return 0, nil
+	}
+
+	action := models.AuditActionUpdate
+	switch {
+	case before == nil:
+		action = models.AuditActionCreate
+	case after == nil:
+		action = models.AuditActionDelete
+	}
+
+	result, err := q.ExecContext(ctx, insertAuditSQL,
+		entityType, entityID, action, audit.ActorFrom(ctx),
+		bytesOrNil(before), bytesOrNil(after), revertedFrom,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("record %s %s %s: %w", action, entityType, entityID, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("audit record id: %w", err)
+	}
+
+	return id, nil
+}
+
+func bytesOrNil(b []byte) any {
+	if b == nil {
+		return nil
+	}
+
+	return string(b)
+}
+
+const auditColumns = `id, entity_type, entity_id, action, actor, before, after, reverted_from, created_at`
+
+const countHistorySQL = `
+SELECT COUNT(*) FROM audit_log
+WHERE (? = '' OR entity_type = ?) AND (? = '' OR entity_id = ?)
+`
+
+const listHistorySQL = `
+SELECT ` + auditColumns + `
+FROM audit_log
+WHERE (? = '' OR entity_type = ?) AND (? = '' OR entity_id = ?)
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+// ListHistory returns matching audit records, most recent first.
+func (r *SQLiteAuditRepository) ListHistory(ctx context.Context, filter models.HistoryFilter, params models.PaginationParams) (*models.PaginatedResponse[models.AuditRecord], error) {
+	args := []any{filter.EntityType, filter.EntityType, filter.EntityID, filter.EntityID}
+
+	var total int
+	if err := r.readDB.QueryRowContext(ctx, countHistorySQL, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count audit records: %w", err)
+	}
+
+	rows, err := r.readDB.QueryContext(ctx, listHistorySQL, append(args, params.PerPage, params.Offset())...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit records: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.AuditRecord{}
+
+	for rows.Next() {
+		rec, err := scanAuditRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, *rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit records: %w", err)
+	}
+
+	return &models.PaginatedResponse[models.AuditRecord]{
+		Items:   items,
+		Total:   total,
+		Page:    params.Page,
+		PerPage: params.PerPage,
+	}, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAuditRecord(row rowScanner) (*models.AuditRecord, error) {
+	var rec models.AuditRecord
+	var before, after sql.NullString
+	var revertedFrom sql.NullInt64
+
+	if err := row.Scan(
+		&rec.ID, &rec.EntityType, &rec.EntityID, &rec.Action, &rec.Actor,
+		&before, &after, &revertedFrom, &rec.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan audit record: %w", err)
+	}
+
+	if before.Valid {
+		rec.Before = json.RawMessage(before.String)
+	}
+
+	if after.Valid {
+		rec.After = json.RawMessage(after.String)
+	}
+
+	if revertedFrom.Valid {
+		rec.RevertedFrom = &revertedFrom.Int64
+	}
+
+	return &rec, nil
+}
+
+const getAuditRecordSQL = `SELECT ` + auditColumns + ` FROM audit_log WHERE id = ?`
+
+func (r *SQLiteAuditRepository) Revert(ctx context.Context, recordID int64, force bool) (*models.AuditRecord, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	target, err := scanAuditRecord(tx.QueryRowContext(ctx, getAuditRecordSQL, recordID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("audit record %d: %w", recordID, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := recordChange(ctx, tx, target.EntityType, target.EntityID, &recordID, func() error {
+		oldTopic, err := cardTopic(ctx, tx, target.EntityType, target.EntityID)
+		if err != nil {
+			return err
+		}
+
+		if err := restoreRow(ctx, tx, target.EntityType, target.EntityID, target.Before, force); err != nil {
+			return err
+		}
+
+		return syncRestoredRow(ctx, tx, target.EntityType, target.EntityID, oldTopic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rec *models.AuditRecord
+	if id != 0 {
+		if rec, err = scanAuditRecord(tx.QueryRowContext(ctx, getAuditRecordSQL, id)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return rec, nil
+}
+
+// restoreRow makes the row match snapshot: deleting it when snapshot is
+// null, inserting it when missing, and updating it otherwise. An update
+// bumps revision and updated_at rather than restoring them, so ETags held by
+// clients still go stale.
+func restoreRow(ctx context.Context, tx *sql.Tx, entityType, entityID string, snapshot json.RawMessage, force bool) error {
+	table, keys, err := lookupAuditTable(entityType, entityID)
+	if err != nil {
+		return err
+	}
+
+	current, err := loadAuditRow(ctx, tx, entityType, entityID)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshot) == 0 || string(snapshot) == "null" {
+		if current == nil {
+			return nil
+		}
+
+		if !force && table.progressSQL != "" {
+			if err := refuseIfProgress(ctx, tx, table.progressSQL, entityType, entityID); err != nil {
+				return err
+			}
+		}
+
+		if err := deleteSearchRows(ctx, tx, entityType, entityID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table.name+" WHERE "+table.where(), keys...); err != nil {
+			return classifyError(err, "revert "+entityType+" "+entityID)
+		}
+
+		return nil
+	}
+
+	want, err := decodeSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf("decode %s %s snapshot: %w", entityType, entityID, err)
+	}
+
+	if current == nil {
+		cols, args := snapshotColumns(want, nil)
+		marks := strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO "+table.name+" ("+strings.Join(cols, ", ")+") VALUES ("+marks+")", args...)
+	} else {
+		skip := map[string]bool{"revision": true, "updated_at": true}
+		for _, k := range table.keys {
+			skip[k] = true
+		}
+
+		cols, args := snapshotColumns(want, func(col string) bool {
+			_, exists := current[col]
+
+			return exists && !skip[col]
+		})
+
+		if sameValues(current, want, cols) {
+			return nil
+		}
+
+		sets := make([]string, len(cols))
+		for i, col := range cols {
+			sets[i] = col + " = ?"
+		}
+
+		if _, ok := current["revision"]; ok {
+			sets = append(sets, "revision = revision + 1")
+		}
+
+		if _, ok := current["updated_at"]; ok {
+			sets = append(sets, "updated_at = CURRENT_TIMESTAMP")
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE "+table.name+" SET "+strings.Join(sets, ", ")+" WHERE "+table.where(), append(args, keys...)...)
+	}
+
+	if err != nil {
+		return classifyError(err, "revert "+entityType+" "+entityID)
+	}
+
+	return refreshSearchRow(ctx, tx, entityType, entityID, want)
+}
+
+// cardTopic returns the topic whose generated cards derive from the row: a
+// topic itself or a concept's defining topic. Other entities have none.
+func cardTopic(ctx context.Context, tx *sql.Tx, entityType, entityID string) (string, error) {
+	switch entityType {
+	case models.AuditEntityTopic:
+		return entityID, nil
+	case models.AuditEntityConcept:
+		return conceptTopic(ctx, tx, entityID)
+	}
+
+	return "", nil
+}
+
+// syncRestoredRow runs the sync hook the entity's writers run, so a revert
+// leaves derived cards matching the restored row. oldTopic is the row's
+// cardTopic before the restore, whose cards a moved concept leaves behind.
+func syncRestoredRow(ctx context.Context, tx *sql.Tx, entityType, entityID, oldTopic string) error {
+	switch entityType {
+	case models.AuditEntityLesson:
+		return syncLessonQuestions(ctx, tx, entityID)
+	case models.AuditEntityModule:
+		return syncModuleQuestions(ctx, tx, entityID)
+	case models.AuditEntityTopic, models.AuditEntityConcept:
+		newTopic, err := cardTopic(ctx, tx, entityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		return syncTopicCards(ctx, tx, newTopic, oldTopic)
+	}
+
+	return nil
+}
+
+func decodeSnapshot(snapshot json.RawMessage) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(snapshot))
+	dec.UseNumber()
+
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil {
+		return nil, err
+	}
+
+	for col, v := range row {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+
+		if i, err := n.Int64(); err == nil {
+			row[col] = i
+		} else if f, err := n.Float64(); err == nil {
+			row[col] = f
+		}
+	}
+
+	return row, nil
+}
+
+// snapshotColumns returns the snapshot's column names in a stable order with
+// their values, keeping only columns keep accepts. Column names come from
+// rows the table itself produced; an insert keeps every column, and an
+// update keeps only columns the current row still has.
+func snapshotColumns(row map[string]any, keep func(string) bool) ([]string, []any) {
+	cols := make([]string, 0, len(row))
+	for col := range row {
+		if keep == nil || keep(col) {
+			cols = append(cols, col)
+		}
+	}
+
+	slices.Sort(cols)
+
+	args := make([]any, len(cols))
+	for i, col := range cols {
+		args[i] = row[col]
+	}
+
+	return cols, args
+}
+
+// sameValues reports whether a and b agree on every column in cols. Values
+// are compared by their JSON encoding, so a REAL read back as 2 matches the
+// snapshot's integer 2.
+func sameValues(a, b map[string]any, cols []string) bool {
+	for _, col := range cols {
+		x, errX := json.Marshal(a[col])
+		y, errY := json.Marshal(b[col])
+
+		if errX != nil || errY != nil || !bytes.Equal(x, y) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deleteSearchRows removes the search entries for a row about to be deleted,
+// including the lessons a topic or module delete cascades to.
+func deleteSearchRows(ctx context.Context, q queryer, entityType, entityID string) error {
+	var err error
+
+	switch entityType {
+	case models.AuditEntityTopic:
+		if _, err = q.ExecContext(ctx, deleteTopicLessonSearchSQL, entityID); err == nil {
+			_, err = q.ExecContext(ctx, deleteSearchSQL, entityType, entityID)
+		}
+	case models.AuditEntityModule:
+		_, err = q.ExecContext(ctx, deleteModuleLessonSearchSQL, entityID)
+	case models.AuditEntityLesson, models.AuditEntityConcept:
+		_, err = q.ExecContext(ctx, deleteSearchSQL, entityType, entityID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("delete search index for %s %s: %w", entityType, entityID, err)
+	}
+
+	return nil
+}
+
+// refreshSearchRow rewrites the search entry for a restored row.
+func refreshSearchRow(ctx context.Context, q queryer, entityType, entityID string, row map[string]any) error {
+	text := func(col string) string {
+		s, _ := row[col].(string)
+
+		return s
+	}
+
+	switch entityType {
+	case models.AuditEntityTopic:
+		return upsertSearchIndex(ctx, q, entityType, entityID, text("title"), text("description"))
+	case models.AuditEntityLesson:
+		return upsertSearchIndex(ctx, q, entityType, entityID, text("title"), "")
+	case models.AuditEntityConcept:
+		return upsertSearchIndex(ctx, q, entityType, entityID, text("name"), text("definition"))
+	}
+
+	return nil
+}
+
+// Verify interface compliance at compile time.
+var _ AuditRepository = (*SQLiteAuditRepository)(nil)
@@ -0,0 +1,286 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/audit"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func listHistory(t *testing.T, repo *repository.SQLiteAuditRepository, entityType, entityID string) []models.AuditRecord {
+	t.Helper()
+
+	page, err := repo.ListHistory(context.Background(),
+		models.HistoryFilter{EntityType: entityType, EntityID: entityID},
+		models.PaginationParams{Page: 1, PerPage: 100},
+	)
+	if err != nil {
+		t.Fatalf("ListHistory() error = %v", err)
+	}
+
+	return page.Items
+}
+
+func snapshotField(t *testing.T, raw json.RawMessage, field string) any {
+	t.Helper()
+
+	var row map[string]any
+	if err := json.Unmarshal(raw, &row); err != nil {
+		t.Fatalf("decode snapshot %s: %v", raw, err)
+	}
+
+	return row[field]
+}
+
+func TestWritesRecordHistoryWithActor(t *testing.T) {
+	db := setupTestDB(t)
+	writes := repository.NewWriteRepository(db)
+	history := repository.NewAuditRepository(db, db)
+	ctx := audit.WithActor(context.Background(), "alice")
+
+	topic := models.TopicInput{ID: "t1", Title: "Old", Status: "published"}
+	if _, err := writes.CreateTopic(ctx, topic); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	topic.Title = "New"
+	if _, err := writes.UpdateTopic(ctx, "t1", 0, topic); err != nil {
+		t.Fatalf("UpdateTopic() error = %v", err)
+	}
+
+	if err := writes.DeleteTopic(ctx, "t1", false); err != nil {
+		t.Fatalf("DeleteTopic() error = %v", err)
+	}
+
+	records := listHistory(t, history, models.AuditEntityTopic, "t1")
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	wantActions := []string{models.AuditActionDelete, models.AuditActionUpdate, models.AuditActionCreate}
+	for i, rec := range records {
+		if rec.Action != wantActions[i] || rec.Actor != "alice" {
+			t.Fatalf("record %d = %s by %s, want %s by alice", i, rec.Action, rec.Actor, wantActions[i])
+		}
+	}
+
+	if records[2].Before != nil || records[0].After != nil {
+		t.Fatalf("create should have no before and delete no after")
+	}
+
+	if got := snapshotField(t, records[1].Before, "title"); got != "Old" {
+		t.Fatalf("update before title = %v, want Old", got)
+	}
+
+	if got := snapshotField(t, records[1].After, "title"); got != "New" {
+		t.Fatalf("update after title = %v, want New", got)
+	}
+}
+
+func TestWritesWithoutChangesRecordNothing(t *testing.T) {
+	db := setupTestDB(t)
+	seedOrderTree(t, db)
+	writes := repository.NewWriteRepository(db)
+	history := repository.NewAuditRepository(db, db)
+
+	if err := writes.ReorderModules(context.Background(), "t1", []string{"m1", "m2", "m3"}); err != nil {
+		t.Fatalf("ReorderModules() error = %v", err)
+	}
+
+	if records := listHistory(t, history, models.AuditEntityModule, ""); len(records) != 0 {
+		t.Fatalf("unchanged reorder recorded %d changes", len(records))
+	}
+
+	if err := writes.ReorderModules(context.Background(), "t1", []string{"m2", "m1", "m3"}); err != nil {
+		t.Fatalf("ReorderModules() error = %v", err)
+	}
+
+	records := listHistory(t, history, models.AuditEntityModule, "")
+	if len(records) != 2 {
+		t.Fatalf("swap recorded %d changes, want 2", len(records))
+	}
+
+	if records[0].Actor != audit.ActorCLI {
+		t.Fatalf("actor = %q, want %q", records[0].Actor, audit.ActorCLI)
+	}
+}
+
+func TestRevertUpdateRestoresRow(t *testing.T) {
+	db := setupTestDB(t)
+	writes := repository.NewWriteRepository(db)
+	history := repository.NewAuditRepository(db, db)
+	ctx := context.Background()
+
+	concept := models.ConceptInput{ID: "c1", Name: "Channel", Definition: "A typed pipe"}
+	if _, err := writes.CreateConcept(ctx, concept); err != nil {
+		t.Fatalf("CreateConcept() error = %v", err)
+	}
+
+	concept.Definition = "Something wrong"
+	if _, err := writes.UpdateConcept(ctx, "c1", 0, concept); err != nil {
+		t.Fatalf("UpdateConcept() error = %v", err)
+	}
+
+	update := listHistory(t, history, models.AuditEntityConcept, "c1")[0]
+
+	rec, err := history.Revert(audit.WithActor(ctx, "bob"), update.ID, false)
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	if rec == nil || rec.RevertedFrom == nil || *rec.RevertedFrom != update.ID || rec.Actor != "bob" {
+		t.Fatalf("unexpected revert record %+v", rec)
+	}
+
+	var definition string
+	var revision int
+	if err := db.QueryRow(`SELECT definition, revision FROM concepts WHERE id = 'c1'`).Scan(&definition, &revision); err != nil {
+		t.Fatalf("load concept: %v", err)
+	}
+
+	if definition != "A typed pipe" || revision != 3 {
+		t.Fatalf("concept = %q rev %d, want restored definition at rev 3", definition, revision)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_id = 'c1' AND body = 'A typed pipe'`); n != 1 {
+		t.Fatalf("search index not refreshed, got %d rows", n)
+	}
+
+	again, err := history.Revert(ctx, update.ID, false)
+	if err != nil || again != nil {
+		t.Fatalf("second Revert() = %+v, %v; want nil, nil", again, err)
+	}
+}
+
+func TestRevertLessonResyncsQuestionCards(t *testing.T) {
+	db := setupTestDB(t)
+	history := repository.NewAuditRepository(db, db)
+	ctx := context.Background()
+	seedQuestionTree(t, db)
+
+	if _, err := repository.NewWriteRepository(db).UpdateLesson(ctx, "l1", 0, models.LessonInput{
+		ModuleID: "m1", Title: "Channels", SortOrder: 1, Content: json.RawMessage(`{}`),
+		ReviewQuestions: json.RawMessage(`[
+			{"question":"What is a channel?","answer":"A typed pipe","concepts_tested":["c1"]}
+		]`),
+	}); err != nil {
+		t.Fatalf("UpdateLesson() error = %v", err)
+	}
+
+	const selectQ = `SELECT COUNT(*) FROM question_cards WHERE question = 'What does select do?'`
+	if n := countRows(t, db, selectQ); n != 0 {
+		t.Fatalf("update kept %d cards of the removed question", n)
+	}
+
+	update := listHistory(t, history, models.AuditEntityLesson, "l1")[0]
+	if _, err := history.Revert(ctx, update.ID, false); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	if n := countRows(t, db, selectQ); n != 1 {
+		t.Fatalf("revert left %d cards of the restored question, want 1", n)
+	}
+}
+
+func TestRevertConceptResyncsGeneratedCards(t *testing.T) {
+	db := setupTestDB(t)
+	history := repository.NewAuditRepository(db, db)
+	ctx := context.Background()
+	seedCardTree(t, db)
+
+	if _, err := repository.NewReviewRepository(db, db).SetCardKinds(ctx, "t1", []string{models.CardKindReverse}); err != nil {
+		t.Fatalf("SetCardKinds() error = %v", err)
+	}
+
+	const original = "A typed pipe that goroutines use to send values."
+
+	if _, err := repository.NewWriteRepository(db).UpdateConcept(ctx, "c1", 0, models.ConceptInput{
+		Name: "Channel", Definition: "Something wrong", DefinedInLesson: "l1", DefinedInTopic: "t1",
+	}); err != nil {
+		t.Fatalf("UpdateConcept() error = %v", err)
+	}
+
+	if _, front, _ := generatedCard(t, db, "c1", models.CardKindReverse, "Channel"); front != "Something wrong" {
+		t.Fatalf("update left reverse card front %q", front)
+	}
+
+	update := listHistory(t, history, models.AuditEntityConcept, "c1")[0]
+	if _, err := history.Revert(ctx, update.ID, false); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	if _, front, _ := generatedCard(t, db, "c1", models.CardKindReverse, "Channel"); front != original {
+		t.Fatalf("reverse card front = %q, want the restored definition", front)
+	}
+}
+
+func TestRevertDeleteRecreatesRow(t *testing.T) {
+	db := setupTestDB(t)
+	seedTopic(t, db, "a", "A", "foundational", "published")
+	seedTopic(t, db, "b", "B", "foundational", "published")
+	writes := repository.NewWriteRepository(db)
+	history := repository.NewAuditRepository(db, db)
+	ctx := context.Background()
+
+	input := models.PrerequisiteInput{TopicID: "a", PrerequisiteTopicID: "b", Priority: "essential", Reason: "basics"}
+	if err := writes.CreatePrerequisite(ctx, input); err != nil {
+		t.Fatalf("CreatePrerequisite() error = %v", err)
+	}
+
+	if err := writes.DeletePrerequisite(ctx, "a", "b"); err != nil {
+		t.Fatalf("DeletePrerequisite() error = %v", err)
+	}
+
+	records := listHistory(t, history, models.AuditEntityPrerequisite, "a/b")
+	if len(records) != 2 || records[0].Action != models.AuditActionDelete {
+		t.Fatalf("unexpected history %+v", records)
+	}
+
+	if _, err := history.Revert(ctx, records[0].ID, false); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM topic_prerequisites WHERE topic_id = 'a' AND reason = 'basics'`); n != 1 {
+		t.Fatalf("prerequisite not restored")
+	}
+}
+
+func TestRevertCreateRespectsProgress(t *testing.T) {
+	db := setupTestDB(t)
+	seedIndexedTree(t, db)
+	history := repository.NewAuditRepository(db, db)
+	ctx := context.Background()
+
+	mustExec(t, db, `INSERT INTO learning_progress (lesson_id, status) VALUES ('l1', 'completed')`)
+
+	create := listHistory(t, history, models.AuditEntityLesson, "l1")[0]
+
+	if _, err := history.Revert(ctx, create.ID, false); !errors.Is(err, repository.ErrHasProgress) {
+		t.Fatalf("Revert() error = %v, want ErrHasProgress", err)
+	}
+
+	if _, err := history.Revert(ctx, create.ID, true); err != nil {
+		t.Fatalf("forced Revert() error = %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM lessons WHERE id = 'l1'`); n != 0 {
+		t.Fatalf("lesson not deleted")
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type = 'lesson' AND entity_id = 'l1'`); n != 0 {
+		t.Fatalf("lesson search row left behind")
+	}
+}
+
+func TestRevertUnknownRecord(t *testing.T) {
+	db := setupTestDB(t)
+	history := repository.NewAuditRepository(db, db)
+
+	if _, err := history.Revert(context.Background(), 42, false); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Revert() error = %v, want ErrNotFound", err)
+	}
+}
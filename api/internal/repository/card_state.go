@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// cardStore loads, stores, logs, and re-derives the review state of cards
+// scheduled apart from concepts: question cards and generated cards. Their
+// state is a models.ConceptRetention with an empty concept ID and no
+// suspension, so the schedulers apply to it unchanged.
+type cardStore struct {
+	// entity names the card in errors.
+	entity      string
+	getState    string
+	updateState string
+	insertLog   string
+	listForeign string
+	listLog     string
+}
+
+// cardStateColumns selects a card's review state in the shape of
+// retentionColumns, with an empty concept ID and no suspension.
+const cardStateColumns = `
+'', status, COALESCE(next_review, ''), review_count, ease_factor,
+interval_days, COALESCE(last_reviewed, ''), COALESCE(last_rating, ''),
+COALESCE(stability, 0), COALESCE(difficulty, 0), scheduler,
+lapses, ''
+`
+
+// newCardStore returns the cardStore of the cards in table, whose reviews
+// logTable records against logColumn.
+func newCardStore(entity, table, logTable, logColumn string) cardStore {
+	return cardStore{
+		entity:   entity,
+		getState: `SELECT ` + cardStateColumns + ` FROM ` + table + ` WHERE id = ?`,
+		updateState: `
+UPDATE ` + table + ` SET status = ?, next_review = ?, review_count = ?, ease_factor = ?,
+       interval_days = ?, last_reviewed = ?, last_rating = ?, stability = ?,
+       difficulty = ?, scheduler = ?, lapses = ?
+WHERE id = ?
+`,
+		insertLog: `
+INSERT INTO ` + logTable + ` (` + logColumn + `, rating, reviewed_at, previous_interval, new_interval, ease_factor, response_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`,
+		listForeign: `SELECT id, ` + cardStateColumns + ` FROM ` + table + ` WHERE scheduler <> ? ORDER BY id`,
+		listLog:     `SELECT ` + logColumn + `, rating, reviewed_at FROM ` + logTable + ` ORDER BY ` + logColumn + `, id`,
+	}
+}
+
+var (
+	questionStore  = newCardStore("question", "question_cards", "question_review_log", "question_id")
+	generatedStore = newCardStore("card", "generated_cards", "generated_card_review_log", "card_id")
+)
+
+// load returns the card's review state, or ErrNotFound.
+func (s cardStore) load(ctx context.Context, q queryer, id string) (*models.ConceptRetention, error) {
+	state := &models.ConceptRetention{}
+
+	err := scanRetention(q.QueryRowContext(ctx, s.getState, id), state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s %s: %w", s.entity, id, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("load %s %s state: %w", s.entity, id, err)
+	}
+
+	return state, nil
+}
+
+func (s cardStore) store(ctx context.Context, q queryer, id string, state models.ConceptRetention) error {
+	if _, err := q.ExecContext(ctx, s.updateState,
+		state.Status, nullIfEmpty(state.NextReview), state.ReviewCount, state.EaseFactor,
+		state.IntervalDays, nullIfEmpty(state.LastReviewed), nullIfEmpty(state.LastRating),
+		nullIfZeroFloat(state.Stability), nullIfZeroFloat(state.Difficulty), state.Scheduler,
+		state.Lapses, id,
+	); err != nil {
+		return classifyError(err, "store "+s.entity+" "+id+" review")
+	}
+
+	return nil
+}
+
+// review schedules the card's next review, stores the new state, and logs
+// the review. A forgotten card gains a lapse.
+func (s cardStore) review(ctx context.Context, q queryer, id string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (models.ConceptRetention, error) {
+	current, err := s.load(ctx, q, id)
+	if err != nil {
+		return models.ConceptRetention{}, err
+	}
+
+	next, err := schedule(*current)
+	if err != nil {
+		return models.ConceptRetention{}, err
+	}
+
+	next.ConceptID = ""
+	next.SuspendedAt = ""
+	next.Lapses = current.Lapses
+
+	if next.LastRating == models.RatingForgot {
+		next.Lapses++
+	}
+
+	if err := s.store(ctx, q, id, next); err != nil {
+		return models.ConceptRetention{}, err
+	}
+
+	if _, err := q.ExecContext(ctx, s.insertLog,
+		id, next.LastRating, next.LastReviewed,
+		current.IntervalDays, next.IntervalDays, next.EaseFactor, nullIfZero(responseMS),
+	); err != nil {
+		return models.ConceptRetention{}, classifyError(err, "log "+s.entity+" "+id+" review")
+	}
+
+	return next, nil
+}
+
+// rederive re-derives the state of cards held by a scheduler other than
+// name, as rederive does for concepts, and returns how many there were.
+func (s cardStore) rederive(ctx context.Context, q queryer, name string, replay ReplayFunc) (int, error) {
+	rows, err := q.QueryContext(ctx, s.listForeign, name)
+	if err != nil {
+		return 0, fmt.Errorf("query %s state: %w", s.entity, err)
+	}
+
+	var ids []string
+	var states []models.ConceptRetention
+
+	for rows.Next() {
+		var id string
+		var state models.ConceptRetention
+		if err := rows.Scan(&id,
+			&state.ConceptID, &state.Status, &state.NextReview, &state.ReviewCount, &state.EaseFactor,
+			&state.IntervalDays, &state.LastReviewed, &state.LastRating,
+			&state.Stability, &state.Difficulty, &state.Scheduler, &state.Lapses, &state.SuspendedAt,
+		); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scan %s state: %w", s.entity, err)
+		}
+
+		ids = append(ids, id)
+		states = append(states, state)
+	}
+
+	err = rows.Err()
+	rows.Close()
+
+	if err != nil {
+		return 0, fmt.Errorf("iterate %s state: %w", s.entity, err)
+	}
+
+	if len(states) == 0 {
+		return 0, nil
+	}
+
+	history, err := s.history(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, current := range states {
+		next, err := replay(current, history[ids[i]])
+		if err != nil {
+			return 0, err
+		}
+
+		next.Lapses = current.Lapses
+
+		if err := s.store(ctx, q, ids[i], next); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(states), nil
+}
+
+// history returns each card's reviews, oldest first.
+func (s cardStore) history(ctx context.Context, q queryer) (map[string][]models.ReviewEvent, error) {
+	rows, err := q.QueryContext(ctx, s.listLog)
+	if err != nil {
+		return nil, fmt.Errorf("query %s review log: %w", s.entity, err)
+	}
+	defer rows.Close()
+
+	history := make(map[string][]models.ReviewEvent)
+
+	for rows.Next() {
+		var id string
+		var e models.ReviewEvent
+		if err := rows.Scan(&id, &e.Rating, &e.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("scan %s review log: %w", s.entity, err)
+		}
+
+		history[id] = append(history[id], e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s review log: %w", s.entity, err)
+	}
+
+	return history, nil
+}
@@ -108,7 +108,7 @@ func (r *SQLiteConceptRepository) ListConcepts(ctx context.Context, params model
 const getConceptSQL = `
 SELECT id, name, definition, COALESCE(defined_in_lesson, ''), COALESCE(defined_in_topic, ''),
        COALESCE(difficulty, ''), COALESCE(flashcard_front, ''), COALESCE(flashcard_back, ''),
-       status, aliases
+       status, aliases, revision
 FROM concepts
 WHERE id = ?
 `
@@ -120,14 +120,20 @@ JOIN lessons l ON l.id = cr.lesson_id
 WHERE cr.concept_id = ?
 `
 
+// GetConceptByID returns the concept with the given ID or alias.
 func (r *SQLiteConceptRepository) GetConceptByID(ctx context.Context, id string) (*models.ConceptDetail, error) {
+	id, err := ResolveConceptID(ctx, r.db, id)
+	if err != nil || id == "" {
+		return nil, err
+	}
+
 	cd := &models.ConceptDetail{}
 	var aliasesRaw *string
 
-	err := r.db.QueryRowContext(ctx, getConceptSQL, id).Scan(
+	err = r.db.QueryRowContext(ctx, getConceptSQL, id).Scan(
 		&cd.ID, &cd.Name, &cd.Definition, &cd.DefinedInLesson, &cd.DefinedInTopic,
 		&cd.Difficulty, &cd.FlashcardFront, &cd.FlashcardBack,
-		&cd.Status, &aliasesRaw,
+		&cd.Status, &aliasesRaw, &cd.Revision,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -178,17 +184,12 @@ func (r *SQLiteConceptRepository) queryReferences(ctx context.Context, conceptID
 	return refs, nil
 }
 
+// GetConceptReferences returns the references of the concept with the given
+// ID or alias, or nil when no such concept exists.
 func (r *SQLiteConceptRepository) GetConceptReferences(ctx context.Context, id string) ([]models.ConceptReference, error) {
-	// First check concept exists.
-	var exists bool
-
-	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM concepts WHERE id = ?)", id).Scan(&exists)
-	if err != nil {
-		return nil, fmt.Errorf("check concept exists %s: %w", id, err)
-	}
-
-	if !exists {
-		return nil, nil
+	id, err := ResolveConceptID(ctx, r.db, id)
+	if err != nil || id == "" {
+		return nil, err
 	}
 
 	return r.queryReferences(ctx, id)
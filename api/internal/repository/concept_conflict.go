@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// ConceptConflictRepository defines the concept conflict review workflow.
+// Conflicts are created by the curriculum ingester when a second curriculum
+// defines an existing concept differently.
+type ConceptConflictRepository interface {
+	ListConflicts(ctx context.Context) ([]models.ConceptConflict, error)
+	ResolveConflict(ctx context.Context, conceptID string, input models.ResolveConflictInput) (*models.ConceptResolution, error)
+}
+
+// SQLiteConceptConflictRepository implements ConceptConflictRepository using
+// SQLite. Reads use the read pool; resolution uses the write handle.
+type SQLiteConceptConflictRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewConceptConflictRepository creates a new SQLiteConceptConflictRepository.
+func NewConceptConflictRepository(readDB, writeDB *sql.DB) *SQLiteConceptConflictRepository {
+	return &SQLiteConceptConflictRepository{readDB: readDB, db: writeDB}
+}
+
+const listConflictedConceptsSQL = `
+SELECT id, name, definition FROM concepts WHERE status = 'conflict' ORDER BY name, id
+`
+
+const listConflictCandidatesSQL = `
+SELECT cc.id, cc.concept_id, cc.name, cc.definition,
+       COALESCE(cc.flashcard_front, ''), COALESCE(cc.flashcard_back, ''),
+       COALESCE(cc.source_lesson, ''), COALESCE(l.title, ''), COALESCE(cc.source_topic, ''),
+       cc.created_at
+FROM concept_candidates cc
+JOIN concepts c ON c.id = cc.concept_id
+LEFT JOIN lessons l ON l.id = cc.source_lesson
+WHERE c.status = 'conflict'
+ORDER BY cc.concept_id, cc.id
+`
+
+// ListConflicts returns every conflicted concept with its candidates, using
+// one query per level.
+func (r *SQLiteConceptConflictRepository) ListConflicts(ctx context.Context) ([]models.ConceptConflict, error) {
+	rows, err := r.readDB.QueryContext(ctx, listConflictedConceptsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query conflicted concepts: %w", err)
+	}
+	defer rows.Close()
+
+	conflicts := []models.ConceptConflict{}
+	index := make(map[string]int)
+
+	for rows.Next() {
+		c := models.ConceptConflict{Candidates: []models.ConceptCandidate{}}
+		if err := rows.Scan(&c.ConceptID, &c.Name, &c.Definition); err != nil {
+			return nil, fmt.Errorf("scan conflicted concept: %w", err)
+		}
+
+		index[c.ConceptID] = len(conflicts)
+		conflicts = append(conflicts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate conflicted concepts: %w", err)
+	}
+
+	candRows, err := r.readDB.QueryContext(ctx, listConflictCandidatesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query concept candidates: %w", err)
+	}
+	defer candRows.Close()
+
+	for candRows.Next() {
+		var conceptID string
+		var cand models.ConceptCandidate
+
+		if err := candRows.Scan(
+			&cand.ID, &conceptID, &cand.Name, &cand.Definition,
+			&cand.FlashcardFront, &cand.FlashcardBack,
+			&cand.SourceLessonID, &cand.SourceLessonTitle, &cand.SourceTopicID,
+			&cand.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan concept candidate: %w", err)
+		}
+
+		if i, ok := index[conceptID]; ok {
+			conflicts[i].Candidates = append(conflicts[i].Candidates, cand)
+		}
+	}
+
+	if err := candRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate concept candidates: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+const getConflictConceptSQL = `
+SELECT name, definition, COALESCE(flashcard_front, ''), COALESCE(flashcard_back, ''),
+       COALESCE(defined_in_lesson, ''), COALESCE(defined_in_topic, ''), status
+FROM concepts
+WHERE id = ?
+`
+
+const getConceptCandidateSQL = `
+SELECT definition, COALESCE(flashcard_front, ''), COALESCE(flashcard_back, ''),
+       COALESCE(source_lesson, ''), COALESCE(source_topic, '')
+FROM concept_candidates
+WHERE id = ? AND concept_id = ?
+`
+
+const resolveConceptSQL = `
+UPDATE concepts SET definition = ?, flashcard_front = ?, flashcard_back = ?,
+                    defined_in_lesson = ?, defined_in_topic = ?, status = 'active',
+                    revision = revision + 1
+WHERE id = ?
+`
+
+const deleteConceptCandidatesSQL = `DELETE FROM concept_candidates WHERE concept_id = ?`
+
+// resetRetentionSQL restarts spaced repetition for a concept whose canonical
+// definition changed. Concepts already being studied go back to learning and
+// are due at once, with no lapses and no longer suspended; concepts never
+// studied stay new. reset_at records the reset so a re-derive replays only
+// later reviews.
+const resetRetentionSQL = `
+UPDATE concept_retention
+SET status = CASE WHEN status = 'new' THEN 'new' ELSE 'learning' END,
+    next_review = CASE WHEN status = 'new' THEN next_review ELSE ?1 END,
+    review_count = 0, ease_factor = 2.5, interval_days = 0,
+    last_reviewed = NULL, last_rating = NULL, stability = NULL, difficulty = NULL,
+    lapses = 0, suspended_at = NULL, reset_at = ?1
+WHERE concept_id = ?2
+`
+
+type conceptCandidate struct {
+	definition, front, back, lesson, topic string
+}
+
+// ResolveConflict replaces the concept's definition with the chosen
+// candidates or the supplied text, clears its candidates, marks it active,
+// and resets its review state when the definition changed.
+func (r *SQLiteConceptConflictRepository) ResolveConflict(ctx context.Context, conceptID string, input models.ResolveConflictInput) (*models.ConceptResolution, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var name, status, oldDefinition string
+	res := &models.ConceptResolution{ConceptID: conceptID}
+
+	err = tx.QueryRowContext(ctx, getConflictConceptSQL, conceptID).Scan(
+		&name, &oldDefinition, &res.FlashcardFront, &res.FlashcardBack,
+		&res.DefinedInLesson, &res.DefinedInTopic, &status,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("concept %s: %w", conceptID, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("load concept %s: %w", conceptID, err)
+	}
+
+	if status != models.ConceptStatusConflict {
+		return nil, fmt.Errorf("concept %s: %w", conceptID, ErrNoConflict)
+	}
+
+	oldTopic := res.DefinedInTopic
+
+	chosen := make([]conceptCandidate, 0, len(input.CandidateIDs))
+
+	for _, id := range input.CandidateIDs {
+		var c conceptCandidate
+
+		err := tx.QueryRowContext(ctx, getConceptCandidateSQL, id, conceptID).Scan(&c.definition, &c.front, &c.back, &c.lesson, &c.topic)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("candidate %d of concept %s: %w", id, conceptID, ErrNotFound)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("load candidate %d: %w", id, err)
+		}
+
+		chosen = append(chosen, c)
+	}
+
+	if len(chosen) > 0 {
+		definitions := make([]string, len(chosen))
+		for i, c := range chosen {
+			definitions[i] = c.definition
+		}
+
+		res.Definition = strings.Join(definitions, "\n\n")
+		res.FlashcardFront, res.FlashcardBack = chosen[0].front, chosen[0].back
+	}
+
+	// A single picked candidate also brings its defining lesson and topic.
+	if len(chosen) == 1 {
+		if chosen[0].lesson != "" {
+			res.DefinedInLesson = chosen[0].lesson
+		}
+
+		if chosen[0].topic != "" {
+			res.DefinedInTopic = chosen[0].topic
+		}
+	}
+
+	if input.Definition != "" {
+		res.Definition = input.Definition
+	}
+
+	if input.FlashcardFront != "" {
+		res.FlashcardFront = input.FlashcardFront
+	}
+
+	if input.FlashcardBack != "" {
+		res.FlashcardBack = input.FlashcardBack
+	}
+
+	if res.Definition == "" {
+		return nil, fmt.Errorf("resolve concept %s: no definition chosen: %w", conceptID, ErrCheckViolation)
+	}
+
+	err = trackChange(ctx, tx, models.AuditEntityConcept, conceptID, func() error {
+		if _, err := tx.ExecContext(ctx, resolveConceptSQL,
+			res.Definition, nullIfEmpty(res.FlashcardFront), nullIfEmpty(res.FlashcardBack),
+			nullIfEmpty(res.DefinedInLesson), nullIfEmpty(res.DefinedInTopic), conceptID,
+		); err != nil {
+			return classifyError(err, "resolve concept "+conceptID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := upsertSearchIndex(ctx, tx, "concept", conceptID, name, res.Definition); err != nil {
+		return nil, err
+	}
+
+	if err := syncTopicCards(ctx, tx, res.DefinedInTopic, oldTopic); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteConceptCandidatesSQL, conceptID); err != nil {
+		return nil, fmt.Errorf("delete concept %s candidates: %w", conceptID, err)
+	}
+
+	if strings.TrimSpace(res.Definition) != strings.TrimSpace(oldDefinition) {
+		result, err := tx.ExecContext(ctx, resetRetentionSQL, time.Now().UTC().Format(time.RFC3339), conceptID)
+		if err != nil {
+			return nil, fmt.Errorf("reset concept %s retention: %w", conceptID, err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("reset concept %s retention rows affected: %w", conceptID, err)
+		}
+
+		res.RetentionReset = n > 0
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return res, nil
+}
+
+// Verify interface compliance at compile time.
+var _ ConceptConflictRepository = (*SQLiteConceptConflictRepository)(nil)
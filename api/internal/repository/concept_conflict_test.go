@@ -0,0 +1,238 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// seedConflict puts "bridge" in conflict between lessons l1 and l4 and
+// returns the two candidate IDs in that order.
+func seedConflict(t *testing.T, db *sql.DB) (int64, int64) {
+	t.Helper()
+
+	seedOrderTree(t, db)
+	seedConcept(t, db, "bridge", "Bridge", "A software switch", "t1")
+	mustExec(t, db, `UPDATE concepts SET status = 'conflict', defined_in_lesson = 'l1', flashcard_front = 'F1', flashcard_back = 'B1' WHERE id = 'bridge'`)
+	mustExec(t, db, `INSERT INTO concept_candidates (id, concept_id, name, definition, flashcard_front, flashcard_back, source_lesson, source_topic)
+		VALUES (1, 'bridge', 'Bridge', 'A software switch', 'F1', 'B1', 'l1', 't1')`)
+	mustExec(t, db, `INSERT INTO concept_candidates (id, concept_id, name, definition, flashcard_front, flashcard_back, source_lesson, source_topic)
+		VALUES (2, 'bridge', 'Bridge', 'A layer 2 forwarding device', 'F2', 'B2', 'l4', 't1')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days, review_count, ease_factor, last_rating)
+		VALUES ('bridge', 'reviewing', 12, 5, 2.8, 'good')`)
+
+	return 1, 2
+}
+
+func TestListConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	seedConflict(t, db)
+	seedConcept(t, db, "other", "Other", "Not in conflict", "t1")
+	repo := repository.NewConceptConflictRepository(db, db)
+
+	conflicts, err := repo.ListConflicts(context.Background())
+	if err != nil {
+		t.Fatalf("list conflicts: %v", err)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].ConceptID != "bridge" {
+		t.Fatalf("expected only bridge in conflict, got %+v", conflicts)
+	}
+
+	cands := conflicts[0].Candidates
+	if len(cands) != 2 || cands[0].SourceLessonID != "l1" || cands[1].SourceLessonID != "l4" {
+		t.Fatalf("expected candidates from l1 and l4, got %+v", cands)
+	}
+
+	if cands[1].SourceLessonTitle == "" || cands[1].Definition != "A layer 2 forwarding device" {
+		t.Fatalf("expected candidate with lesson title and definition, got %+v", cands[1])
+	}
+}
+
+func TestResolveConflictPickCandidate(t *testing.T) {
+	db := setupTestDB(t)
+	_, second := seedConflict(t, db)
+	repo := repository.NewConceptConflictRepository(db, db)
+
+	res, err := repo.ResolveConflict(context.Background(), "bridge", models.ResolveConflictInput{CandidateIDs: []int64{second}})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if res.Definition != "A layer 2 forwarding device" || res.FlashcardFront != "F2" || res.DefinedInLesson != "l4" || !res.RetentionReset {
+		t.Fatalf("unexpected resolution %+v", res)
+	}
+
+	var status, definition, lesson string
+	if err := db.QueryRow(`SELECT status, definition, defined_in_lesson FROM concepts WHERE id = 'bridge'`).Scan(&status, &definition, &lesson); err != nil {
+		t.Fatalf("query concept: %v", err)
+	}
+
+	if status != "active" || definition != res.Definition || lesson != "l4" {
+		t.Fatalf("concept not updated: %s %q %s", status, definition, lesson)
+	}
+
+	var candidates int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM concept_candidates WHERE concept_id = 'bridge'`).Scan(&candidates); err != nil {
+		t.Fatalf("count candidates: %v", err)
+	}
+
+	if candidates != 0 {
+		t.Fatalf("expected candidates cleared, got %d", candidates)
+	}
+
+	var retStatus string
+	var interval, reviews int
+	var ease float64
+	var lastRating sql.NullString
+	if err := db.QueryRow(`SELECT status, interval_days, review_count, ease_factor, last_rating FROM concept_retention WHERE concept_id = 'bridge'`).
+		Scan(&retStatus, &interval, &reviews, &ease, &lastRating); err != nil {
+		t.Fatalf("query retention: %v", err)
+	}
+
+	if retStatus != "learning" || interval != 0 || reviews != 0 || ease != 2.5 || lastRating.Valid {
+		t.Fatalf("expected retention reset, got %s %d %d %v %v", retStatus, interval, reviews, ease, lastRating)
+	}
+}
+
+func TestResolveConflictResetSurvivesRederive(t *testing.T) {
+	db := setupTestDB(t)
+	_, second := seedConflict(t, db)
+	ctx := context.Background()
+	mustExec(t, db, `INSERT INTO review_log (concept_id, rating, reviewed_at) VALUES
+		('bridge', 'good', '2026-01-01T09:00:00Z'), ('bridge', 'easy', '2026-01-05T09:00:00Z')`)
+
+	if _, err := repository.NewConceptConflictRepository(db, db).ResolveConflict(ctx, "bridge", models.ResolveConflictInput{CandidateIDs: []int64{second}}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	reviews := repository.NewReviewRepository(db, db)
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	later := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	if _, err := reviews.RecordReview(ctx, "bridge", 0, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+		return sm2.Review(current, models.RatingHard, later)
+	}); err != nil {
+		t.Fatalf("record review: %v", err)
+	}
+
+	var replayed []models.ReviewEvent
+	fsrs := review.FSRS{MasteryDays: review.DefaultMasteryDays}
+	replay := func(current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error) {
+		if current.ConceptID == "bridge" {
+			replayed = history
+		}
+
+		return review.Replay(fsrs, current, history)
+	}
+
+	if _, err := reviews.SwitchScheduler(ctx, review.NameFSRS, replay); err != nil {
+		t.Fatalf("switch scheduler: %v", err)
+	}
+
+	if len(replayed) != 1 || replayed[0].Rating != models.RatingHard {
+		t.Fatalf("expected only the review after the reset replayed, got %+v", replayed)
+	}
+
+	var count int
+	var scheduler string
+	if err := db.QueryRow(`SELECT review_count, scheduler FROM concept_retention WHERE concept_id = 'bridge'`).Scan(&count, &scheduler); err != nil {
+		t.Fatalf("query retention: %v", err)
+	}
+
+	if count != 1 || scheduler != review.NameFSRS {
+		t.Fatalf("expected the re-derived state to count 1 review under fsrs, got %d under %s", count, scheduler)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM review_log WHERE concept_id = 'bridge'`); n != 3 {
+		t.Fatalf("expected the review log kept, got %d rows", n)
+	}
+}
+
+func TestResolveConflictKeepingDefinitionLeavesRetention(t *testing.T) {
+	db := setupTestDB(t)
+	first, _ := seedConflict(t, db)
+	repo := repository.NewConceptConflictRepository(db, db)
+
+	res, err := repo.ResolveConflict(context.Background(), "bridge", models.ResolveConflictInput{CandidateIDs: []int64{first}})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if res.RetentionReset {
+		t.Fatal("expected retention kept when the definition is unchanged")
+	}
+
+	var interval int
+	if err := db.QueryRow(`SELECT interval_days FROM concept_retention WHERE concept_id = 'bridge'`).Scan(&interval); err != nil {
+		t.Fatalf("query retention: %v", err)
+	}
+
+	if interval != 12 {
+		t.Fatalf("expected interval 12, got %d", interval)
+	}
+}
+
+func TestResolveConflictMergeAndCustom(t *testing.T) {
+	db := setupTestDB(t)
+	first, second := seedConflict(t, db)
+	repo := repository.NewConceptConflictRepository(db, db)
+
+	res, err := repo.ResolveConflict(context.Background(), "bridge", models.ResolveConflictInput{
+		CandidateIDs:  []int64{first, second},
+		FlashcardBack: "Custom back",
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if res.Definition != "A software switch\n\nA layer 2 forwarding device" || res.FlashcardFront != "F1" || res.FlashcardBack != "Custom back" {
+		t.Fatalf("unexpected merged resolution %+v", res)
+	}
+
+	if res.DefinedInLesson != "l1" {
+		t.Fatalf("expected defining lesson kept for a merge, got %s", res.DefinedInLesson)
+	}
+}
+
+func TestResolveConflictErrors(t *testing.T) {
+	db := setupTestDB(t)
+	seedConflict(t, db)
+	seedConcept(t, db, "other", "Other", "Not in conflict", "t1")
+	mustExec(t, db, `INSERT INTO concept_candidates (id, concept_id, name, definition) VALUES (3, 'other', 'Other', 'Stray')`)
+	repo := repository.NewConceptConflictRepository(db, db)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		concept string
+		input   models.ResolveConflictInput
+		want    error
+	}{
+		{"missing concept", "nope", models.ResolveConflictInput{Definition: "x"}, repository.ErrNotFound},
+		{"not in conflict", "other", models.ResolveConflictInput{Definition: "x"}, repository.ErrNoConflict},
+		{"foreign candidate", "bridge", models.ResolveConflictInput{CandidateIDs: []int64{3}}, repository.ErrNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := repo.ResolveConflict(ctx, tc.concept, tc.input); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	var status string
+	if err := db.QueryRow(`SELECT status FROM concepts WHERE id = 'bridge'`).Scan(&status); err != nil {
+		t.Fatalf("query concept: %v", err)
+	}
+
+	if status != "conflict" {
+		t.Fatalf("expected failed resolution to roll back, got %s", status)
+	}
+}
@@ -8,4 +8,11 @@ var (
 	ErrDuplicate      = errors.New("duplicate entry")
 	ErrFKViolation    = errors.New("foreign key violation")
 	ErrCheckViolation = errors.New("check constraint violation")
+	ErrHasProgress    = errors.New("delete would remove learning progress")
+	ErrStaleRevision  = errors.New("revision does not match")
+	ErrOrderMismatch  = errors.New("order is not a permutation of the current children")
+	ErrArchived       = errors.New("target is archived")
+	ErrNoConflict     = errors.New("concept has no conflict to resolve")
+	ErrSessionClosed  = errors.New("review session is not active")
+	ErrWrongCard      = errors.New("concept is not the session's next card")
 )
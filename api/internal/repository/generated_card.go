@@ -0,0 +1,516 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// generatedCardColumns selects a generated card's models.ReviewCard from
+// generated_cards g joined to concepts c, in the shape of reviewCardColumns.
+const generatedCardColumns = `
+'', g.status, COALESCE(g.next_review, ''), g.review_count,
+g.ease_factor, g.interval_days, COALESCE(g.last_reviewed, ''), COALESCE(g.last_rating, ''),
+COALESCE(g.stability, 0), COALESCE(g.difficulty, 0), g.scheduler,
+g.lapses, '',
+g.front, g.back, g.front, g.back,
+COALESCE(c.defined_in_lesson, ''), g.topic_id,
+g.kind, '', g.id, g.concept_id, ''
+`
+
+// clozeBlank replaces a cloze card's term in the definition.
+const clozeBlank = "[...]"
+
+// minClozeTermRunes keeps very short names and aliases out of cloze cards.
+const minClozeTermRunes = 3
+
+// emphasisPattern matches the **bold**, __bold__, and `code` spans a
+// definition marks as key terms.
+var emphasisPattern = regexp.MustCompile("\\*\\*([^*]+)\\*\\*|__([^_]+)__|`([^`]+)`")
+
+// cardConcept is a concept as card generation sees it.
+type cardConcept struct {
+	id, name, definition, topicTitle string
+	// terms are the concept's own name and aliases.
+	terms    []string
+	enrolled bool
+}
+
+// generatedCard is one card generated from a concept.
+type generatedCard struct {
+	id, kind, front, back string
+}
+
+// generatedCardID derives a generated card's ID from its kind, concept,
+// and, for a cloze card, the term it blanks out. It does not depend on the
+// card's text, so a changed definition updates the card in place and keeps
+// its review state.
+func generatedCardID(kind, conceptID, term string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + conceptID + "\x00" + strings.ToLower(term)))
+
+	return "g-" + hex.EncodeToString(sum[:8])
+}
+
+// generateCards derives the concept's cards of the enabled kinds from its
+// stored name, definition, and topic: its reverse card, which asks for the
+// name given the definition, then a cloze card for each key term in the
+// definition in the order they appear, then its topic card, which asks
+// which topic defines the concept. Key terms are the names and aliases of
+// the topic's other concepts in keyTerms, and the definition's emphasized
+// spans.
+func generateCards(c cardConcept, enabled map[string]bool, keyTerms []string, patterns termPatterns) []generatedCard {
+	definition := strings.TrimSpace(c.definition)
+	var cards []generatedCard
+
+	if enabled[models.CardKindReverse] && definition != "" {
+		cards = append(cards, generatedCard{
+			id:    generatedCardID(models.CardKindReverse, c.id, ""),
+			kind:  models.CardKindReverse,
+			front: definition,
+			back:  c.name,
+		})
+	}
+
+	if enabled[models.CardKindCloze] && definition != "" {
+		cards = append(cards, clozeCards(c, definition, keyTerms, patterns)...)
+	}
+
+	if enabled[models.CardKindTopic] && c.topicTitle != "" {
+		cards = append(cards, generatedCard{
+			id:    generatedCardID(models.CardKindTopic, c.id, ""),
+			kind:  models.CardKindTopic,
+			front: "Which topic defines " + c.name + "?",
+			back:  c.topicTitle,
+		})
+	}
+
+	return cards
+}
+
+// span is a byte range of a definition.
+type span struct{ start, end int }
+
+// clozeCards blanks each key term out of the definition. Longer terms win
+// where terms overlap, so "buffered channel" is one card, not a "channel"
+// card with a word left over; a term with no occurrence left makes none.
+func clozeCards(c cardConcept, definition string, keyTerms []string, patterns termPatterns) []generatedCard {
+	own := make(map[string]bool, len(c.terms))
+	for _, t := range c.terms {
+		own[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+
+	candidates := slices.Clone(keyTerms)
+	for _, m := range emphasisPattern.FindAllStringSubmatch(definition, -1) {
+		candidates = append(candidates, m[1]+m[2]+m[3])
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	terms := candidates[:0]
+
+	for _, t := range candidates {
+		t = strings.TrimSpace(t)
+		key := strings.ToLower(t)
+
+		if utf8.RuneCountInString(t) < minClozeTermRunes || own[key] || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		terms = append(terms, t)
+	}
+
+	slices.SortFunc(terms, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(strings.ToLower(a), strings.ToLower(b)))
+	})
+
+	type cloze struct {
+		term  string
+		spans []span
+	}
+
+	var covered []span
+	var clozes []cloze
+
+	for _, term := range terms {
+		var spans []span
+
+		for _, s := range patterns.spans(definition, term) {
+			if !slices.ContainsFunc(covered, func(o span) bool { return s.start < o.end && o.start < s.end }) {
+				spans = append(spans, s)
+			}
+		}
+
+		if len(spans) == 0 {
+			continue
+		}
+
+		covered = append(covered, spans...)
+		clozes = append(clozes, cloze{term: term, spans: spans})
+	}
+
+	slices.SortFunc(clozes, func(a, b cloze) int { return cmp.Compare(a.spans[0].start, b.spans[0].start) })
+
+	cards := make([]generatedCard, 0, len(clozes))
+
+	for _, cl := range clozes {
+		var front strings.Builder
+		last := 0
+
+		for _, s := range cl.spans {
+			front.WriteString(definition[last:s.start])
+			front.WriteString(clozeBlank)
+			last = s.end
+		}
+
+		front.WriteString(definition[last:])
+
+		first := cl.spans[0]
+		cards = append(cards, generatedCard{
+			id:    generatedCardID(models.CardKindCloze, c.id, cl.term),
+			kind:  models.CardKindCloze,
+			front: front.String(),
+			back:  definition[first.start:first.end],
+		})
+	}
+
+	return cards
+}
+
+// termPatterns holds the compiled pattern of each cloze term, by lowercased
+// term. A topic's concept names are key terms of every other concept's
+// definition, so one sync compiles each only once.
+type termPatterns map[string]*regexp.Regexp
+
+// spans finds the whole-word occurrences of term in text, ignoring case, in
+// order.
+func (p termPatterns) spans(text, term string) []span {
+	key := strings.ToLower(term)
+
+	pattern, ok := p[key]
+	if !ok {
+		pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+		p[key] = pattern
+	}
+
+	var spans []span
+
+	for _, m := range pattern.FindAllStringIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+
+		spans = append(spans, span{m[0], m[1]})
+	}
+
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsNumber(r))
+}
+
+const listTopicCardKindsSQL = `SELECT kind FROM topic_card_kinds WHERE topic_id = ? ORDER BY kind`
+
+// listCardConceptsSQL selects the concepts a topic defines, with the topic
+// title and whether each is in review.
+const listCardConceptsSQL = `
+SELECT c.id, c.name, c.definition, COALESCE(c.aliases, ''), t.title,
+       COALESCE(cr.status, 'new') <> 'new'
+FROM concepts c
+JOIN topics t ON t.id = c.defined_in_topic
+LEFT JOIN concept_retention cr ON cr.concept_id = c.id
+WHERE c.defined_in_topic = ?
+ORDER BY c.id
+`
+
+// upsertGeneratedCardSQL stores a generated card. An existing card keeps
+// its review state and takes the new text.
+const upsertGeneratedCardSQL = `
+INSERT INTO generated_cards (id, kind, concept_id, topic_id, position, front, back, status, next_review)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+  topic_id = excluded.topic_id, position = excluded.position,
+  front = excluded.front, back = excluded.back
+`
+
+// deleteStaleCardsSQL removes the cards of topic ?1 that are not in the
+// JSON array ?2, and the cards of the topic's concepts still filed under
+// the topic they came from, which the sync did not regenerate. Both scans
+// are limited to the topic and its concepts.
+const deleteStaleCardsSQL = `
+DELETE FROM generated_cards
+WHERE (topic_id = ?1 AND id NOT IN (SELECT value FROM json_each(?2)))
+   OR (concept_id IN (SELECT id FROM concepts WHERE defined_in_topic = ?1) AND topic_id <> ?1)
+`
+
+const getConceptTopicSQL = `SELECT COALESCE(defined_in_topic, '') FROM concepts WHERE id = ?`
+
+// conceptTopic returns the topic defining the concept, or "" when it has
+// none or does not exist.
+func conceptTopic(ctx context.Context, q queryer, conceptID string) (string, error) {
+	var topicID string
+
+	err := q.QueryRowContext(ctx, getConceptTopicSQL, conceptID).Scan(&topicID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("load concept %s topic: %w", conceptID, err)
+	}
+
+	return topicID, nil
+}
+
+// syncTopicCards regenerates the generated cards of each topic's concepts.
+// Writers call it whenever a topic or its concepts change. Empty and
+// repeated topic IDs are skipped.
+func syncTopicCards(ctx context.Context, q queryer, topicIDs ...string) error {
+	for i, topicID := range topicIDs {
+		if topicID == "" || slices.Contains(topicIDs[:i], topicID) {
+			continue
+		}
+
+		if err := syncCards(ctx, q, topicID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncCards regenerates one topic's cards and deletes those no longer
+// generated, including every card of a kind the topic disabled. New cards
+// of a concept in review start in review, due a day later, as enrollment
+// would have put them.
+func syncCards(ctx context.Context, q queryer, topicID string) error {
+	kinds, err := queryIDs(ctx, q, listTopicCardKindsSQL, topicID)
+	if err != nil {
+		return err
+	}
+
+	enabled := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		enabled[k] = true
+	}
+
+	var concepts []cardConcept
+	if len(enabled) > 0 {
+		if concepts, err = listCardConcepts(ctx, q, topicID); err != nil {
+			return err
+		}
+	}
+
+	due := time.Now().UTC().AddDate(0, 0, 1).Format(time.RFC3339)
+	ids := []string{}
+	patterns := termPatterns{}
+
+	for i, c := range concepts {
+		var keyTerms []string
+		for j, other := range concepts {
+			if j != i {
+				keyTerms = append(keyTerms, other.terms...)
+			}
+		}
+
+		status, nextReview := models.RetentionStatusNew, ""
+		if c.enrolled {
+			status, nextReview = models.RetentionStatusLearning, due
+		}
+
+		for position, card := range generateCards(c, enabled, keyTerms, patterns) {
+			ids = append(ids, card.id)
+
+			if _, err := q.ExecContext(ctx, upsertGeneratedCardSQL,
+				card.id, card.kind, c.id, topicID, position, card.front, card.back,
+				status, nullIfEmpty(nextReview),
+			); err != nil {
+				return classifyError(err, "store card "+card.id)
+			}
+		}
+	}
+
+	keep, _ := json.Marshal(ids)
+
+	if _, err := q.ExecContext(ctx, deleteStaleCardsSQL, topicID, string(keep)); err != nil {
+		return fmt.Errorf("delete stale topic %s cards: %w", topicID, err)
+	}
+
+	return nil
+}
+
+func listCardConcepts(ctx context.Context, q queryer, topicID string) ([]cardConcept, error) {
+	rows, err := q.QueryContext(ctx, listCardConceptsSQL, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("query topic %s concepts: %w", topicID, err)
+	}
+	defer rows.Close()
+
+	var concepts []cardConcept
+
+	for rows.Next() {
+		var c cardConcept
+		var aliases string
+		if err := rows.Scan(&c.id, &c.name, &c.definition, &aliases, &c.topicTitle, &c.enrolled); err != nil {
+			return nil, fmt.Errorf("scan topic %s concept: %w", topicID, err)
+		}
+
+		c.terms = append([]string{c.name}, models.ParseJSONStringSlice(&aliases)...)
+		concepts = append(concepts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate topic %s concepts: %w", topicID, err)
+	}
+
+	return concepts, nil
+}
+
+// SyncTopicCards regenerates the generated cards of a topic's concepts, for
+// writers outside this package, such as the curriculum ingester, that hold
+// their own transaction.
+func SyncTopicCards(ctx context.Context, tx *sql.Tx, topicID string) error {
+	return syncTopicCards(ctx, tx, topicID)
+}
+
+const listAllTopicsSQL = `SELECT id FROM topics ORDER BY id`
+
+const countGeneratedCardsSQL = `SELECT COUNT(*) FROM generated_cards`
+
+func (r *SQLiteReviewRepository) SyncCards(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	topicIDs, err := queryIDs(ctx, tx, listAllTopicsSQL)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := syncTopicCards(ctx, tx, topicIDs...); err != nil {
+		return 0, err
+	}
+
+	var n int
+	if err := tx.QueryRowContext(ctx, countGeneratedCardsSQL).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count generated cards: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return n, nil
+}
+
+const countTopicCardsSQL = `SELECT COUNT(*) FROM generated_cards WHERE topic_id = ?`
+
+const deleteTopicCardKindsSQL = `DELETE FROM topic_card_kinds WHERE topic_id = ?`
+
+const insertTopicCardKindSQL = `INSERT OR IGNORE INTO topic_card_kinds (topic_id, kind) VALUES (?, ?)`
+
+func (r *SQLiteReviewRepository) GetCardKinds(ctx context.Context, topicID string) (*models.TopicCardKinds, error) {
+	return loadCardKinds(ctx, r.readDB, topicID)
+}
+
+// loadCardKinds returns the topic's enabled kinds and card count, or
+// ErrNotFound.
+func loadCardKinds(ctx context.Context, q queryer, topicID string) (*models.TopicCardKinds, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, topicExistsSQL, topicID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check topic %s: %w", topicID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("topic %s: %w", topicID, ErrNotFound)
+	}
+
+	kinds, err := queryIDs(ctx, q, listTopicCardKindsSQL, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	ck := &models.TopicCardKinds{TopicID: topicID, Kinds: []string{}}
+	ck.Kinds = append(ck.Kinds, kinds...)
+
+	if err := q.QueryRowContext(ctx, countTopicCardsSQL, topicID).Scan(&ck.Cards); err != nil {
+		return nil, fmt.Errorf("count topic %s cards: %w", topicID, err)
+	}
+
+	return ck, nil
+}
+
+func (r *SQLiteReviewRepository) SetCardKinds(ctx context.Context, topicID string, kinds []string) (*models.TopicCardKinds, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, topicExistsSQL, topicID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check topic %s: %w", topicID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("topic %s: %w", topicID, ErrNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteTopicCardKindsSQL, topicID); err != nil {
+		return nil, fmt.Errorf("clear topic %s card kinds: %w", topicID, err)
+	}
+
+	for _, kind := range kinds {
+		if _, err := tx.ExecContext(ctx, insertTopicCardKindSQL, topicID, kind); err != nil {
+			return nil, classifyError(err, "enable "+kind+" cards for topic "+topicID)
+		}
+	}
+
+	if err := syncCards(ctx, tx, topicID); err != nil {
+		return nil, err
+	}
+
+	ck, err := loadCardKinds(ctx, tx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return ck, nil
+}
+
+func (r *SQLiteReviewRepository) RecordCardReview(ctx context.Context, cardID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.CardAnswer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	state, err := generatedStore.review(ctx, tx, cardID, responseMS, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return &models.CardAnswer{CardID: cardID, State: state}, nil
+}
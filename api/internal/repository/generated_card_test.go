@@ -0,0 +1,273 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// seedCardTree writes three concepts of topic t1, defined in lesson l1,
+// through the write repository. c2's definition names c3 and c1 (covered by
+// c3's longer name) and marks `select`; c3's names c1.
+func seedCardTree(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	ctx := context.Background()
+	w := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Go", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Concurrency", 1)
+	seedLesson(t, db, "l1", "m1", "Goroutines", 1)
+
+	for _, c := range []models.ConceptInput{
+		{ID: "c1", Name: "Channel", Definition: "A typed pipe that goroutines use to send values."},
+		{ID: "c2", Name: "Goroutine", Definition: "A lightweight thread that sends values over a buffered channel or a `select` block.", Aliases: []string{"green thread"}},
+		{ID: "c3", Name: "Buffered channel", Definition: "A channel with a fixed capacity."},
+	} {
+		c.DefinedInLesson, c.DefinedInTopic = "l1", "t1"
+
+		if _, err := w.CreateConcept(ctx, c); err != nil {
+			t.Fatalf("CreateConcept(%s) error = %v", c.ID, err)
+		}
+	}
+}
+
+// generatedCard returns the front, back, and review count of the concept's
+// card of the given kind whose back is back, or the only one when back is
+// empty.
+func generatedCard(t *testing.T, db *sql.DB, conceptID, kind, back string) (id, front string, reviews int) {
+	t.Helper()
+
+	err := db.QueryRow(`SELECT id, front, review_count FROM generated_cards
+		WHERE concept_id = ? AND kind = ? AND (? = '' OR back = ?)`, conceptID, kind, back, back).Scan(&id, &front, &reviews)
+	if err != nil {
+		t.Fatalf("%s card of %s backed %q: %v", kind, conceptID, back, err)
+	}
+
+	return id, front, reviews
+}
+
+func TestGeneratedCardsSyncAndReview(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedCardTree(t, db)
+
+	repo := repository.NewReviewRepository(db, db)
+
+	kinds, err := repo.GetCardKinds(ctx, "t1")
+	if err != nil || len(kinds.Kinds) != 0 || kinds.Cards != 0 {
+		t.Fatalf("GetCardKinds() = %+v, %v; want none enabled", kinds, err)
+	}
+
+	kinds, err = repo.SetCardKinds(ctx, "t1", models.GeneratedCardKinds)
+	if err != nil {
+		t.Fatalf("SetCardKinds() error = %v", err)
+	}
+
+	// Three reverse, three topic, and three cloze cards: two for c2, one
+	// for c3.
+	if len(kinds.Kinds) != 3 || kinds.Cards != 9 {
+		t.Fatalf("SetCardKinds() = %+v, want 3 kinds and 9 cards", kinds)
+	}
+
+	_, front, _ := generatedCard(t, db, "c2", models.CardKindReverse, "Goroutine")
+	if front != "A lightweight thread that sends values over a buffered channel or a `select` block." {
+		t.Fatalf("reverse card front = %q", front)
+	}
+
+	clozeID, front, _ := generatedCard(t, db, "c2", models.CardKindCloze, "buffered channel")
+	if front != "A lightweight thread that sends values over a [...] or a `select` block." {
+		t.Fatalf("cloze card front = %q", front)
+	}
+
+	generatedCard(t, db, "c2", models.CardKindCloze, "select")
+	generatedCard(t, db, "c3", models.CardKindCloze, "channel")
+
+	if _, front, _ = generatedCard(t, db, "c1", models.CardKindTopic, "Go"); front != "Which topic defines Channel?" {
+		t.Fatalf("topic card front = %q", front)
+	}
+
+	progress, err := repository.NewProgressRepository(db, db).UpdateLessonProgress(ctx, "l1", models.UpdateProgressInput{Status: models.ProgressStatusCompleted})
+	if err != nil {
+		t.Fatalf("UpdateLessonProgress() error = %v", err)
+	}
+
+	if progress.Enrolled != 3 || progress.EnrolledCards != 9 {
+		t.Fatalf("UpdateLessonProgress() = %+v, want 3 concepts and 9 cards enrolled", progress)
+	}
+
+	due, err := repo.ListDue(ctx, time.Now().UTC().AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("ListDue() error = %v", err)
+	}
+
+	var cloze *models.ReviewCard
+	for i, c := range due {
+		if c.CardID == clozeID {
+			cloze = &due[i]
+		}
+	}
+
+	if len(due) != 12 || cloze == nil || cloze.Kind != models.CardKindCloze || cloze.ConceptID != "" ||
+		cloze.FlashcardBack != "buffered channel" || cloze.LessonID != "l1" || cloze.TopicID != "t1" ||
+		len(cloze.ConceptIDs) != 1 || cloze.ConceptIDs[0] != "c2" {
+		t.Fatalf("ListDue() = %d cards, cloze card %+v", len(due), cloze)
+	}
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	now := time.Now().UTC()
+
+	answer, err := repo.RecordCardReview(ctx, clozeID, 900, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+		return sm2.Review(current, models.RatingForgot, now)
+	})
+	if err != nil {
+		t.Fatalf("RecordCardReview() error = %v", err)
+	}
+
+	if answer.CardID != clozeID || answer.State.ReviewCount != 1 || answer.State.Lapses != 1 || answer.State.ConceptID != "" {
+		t.Fatalf("RecordCardReview() = %+v, want one lapsed review", answer)
+	}
+
+	var logged, conceptReviews int
+	if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM generated_card_review_log WHERE card_id = ? AND response_ms = 900),
+		(SELECT COUNT(*) FROM review_log)`, clozeID).Scan(&logged, &conceptReviews); err != nil {
+		t.Fatalf("count review logs: %v", err)
+	}
+
+	if logged != 1 || conceptReviews != 0 {
+		t.Fatalf("logged %d card and %d concept reviews, want 1 and 0", logged, conceptReviews)
+	}
+
+	if _, err := repo.RecordCardReview(ctx, "g-missing", 0, nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("RecordCardReview(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Editing the definition updates the kept cloze card in place and drops
+	// the one whose term is gone.
+	if _, err := repository.NewWriteRepository(db).UpdateConcept(ctx, "c2", 0, models.ConceptInput{
+		Name: "Goroutine", Definition: "A lightweight thread that talks over a buffered channel.",
+		DefinedInLesson: "l1", DefinedInTopic: "t1",
+	}); err != nil {
+		t.Fatalf("UpdateConcept() error = %v", err)
+	}
+
+	id, front, reviews := generatedCard(t, db, "c2", models.CardKindCloze, "")
+	if id != clozeID || reviews != 1 || front != "A lightweight thread that talks over a [...]." {
+		t.Fatalf("cloze card = %s %q with %d reviews, want %s updated in place", id, front, reviews, clozeID)
+	}
+
+	if kinds, err = repo.SetCardKinds(ctx, "t1", []string{models.CardKindReverse}); err != nil || kinds.Cards != 3 {
+		t.Fatalf("SetCardKinds(reverse) = %+v, %v; want 3 cards", kinds, err)
+	}
+
+	if _, err := repo.SetCardKinds(ctx, "missing", nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("SetCardKinds(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGeneratedCardsFollowConcepts(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedCardTree(t, db)
+	seedTopic(t, db, "t2", "Rust", "foundational", "published")
+
+	repo := repository.NewReviewRepository(db, db)
+	w := repository.NewWriteRepository(db)
+
+	if _, err := repo.SetCardKinds(ctx, "t1", []string{models.CardKindCloze, models.CardKindTopic}); err != nil {
+		t.Fatalf("SetCardKinds() error = %v", err)
+	}
+
+	// Deleting c3 takes its cards and the cloze card its name made in c2.
+	if err := w.DeleteConcept(ctx, "c3", true); err != nil {
+		t.Fatalf("DeleteConcept() error = %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM generated_cards WHERE kind = 'cloze' AND back = 'buffered channel'`).Scan(&count); err != nil {
+		t.Fatalf("count cloze cards: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatalf("%d cloze cards for the deleted concept's name, want 0", count)
+	}
+
+	// Moving c1 to a topic without generated cards drops its cards.
+	if _, err := w.UpdateConcept(ctx, "c1", 0, models.ConceptInput{
+		Name: "Channel", Definition: "A typed pipe.", DefinedInTopic: "t2",
+	}); err != nil {
+		t.Fatalf("UpdateConcept() error = %v", err)
+	}
+
+	if err := db.QueryRow(`SELECT COUNT(*) FROM generated_cards WHERE concept_id = 'c1'`).Scan(&count); err != nil {
+		t.Fatalf("count c1 cards: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatalf("c1 has %d cards after moving topics, want 0", count)
+	}
+
+	// Renaming the topic rewrites its topic cards.
+	if _, err := w.UpdateTopic(ctx, "t1", 0, models.TopicInput{Title: "Golang", Status: "published"}); err != nil {
+		t.Fatalf("UpdateTopic() error = %v", err)
+	}
+
+	generatedCard(t, db, "c2", models.CardKindTopic, "Golang")
+
+	n, err := repo.SyncCards(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("SyncCards() = %d, %v; want c2's cloze and topic cards", n, err)
+	}
+}
+
+func TestGeneratedCardSession(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedCardTree(t, db)
+
+	repo := repository.NewReviewRepository(db, db)
+
+	if _, err := repo.SetCardKinds(ctx, "t1", []string{models.CardKindReverse}); err != nil {
+		t.Fatalf("SetCardKinds() error = %v", err)
+	}
+
+	mustExec(t, db, `UPDATE generated_cards SET status = 'learning', next_review = '2026-01-01T09:00:00Z' WHERE concept_id = 'c1'`)
+
+	now := time.Now().UTC()
+
+	session, err := repo.CreateSession(ctx, review.DueCutoff(now), models.SessionOptions{NewLimit: 5, ReviewLimit: 5},
+		func(reviews, newCards []models.ReviewCard) []models.ReviewCard { return append(newCards, reviews...) })
+	if err != nil || session.Total != 1 {
+		t.Fatalf("CreateSession() = %+v, %v; want c1's reverse card", session, err)
+	}
+
+	card, err := repo.NextSessionCard(ctx, session.ID)
+	if err != nil || card == nil || card.Kind != models.CardKindReverse || card.CardID == "" || card.FlashcardBack != "Channel" {
+		t.Fatalf("NextSessionCard() = %+v, %v; want the reverse card", card, err)
+	}
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+
+	if _, err := repo.AnswerSessionCard(ctx, session.ID, "c1", 0, nil); !errors.Is(err, repository.ErrWrongCard) {
+		t.Fatalf("AnswerSessionCard(concept) error = %v, want ErrWrongCard", err)
+	}
+
+	answer, err := repo.AnswerSessionCard(ctx, session.ID, card.CardID, 0, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+		return sm2.Review(current, models.RatingForgot, now)
+	})
+	if err != nil || answer.CardID != card.CardID || answer.Summary == nil {
+		t.Fatalf("AnswerSessionCard() = %+v, %v", answer, err)
+	}
+
+	summary := answer.Summary
+	if summary.Reviewed != 1 || summary.Cards[0].ConceptID != "c1" || summary.Cards[0].CardID != card.CardID ||
+		len(summary.Restudy) != 1 || summary.Restudy[0].LessonID != "l1" {
+		t.Fatalf("session summary = %+v, want the forgotten card linked to l1", summary)
+	}
+}
@@ -0,0 +1,380 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// LeechRepository manages leeches: concepts a review suspended after too
+// many lapses. A leech returns to the review queue when unsuspended or when
+// a rewritten card is approved. Rewrites come from flashcard research jobs,
+// which the research orchestrator runs like any other job and which store
+// their card as a proposal through ProposeCard.
+type LeechRepository interface {
+	// ListLeeches returns the suspended concepts, most lapses first.
+	ListLeeches(ctx context.Context) ([]models.Leech, error)
+	// GetLeech returns a suspended concept, or ErrNotFound.
+	GetLeech(ctx context.Context, conceptID string) (*models.Leech, error)
+	// Unsuspend returns a leech to the queue, due at once with its lapses
+	// cleared.
+	Unsuspend(ctx context.Context, conceptID string) (*models.ConceptRetention, error)
+	// RegenerateCard queues a flashcard research job for a leech. It
+	// returns ErrDuplicate while an earlier job is unfinished or its
+	// proposal awaits a decision.
+	RegenerateCard(ctx context.Context, conceptID string) (*models.ResearchJob, error)
+	// ProposeCard stores the card jobID wrote for the concept.
+	ProposeCard(ctx context.Context, conceptID, jobID string, card models.CardProposal) (*models.CardProposal, error)
+	// ApproveProposal replaces the concept's definition and flashcard with
+	// its pending proposal and restarts its review, unsuspended.
+	ApproveProposal(ctx context.Context, conceptID string) (*models.CardProposal, error)
+	// RejectProposal discards the concept's pending proposal.
+	RejectProposal(ctx context.Context, conceptID string) (*models.CardProposal, error)
+}
+
+// SQLiteLeechRepository implements LeechRepository using SQLite. Reads use
+// the read pool; writes use the write handle.
+type SQLiteLeechRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewLeechRepository creates a new SQLiteLeechRepository.
+func NewLeechRepository(readDB, writeDB *sql.DB) *SQLiteLeechRepository {
+	return &SQLiteLeechRepository{readDB: readDB, db: writeDB}
+}
+
+// proposalColumns selects a models.CardProposal from card_proposals p.
+const proposalColumns = `
+p.id, p.concept_id, COALESCE(p.job_id, ''), p.status, p.definition,
+p.flashcard_front, p.flashcard_back, p.created_at, COALESCE(p.resolved_at, '')
+`
+
+// leechSQL selects suspended concepts with their defining lesson, latest
+// flashcard job, and pending proposal.
+const leechSQL = `
+SELECT c.id, c.name, c.definition, COALESCE(c.flashcard_front, ''), COALESCE(c.flashcard_back, ''),
+       cr.lapses, cr.review_count, cr.suspended_at,
+       COALESCE(c.defined_in_lesson, ''), COALESCE(l.title, ''),
+       COALESCE(c.defined_in_topic, ''), COALESCE(t.title, ''),
+       COALESCE(j.id, ''), COALESCE(j.status, ''),
+       p.id, COALESCE(p.job_id, ''), COALESCE(p.definition, ''),
+       COALESCE(p.flashcard_front, ''), COALESCE(p.flashcard_back, ''), COALESCE(p.created_at, '')
+FROM concept_retention cr
+JOIN concepts c ON c.id = cr.concept_id
+LEFT JOIN lessons l ON l.id = c.defined_in_lesson
+LEFT JOIN topics t ON t.id = c.defined_in_topic
+LEFT JOIN research_jobs j ON j.rowid = (
+  SELECT MAX(rowid) FROM research_jobs WHERE kind = 'flashcard' AND concept_id = c.id
+)
+LEFT JOIN card_proposals p ON p.id = (
+  SELECT MAX(id) FROM card_proposals WHERE concept_id = c.id AND status = 'proposed'
+)
+WHERE cr.suspended_at IS NOT NULL
+`
+
+const listLeechesSQL = leechSQL + `ORDER BY cr.lapses DESC, julianday(cr.suspended_at), c.id`
+
+const getLeechSQL = leechSQL + `AND c.id = ?`
+
+func (r *SQLiteLeechRepository) ListLeeches(ctx context.Context) ([]models.Leech, error) {
+	rows, err := r.readDB.QueryContext(ctx, listLeechesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query leeches: %w", err)
+	}
+	defer rows.Close()
+
+	leeches := []models.Leech{}
+
+	for rows.Next() {
+		var l models.Leech
+		if err := scanLeech(rows, &l); err != nil {
+			return nil, fmt.Errorf("scan leech: %w", err)
+		}
+
+		leeches = append(leeches, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate leeches: %w", err)
+	}
+
+	return leeches, nil
+}
+
+func (r *SQLiteLeechRepository) GetLeech(ctx context.Context, conceptID string) (*models.Leech, error) {
+	return getLeech(ctx, r.readDB, conceptID)
+}
+
+func getLeech(ctx context.Context, q queryer, conceptID string) (*models.Leech, error) {
+	l := &models.Leech{}
+
+	err := scanLeech(q.QueryRowContext(ctx, getLeechSQL, conceptID), l)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("leech %s: %w", conceptID, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get leech %s: %w", conceptID, err)
+	}
+
+	return l, nil
+}
+
+func scanLeech(row rowScanner, l *models.Leech) error {
+	var proposalID sql.NullInt64
+	var p models.CardProposal
+
+	if err := row.Scan(
+		&l.ConceptID, &l.Name, &l.Definition, &l.FlashcardFront, &l.FlashcardBack,
+		&l.Lapses, &l.ReviewCount, &l.SuspendedAt,
+		&l.LessonID, &l.LessonTitle, &l.TopicID, &l.TopicTitle,
+		&l.JobID, &l.JobStatus,
+		&proposalID, &p.JobID, &p.Definition, &p.FlashcardFront, &p.FlashcardBack, &p.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	if proposalID.Valid {
+		p.ID = proposalID.Int64
+		p.ConceptID = l.ConceptID
+		p.Status = models.ProposalStatusProposed
+		l.Proposal = &p
+	}
+
+	return nil
+}
+
+const unsuspendSQL = `
+UPDATE concept_retention SET suspended_at = NULL, lapses = 0, next_review = ?
+WHERE concept_id = ? AND suspended_at IS NOT NULL
+`
+
+func (r *SQLiteLeechRepository) Unsuspend(ctx context.Context, conceptID string) (*models.ConceptRetention, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, unsuspendSQL, time.Now().UTC().Format(time.RFC3339), conceptID)
+	if err != nil {
+		return nil, fmt.Errorf("unsuspend concept %s: %w", conceptID, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("unsuspend concept %s rows affected: %w", conceptID, err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("leech %s: %w", conceptID, ErrNotFound)
+	}
+
+	s := &models.ConceptRetention{}
+	if err := scanRetention(tx.QueryRowContext(ctx, getRetentionSQL, conceptID), s); err != nil {
+		return nil, fmt.Errorf("load concept %s retention: %w", conceptID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return s, nil
+}
+
+const createFlashcardJobSQL = `
+INSERT INTO research_jobs (id, kind, concept_id, root_topic, current_topic, status)
+VALUES (?, 'flashcard', ?, ?, ?, ?)
+`
+
+func (r *SQLiteLeechRepository) RegenerateCard(ctx context.Context, conceptID string) (*models.ResearchJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	leech, err := getLeech(ctx, tx, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if leech.JobID != "" && !models.IsTerminalStatus(leech.JobStatus) {
+		return nil, fmt.Errorf("leech %s: research job %s unfinished: %w", conceptID, leech.JobID, ErrDuplicate)
+	}
+
+	if leech.Proposal != nil {
+		return nil, fmt.Errorf("leech %s: card proposal %d pending: %w", conceptID, leech.Proposal.ID, ErrDuplicate)
+	}
+
+	job := &models.ResearchJob{
+		ID:           uuid.New().String(),
+		Kind:         models.ResearchKindFlashcard,
+		ConceptID:    conceptID,
+		RootTopic:    leech.Name,
+		CurrentTopic: leech.Name,
+		Status:       models.ResearchStatusQueued,
+	}
+
+	if _, err := tx.ExecContext(ctx, createFlashcardJobSQL,
+		job.ID, conceptID, job.RootTopic, job.CurrentTopic, job.Status,
+	); err != nil {
+		return nil, classifyError(err, "create flashcard job for concept "+conceptID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return job, nil
+}
+
+const insertProposalSQL = `
+INSERT INTO card_proposals (concept_id, job_id, definition, flashcard_front, flashcard_back, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+const getProposalSQL = `SELECT ` + proposalColumns + ` FROM card_proposals p WHERE p.id = ?`
+
+func (r *SQLiteLeechRepository) ProposeCard(ctx context.Context, conceptID, jobID string, card models.CardProposal) (*models.CardProposal, error) {
+	result, err := r.db.ExecContext(ctx, insertProposalSQL,
+		conceptID, nullIfEmpty(jobID), card.Definition, card.FlashcardFront, card.FlashcardBack,
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, classifyError(err, "propose card for concept "+conceptID)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("card proposal id: %w", err)
+	}
+
+	return getProposal(ctx, r.db, id)
+}
+
+func getProposal(ctx context.Context, q queryer, id int64) (*models.CardProposal, error) {
+	p := &models.CardProposal{}
+
+	if err := q.QueryRowContext(ctx, getProposalSQL, id).Scan(
+		&p.ID, &p.ConceptID, &p.JobID, &p.Status, &p.Definition,
+		&p.FlashcardFront, &p.FlashcardBack, &p.CreatedAt, &p.ResolvedAt,
+	); err != nil {
+		return nil, fmt.Errorf("get card proposal %d: %w", id, err)
+	}
+
+	return p, nil
+}
+
+const getPendingProposalSQL = `
+SELECT MAX(id) FROM card_proposals WHERE concept_id = ? AND status = 'proposed'
+`
+
+const resolveProposalSQL = `UPDATE card_proposals SET status = ?, resolved_at = ? WHERE id = ?`
+
+const updateConceptCardSQL = `
+UPDATE concepts SET definition = ?, flashcard_front = ?, flashcard_back = ?, revision = revision + 1
+WHERE id = ?
+`
+
+const getConceptNameSQL = `SELECT name FROM concepts WHERE id = ?`
+
+func (r *SQLiteLeechRepository) ApproveProposal(ctx context.Context, conceptID string) (*models.CardProposal, error) {
+	return r.resolveProposal(ctx, conceptID, models.ProposalStatusApproved, func(tx *sql.Tx, p *models.CardProposal, now string) error {
+		var name string
+		if err := tx.QueryRowContext(ctx, getConceptNameSQL, conceptID).Scan(&name); err != nil {
+			return fmt.Errorf("load concept %s: %w", conceptID, err)
+		}
+
+		err := trackChange(ctx, tx, models.AuditEntityConcept, conceptID, func() error {
+			if _, err := tx.ExecContext(ctx, updateConceptCardSQL,
+				p.Definition, p.FlashcardFront, p.FlashcardBack, conceptID,
+			); err != nil {
+				return classifyError(err, "update concept "+conceptID+" card")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := upsertSearchIndex(ctx, tx, "concept", conceptID, name, p.Definition); err != nil {
+			return err
+		}
+
+		topicID, err := conceptTopic(ctx, tx, conceptID)
+		if err != nil {
+			return err
+		}
+
+		if err := syncTopicCards(ctx, tx, topicID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, resetRetentionSQL, now, conceptID); err != nil {
+			return fmt.Errorf("reset concept %s retention: %w", conceptID, err)
+		}
+
+		return nil
+	})
+}
+
+func (r *SQLiteLeechRepository) RejectProposal(ctx context.Context, conceptID string) (*models.CardProposal, error) {
+	return r.resolveProposal(ctx, conceptID, models.ProposalStatusRejected, nil)
+}
+
+// resolveProposal settles the concept's pending proposal with status,
+// running apply first when given, in one transaction.
+func (r *SQLiteLeechRepository) resolveProposal(ctx context.Context, conceptID, status string, apply func(tx *sql.Tx, p *models.CardProposal, now string) error) (*models.CardProposal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id sql.NullInt64
+	if err := tx.QueryRowContext(ctx, getPendingProposalSQL, conceptID).Scan(&id); err != nil {
+		return nil, fmt.Errorf("find concept %s card proposal: %w", conceptID, err)
+	}
+
+	if !id.Valid {
+		return nil, fmt.Errorf("card proposal for concept %s: %w", conceptID, ErrNotFound)
+	}
+
+	p, err := getProposal(ctx, tx, id.Int64)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if apply != nil {
+		if err := apply(tx, p, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, resolveProposalSQL, status, now, p.ID); err != nil {
+		return nil, fmt.Errorf("resolve card proposal %d: %w", p.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	p.Status = status
+	p.ResolvedAt = now
+
+	return p, nil
+}
+
+// Verify interface compliance at compile time.
+var _ LeechRepository = (*SQLiteLeechRepository)(nil)
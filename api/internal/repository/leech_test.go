@@ -0,0 +1,204 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestReviewSuspendsLeech(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Go", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Concurrency", 1)
+	seedLesson(t, db, "l1", "m1", "Channels", 1)
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	seedConcept(t, db, "c2", "Select", "Waits on channels", "t1")
+	mustExec(t, db, `UPDATE concepts SET defined_in_lesson = 'l1' WHERE id = 'c1'`)
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	forget := func(repo *repository.SQLiteReviewRepository, conceptID string) *models.ConceptRetention {
+		t.Helper()
+
+		state, err := repo.RecordReview(ctx, conceptID, 0, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, models.RatingForgot, now)
+		})
+		if err != nil {
+			t.Fatalf("RecordReview(%s) error = %v", conceptID, err)
+		}
+
+		return state
+	}
+
+	repo := repository.NewReviewRepository(db, db).WithLeechThreshold(2)
+
+	if state := forget(repo, "c1"); state.Lapses != 1 || state.SuspendedAt != "" {
+		t.Fatalf("first lapse = %+v, want 1 lapse and not suspended", state)
+	}
+
+	if state := forget(repo, "c1"); state.Lapses != 2 || state.SuspendedAt != now.Format(time.RFC3339) {
+		t.Fatalf("second lapse = %+v, want suspended at %s", state, now.Format(time.RFC3339))
+	}
+
+	// With the threshold off, lapses still count but never suspend.
+	unlimited := repository.NewReviewRepository(db, db)
+	for range 3 {
+		forget(unlimited, "c2")
+	}
+
+	cards, err := repo.ListDue(ctx, now.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("ListDue() error = %v", err)
+	}
+
+	if len(cards) != 1 || cards[0].ConceptID != "c2" || cards[0].Lapses != 3 {
+		t.Fatalf("ListDue() = %+v, want only c2 with 3 lapses", cards)
+	}
+
+	stats, err := repo.GetStats(ctx, now.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+
+	if stats.DueToday != 1 || stats.Suspended != 1 {
+		t.Fatalf("GetStats() = %+v, want 1 due and 1 suspended", stats)
+	}
+
+	leeches := repository.NewLeechRepository(db, db)
+
+	list, err := leeches.ListLeeches(ctx)
+	if err != nil {
+		t.Fatalf("ListLeeches() error = %v", err)
+	}
+
+	if len(list) != 1 || list[0].ConceptID != "c1" || list[0].Lapses != 2 ||
+		list[0].LessonID != "l1" || list[0].LessonTitle != "Channels" || list[0].TopicTitle != "Go" {
+		t.Fatalf("ListLeeches() = %+v", list)
+	}
+
+	if _, err := leeches.Unsuspend(ctx, "c2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Unsuspend(not a leech) error = %v, want ErrNotFound", err)
+	}
+
+	state, err := leeches.Unsuspend(ctx, "c1")
+	if err != nil {
+		t.Fatalf("Unsuspend() error = %v", err)
+	}
+
+	if state.SuspendedAt != "" || state.Lapses != 0 || state.NextReview == "" {
+		t.Fatalf("Unsuspend() = %+v, want due and cleared", state)
+	}
+
+	if list, _ := leeches.ListLeeches(ctx); len(list) != 0 {
+		t.Fatalf("ListLeeches() after unsuspend = %+v, want none", list)
+	}
+}
+
+func TestLeechCardProposals(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	repo := repository.NewLeechRepository(db, db)
+
+	seedTopic(t, db, "t1", "Go", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, review_count, ease_factor, lapses, suspended_at)
+		VALUES ('c1', 'learning', '2026-03-11T09:00:00Z', 10, 1.3, 8, '2026-03-10T09:00:00Z')`)
+
+	if _, err := repo.RegenerateCard(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("RegenerateCard(missing) error = %v, want ErrNotFound", err)
+	}
+
+	job, err := repo.RegenerateCard(ctx, "c1")
+	if err != nil {
+		t.Fatalf("RegenerateCard() error = %v", err)
+	}
+
+	if job.Kind != models.ResearchKindFlashcard || job.ConceptID != "c1" || job.Status != models.ResearchStatusQueued {
+		t.Fatalf("RegenerateCard() = %+v", job)
+	}
+
+	if _, err := repo.RegenerateCard(ctx, "c1"); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("RegenerateCard(job queued) error = %v, want ErrDuplicate", err)
+	}
+
+	if _, err := repo.ApproveProposal(ctx, "c1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("ApproveProposal(none) error = %v, want ErrNotFound", err)
+	}
+
+	mustExec(t, db, `UPDATE research_jobs SET status = 'published' WHERE id = ?`, job.ID)
+
+	card := models.CardProposal{Definition: "A typed conduit between goroutines", FlashcardFront: "What does a channel carry?", FlashcardBack: "Values of one type"}
+	for range 2 {
+		if _, err := repo.ProposeCard(ctx, "c1", job.ID, card); err != nil {
+			t.Fatalf("ProposeCard() error = %v", err)
+		}
+	}
+
+	if _, err := repo.RegenerateCard(ctx, "c1"); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("RegenerateCard(proposal pending) error = %v, want ErrDuplicate", err)
+	}
+
+	leech, err := repo.GetLeech(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetLeech() error = %v", err)
+	}
+
+	if leech.JobID != job.ID || leech.JobStatus != models.ResearchStatusPublished || leech.Proposal == nil || leech.Proposal.ID != 2 {
+		t.Fatalf("GetLeech() = %+v, want latest job and proposal", leech)
+	}
+
+	rejected, err := repo.RejectProposal(ctx, "c1")
+	if err != nil || rejected.Status != models.ProposalStatusRejected || rejected.ID != 2 {
+		t.Fatalf("RejectProposal() = %+v, %v", rejected, err)
+	}
+
+	approved, err := repo.ApproveProposal(ctx, "c1")
+	if err != nil {
+		t.Fatalf("ApproveProposal() error = %v", err)
+	}
+
+	if approved.ID != 1 || approved.Status != models.ProposalStatusApproved || approved.ResolvedAt == "" {
+		t.Fatalf("ApproveProposal() = %+v", approved)
+	}
+
+	var definition, front string
+	var revision int
+	if err := db.QueryRow(`SELECT definition, flashcard_front, revision FROM concepts WHERE id = 'c1'`).Scan(&definition, &front, &revision); err != nil {
+		t.Fatalf("query concept: %v", err)
+	}
+
+	if definition != card.Definition || front != card.FlashcardFront || revision != 2 {
+		t.Fatalf("concept = %q %q rev %d, want the proposed card at revision 2", definition, front, revision)
+	}
+
+	var changes int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE entity_type = 'concept' AND entity_id = 'c1'`).Scan(&changes); err != nil {
+		t.Fatalf("query audit log: %v", err)
+	}
+
+	if changes != 1 {
+		t.Fatalf("audit log has %d concept changes, want 1", changes)
+	}
+
+	var lapses, count int
+	var suspended, resetAt *string
+	if err := db.QueryRow(`SELECT lapses, review_count, suspended_at, reset_at FROM concept_retention WHERE concept_id = 'c1'`).Scan(&lapses, &count, &suspended, &resetAt); err != nil {
+		t.Fatalf("query retention: %v", err)
+	}
+
+	if lapses != 0 || count != 0 || suspended != nil || resetAt == nil {
+		t.Fatalf("retention = %d lapses, %d reviews, suspended %v, reset %v; want a fresh start", lapses, count, suspended, resetAt)
+	}
+
+	if _, err := repo.GetLeech(ctx, "c1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetLeech() after approval error = %v, want ErrNotFound", err)
+	}
+}
@@ -26,7 +26,8 @@ func NewLessonRepository(db *sql.DB) *SQLiteLessonRepository {
 
 const getLessonSQL = `
 SELECT id, module_id, title, sort_order, COALESCE(estimated_minutes, 0),
-       content, examples, exercises, review_questions
+       content, examples, exercises, review_questions, revision,
+       COALESCE(archived_at, '')
 FROM lessons
 WHERE id = ?
 `
@@ -37,7 +38,7 @@ func (r *SQLiteLessonRepository) GetLessonByID(ctx context.Context, id string) (
 
 	err := r.db.QueryRowContext(ctx, getLessonSQL, id).Scan(
 		&ld.ID, &ld.ModuleID, &ld.Title, &ld.SortOrder, &ld.EstimatedMinutes,
-		&contentRaw, &examplesRaw, &exercisesRaw, &reviewRaw,
+		&contentRaw, &examplesRaw, &exercisesRaw, &reviewRaw, &ld.Revision, &ld.ArchivedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MaintenanceRepository defines database housekeeping used by scheduled tasks.
+type MaintenanceRepository interface {
+	OptimizeSearchIndex(ctx context.Context) error
+	BackupTo(ctx context.Context, path string) error
+	CountDueReviews(ctx context.Context, asOf time.Time) (int, error)
+}
+
+// SQLiteMaintenanceRepository implements MaintenanceRepository using SQLite.
+// Reads use the read pool; optimize and backup run on the write handle.
+type SQLiteMaintenanceRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewMaintenanceRepository creates a new SQLiteMaintenanceRepository.
+func NewMaintenanceRepository(readDB, writeDB *sql.DB) *SQLiteMaintenanceRepository {
+	return &SQLiteMaintenanceRepository{readDB: readDB, db: writeDB}
+}
+
+const optimizeSearchIndexSQL = `INSERT INTO search_index (search_index) VALUES ('optimize')`
+
+// OptimizeSearchIndex merges the FTS5 index segments into one.
+func (r *SQLiteMaintenanceRepository) OptimizeSearchIndex(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, optimizeSearchIndexSQL); err != nil {
+		return fmt.Errorf("optimize search index: %w", err)
+	}
+
+	return nil
+}
+
+const backupSQL = `VACUUM INTO ?`
+
+// BackupTo writes a consistent, compacted copy of the database to path,
+// which must not exist yet.
+func (r *SQLiteMaintenanceRepository) BackupTo(ctx context.Context, path string) error {
+	if _, err := r.db.ExecContext(ctx, backupSQL, path); err != nil {
+		return fmt.Errorf("backup database to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+const countDueReviewsSQL = `
+SELECT
+  (SELECT COUNT(*) FROM concept_retention
+   WHERE status IN ('learning', 'reviewing') AND suspended_at IS NULL
+     AND next_review IS NOT NULL AND julianday(next_review) <= julianday(?1))
+  +
+  (SELECT COUNT(*) FROM question_cards
+   WHERE status IN ('learning', 'reviewing')
+     AND next_review IS NOT NULL AND julianday(next_review) <= julianday(?1))
+  +
+  (SELECT COUNT(*) FROM generated_cards
+   WHERE status IN ('learning', 'reviewing')
+     AND next_review IS NOT NULL AND julianday(next_review) <= julianday(?1))
+`
+
+// CountDueReviews returns how many concept, question, and generated cards
+// are due for review at asOf. Like the review queue, it ignores new, mastered and
+// suspended cards.
+func (r *SQLiteMaintenanceRepository) CountDueReviews(ctx context.Context, asOf time.Time) (int, error) {
+	var count int
+	if err := r.readDB.QueryRowContext(ctx, countDueReviewsSQL, asOf.UTC().Format(time.RFC3339)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count due reviews: %w", err)
+	}
+
+	return count, nil
+}
+
+// Verify interface compliance at compile time.
+var _ MaintenanceRepository = (*SQLiteMaintenanceRepository)(nil)
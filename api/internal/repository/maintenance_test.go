@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func TestCountDueReviews(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewMaintenanceRepository(db, db)
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	for _, id := range []string{"c1", "c2", "c3", "c4", "c5", "c6"} {
+		seedConcept(t, db, id, id, "def", "t1")
+	}
+
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c1', 'learning', '2026-03-01')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c2', 'reviewing', '2026-03-10T06:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c3', 'reviewing', '2026-03-11T00:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id) VALUES ('c4')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c5', 'mastered', '2026-03-01')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c6', 'new', '2026-03-01')`)
+
+	seedModule(t, db, "m1", "t1", "Module", 1)
+	mustExec(t, db, `INSERT INTO question_cards (id, source, module_id, position, question, answer, status, next_review)
+		VALUES ('q1', 'assessment', 'm1', 1, 'Q?', 'A', 'reviewing', '2026-03-09'),
+		       ('q2', 'assessment', 'm1', 2, 'Q2?', 'A', 'new', '2026-03-09')`)
+	mustExec(t, db, `INSERT INTO generated_cards (id, kind, concept_id, topic_id, position, front, back, status, next_review)
+		VALUES ('g1', 'reverse', 'c1', 't1', 1, 'front', 'back', 'learning', '2026-03-10T07:00:00Z'),
+		       ('g2', 'reverse', 'c2', 't1', 2, 'front', 'back', 'learning', '2026-03-12')`)
+
+	due, err := repo.CountDueReviews(context.Background(), time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("count due: %v", err)
+	}
+
+	if due != 4 {
+		t.Fatalf("expected 4 due reviews, got %d", due)
+	}
+}
+
+func TestBackupToAndOptimize(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewMaintenanceRepository(db, db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	mustExec(t, db, `INSERT INTO search_index (entity_type, entity_id, title, body) VALUES ('topic', 't1', 'Topic', 'body')`)
+
+	if err := repo.OptimizeSearchIndex(ctx); err != nil {
+		t.Fatalf("optimize: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := repo.BackupTo(ctx, path); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	backup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	defer backup.Close()
+
+	var count int
+	if err := backup.QueryRow(`SELECT COUNT(*) FROM topics`).Scan(&count); err != nil {
+		t.Fatalf("query backup: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 topic in backup, got %d", count)
+	}
+
+	if err := repo.BackupTo(ctx, path); err == nil {
+		t.Fatal("expected error backing up over an existing file")
+	}
+}
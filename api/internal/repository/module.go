@@ -11,7 +11,7 @@ import (
 
 // ModuleRepository defines read operations for modules.
 type ModuleRepository interface {
-	GetModuleByID(ctx context.Context, id string) (*models.ModuleDetail, error)
+	GetModuleByID(ctx context.Context, id string, opts models.ReadOptions) (*models.ModuleDetail, error)
 }
 
 // SQLiteModuleRepository implements ModuleRepository using SQLite.
@@ -26,25 +26,28 @@ func NewModuleRepository(db *sql.DB) *SQLiteModuleRepository {
 
 const getModuleSQL = `
 SELECT id, topic_id, title, COALESCE(description, ''), learning_objectives,
-       COALESCE(estimated_minutes, 0), sort_order, assessment
+       COALESCE(estimated_minutes, 0), sort_order, assessment, revision,
+       COALESCE(archived_at, '')
 FROM modules
 WHERE id = ?
 `
 
 const getLessonsForModuleSummarySQL = `
-SELECT id, title, sort_order, COALESCE(estimated_minutes, 0)
+SELECT id, title, sort_order, COALESCE(estimated_minutes, 0), COALESCE(archived_at, '')
 FROM lessons
-WHERE module_id = ?
+WHERE module_id = ? AND (? OR archived_at IS NULL)
 ORDER BY sort_order
 `
 
-func (r *SQLiteModuleRepository) GetModuleByID(ctx context.Context, id string) (*models.ModuleDetail, error) {
+// GetModuleByID returns the module, archived or not, with its lesson
+// summaries. Archived lessons are omitted unless opts.IncludeArchived is set.
+func (r *SQLiteModuleRepository) GetModuleByID(ctx context.Context, id string, opts models.ReadOptions) (*models.ModuleDetail, error) {
 	md := &models.ModuleDetail{}
 	var loRaw, assessRaw *string
 
 	err := r.db.QueryRowContext(ctx, getModuleSQL, id).Scan(
 		&md.ID, &md.TopicID, &md.Title, &md.Description, &loRaw,
-		&md.EstimatedMinutes, &md.SortOrder, &assessRaw,
+		&md.EstimatedMinutes, &md.SortOrder, &assessRaw, &md.Revision, &md.ArchivedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -57,7 +60,7 @@ func (r *SQLiteModuleRepository) GetModuleByID(ctx context.Context, id string) (
 	md.LearningObjectives = models.ParseJSONStringSlice(loRaw)
 	md.Assessment = models.ParseJSONRaw(assessRaw)
 
-	lessons, err := r.queryLessonSummaries(ctx, id)
+	lessons, err := r.queryLessonSummaries(ctx, id, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -67,8 +70,8 @@ func (r *SQLiteModuleRepository) GetModuleByID(ctx context.Context, id string) (
 	return md, nil
 }
 
-func (r *SQLiteModuleRepository) queryLessonSummaries(ctx context.Context, moduleID string) ([]models.LessonSummary, error) {
-	rows, err := r.db.QueryContext(ctx, getLessonsForModuleSummarySQL, moduleID)
+func (r *SQLiteModuleRepository) queryLessonSummaries(ctx context.Context, moduleID string, opts models.ReadOptions) ([]models.LessonSummary, error) {
+	rows, err := r.db.QueryContext(ctx, getLessonsForModuleSummarySQL, moduleID, opts.IncludeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("query lessons for module %s: %w", moduleID, err)
 	}
@@ -78,7 +81,7 @@ func (r *SQLiteModuleRepository) queryLessonSummaries(ctx context.Context, modul
 
 	for rows.Next() {
 		var ls models.LessonSummary
-		if err := rows.Scan(&ls.ID, &ls.Title, &ls.SortOrder, &ls.EstimatedMinutes); err != nil {
+		if err := rows.Scan(&ls.ID, &ls.Title, &ls.SortOrder, &ls.EstimatedMinutes, &ls.ArchivedAt); err != nil {
 			return nil, fmt.Errorf("scan lesson summary: %w", err)
 		}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
 )
 
@@ -17,7 +18,7 @@ func TestGetModuleByID(t *testing.T) {
 	seedLesson(t, db, "lesson-2", "mod-1", "Variables", 2)
 	seedLesson(t, db, "lesson-3", "mod-1", "Functions", 3)
 
-	module, err := repo.GetModuleByID(context.Background(), "mod-1")
+	module, err := repo.GetModuleByID(context.Background(), "mod-1", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("get module: %v", err)
 	}
@@ -47,7 +48,7 @@ func TestGetModuleByIDNotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewModuleRepository(db)
 
-	module, err := repo.GetModuleByID(context.Background(), "nonexistent")
+	module, err := repo.GetModuleByID(context.Background(), "nonexistent", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
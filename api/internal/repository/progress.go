@@ -11,19 +11,30 @@ import (
 
 // ProgressRepository defines operations for learning progress tracking.
 type ProgressRepository interface {
-	GetTopicProgress(ctx context.Context, topicID string) (*models.TopicProgress, error)
+	GetTopicProgress(ctx context.Context, topicID string, opts models.ReadOptions) (*models.TopicProgress, error)
 	UpdateLessonProgress(ctx context.Context, lessonID string, input models.UpdateProgressInput) (*models.LessonProgress, error)
-	GetProgressSummary(ctx context.Context) (*models.ProgressSummary, error)
+	GetProgressSummary(ctx context.Context, opts models.ReadOptions) (*models.ProgressSummary, error)
 }
 
 // SQLiteProgressRepository implements ProgressRepository using SQLite.
+// Reads use the read pool; progress updates use the write handle.
 type SQLiteProgressRepository struct {
-	db *sql.DB
+	readDB     *sql.DB
+	db         *sql.DB
+	enrollment models.EnrollmentOptions
 }
 
 // NewProgressRepository creates a new SQLiteProgressRepository.
-func NewProgressRepository(db *sql.DB) *SQLiteProgressRepository {
-	return &SQLiteProgressRepository{db: db}
+func NewProgressRepository(readDB, writeDB *sql.DB) *SQLiteProgressRepository {
+	return &SQLiteProgressRepository{readDB: readDB, db: writeDB}
+}
+
+// WithEnrollment sets which concepts lesson progress enrolls and un-enrolls
+// from review, and returns the repository.
+func (r *SQLiteProgressRepository) WithEnrollment(opts models.EnrollmentOptions) *SQLiteProgressRepository {
+	r.enrollment = opts
+
+	return r
 }
 
 const checkTopicExistsSQL = `SELECT EXISTS(SELECT 1 FROM topics WHERE id = ?)`
@@ -31,18 +42,21 @@ const checkTopicExistsSQL = `SELECT EXISTS(SELECT 1 FROM topics WHERE id = ?)`
 const getTopicProgressSQL = `
 SELECT l.id, l.title, COALESCE(lp.status, 'not_started'),
        COALESCE(lp.started_at, ''), COALESCE(lp.completed_at, ''),
-       COALESCE(lp.notes, '')
+       COALESCE(lp.notes, ''), l.archived_at IS NOT NULL OR m.archived_at IS NOT NULL
 FROM modules m
 JOIN lessons l ON l.module_id = m.id
 LEFT JOIN learning_progress lp ON lp.lesson_id = l.id
-WHERE m.topic_id = ?
+WHERE m.topic_id = ? AND (? OR (l.archived_at IS NULL AND m.archived_at IS NULL))
 ORDER BY m.sort_order, l.sort_order
 `
 
-func (r *SQLiteProgressRepository) GetTopicProgress(ctx context.Context, topicID string) (*models.TopicProgress, error) {
+// GetTopicProgress lists progress for the topic's lessons. Lessons that are
+// archived, or sit in an archived module, are omitted unless
+// opts.IncludeArchived is set; their progress rows are kept either way.
+func (r *SQLiteProgressRepository) GetTopicProgress(ctx context.Context, topicID string, opts models.ReadOptions) (*models.TopicProgress, error) {
 	// Verify topic exists.
 	var exists bool
-	if err := r.db.QueryRowContext(ctx, checkTopicExistsSQL, topicID).Scan(&exists); err != nil {
+	if err := r.readDB.QueryRowContext(ctx, checkTopicExistsSQL, topicID).Scan(&exists); err != nil {
 		return nil, fmt.Errorf("check topic %s: %w", topicID, err)
 	}
 
@@ -50,7 +64,7 @@ func (r *SQLiteProgressRepository) GetTopicProgress(ctx context.Context, topicID
 		return nil, ErrNotFound
 	}
 
-	rows, err := r.db.QueryContext(ctx, getTopicProgressSQL, topicID)
+	rows, err := r.readDB.QueryContext(ctx, getTopicProgressSQL, topicID, opts.IncludeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("query topic progress %s: %w", topicID, err)
 	}
@@ -60,7 +74,7 @@ func (r *SQLiteProgressRepository) GetTopicProgress(ctx context.Context, topicID
 
 	for rows.Next() {
 		var lp models.LessonProgress
-		if err := rows.Scan(&lp.LessonID, &lp.LessonTitle, &lp.Status, &lp.StartedAt, &lp.CompletedAt, &lp.Notes); err != nil {
+		if err := rows.Scan(&lp.LessonID, &lp.LessonTitle, &lp.Status, &lp.StartedAt, &lp.CompletedAt, &lp.Notes, &lp.Archived); err != nil {
 			return nil, fmt.Errorf("scan lesson progress: %w", err)
 		}
 
@@ -96,10 +110,99 @@ FROM learning_progress
 WHERE lesson_id = ?
 `
 
+// lessonConceptsSQL selects the concepts a lesson teaches: those it defines
+// and, when ?2 is true, those it references. ?1 is the lesson ID.
+const lessonConceptsSQL = `
+SELECT id FROM concepts WHERE defined_in_lesson = ?1
+UNION
+SELECT concept_id FROM concept_references WHERE ?2 AND lesson_id = ?1
+`
+
+// enrollConceptsSQL moves the lesson's new concepts to learning, due at ?3.
+// Concepts already in review keep their schedule.
+const enrollConceptsSQL = `
+INSERT INTO concept_retention (concept_id, status, next_review)
+SELECT id, 'learning', ?3 FROM (` + lessonConceptsSQL + `) WHERE true
+ON CONFLICT(concept_id) DO UPDATE SET status = 'learning', next_review = excluded.next_review
+WHERE concept_retention.status = 'new'
+`
+
+// unenrollConceptsSQL returns the lesson's never-reviewed concepts to new,
+// unless another completed lesson still teaches them.
+const unenrollConceptsSQL = `
+UPDATE concept_retention
+SET status = 'new', next_review = NULL
+WHERE status = 'learning' AND review_count = 0 AND last_reviewed IS NULL
+  AND concept_id IN (` + lessonConceptsSQL + `)
+  AND concept_id NOT IN (
+    SELECT c.id FROM concepts c
+    JOIN learning_progress lp ON lp.lesson_id = c.defined_in_lesson
+    WHERE lp.status = 'completed' AND lp.lesson_id <> ?1
+    UNION
+    SELECT cr.concept_id FROM concept_references cr
+    JOIN learning_progress lp ON lp.lesson_id = cr.lesson_id
+    WHERE ?2 AND lp.status = 'completed' AND lp.lesson_id <> ?1
+  )
+`
+
+// enrollQuestionsSQL moves the new question cards of lesson ?1 to
+// learning, due at ?2, along with its module's assessment questions once
+// that completes the module.
+const enrollQuestionsSQL = `
+UPDATE question_cards
+SET status = 'learning', next_review = ?2
+WHERE status = 'new'
+  AND (lesson_id = ?1 OR (source = 'assessment' AND EXISTS (
+    SELECT 1 FROM modules m
+    WHERE m.id = question_cards.module_id
+      AND m.id = (SELECT module_id FROM lessons WHERE id = ?1)
+      AND ` + moduleCompletedSQL + `
+  )))
+`
+
+// unenrollQuestionsSQL returns the never-reviewed question cards of lesson
+// ?1, and of its module's assessment, to new.
+const unenrollQuestionsSQL = `
+UPDATE question_cards
+SET status = 'new', next_review = NULL
+WHERE status = 'learning' AND review_count = 0 AND last_reviewed IS NULL
+  AND (lesson_id = ?1 OR (source = 'assessment' AND module_id = (SELECT module_id FROM lessons WHERE id = ?1)))
+`
+
+// enrollCardsSQL moves the new generated cards of concepts in review to
+// learning, due at ?1, so they follow the concepts enrollment just moved.
+const enrollCardsSQL = `
+UPDATE generated_cards
+SET status = 'learning', next_review = ?1
+WHERE status = 'new'
+  AND concept_id IN (SELECT concept_id FROM concept_retention WHERE status <> 'new')
+`
+
+// unenrollCardsSQL returns the never-reviewed generated cards of concepts
+// no longer in review to new.
+const unenrollCardsSQL = `
+UPDATE generated_cards
+SET status = 'new', next_review = NULL
+WHERE status = 'learning' AND review_count = 0 AND last_reviewed IS NULL
+  AND concept_id NOT IN (SELECT concept_id FROM concept_retention WHERE status <> 'new')
+`
+
+// UpdateLessonProgress stores the lesson's progress. Completing a lesson
+// enrolls the concepts it teaches, their generated cards, and its question
+// cards in review, first due a day later, and the module's assessment
+// questions once every lesson of the module is completed. Going back to
+// not_started un-enrolls them when the options ask for it. Both happen in
+// the same transaction as the progress update.
 func (r *SQLiteProgressRepository) UpdateLessonProgress(ctx context.Context, lessonID string, input models.UpdateProgressInput) (*models.LessonProgress, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	// Verify lesson exists.
 	var exists bool
-	if err := r.db.QueryRowContext(ctx, checkLessonExistsSQL, lessonID).Scan(&exists); err != nil {
+	if err := tx.QueryRowContext(ctx, checkLessonExistsSQL, lessonID).Scan(&exists); err != nil {
 		return nil, fmt.Errorf("check lesson %s: %w", lessonID, err)
 	}
 
@@ -107,47 +210,121 @@ func (r *SQLiteProgressRepository) UpdateLessonProgress(ctx context.Context, les
 		return nil, ErrNotFound
 	}
 
-	now := time.Now().UTC().Format(time.RFC3339)
+	now := time.Now().UTC()
+	stamp := now.Format(time.RFC3339)
 
 	var startedAt, completedAt string
 	if input.Status != models.ProgressStatusNotStarted {
-		startedAt = now
+		startedAt = stamp
 	}
 
 	if input.Status == models.ProgressStatusCompleted {
-		completedAt = now
+		completedAt = stamp
 	}
 
-	if _, err := r.db.ExecContext(ctx, upsertProgressSQL, lessonID, input.Status, startedAt, completedAt, input.Notes); err != nil {
+	if _, err := tx.ExecContext(ctx, upsertProgressSQL, lessonID, input.Status, startedAt, completedAt, input.Notes); err != nil {
 		return nil, fmt.Errorf("upsert progress %s: %w", lessonID, err)
 	}
 
+	var enrolled, unenrolled, enrolledQuestions, unenrolledQuestions, enrolledCards, unenrolledCards int64
+
+	switch {
+	case input.Status == models.ProgressStatusCompleted:
+		due := now.AddDate(0, 0, 1).Format(time.RFC3339)
+
+		enrolled, err = execAffected(ctx, tx, enrollConceptsSQL, lessonID, r.enrollment.IncludeReferenced, due)
+		if err != nil {
+			return nil, fmt.Errorf("enroll lesson %s concepts: %w", lessonID, err)
+		}
+
+		enrolledQuestions, err = execAffected(ctx, tx, enrollQuestionsSQL, lessonID, due)
+		if err != nil {
+			return nil, fmt.Errorf("enroll lesson %s questions: %w", lessonID, err)
+		}
+
+		enrolledCards, err = execAffected(ctx, tx, enrollCardsSQL, due)
+		if err != nil {
+			return nil, fmt.Errorf("enroll lesson %s generated cards: %w", lessonID, err)
+		}
+	case input.Status == models.ProgressStatusNotStarted && r.enrollment.UnenrollOnReset:
+		unenrolled, err = execAffected(ctx, tx, unenrollConceptsSQL, lessonID, r.enrollment.IncludeReferenced)
+		if err != nil {
+			return nil, fmt.Errorf("unenroll lesson %s concepts: %w", lessonID, err)
+		}
+
+		unenrolledQuestions, err = execAffected(ctx, tx, unenrollQuestionsSQL, lessonID)
+		if err != nil {
+			return nil, fmt.Errorf("unenroll lesson %s questions: %w", lessonID, err)
+		}
+
+		unenrolledCards, err = execAffected(ctx, tx, unenrollCardsSQL)
+		if err != nil {
+			return nil, fmt.Errorf("unenroll lesson %s generated cards: %w", lessonID, err)
+		}
+	}
+
 	// Read back the stored row to return accurate timestamps.
-	lp := &models.LessonProgress{}
-	if err := r.db.QueryRowContext(ctx, getProgressSQL, lessonID).Scan(
+	lp := &models.LessonProgress{
+		Enrolled:            int(enrolled),
+		Unenrolled:          int(unenrolled),
+		EnrolledQuestions:   int(enrolledQuestions),
+		UnenrolledQuestions: int(unenrolledQuestions),
+		EnrolledCards:       int(enrolledCards),
+		UnenrolledCards:     int(unenrolledCards),
+	}
+	if err := tx.QueryRowContext(ctx, getProgressSQL, lessonID).Scan(
 		&lp.LessonID, &lp.Status, &lp.StartedAt, &lp.CompletedAt, &lp.Notes,
 	); err != nil {
 		return nil, fmt.Errorf("read back progress %s: %w", lessonID, err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
 	return lp, nil
 }
 
+// execAffected runs query in tx and returns how many rows it changed.
+func execAffected(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return n, nil
+}
+
 const getProgressSummarySQL = `
+WITH visible AS (
+  SELECT l.id, m.topic_id
+  FROM lessons l
+  JOIN modules m ON m.id = l.module_id
+  WHERE ? OR (l.archived_at IS NULL AND m.archived_at IS NULL)
+)
 SELECT
-  (SELECT COUNT(*) FROM lessons) AS total_lessons,
-  (SELECT COUNT(*) FROM learning_progress WHERE status = 'completed') AS completed_lessons,
-  (SELECT COUNT(DISTINCT m.topic_id)
+  (SELECT COUNT(*) FROM visible) AS total_lessons,
+  (SELECT COUNT(*)
+   FROM learning_progress lp
+   JOIN visible v ON v.id = lp.lesson_id
+   WHERE lp.status = 'completed') AS completed_lessons,
+  (SELECT COUNT(DISTINCT v.topic_id)
    FROM learning_progress lp
-   JOIN lessons l ON l.id = lp.lesson_id
-   JOIN modules m ON m.id = l.module_id
+   JOIN visible v ON v.id = lp.lesson_id
    WHERE lp.status IN ('in_progress', 'completed')) AS active_topics
 `
 
-func (r *SQLiteProgressRepository) GetProgressSummary(ctx context.Context) (*models.ProgressSummary, error) {
+// GetProgressSummary totals progress across all topics. Archived lessons and
+// lessons in archived modules count only when opts.IncludeArchived is set.
+func (r *SQLiteProgressRepository) GetProgressSummary(ctx context.Context, opts models.ReadOptions) (*models.ProgressSummary, error) {
 	ps := &models.ProgressSummary{}
 
-	if err := r.db.QueryRowContext(ctx, getProgressSummarySQL).Scan(
+	if err := r.readDB.QueryRowContext(ctx, getProgressSummarySQL, opts.IncludeArchived).Scan(
 		&ps.TotalLessons, &ps.CompletedLessons, &ps.ActiveTopics,
 	); err != nil {
 		return nil, fmt.Errorf("query progress summary: %w", err)
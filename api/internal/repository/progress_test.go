@@ -2,9 +2,11 @@ package repository_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
@@ -12,14 +14,14 @@ import (
 
 func TestGetTopicProgress_DefaultNotStarted(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
 	seedTopic(t, db, "topic-1", "Go Basics", "foundational", "published")
 	seedModule(t, db, "mod-1", "topic-1", "Module 1", 1)
 	seedLesson(t, db, "lesson-1", "mod-1", "Lesson 1", 1)
 	seedLesson(t, db, "lesson-2", "mod-1", "Lesson 2", 2)
 
-	tp, err := repo.GetTopicProgress(context.Background(), "topic-1")
+	tp, err := repo.GetTopicProgress(context.Background(), "topic-1", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("get topic progress: %v", err)
 	}
@@ -41,9 +43,9 @@ func TestGetTopicProgress_DefaultNotStarted(t *testing.T) {
 
 func TestGetTopicProgress_NotFoundTopic(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
-	_, err := repo.GetTopicProgress(context.Background(), "nonexistent")
+	_, err := repo.GetTopicProgress(context.Background(), "nonexistent", models.ReadOptions{})
 	if !errors.Is(err, repository.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -51,7 +53,7 @@ func TestGetTopicProgress_NotFoundTopic(t *testing.T) {
 
 func TestUpdateLessonProgress_CreateNew(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
 	seedTopic(t, db, "topic-1", "Go Basics", "foundational", "published")
 	seedModule(t, db, "mod-1", "topic-1", "Module 1", 1)
@@ -83,7 +85,7 @@ func TestUpdateLessonProgress_CreateNew(t *testing.T) {
 
 func TestUpdateLessonProgress_Upsert(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
 	seedTopic(t, db, "topic-1", "Go Basics", "foundational", "published")
 	seedModule(t, db, "mod-1", "topic-1", "Module 1", 1)
@@ -125,7 +127,7 @@ func TestUpdateLessonProgress_Upsert(t *testing.T) {
 
 func TestUpdateLessonProgress_NotFoundLesson(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
 	_, err := repo.UpdateLessonProgress(context.Background(), "nonexistent", models.UpdateProgressInput{
 		Status: models.ProgressStatusCompleted,
@@ -138,7 +140,7 @@ func TestUpdateLessonProgress_NotFoundLesson(t *testing.T) {
 
 func TestGetProgressSummary(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
 	seedTopic(t, db, "topic-1", "Go Basics", "foundational", "published")
 	seedModule(t, db, "mod-1", "topic-1", "Module 1", 1)
@@ -154,7 +156,7 @@ func TestGetProgressSummary(t *testing.T) {
 		t.Fatalf("update progress: %v", err)
 	}
 
-	ps, err := repo.GetProgressSummary(context.Background())
+	ps, err := repo.GetProgressSummary(context.Background(), models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("get progress summary: %v", err)
 	}
@@ -179,7 +181,7 @@ func TestGetProgressSummary(t *testing.T) {
 
 func TestCompletedAtAutoSet(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewProgressRepository(db)
+	repo := repository.NewProgressRepository(db, db)
 
 	seedTopic(t, db, "topic-1", "Go Basics", "foundational", "published")
 	seedModule(t, db, "mod-1", "topic-1", "Module 1", 1)
@@ -200,3 +202,150 @@ func TestCompletedAtAutoSet(t *testing.T) {
 		t.Fatal("expected started_at to be auto-set on completed status")
 	}
 }
+
+// seedEnrollmentTree creates lessons l1 and l2 with c1 defined in l1, c2
+// defined in l2 and referenced by l1, and c3 defined in l1 but already
+// being reviewed.
+func seedEnrollmentTree(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Module", 1)
+	seedLesson(t, db, "l1", "m1", "Lesson 1", 1)
+	seedLesson(t, db, "l2", "m1", "Lesson 2", 2)
+
+	for _, id := range []string{"c1", "c2", "c3"} {
+		seedConcept(t, db, id, id, "def", "t1")
+	}
+
+	mustExec(t, db, `UPDATE concepts SET defined_in_lesson = 'l1' WHERE id IN ('c1', 'c3')`)
+	mustExec(t, db, `UPDATE concepts SET defined_in_lesson = 'l2' WHERE id = 'c2'`)
+	seedConceptReference(t, db, "c2", "l1")
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, review_count, interval_days)
+		VALUES ('c3', 'reviewing', '2030-01-01T00:00:00Z', 4, 20)`)
+}
+
+func retentionStatus(t *testing.T, db *sql.DB, conceptID string) string {
+	t.Helper()
+
+	var status string
+	err := db.QueryRow(`SELECT status FROM concept_retention WHERE concept_id = ?`, conceptID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.RetentionStatusNew
+	}
+
+	if err != nil {
+		t.Fatalf("load %s retention: %v", conceptID, err)
+	}
+
+	return status
+}
+
+func TestCompletingLessonEnrollsDefinedConcepts(t *testing.T) {
+	db := setupTestDB(t)
+	seedEnrollmentTree(t, db)
+	repo := repository.NewProgressRepository(db, db)
+
+	lp, err := repo.UpdateLessonProgress(context.Background(), "l1", models.UpdateProgressInput{Status: models.ProgressStatusCompleted})
+	if err != nil {
+		t.Fatalf("complete lesson: %v", err)
+	}
+
+	if lp.Enrolled != 1 {
+		t.Fatalf("expected 1 enrolled concept, got %d", lp.Enrolled)
+	}
+
+	var next string
+	if err := db.QueryRow(`SELECT next_review FROM concept_retention WHERE concept_id = 'c1' AND status = 'learning'`).Scan(&next); err != nil {
+		t.Fatalf("c1 not enrolled: %v", err)
+	}
+
+	due, err := time.Parse(time.RFC3339, next)
+	if err != nil || time.Until(due) < 23*time.Hour {
+		t.Fatalf("expected first review a day out, got %q", next)
+	}
+
+	if got := retentionStatus(t, db, "c2"); got != models.RetentionStatusNew {
+		t.Fatalf("referenced concept enrolled without the option: %s", got)
+	}
+
+	var interval int
+	if err := db.QueryRow(`SELECT interval_days FROM concept_retention WHERE concept_id = 'c3' AND status = 'reviewing'`).Scan(&interval); err != nil || interval != 20 {
+		t.Fatalf("concept under review was rescheduled: %d, %v", interval, err)
+	}
+
+	// Completing again enrolls nothing new.
+	lp, err = repo.UpdateLessonProgress(context.Background(), "l1", models.UpdateProgressInput{Status: models.ProgressStatusCompleted})
+	if err != nil || lp.Enrolled != 0 {
+		t.Fatalf("repeat completion enrolled %d, %v", lp.Enrolled, err)
+	}
+}
+
+func TestCompletingLessonEnrollsReferencedConcepts(t *testing.T) {
+	db := setupTestDB(t)
+	seedEnrollmentTree(t, db)
+	repo := repository.NewProgressRepository(db, db).WithEnrollment(models.EnrollmentOptions{IncludeReferenced: true})
+
+	lp, err := repo.UpdateLessonProgress(context.Background(), "l1", models.UpdateProgressInput{Status: models.ProgressStatusCompleted})
+	if err != nil {
+		t.Fatalf("complete lesson: %v", err)
+	}
+
+	if lp.Enrolled != 2 || retentionStatus(t, db, "c2") != models.RetentionStatusLearning {
+		t.Fatalf("expected c1 and c2 enrolled, got %d", lp.Enrolled)
+	}
+}
+
+func TestResettingLessonUnenrollsUnreviewedConcepts(t *testing.T) {
+	db := setupTestDB(t)
+	seedEnrollmentTree(t, db)
+	ctx := context.Background()
+	opts := models.EnrollmentOptions{IncludeReferenced: true, UnenrollOnReset: true}
+	repo := repository.NewProgressRepository(db, db).WithEnrollment(opts)
+
+	for _, lesson := range []string{"l1", "l2"} {
+		if _, err := repo.UpdateLessonProgress(ctx, lesson, models.UpdateProgressInput{Status: models.ProgressStatusCompleted}); err != nil {
+			t.Fatalf("complete %s: %v", lesson, err)
+		}
+	}
+
+	lp, err := repo.UpdateLessonProgress(ctx, "l1", models.UpdateProgressInput{Status: models.ProgressStatusNotStarted})
+	if err != nil {
+		t.Fatalf("reset lesson: %v", err)
+	}
+
+	// c2 stays: l2 is still completed. c3 stays: it has been reviewed.
+	if lp.Unenrolled != 1 {
+		t.Fatalf("expected 1 unenrolled concept, got %d", lp.Unenrolled)
+	}
+
+	want := map[string]string{
+		"c1": models.RetentionStatusNew,
+		"c2": models.RetentionStatusLearning,
+		"c3": models.RetentionStatusReviewing,
+	}
+	for id, status := range want {
+		if got := retentionStatus(t, db, id); got != status {
+			t.Fatalf("%s status = %s, want %s", id, got, status)
+		}
+	}
+}
+
+func TestResettingLessonKeepsEnrollmentByDefault(t *testing.T) {
+	db := setupTestDB(t)
+	seedEnrollmentTree(t, db)
+	ctx := context.Background()
+	repo := repository.NewProgressRepository(db, db)
+
+	if _, err := repo.UpdateLessonProgress(ctx, "l1", models.UpdateProgressInput{Status: models.ProgressStatusCompleted}); err != nil {
+		t.Fatalf("complete lesson: %v", err)
+	}
+
+	if _, err := repo.UpdateLessonProgress(ctx, "l1", models.UpdateProgressInput{Status: models.ProgressStatusNotStarted}); err != nil {
+		t.Fatalf("reset lesson: %v", err)
+	}
+
+	if got := retentionStatus(t, db, "c1"); got != models.RetentionStatusLearning {
+		t.Fatalf("c1 status = %s, want learning", got)
+	}
+}
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// questionCardColumns selects a question's models.ReviewCard from
+// question_cards q joined to modules m, in the shape of reviewCardColumns.
+const questionCardColumns = `
+'', q.status, COALESCE(q.next_review, ''), q.review_count,
+q.ease_factor, q.interval_days, COALESCE(q.last_reviewed, ''), COALESCE(q.last_rating, ''),
+COALESCE(q.stability, 0), COALESCE(q.difficulty, 0), q.scheduler,
+q.lapses, '',
+q.question, q.answer, q.question, q.answer,
+COALESCE(q.lesson_id, ''), m.topic_id,
+'question', q.id, '',
+COALESCE((SELECT group_concat(qc.concept_id, ',' ORDER BY qc.concept_id)
+          FROM question_concepts qc WHERE qc.question_id = q.id), ''),
+q.module_id
+`
+
+// sourceQuestion is a question as stored in lessons.review_questions and
+// modules.assessment.
+type sourceQuestion struct {
+	Question       string   `json:"question"`
+	Answer         string   `json:"answer"`
+	ConceptsTested []string `json:"concepts_tested"`
+}
+
+// questionCardID derives a question card's ID from its source, the lesson
+// or module holding it, and its text, so a re-sync keeps an unchanged
+// question's review state and an edited question starts over.
+func questionCardID(source, ownerID, question string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + ownerID + "\x00" + question))
+
+	return "q-" + hex.EncodeToString(sum[:8])
+}
+
+// getLessonQuestionsSQL selects a lesson's module, its review questions, and
+// whether it is completed.
+const getLessonQuestionsSQL = `
+SELECT l.module_id, COALESCE(l.review_questions, ''),
+       EXISTS(SELECT 1 FROM learning_progress lp WHERE lp.lesson_id = l.id AND lp.status = 'completed')
+FROM lessons l
+WHERE l.id = ?
+`
+
+// moduleCompletedSQL is true when module m has lessons and every one that
+// is not archived is completed.
+const moduleCompletedSQL = `
+(SELECT COUNT(*) > 0 AND SUM(COALESCE(lp.status, '') = 'completed') = COUNT(*)
+ FROM lessons l
+ LEFT JOIN learning_progress lp ON lp.lesson_id = l.id
+ WHERE l.module_id = m.id AND l.archived_at IS NULL)
+`
+
+const getModuleQuestionsSQL = `
+SELECT COALESCE(m.assessment, ''), ` + moduleCompletedSQL + `
+FROM modules m
+WHERE m.id = ?
+`
+
+// upsertQuestionCardSQL stores a synced question. An existing card keeps
+// its review state.
+const upsertQuestionCardSQL = `
+INSERT INTO question_cards (id, source, module_id, lesson_id, position, question, answer, status, next_review)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+  module_id = excluded.module_id, position = excluded.position, answer = excluded.answer
+`
+
+const deleteQuestionConceptsSQL = `DELETE FROM question_concepts WHERE question_id = ?`
+
+const insertQuestionConceptSQL = `INSERT OR IGNORE INTO question_concepts (question_id, concept_id) VALUES (?, ?)`
+
+// deleteStaleQuestionsSQL removes the cards of source ?1 held by lesson or
+// module ?2 that are not in the JSON array ?3.
+const deleteStaleQuestionsSQL = `
+DELETE FROM question_cards
+WHERE source = ?1 AND (CASE source WHEN 'lesson' THEN lesson_id ELSE module_id END) = ?2
+  AND id NOT IN (SELECT value FROM json_each(?3))
+`
+
+// syncLessonQuestions syncs a lesson's question cards with its
+// review_questions. A lesson that does not exist has none.
+func syncLessonQuestions(ctx context.Context, q queryer, lessonID string) error {
+	var moduleID, raw string
+	var completed bool
+
+	err := q.QueryRowContext(ctx, getLessonQuestionsSQL, lessonID).Scan(&moduleID, &raw, &completed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("load lesson %s questions: %w", lessonID, err)
+	}
+
+	var questions []sourceQuestion
+	if raw != "" {
+		// Content that is not a question list yields no cards.
+		_ = json.Unmarshal([]byte(raw), &questions)
+	}
+
+	return syncQuestions(ctx, q, models.QuestionSourceLesson, moduleID, lessonID, questions, completed)
+}
+
+// syncModuleQuestions syncs a module's question cards with its assessment.
+// A module that does not exist has none.
+func syncModuleQuestions(ctx context.Context, q queryer, moduleID string) error {
+	var raw string
+	var completed bool
+
+	err := q.QueryRowContext(ctx, getModuleQuestionsSQL, moduleID).Scan(&raw, &completed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("load module %s assessment: %w", moduleID, err)
+	}
+
+	var assessment struct {
+		Questions []sourceQuestion `json:"questions"`
+	}
+
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &assessment)
+	}
+
+	return syncQuestions(ctx, q, models.QuestionSourceAssessment, moduleID, "", assessment.Questions, completed)
+}
+
+// syncQuestions stores the questions of one lesson, or of one module's
+// assessment when lessonID is empty, as question cards, and deletes the
+// cards of questions no longer there along with their history. New cards of a completed lesson or module start in review,
+// due a day later, as enrollment would have put them.
+func syncQuestions(ctx context.Context, q queryer, source, moduleID, lessonID string, questions []sourceQuestion, enrolled bool) error {
+	owner := lessonID
+	if source == models.QuestionSourceAssessment {
+		owner = moduleID
+	}
+
+	status, nextReview := models.RetentionStatusNew, ""
+	if enrolled {
+		status = models.RetentionStatusLearning
+		nextReview = time.Now().UTC().AddDate(0, 0, 1).Format(time.RFC3339)
+	}
+
+	ids := []string{}
+	seen := make(map[string]bool, len(questions))
+
+	for i, sq := range questions {
+		text := strings.TrimSpace(sq.Question)
+		if text == "" {
+			continue
+		}
+
+		id := questionCardID(source, owner, text)
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		ids = append(ids, id)
+
+		if _, err := q.ExecContext(ctx, upsertQuestionCardSQL,
+			id, source, moduleID, nullIfEmpty(lessonID), i, text, strings.TrimSpace(sq.Answer),
+			status, nullIfEmpty(nextReview),
+		); err != nil {
+			return classifyError(err, "store question "+id)
+		}
+
+		if err := linkQuestionConcepts(ctx, q, id, sq.ConceptsTested); err != nil {
+			return err
+		}
+	}
+
+	keep, _ := json.Marshal(ids)
+
+	if _, err := q.ExecContext(ctx, deleteStaleQuestionsSQL, source, owner, string(keep)); err != nil {
+		return fmt.Errorf("delete stale %s %s questions: %w", source, owner, err)
+	}
+
+	return nil
+}
+
+// linkQuestionConcepts replaces the concepts a question tests. IDs resolve
+// through aliases; one that names no concept is linked as given.
+func linkQuestionConcepts(ctx context.Context, q queryer, questionID string, conceptIDs []string) error {
+	if _, err := q.ExecContext(ctx, deleteQuestionConceptsSQL, questionID); err != nil {
+		return fmt.Errorf("clear question %s concepts: %w", questionID, err)
+	}
+
+	for _, raw := range conceptIDs {
+		conceptID, err := ResolveConceptID(ctx, q, raw)
+		if err != nil {
+			return err
+		}
+
+		if conceptID == "" {
+			conceptID = strings.TrimSpace(raw)
+		}
+
+		if conceptID == "" {
+			continue
+		}
+
+		if _, err := q.ExecContext(ctx, insertQuestionConceptSQL, questionID, conceptID); err != nil {
+			return fmt.Errorf("link question %s to concept %s: %w", questionID, conceptID, err)
+		}
+	}
+
+	return nil
+}
+
+const listTopicModulesSQL = `SELECT id FROM modules WHERE topic_id = ? ORDER BY sort_order, id`
+
+const listTopicLessonsSQL = `
+SELECT l.id FROM lessons l
+JOIN modules m ON m.id = l.module_id
+WHERE m.topic_id = ?
+ORDER BY m.sort_order, l.sort_order, l.id
+`
+
+const listAllModulesSQL = `SELECT id FROM modules ORDER BY id`
+
+const listAllLessonsSQL = `SELECT id FROM lessons ORDER BY id`
+
+// syncQuestionsOf syncs the question cards of every module, then every
+// lesson, that the two queries list.
+func syncQuestionsOf(ctx context.Context, q queryer, modulesSQL, lessonsSQL string, args ...any) error {
+	moduleIDs, err := queryIDs(ctx, q, modulesSQL, args...)
+	if err != nil {
+		return err
+	}
+
+	lessonIDs, err := queryIDs(ctx, q, lessonsSQL, args...)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range moduleIDs {
+		if err := syncModuleQuestions(ctx, q, id); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range lessonIDs {
+		if err := syncLessonQuestions(ctx, q, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryIDs(ctx context.Context, q queryer, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan ID: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// SyncTopicQuestions syncs the question cards of a topic's modules and
+// lessons, for writers outside this package, such as the curriculum
+// ingester, that hold their own transaction.
+func SyncTopicQuestions(ctx context.Context, tx *sql.Tx, topicID string) error {
+	return syncQuestionsOf(ctx, tx, listTopicModulesSQL, listTopicLessonsSQL, topicID)
+}
+
+const countQuestionCardsSQL = `SELECT COUNT(*) FROM question_cards`
+
+func (r *SQLiteReviewRepository) SyncQuestions(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := syncQuestionsOf(ctx, tx, listAllModulesSQL, listAllLessonsSQL); err != nil {
+		return 0, err
+	}
+
+	var n int
+	if err := tx.QueryRowContext(ctx, countQuestionCardsSQL).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count question cards: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return n, nil
+}
+
+// listTestedConceptsSQL lists the concepts a question tests that are in
+// review: enrolled and not suspended.
+const listTestedConceptsSQL = `
+SELECT qc.concept_id FROM question_concepts qc
+JOIN concept_retention cr ON cr.concept_id = qc.concept_id
+WHERE qc.question_id = ? AND cr.status <> 'new' AND cr.suspended_at IS NULL
+ORDER BY qc.concept_id
+`
+
+func (r *SQLiteReviewRepository) RecordQuestionReview(ctx context.Context, questionID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.QuestionAnswer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	answer, err := r.applyQuestionReview(ctx, tx, questionID, responseMS, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return answer, nil
+}
+
+// applyQuestionReview schedules the question's next review, stores and logs
+// it, then reviews each tested concept in review with the same schedule.
+// Those concept reviews name the question and carry no response time.
+func (r *SQLiteReviewRepository) applyQuestionReview(ctx context.Context, q queryer, questionID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.QuestionAnswer, error) {
+	next, err := questionStore.review(ctx, q, questionID, responseMS, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	conceptIDs, err := queryIDs(ctx, q, listTestedConceptsSQL, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &models.QuestionAnswer{QuestionID: questionID, State: next, Concepts: []models.ConceptRetention{}}
+
+	for _, conceptID := range conceptIDs {
+		state, err := r.applyReview(ctx, q, conceptID, 0, questionID, schedule)
+		if err != nil {
+			return nil, err
+		}
+
+		answer.Concepts = append(answer.Concepts, *state)
+	}
+
+	return answer, nil
+}
@@ -0,0 +1,241 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// seedQuestionTree writes a module with one assessment question and a
+// lesson with two review questions through the write repository. c1 and c2
+// are defined in the lesson; c3 is only tested.
+func seedQuestionTree(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	ctx := context.Background()
+	w := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Go", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	seedConcept(t, db, "c2", "Select", "Waits on channels", "t1")
+	seedConcept(t, db, "c3", "Buffer", "Channel capacity", "t1")
+	mustExec(t, db, `UPDATE concepts SET aliases = '["select-stmt"]' WHERE id = 'c2'`)
+
+	if _, err := w.CreateModule(ctx, models.ModuleInput{
+		ID: "m1", TopicID: "t1", Title: "Concurrency", SortOrder: 1,
+		Assessment: json.RawMessage(`{"questions":[
+			{"type":"conceptual","question":"When does a send block?","answer":"When no receiver is ready","concepts_tested":["c1","c3"]}
+		]}`),
+	}); err != nil {
+		t.Fatalf("CreateModule() error = %v", err)
+	}
+
+	if _, err := w.CreateLesson(ctx, models.LessonInput{
+		ID: "l1", ModuleID: "m1", Title: "Channels", SortOrder: 1, Content: json.RawMessage(`{}`),
+		ReviewQuestions: json.RawMessage(`[
+			{"question":"What is a channel?","answer":"A typed pipe","concepts_tested":["c1","select-stmt"]},
+			{"question":"What does select do?","answer":"Waits on channels","concepts_tested":["c2"]}
+		]`),
+	}); err != nil {
+		t.Fatalf("CreateLesson() error = %v", err)
+	}
+
+	mustExec(t, db, `UPDATE concepts SET defined_in_lesson = 'l1' WHERE id IN ('c1', 'c2')`)
+}
+
+func questionID(t *testing.T, db *sql.DB, question string) string {
+	t.Helper()
+
+	var id string
+	if err := db.QueryRow(`SELECT id FROM question_cards WHERE question = ?`, question).Scan(&id); err != nil {
+		t.Fatalf("question %q: %v", question, err)
+	}
+
+	return id
+}
+
+func TestQuestionCardsSyncAndReview(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedQuestionTree(t, db)
+
+	var cards, links int
+	if err := db.QueryRow(`SELECT COUNT(*), (SELECT COUNT(*) FROM question_concepts) FROM question_cards WHERE status = 'new'`).Scan(&cards, &links); err != nil {
+		t.Fatalf("count question cards: %v", err)
+	}
+
+	if cards != 3 || links != 5 {
+		t.Fatalf("synced %d new cards with %d concept links, want 3 and 5", cards, links)
+	}
+
+	channelQ := questionID(t, db, "What is a channel?")
+
+	progress, err := repository.NewProgressRepository(db, db).UpdateLessonProgress(ctx, "l1", models.UpdateProgressInput{Status: models.ProgressStatusCompleted})
+	if err != nil {
+		t.Fatalf("UpdateLessonProgress() error = %v", err)
+	}
+
+	// The only lesson completes the module, so its assessment enrolls too.
+	if progress.Enrolled != 2 || progress.EnrolledQuestions != 3 {
+		t.Fatalf("UpdateLessonProgress() = %+v, want 2 concepts and 3 questions enrolled", progress)
+	}
+
+	repo := repository.NewReviewRepository(db, db)
+	later := time.Now().UTC().AddDate(0, 0, 2)
+
+	due, err := repo.ListDue(ctx, later)
+	if err != nil {
+		t.Fatalf("ListDue() error = %v", err)
+	}
+
+	var questions []models.ReviewCard
+	for _, c := range due {
+		if c.Kind == models.CardKindQuestion {
+			questions = append(questions, c)
+		}
+	}
+
+	if len(due) != 5 || len(questions) != 3 {
+		t.Fatalf("ListDue() = %d cards with %d questions, want 5 with 3", len(due), len(questions))
+	}
+
+	for _, c := range questions {
+		if c.QuestionID == channelQ {
+			if c.FlashcardFront != "What is a channel?" || c.LessonID != "l1" || c.TopicID != "t1" ||
+				len(c.ConceptIDs) != 2 || c.ConceptIDs[0] != "c1" || c.ConceptIDs[1] != "c2" {
+				t.Fatalf("question card = %+v, want the lesson question testing c1 and c2", c)
+			}
+		}
+	}
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	now := time.Now().UTC()
+
+	answer, err := repo.RecordQuestionReview(ctx, channelQ, 1500, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+		return sm2.Review(current, models.RatingGood, now)
+	})
+	if err != nil {
+		t.Fatalf("RecordQuestionReview() error = %v", err)
+	}
+
+	if answer.State.ReviewCount != 1 || answer.State.ConceptID != "" || len(answer.Concepts) != 2 ||
+		answer.Concepts[0].ConceptID != "c1" || answer.Concepts[1].ReviewCount != 1 {
+		t.Fatalf("RecordQuestionReview() = %+v, want the question and c1, c2 reviewed", answer)
+	}
+
+	var logged int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM review_log WHERE question_id = ? AND response_ms IS NULL`, channelQ).Scan(&logged); err != nil {
+		t.Fatalf("count review log: %v", err)
+	}
+
+	if logged != 2 {
+		t.Fatalf("review_log has %d reviews from the question, want 2", logged)
+	}
+
+	// c3 is not in review, so the assessment question reviews nothing else.
+	assessment, err := repo.RecordQuestionReview(ctx, questionID(t, db, "When does a send block?"), 0,
+		func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, models.RatingForgot, now)
+		})
+	if err != nil || assessment.State.Lapses != 1 || len(assessment.Concepts) != 1 {
+		t.Fatalf("RecordQuestionReview(assessment) = %+v, %v; want a lapse and only c1", assessment, err)
+	}
+
+	if _, err := repo.RecordQuestionReview(ctx, "q-missing", 0, nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("RecordQuestionReview(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Editing the lesson keeps the unchanged question's state and drops the
+	// removed one.
+	if _, err := repository.NewWriteRepository(db).UpdateLesson(ctx, "l1", 0, models.LessonInput{
+		ModuleID: "m1", Title: "Channels", SortOrder: 1, Content: json.RawMessage(`{}`),
+		ReviewQuestions: json.RawMessage(`[{"question":"What is a channel?","answer":"A conduit","concepts_tested":["c1"]}]`),
+	}); err != nil {
+		t.Fatalf("UpdateLesson() error = %v", err)
+	}
+
+	var count, reviews int
+	var answerText string
+	if err := db.QueryRow(`SELECT COUNT(*), MAX(review_count), MAX(answer) FROM question_cards WHERE lesson_id = 'l1'`).Scan(&count, &reviews, &answerText); err != nil {
+		t.Fatalf("query lesson questions: %v", err)
+	}
+
+	if count != 1 || reviews != 1 || answerText != "A conduit" {
+		t.Fatalf("lesson questions = %d, review count %d, answer %q; want 1 kept with the new answer", count, reviews, answerText)
+	}
+
+	fsrs, err := review.New(review.NameFSRS, review.DefaultMasteryDays)
+	if err != nil {
+		t.Fatalf("review.New() error = %v", err)
+	}
+
+	n, err := repo.Rederive(ctx, review.NameFSRS, func(current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error) {
+		return review.Replay(fsrs, current, history)
+	})
+	if err != nil {
+		t.Fatalf("Rederive() error = %v", err)
+	}
+
+	// Two concepts and two question cards.
+	if n != 4 {
+		t.Fatalf("Rederive() = %d, want 4", n)
+	}
+}
+
+func TestQuestionCardSession(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedQuestionTree(t, db)
+
+	mustExec(t, db, `UPDATE question_cards SET status = 'learning', next_review = '2026-01-01T09:00:00Z' WHERE lesson_id = 'l1'`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, review_count, interval_days) VALUES
+		('c1', 'reviewing', '2026-01-02T09:00:00Z', 2, 3)`)
+
+	repo := repository.NewReviewRepository(db, db)
+	now := time.Now().UTC()
+
+	session, err := repo.CreateSession(ctx, review.DueCutoff(now), models.SessionOptions{NewLimit: 5, ReviewLimit: 5},
+		func(reviews, newCards []models.ReviewCard) []models.ReviewCard { return append(newCards, reviews...) })
+	if err != nil || session.Total != 3 {
+		t.Fatalf("CreateSession() = %+v, %v; want 2 questions and c1", session, err)
+	}
+
+	card, err := repo.NextSessionCard(ctx, session.ID)
+	if err != nil || card == nil || card.Kind != models.CardKindQuestion || !card.New {
+		t.Fatalf("NextSessionCard() = %+v, %v; want a new question card", card, err)
+	}
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	schedule := func(rating string) func(models.ConceptRetention) (models.ConceptRetention, error) {
+		return func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, rating, now)
+		}
+	}
+
+	if _, err := repo.AnswerSessionCard(ctx, session.ID, "c1", 0, schedule(models.RatingGood)); !errors.Is(err, repository.ErrWrongCard) {
+		t.Fatalf("AnswerSessionCard(wrong card) error = %v, want ErrWrongCard", err)
+	}
+
+	answer, err := repo.AnswerSessionCard(ctx, session.ID, card.QuestionID, 0, schedule(models.RatingForgot))
+	if err != nil || answer.QuestionID != card.QuestionID || answer.Remaining != 2 {
+		t.Fatalf("AnswerSessionCard(question) = %+v, %v", answer, err)
+	}
+
+	for range 2 {
+		if answer, err = repo.AnswerSessionCard(ctx, session.ID, "", 0, schedule(models.RatingGood)); err != nil {
+			t.Fatalf("AnswerSessionCard() error = %v", err)
+		}
+	}
+
+	if answer.Summary == nil || answer.Summary.Reviewed != 3 || len(answer.Summary.Restudy) != 1 ||
+		answer.Summary.Restudy[0].QuestionID != card.QuestionID || answer.Summary.Restudy[0].LessonID != "l1" {
+		t.Fatalf("session summary = %+v, want the forgotten question linked to l1", answer.Summary)
+	}
+}
@@ -24,13 +24,16 @@ type ResearchJobRepository interface {
 }
 
 // SQLiteResearchJobRepository implements ResearchJobRepository using SQLite.
+// Reads use the read pool so job polling is never queued behind an ingest
+// transaction on the write handle.
 type SQLiteResearchJobRepository struct {
-	db *sql.DB
+	readDB *sql.DB
+	db     *sql.DB
 }
 
 // NewResearchJobRepository creates a new SQLiteResearchJobRepository.
-func NewResearchJobRepository(db *sql.DB) *SQLiteResearchJobRepository {
-	return &SQLiteResearchJobRepository{db: db}
+func NewResearchJobRepository(readDB, writeDB *sql.DB) *SQLiteResearchJobRepository {
+	return &SQLiteResearchJobRepository{readDB: readDB, db: writeDB}
 }
 
 const createJobSQL = `
@@ -39,7 +42,7 @@ VALUES (?, ?, ?, ?)
 `
 
 const getJobByIDSQL = `
-SELECT id, COALESCE(root_topic, ''), COALESCE(current_topic, ''), status,
+SELECT id, kind, COALESCE(concept_id, ''), COALESCE(root_topic, ''), COALESCE(current_topic, ''), status,
        COALESCE(progress, ''), COALESCE(error, ''),
        COALESCE(started_at, ''), COALESCE(completed_at, '')
 FROM research_jobs
@@ -63,8 +66,8 @@ func (r *SQLiteResearchJobRepository) GetJobByID(ctx context.Context, id string)
 	job := &models.ResearchJob{}
 	var progressStr, errStr string
 
-	err := r.db.QueryRowContext(ctx, getJobByIDSQL, id).Scan(
-		&job.ID, &job.RootTopic, &job.CurrentTopic, &job.Status,
+	err := r.readDB.QueryRowContext(ctx, getJobByIDSQL, id).Scan(
+		&job.ID, &job.Kind, &job.ConceptID, &job.RootTopic, &job.CurrentTopic, &job.Status,
 		&progressStr, &errStr,
 		&job.StartedAt, &job.CompletedAt,
 	)
@@ -87,7 +90,7 @@ func (r *SQLiteResearchJobRepository) GetJobByID(ctx context.Context, id string)
 const countJobsSQL = `SELECT COUNT(*) FROM research_jobs`
 
 const listJobsSQL = `
-SELECT id, COALESCE(root_topic, ''), status,
+SELECT id, kind, COALESCE(root_topic, ''), status,
        COALESCE(started_at, ''), COALESCE(completed_at, '')
 FROM research_jobs
 ORDER BY rowid DESC
@@ -96,11 +99,11 @@ LIMIT ? OFFSET ?
 
 func (r *SQLiteResearchJobRepository) ListJobs(ctx context.Context, params models.PaginationParams) (*models.PaginatedResponse[models.ResearchJobSummary], error) {
 	var total int
-	if err := r.db.QueryRowContext(ctx, countJobsSQL).Scan(&total); err != nil {
+	if err := r.readDB.QueryRowContext(ctx, countJobsSQL).Scan(&total); err != nil {
 		return nil, fmt.Errorf("count research jobs: %w", err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, listJobsSQL, params.PerPage, params.Offset())
+	rows, err := r.readDB.QueryContext(ctx, listJobsSQL, params.PerPage, params.Offset())
 	if err != nil {
 		return nil, fmt.Errorf("list research jobs: %w", err)
 	}
@@ -110,7 +113,7 @@ func (r *SQLiteResearchJobRepository) ListJobs(ctx context.Context, params model
 
 	for rows.Next() {
 		var job models.ResearchJobSummary
-		if err := rows.Scan(&job.ID, &job.RootTopic, &job.Status, &job.StartedAt, &job.CompletedAt); err != nil {
+		if err := rows.Scan(&job.ID, &job.Kind, &job.RootTopic, &job.Status, &job.StartedAt, &job.CompletedAt); err != nil {
 			return nil, fmt.Errorf("scan research job summary: %w", err)
 		}
 
@@ -227,7 +230,7 @@ SELECT id FROM research_jobs WHERE status = ? ORDER BY rowid ASC LIMIT 1
 func (r *SQLiteResearchJobRepository) FindOldestByStatus(ctx context.Context, status string) (string, error) {
 	var id string
 
-	err := r.db.QueryRowContext(ctx, findOldestByStatusSQL, status).Scan(&id)
+	err := r.readDB.QueryRowContext(ctx, findOldestByStatusSQL, status).Scan(&id)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -12,7 +12,7 @@ import (
 
 func TestCreateJob(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	job, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{
 		Topic: "Go Concurrency",
@@ -40,7 +40,7 @@ func TestCreateJob(t *testing.T) {
 
 func TestGetJobByID(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	created, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{
 		Topic: "Rust Lifetimes",
@@ -65,7 +65,7 @@ func TestGetJobByID(t *testing.T) {
 
 func TestGetJobByIDNotFound(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	_, err := repo.GetJobByID(context.Background(), "nonexistent")
 	if !errors.Is(err, repository.ErrNotFound) {
@@ -75,7 +75,7 @@ func TestGetJobByIDNotFound(t *testing.T) {
 
 func TestListJobs(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	// Empty list.
 	list, err := repo.ListJobs(context.Background(), models.PaginationParams{Page: 1, PerPage: 10})
@@ -119,7 +119,7 @@ func TestListJobs(t *testing.T) {
 
 func TestListJobsPagination(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	for _, topic := range []string{"A", "B", "C"} {
 		if _, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{Topic: topic}); err != nil {
@@ -152,7 +152,7 @@ func TestListJobsPagination(t *testing.T) {
 
 func TestUpdateJobStatus(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	job, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{Topic: "Test Topic"})
 	if err != nil {
@@ -198,7 +198,7 @@ func TestUpdateJobStatus(t *testing.T) {
 
 func TestUpdateJobStatusFailed(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	job, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{Topic: "Fail Topic"})
 	if err != nil {
@@ -229,7 +229,7 @@ func TestUpdateJobStatusFailed(t *testing.T) {
 
 func TestUpdateJobStatusNotFound(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	err := repo.UpdateJobStatus(context.Background(), "nonexistent", models.ResearchStatusFailed, "")
 	if !errors.Is(err, repository.ErrNotFound) {
@@ -239,7 +239,7 @@ func TestUpdateJobStatusNotFound(t *testing.T) {
 
 func TestUpdateJobProgress(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	job, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{Topic: "Progress Topic"})
 	if err != nil {
@@ -283,7 +283,7 @@ func TestUpdateJobProgress(t *testing.T) {
 
 func TestUpdateJobProgressNotFound(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	err := repo.UpdateJobProgress(context.Background(), "nonexistent", models.ResearchProgress{})
 	if !errors.Is(err, repository.ErrNotFound) {
@@ -293,7 +293,7 @@ func TestUpdateJobProgressNotFound(t *testing.T) {
 
 func TestUpdateJobStatusStartedAtNotOverwritten(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	job, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{Topic: "Sticky Start"})
 	if err != nil {
@@ -332,7 +332,7 @@ func TestUpdateJobStatusStartedAtNotOverwritten(t *testing.T) {
 
 func TestUpdateJobCurrentTopic(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	job, err := repo.CreateJob(context.Background(), models.CreateResearchJobInput{Topic: "Original"})
 	if err != nil {
@@ -355,7 +355,7 @@ func TestUpdateJobCurrentTopic(t *testing.T) {
 
 func TestUpdateJobCurrentTopicNotFound(t *testing.T) {
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 
 	err := repo.UpdateJobCurrentTopic(context.Background(), "nonexistent", "topic")
 	if !errors.Is(err, repository.ErrNotFound) {
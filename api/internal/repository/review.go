@@ -0,0 +1,607 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// ReplayFunc re-derives a concept's review state from its history, oldest
+// review first.
+type ReplayFunc func(current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error)
+
+// ArrangeFunc orders the cards of a new review session.
+type ArrangeFunc func(reviews, newCards []models.ReviewCard) []models.ReviewCard
+
+// ReviewRepository stores spaced repetition state in concept_retention,
+// question_cards, and generated_cards, and every rating in review_log,
+// question_review_log, and generated_card_review_log.
+// Scheduling itself lives in the review package; RecordReview hands the
+// current state to a schedule function and stores what it returns.
+type ReviewRepository interface {
+	// ListDue returns learning and reviewing concept, question, and
+	// generated cards whose next review is before dueBefore, soonest first.
+	// New and mastered cards are not due.
+	ListDue(ctx context.Context, dueBefore time.Time) ([]models.ReviewCard, error)
+	// RecordReview applies schedule to the concept's retention state inside
+	// one transaction and logs the review with its response time, zero when
+	// untimed. A concept without state starts from a new one.
+	RecordReview(ctx context.Context, conceptID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.ConceptRetention, error)
+	// RecordQuestionReview applies schedule to the question card's state
+	// and, in the same transaction, reviews every concept it tests that is
+	// in review, as RecordReview would.
+	RecordQuestionReview(ctx context.Context, questionID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.QuestionAnswer, error)
+	// SyncQuestions syncs every lesson's and module's question cards with
+	// their questions and returns how many cards there are.
+	SyncQuestions(ctx context.Context) (int, error)
+	// RecordCardReview applies schedule to the generated card's state. The
+	// concept it was generated from keeps its own.
+	RecordCardReview(ctx context.Context, cardID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.CardAnswer, error)
+	// SyncCards regenerates every topic's generated cards and returns how
+	// many cards there are.
+	SyncCards(ctx context.Context) (int, error)
+	// GetCardKinds returns the kinds of generated cards the topic enables.
+	GetCardKinds(ctx context.Context, topicID string) (*models.TopicCardKinds, error)
+	// SetCardKinds replaces the kinds of generated cards the topic enables
+	// and regenerates its cards in the same transaction. Cards of a kind no
+	// longer enabled are deleted with their history.
+	SetCardKinds(ctx context.Context, topicID string, kinds []string) (*models.TopicCardKinds, error)
+	// GetStats counts reviews for the UTC day ending at dayEnd.
+	GetStats(ctx context.Context, dayEnd time.Time) (*models.ReviewStats, error)
+	// GetAnalytics summarizes review_log over the days days ending at
+	// dayEnd and forecasts the reviews due over the days days from it.
+	GetAnalytics(ctx context.Context, dayEnd time.Time, days int) (*models.ReviewAnalytics, error)
+	// CreateSession abandons any active session and starts one with the
+	// cards due before dayEnd, within opts, in the order arrange gives.
+	CreateSession(ctx context.Context, dayEnd time.Time, opts models.SessionOptions, arrange ArrangeFunc) (*models.ReviewSession, error)
+	// GetSession returns a session with the summary of its answered cards.
+	GetSession(ctx context.Context, id string) (*models.ReviewSession, error)
+	// NextSessionCard returns the session's first unanswered card, or nil
+	// when none is left.
+	NextSessionCard(ctx context.Context, id string) (*models.SessionCard, error)
+	// AnswerSessionCard reviews the session's next card as RecordReview,
+	// RecordQuestionReview, or RecordCardReview does and completes the
+	// session after its last card. A non-empty cardID must name that card's
+	// concept, question, or generated card.
+	AnswerSessionCard(ctx context.Context, id, cardID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.SessionAnswer, error)
+	// ListRetention returns every concept's review state.
+	ListRetention(ctx context.Context) ([]models.ConceptRetention, error)
+	// ListHistory returns each concept's reviews since its last reset,
+	// oldest first.
+	ListHistory(ctx context.Context) (map[string][]models.ReviewEvent, error)
+	// GetSchedulerPreference returns the scheduler chosen at runtime, or ""
+	// when none has been.
+	GetSchedulerPreference(ctx context.Context) (string, error)
+	// SwitchScheduler stores name as the preferred scheduler and re-derives
+	// the state of concepts and cards held by another scheduler, in one
+	// transaction. It returns how many were re-derived.
+	SwitchScheduler(ctx context.Context, name string, replay ReplayFunc) (int, error)
+	// Rederive re-derives the state of concepts, question cards, and
+	// generated cards held by a scheduler other than name and returns how
+	// many there were.
+	Rederive(ctx context.Context, name string, replay ReplayFunc) (int, error)
+}
+
+// SQLiteReviewRepository implements ReviewRepository using SQLite. Reads use
+// the read pool; reviews use the write handle.
+type SQLiteReviewRepository struct {
+	readDB         *sql.DB
+	db             *sql.DB
+	leechThreshold int
+}
+
+// NewReviewRepository creates a new SQLiteReviewRepository.
+func NewReviewRepository(readDB, writeDB *sql.DB) *SQLiteReviewRepository {
+	return &SQLiteReviewRepository{readDB: readDB, db: writeDB}
+}
+
+// WithLeechThreshold sets the lapses after which a review suspends a
+// concept as a leech, 0 to never suspend, and returns the repository.
+func (r *SQLiteReviewRepository) WithLeechThreshold(lapses int) *SQLiteReviewRepository {
+	r.leechThreshold = lapses
+
+	return r
+}
+
+// reviewCardColumns selects a concept's models.ReviewCard from
+// concept_retention cr joined to concepts c. questionCardColumns and
+// generatedCardColumns select the same columns for the other cards.
+const reviewCardColumns = `
+cr.concept_id, cr.status, COALESCE(cr.next_review, ''), cr.review_count,
+cr.ease_factor, cr.interval_days, COALESCE(cr.last_reviewed, ''), COALESCE(cr.last_rating, ''),
+COALESCE(cr.stability, 0), COALESCE(cr.difficulty, 0), cr.scheduler,
+cr.lapses, COALESCE(cr.suspended_at, ''),
+c.name, c.definition, COALESCE(c.flashcard_front, ''), COALESCE(c.flashcard_back, ''),
+COALESCE(c.defined_in_lesson, ''), COALESCE(c.defined_in_topic, ''),
+'concept', '', '', '', ''
+`
+
+const listDueReviewsSQL = `
+SELECT ` + reviewCardColumns + `
+FROM concept_retention cr
+JOIN concepts c ON c.id = cr.concept_id
+WHERE cr.status IN ('learning', 'reviewing') AND cr.suspended_at IS NULL
+  AND cr.next_review IS NOT NULL AND julianday(cr.next_review) < julianday(?)
+ORDER BY julianday(cr.next_review), cr.concept_id
+`
+
+const listDueQuestionsSQL = `
+SELECT ` + questionCardColumns + `
+FROM question_cards q
+JOIN modules m ON m.id = q.module_id
+WHERE q.status IN ('learning', 'reviewing')
+  AND q.next_review IS NOT NULL AND julianday(q.next_review) < julianday(?)
+ORDER BY julianday(q.next_review), q.id
+`
+
+const listDueCardsSQL = `
+SELECT ` + generatedCardColumns + `
+FROM generated_cards g
+JOIN concepts c ON c.id = g.concept_id
+WHERE g.status IN ('learning', 'reviewing')
+  AND g.next_review IS NOT NULL AND julianday(g.next_review) < julianday(?)
+ORDER BY julianday(g.next_review), g.id
+`
+
+func (r *SQLiteReviewRepository) ListDue(ctx context.Context, dueBefore time.Time) ([]models.ReviewCard, error) {
+	before := dueBefore.UTC().Format(time.RFC3339)
+
+	concepts, err := queryReviewCards(ctx, r.readDB, listDueReviewsSQL, before)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := queryReviewCards(ctx, r.readDB, listDueQuestionsSQL, before)
+	if err != nil {
+		return nil, err
+	}
+
+	generated, err := queryReviewCards(ctx, r.readDB, listDueCardsSQL, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeDue(concepts, questions, generated), nil
+}
+
+// mergeDue interleaves due concept, question, and generated cards, each
+// already sorted, soonest first. Among cards due together, concepts go
+// first, then questions.
+func mergeDue(concepts []models.ReviewCard, others ...[]models.ReviewCard) []models.ReviewCard {
+	cards := slices.Concat(append([][]models.ReviewCard{concepts}, others...)...)
+
+	slices.SortStableFunc(cards, func(a, b models.ReviewCard) int {
+		return strings.Compare(a.NextReview, b.NextReview)
+	})
+
+	return cards
+}
+
+func queryReviewCards(ctx context.Context, q queryer, query string, args ...any) ([]models.ReviewCard, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query due reviews: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviewCards(rows)
+}
+
+func scanReviewCards(rows *sql.Rows) ([]models.ReviewCard, error) {
+	cards := []models.ReviewCard{}
+
+	for rows.Next() {
+		var c models.ReviewCard
+		if err := scanReviewCard(rows, &c); err != nil {
+			return nil, fmt.Errorf("scan due review: %w", err)
+		}
+
+		cards = append(cards, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate due reviews: %w", err)
+	}
+
+	return cards, nil
+}
+
+// scanReviewCard scans reviewCardColumns, questionCardColumns, or
+// generatedCardColumns, plus any extra destinations.
+func scanReviewCard(row rowScanner, c *models.ReviewCard, extra ...any) error {
+	var conceptIDs string
+
+	if err := row.Scan(append([]any{
+		&c.ConceptID, &c.Status, &c.NextReview, &c.ReviewCount,
+		&c.EaseFactor, &c.IntervalDays, &c.LastReviewed, &c.LastRating,
+		&c.Stability, &c.Difficulty, &c.Scheduler, &c.Lapses, &c.SuspendedAt, &c.Name, &c.Definition, &c.FlashcardFront, &c.FlashcardBack,
+		&c.LessonID, &c.TopicID, &c.Kind, &c.QuestionID, &c.CardID, &conceptIDs, &c.ModuleID,
+	}, extra...)...); err != nil {
+		return err
+	}
+
+	if conceptIDs != "" {
+		c.ConceptIDs = strings.Split(conceptIDs, ",")
+	}
+
+	return nil
+}
+
+const conceptExistsSQL = `SELECT EXISTS(SELECT 1 FROM concepts WHERE id = ?)`
+
+const retentionColumns = `
+concept_id, status, COALESCE(next_review, ''), review_count, ease_factor,
+interval_days, COALESCE(last_reviewed, ''), COALESCE(last_rating, ''),
+COALESCE(stability, 0), COALESCE(difficulty, 0), scheduler,
+lapses, COALESCE(suspended_at, '')
+`
+
+const getRetentionSQL = `SELECT ` + retentionColumns + ` FROM concept_retention WHERE concept_id = ?`
+
+const upsertRetentionSQL = `
+INSERT INTO concept_retention (concept_id, status, next_review, review_count, ease_factor,
+                               interval_days, last_reviewed, last_rating, stability, difficulty, scheduler,
+                               lapses, suspended_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(concept_id) DO UPDATE SET
+  status = excluded.status, next_review = excluded.next_review,
+  review_count = excluded.review_count, ease_factor = excluded.ease_factor,
+  interval_days = excluded.interval_days, last_reviewed = excluded.last_reviewed,
+  last_rating = excluded.last_rating, stability = excluded.stability,
+  difficulty = excluded.difficulty, scheduler = excluded.scheduler,
+  lapses = excluded.lapses, suspended_at = excluded.suspended_at
+`
+
+const insertReviewLogSQL = `
+INSERT INTO review_log (concept_id, rating, reviewed_at, previous_interval, new_interval, ease_factor, response_ms, question_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+func (r *SQLiteReviewRepository) RecordReview(ctx context.Context, conceptID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.ConceptRetention, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	next, err := r.applyReview(ctx, tx, conceptID, responseMS, "", schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return next, nil
+}
+
+// applyReview schedules the concept's next review, stores the new state, and
+// logs the review, naming the question it came from when questionID is set.
+// A forgotten concept gains a lapse and is suspended once its lapses reach
+// the leech threshold.
+func (r *SQLiteReviewRepository) applyReview(ctx context.Context, q queryer, conceptID string, responseMS int, questionID string, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.ConceptRetention, error) {
+	current, err := loadRetention(ctx, q, conceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := schedule(*current)
+	if err != nil {
+		return nil, err
+	}
+
+	next.ConceptID = conceptID
+	next.Lapses = current.Lapses
+	next.SuspendedAt = current.SuspendedAt
+
+	if next.LastRating == models.RatingForgot {
+		next.Lapses++
+
+		if r.leechThreshold > 0 && next.Lapses >= r.leechThreshold && next.SuspendedAt == "" {
+			next.SuspendedAt = next.LastReviewed
+		}
+	}
+
+	if err := storeRetention(ctx, q, next); err != nil {
+		return nil, err
+	}
+
+	if _, err := q.ExecContext(ctx, insertReviewLogSQL,
+		conceptID, next.LastRating, next.LastReviewed,
+		current.IntervalDays, next.IntervalDays, next.EaseFactor, nullIfZero(responseMS), nullIfEmpty(questionID),
+	); err != nil {
+		return nil, classifyError(err, "log concept "+conceptID+" review")
+	}
+
+	return &next, nil
+}
+
+func storeRetention(ctx context.Context, q queryer, s models.ConceptRetention) error {
+	if _, err := q.ExecContext(ctx, upsertRetentionSQL,
+		s.ConceptID, s.Status, nullIfEmpty(s.NextReview), s.ReviewCount, s.EaseFactor,
+		s.IntervalDays, nullIfEmpty(s.LastReviewed), nullIfEmpty(s.LastRating),
+		nullIfZeroFloat(s.Stability), nullIfZeroFloat(s.Difficulty), s.Scheduler,
+		s.Lapses, nullIfEmpty(s.SuspendedAt),
+	); err != nil {
+		return classifyError(err, "store concept "+s.ConceptID+" review")
+	}
+
+	return nil
+}
+
+// loadRetention returns the concept's review state, a new state when it has
+// none, or ErrNotFound when the concept does not exist.
+func loadRetention(ctx context.Context, q queryer, conceptID string) (*models.ConceptRetention, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, conceptExistsSQL, conceptID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check concept %s: %w", conceptID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("concept %s: %w", conceptID, ErrNotFound)
+	}
+
+	s := &models.ConceptRetention{}
+
+	err := scanRetention(q.QueryRowContext(ctx, getRetentionSQL, conceptID), s)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.ConceptRetention{
+			ConceptID:  conceptID,
+			Status:     models.RetentionStatusNew,
+			EaseFactor: models.DefaultEaseFactor,
+		}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("load concept %s retention: %w", conceptID, err)
+	}
+
+	return s, nil
+}
+
+// getReviewStatsSQL takes the day's start, its end, and the end of the
+// upcoming week. Due and upcoming reviews include question and generated
+// cards.
+const getReviewStatsSQL = `
+SELECT
+  COALESCE(SUM(CASE WHEN status IN ('learning', 'reviewing') AND suspended_at IS NULL AND next_review IS NOT NULL
+                     AND julianday(next_review) < julianday(?2) THEN 1 ELSE 0 END), 0),
+  COALESCE(SUM(CASE WHEN status IN ('learning', 'reviewing') AND suspended_at IS NULL AND next_review IS NOT NULL
+                     AND julianday(next_review) >= julianday(?2)
+                     AND julianday(next_review) < julianday(?3) THEN 1 ELSE 0 END), 0),
+  COALESCE(SUM(CASE WHEN last_reviewed IS NOT NULL
+                     AND julianday(last_reviewed) >= julianday(?1) THEN 1 ELSE 0 END), 0),
+  COALESCE(SUM(status = 'new'), 0),
+  COALESCE(SUM(status = 'learning'), 0),
+  COALESCE(SUM(status = 'reviewing'), 0),
+  COALESCE(SUM(status = 'mastered'), 0),
+  COALESCE(SUM(suspended_at IS NOT NULL), 0)
+FROM concept_retention
+`
+
+const getCardStatsSQL = `
+SELECT
+  COALESCE(SUM(CASE WHEN status IN ('learning', 'reviewing') AND next_review IS NOT NULL
+                     AND julianday(next_review) < julianday(?1) THEN 1 ELSE 0 END), 0),
+  COALESCE(SUM(CASE WHEN status IN ('learning', 'reviewing') AND next_review IS NOT NULL
+                     AND julianday(next_review) >= julianday(?1)
+                     AND julianday(next_review) < julianday(?2) THEN 1 ELSE 0 END), 0)
+FROM (
+  SELECT status, next_review FROM question_cards
+  UNION ALL
+  SELECT status, next_review FROM generated_cards
+)
+`
+
+func (r *SQLiteReviewRepository) GetStats(ctx context.Context, dayEnd time.Time) (*models.ReviewStats, error) {
+	dayEnd = dayEnd.UTC()
+	s := &models.ReviewStats{}
+
+	if err := r.readDB.QueryRowContext(ctx, getReviewStatsSQL,
+		dayEnd.AddDate(0, 0, -1).Format(time.RFC3339),
+		dayEnd.Format(time.RFC3339),
+		dayEnd.AddDate(0, 0, 7).Format(time.RFC3339),
+	).Scan(&s.DueToday, &s.Upcoming, &s.ReviewedToday, &s.New, &s.Learning, &s.Reviewing, &s.Mastered, &s.Suspended); err != nil {
+		return nil, fmt.Errorf("query review stats: %w", err)
+	}
+
+	var due, upcoming int
+	if err := r.readDB.QueryRowContext(ctx, getCardStatsSQL,
+		dayEnd.Format(time.RFC3339),
+		dayEnd.AddDate(0, 0, 7).Format(time.RFC3339),
+	).Scan(&due, &upcoming); err != nil {
+		return nil, fmt.Errorf("query card stats: %w", err)
+	}
+
+	s.DueToday += due
+	s.Upcoming += upcoming
+
+	return s, nil
+}
+
+func scanRetention(row rowScanner, s *models.ConceptRetention) error {
+	return row.Scan(
+		&s.ConceptID, &s.Status, &s.NextReview, &s.ReviewCount, &s.EaseFactor,
+		&s.IntervalDays, &s.LastReviewed, &s.LastRating,
+		&s.Stability, &s.Difficulty, &s.Scheduler, &s.Lapses, &s.SuspendedAt,
+	)
+}
+
+const listRetentionSQL = `SELECT ` + retentionColumns + ` FROM concept_retention ORDER BY concept_id`
+
+func (r *SQLiteReviewRepository) ListRetention(ctx context.Context) ([]models.ConceptRetention, error) {
+	return listRetention(ctx, r.readDB, listRetentionSQL)
+}
+
+func listRetention(ctx context.Context, q queryer, query string, args ...any) ([]models.ConceptRetention, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query review state: %w", err)
+	}
+	defer rows.Close()
+
+	states := []models.ConceptRetention{}
+
+	for rows.Next() {
+		var s models.ConceptRetention
+		if err := scanRetention(rows, &s); err != nil {
+			return nil, fmt.Errorf("scan review state: %w", err)
+		}
+
+		states = append(states, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate review state: %w", err)
+	}
+
+	return states, nil
+}
+
+// Reviews are replayed in the order they happened. A merge can interleave
+// two concepts' logs, so insertion order alone is not enough. Reviews
+// logged before a concept's reset_at belong to the state the reset threw
+// away and are left out.
+const listReviewLogSQL = `
+SELECT l.concept_id, l.rating, l.reviewed_at
+FROM review_log l
+LEFT JOIN concept_retention r ON r.concept_id = l.concept_id
+WHERE r.reset_at IS NULL OR julianday(l.reviewed_at) > julianday(r.reset_at)
+ORDER BY l.concept_id, julianday(l.reviewed_at), l.id
+`
+
+func (r *SQLiteReviewRepository) ListHistory(ctx context.Context) (map[string][]models.ReviewEvent, error) {
+	return listHistory(ctx, r.readDB)
+}
+
+func listHistory(ctx context.Context, q queryer) (map[string][]models.ReviewEvent, error) {
+	rows, err := q.QueryContext(ctx, listReviewLogSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query review log: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[string][]models.ReviewEvent)
+
+	for rows.Next() {
+		var conceptID string
+		var e models.ReviewEvent
+		if err := rows.Scan(&conceptID, &e.Rating, &e.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("scan review log: %w", err)
+		}
+
+		history[conceptID] = append(history[conceptID], e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate review log: %w", err)
+	}
+
+	return history, nil
+}
+
+// schedulerSettingKey is the settings key holding the preferred scheduler.
+const schedulerSettingKey = "review_scheduler"
+
+const getSettingSQL = `SELECT value FROM settings WHERE key = ?`
+
+const upsertSettingSQL = `
+INSERT INTO settings (key, value) VALUES (?, ?)
+ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+`
+
+func (r *SQLiteReviewRepository) GetSchedulerPreference(ctx context.Context) (string, error) {
+	return loadSchedulerPreference(ctx, r.readDB)
+}
+
+func loadSchedulerPreference(ctx context.Context, q RowQueryer) (string, error) {
+	var name string
+
+	err := q.QueryRowContext(ctx, getSettingSQL, schedulerSettingKey).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("load scheduler preference: %w", err)
+	}
+
+	return name, nil
+}
+
+func (r *SQLiteReviewRepository) SwitchScheduler(ctx context.Context, name string, replay ReplayFunc) (int, error) {
+	return r.rederive(ctx, name, replay, true)
+}
+
+func (r *SQLiteReviewRepository) Rederive(ctx context.Context, name string, replay ReplayFunc) (int, error) {
+	return r.rederive(ctx, name, replay, false)
+}
+
+const listForeignRetentionSQL = `SELECT ` + retentionColumns + ` FROM concept_retention WHERE scheduler <> ? ORDER BY concept_id`
+
+func (r *SQLiteReviewRepository) rederive(ctx context.Context, name string, replay ReplayFunc, prefer bool) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if prefer {
+		if _, err := tx.ExecContext(ctx, upsertSettingSQL, schedulerSettingKey, name); err != nil {
+			return 0, fmt.Errorf("store scheduler preference: %w", err)
+		}
+	}
+
+	states, err := listRetention(ctx, tx, listForeignRetentionSQL, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var history map[string][]models.ReviewEvent
+	if len(states) > 0 {
+		if history, err = listHistory(ctx, tx); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, current := range states {
+		next, err := replay(current, history[current.ConceptID])
+		if err != nil {
+			return 0, err
+		}
+
+		next.ConceptID = current.ConceptID
+		next.Lapses = current.Lapses
+		next.SuspendedAt = current.SuspendedAt
+
+		if err := storeRetention(ctx, tx, next); err != nil {
+			return 0, err
+		}
+	}
+
+	questions, err := questionStore.rederive(ctx, tx, name, replay)
+	if err != nil {
+		return 0, err
+	}
+
+	generated, err := generatedStore.rederive(ctx, tx, name, replay)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(states) + questions + generated, nil
+}
+
+// Verify interface compliance at compile time.
+var _ ReviewRepository = (*SQLiteReviewRepository)(nil)
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// hardestConceptsLimit caps the hardest concepts in the analytics.
+const hardestConceptsLimit = 10
+
+// A recall review tests a concept already past its first review, so its
+// rating measures retention rather than first exposure. Reviews logged
+// before previous_interval was recorded count as recall.
+const (
+	recallReviewSQL   = `COALESCE(l.previous_interval, 1) > 0`
+	recalledReviewSQL = recallReviewSQL + ` AND l.rating <> 'forgot'`
+)
+
+// reviewWindowSQL limits review_log to [?1, ?2).
+const reviewWindowSQL = `julianday(l.reviewed_at) >= julianday(?1) AND julianday(l.reviewed_at) < julianday(?2)`
+
+const dailyReviewsSQL = `
+SELECT date(l.reviewed_at), COUNT(*),
+       COALESCE(SUM(` + recallReviewSQL + `), 0),
+       COALESCE(SUM(` + recalledReviewSQL + `), 0)
+FROM review_log l
+WHERE ` + reviewWindowSQL + `
+GROUP BY date(l.reviewed_at)
+`
+
+// dueForecastSQL groups the concept, question, and generated card reviews
+// due before ?2 by day. Those due before ?1, the end of today, share an empty day.
+const dueForecastSQL = `
+SELECT CASE WHEN julianday(next_review) < julianday(?1) THEN '' ELSE date(next_review) END AS day, COUNT(*)
+FROM (
+  SELECT next_review FROM concept_retention
+  WHERE status IN ('learning', 'reviewing') AND suspended_at IS NULL
+  UNION ALL
+  SELECT next_review FROM question_cards
+  WHERE status IN ('learning', 'reviewing')
+  UNION ALL
+  SELECT next_review FROM generated_cards
+  WHERE status IN ('learning', 'reviewing')
+)
+WHERE next_review IS NOT NULL AND julianday(next_review) < julianday(?2)
+GROUP BY day
+`
+
+const hardestConceptsSQL = `
+SELECT l.concept_id, c.name, COALESCE(c.defined_in_topic, ''), COUNT(*) AS reviews,
+       SUM(l.rating = 'forgot') AS forgotten,
+       COALESCE(cr.ease_factor, 2.5) AS ease, COALESCE(cr.difficulty, 0)
+FROM review_log l
+JOIN concepts c ON c.id = l.concept_id
+LEFT JOIN concept_retention cr ON cr.concept_id = l.concept_id
+WHERE ` + reviewWindowSQL + `
+GROUP BY l.concept_id
+HAVING forgotten > 0
+ORDER BY forgotten DESC, forgotten * 1.0 / reviews DESC, ease, l.concept_id
+LIMIT ?3
+`
+
+const topicRetentionSQL = `
+SELECT t.id, t.title, COUNT(DISTINCT l.concept_id), COUNT(*),
+       COALESCE(SUM(` + recallReviewSQL + `), 0),
+       COALESCE(SUM(` + recalledReviewSQL + `), 0)
+FROM review_log l
+JOIN concepts c ON c.id = l.concept_id
+JOIN topics t ON t.id = c.defined_in_topic
+WHERE ` + reviewWindowSQL + `
+GROUP BY t.id
+`
+
+func (r *SQLiteReviewRepository) GetAnalytics(ctx context.Context, dayEnd time.Time, days int) (*models.ReviewAnalytics, error) {
+	dayEnd = dayEnd.UTC()
+	from := dayEnd.AddDate(0, 0, -days).Format(time.RFC3339)
+	to := dayEnd.Format(time.RFC3339)
+
+	a := &models.ReviewAnalytics{Days: days}
+
+	daily, err := r.dailyReviews(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var recall, recalled int
+
+	a.Daily = make([]models.DailyReviews, days)
+
+	for i := range a.Daily {
+		date := dayEnd.AddDate(0, 0, i-days).Format(time.DateOnly)
+
+		d := daily[date]
+		d.Date = date
+		d.RetentionRate = ratio(d.Recalled, d.Recall)
+		a.Daily[i] = d
+
+		a.Reviews += d.Reviews
+		recall += d.Recall
+		recalled += d.Recalled
+	}
+
+	a.RetentionRate = ratio(recalled, recall)
+
+	if a.Forecast, err = r.dueForecast(ctx, dayEnd, days); err != nil {
+		return nil, err
+	}
+
+	if a.Hardest, err = r.hardestConcepts(ctx, from, to); err != nil {
+		return nil, err
+	}
+
+	if a.Topics, err = r.topicRetention(ctx, from, to); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// dailyReviews returns the review counts in [from, to) by date.
+func (r *SQLiteReviewRepository) dailyReviews(ctx context.Context, from, to string) (map[string]models.DailyReviews, error) {
+	rows, err := r.readDB.QueryContext(ctx, dailyReviewsSQL, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query daily reviews: %w", err)
+	}
+	defer rows.Close()
+
+	daily := make(map[string]models.DailyReviews)
+
+	for rows.Next() {
+		var d models.DailyReviews
+		if err := rows.Scan(&d.Date, &d.Reviews, &d.Recall, &d.Recalled); err != nil {
+			return nil, fmt.Errorf("scan daily reviews: %w", err)
+		}
+
+		daily[d.Date] = d
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate daily reviews: %w", err)
+	}
+
+	return daily, nil
+}
+
+// dueForecast returns the reviews due on each of the days days starting with
+// the one ending at dayEnd, counting overdue reviews as due today.
+func (r *SQLiteReviewRepository) dueForecast(ctx context.Context, dayEnd time.Time, days int) ([]models.DueForecast, error) {
+	rows, err := r.readDB.QueryContext(ctx, dueForecastSQL,
+		dayEnd.Format(time.RFC3339), dayEnd.AddDate(0, 0, days-1).Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("query due forecast: %w", err)
+	}
+	defer rows.Close()
+
+	due := make(map[string]int)
+
+	for rows.Next() {
+		var date string
+		var n int
+		if err := rows.Scan(&date, &n); err != nil {
+			return nil, fmt.Errorf("scan due forecast: %w", err)
+		}
+
+		due[date] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate due forecast: %w", err)
+	}
+
+	forecast := make([]models.DueForecast, days)
+
+	for i := range forecast {
+		date := dayEnd.AddDate(0, 0, i-1).Format(time.DateOnly)
+		forecast[i] = models.DueForecast{Date: date, Due: due[date]}
+	}
+
+	forecast[0].Due += due[""]
+
+	return forecast, nil
+}
+
+func (r *SQLiteReviewRepository) hardestConcepts(ctx context.Context, from, to string) ([]models.HardConcept, error) {
+	rows, err := r.readDB.QueryContext(ctx, hardestConceptsSQL, from, to, hardestConceptsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("query hardest concepts: %w", err)
+	}
+	defer rows.Close()
+
+	hardest := []models.HardConcept{}
+
+	for rows.Next() {
+		var c models.HardConcept
+		if err := rows.Scan(&c.ConceptID, &c.Name, &c.TopicID, &c.Reviews, &c.Lapses, &c.EaseFactor, &c.Difficulty); err != nil {
+			return nil, fmt.Errorf("scan hardest concept: %w", err)
+		}
+
+		c.LapseRate = ratio(c.Lapses, c.Reviews)
+		hardest = append(hardest, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate hardest concepts: %w", err)
+	}
+
+	return hardest, nil
+}
+
+// topicRetention returns the retention of each reviewed topic, worst first.
+// Topics with no recall reviews yet come last.
+func (r *SQLiteReviewRepository) topicRetention(ctx context.Context, from, to string) ([]models.TopicRetention, error) {
+	rows, err := r.readDB.QueryContext(ctx, topicRetentionSQL, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query topic retention: %w", err)
+	}
+	defer rows.Close()
+
+	topics := []models.TopicRetention{}
+
+	for rows.Next() {
+		var t models.TopicRetention
+		if err := rows.Scan(&t.TopicID, &t.Title, &t.Concepts, &t.Reviews, &t.Recall, &t.Recalled); err != nil {
+			return nil, fmt.Errorf("scan topic retention: %w", err)
+		}
+
+		t.RetentionRate = ratio(t.Recalled, t.Recall)
+		topics = append(topics, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate topic retention: %w", err)
+	}
+
+	slices.SortFunc(topics, func(a, b models.TopicRetention) int {
+		if (a.Recall == 0) != (b.Recall == 0) {
+			if a.Recall == 0 {
+				return 1
+			}
+
+			return -1
+		}
+
+		return cmp.Or(cmp.Compare(a.RetentionRate, b.RetentionRate), cmp.Compare(a.TopicID, b.TopicID))
+	})
+
+	return topics, nil
+}
+
+// ratio returns n/d rounded to four places, or 0 when d is 0.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+
+	return math.Round(float64(n)/float64(d)*10000) / 10000
+}
@@ -0,0 +1,480 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// usedTodaySQL counts the new cards and reviews logged in [?1, ?2), which
+// count against the day's session caps. A question counts once, not once
+// more for each concept it reviewed.
+const usedTodaySQL = `
+SELECT COALESCE(SUM(NOT (` + recallReviewSQL + `)), 0), COALESCE(SUM(` + recallReviewSQL + `), 0)
+FROM (
+  SELECT l.previous_interval FROM review_log l
+  WHERE ` + reviewWindowSQL + ` AND l.question_id IS NULL
+  UNION ALL
+  SELECT l.previous_interval FROM question_review_log l
+  WHERE ` + reviewWindowSQL + `
+  UNION ALL
+  SELECT l.previous_interval FROM generated_card_review_log l
+  WHERE ` + reviewWindowSQL + `
+) l
+`
+
+// activeTopicsSQL selects the topics with a lesson in progress.
+const activeTopicsSQL = `
+SELECT m.topic_id FROM learning_progress lp
+JOIN lessons l ON l.id = lp.lesson_id
+JOIN modules m ON m.id = l.module_id
+WHERE lp.status = 'in_progress'
+`
+
+// sessionCandidatesSQL lists the concept cards due before ?1, soonest
+// first. With ?2 set, only concepts of topics with a lesson in progress
+// qualify. sessionQuestionCandidatesSQL and sessionGeneratedCandidatesSQL do
+// the same for question and generated cards.
+const sessionCandidatesSQL = `
+SELECT ` + reviewCardColumns + `
+FROM concept_retention cr
+JOIN concepts c ON c.id = cr.concept_id
+WHERE cr.status IN ('learning', 'reviewing') AND cr.suspended_at IS NULL
+  AND cr.next_review IS NOT NULL AND julianday(cr.next_review) < julianday(?1)
+  AND (NOT ?2 OR c.defined_in_topic IN (` + activeTopicsSQL + `))
+ORDER BY julianday(cr.next_review), cr.concept_id
+`
+
+const sessionQuestionCandidatesSQL = `
+SELECT ` + questionCardColumns + `
+FROM question_cards q
+JOIN modules m ON m.id = q.module_id
+WHERE q.status IN ('learning', 'reviewing')
+  AND q.next_review IS NOT NULL AND julianday(q.next_review) < julianday(?1)
+  AND (NOT ?2 OR m.topic_id IN (` + activeTopicsSQL + `))
+ORDER BY julianday(q.next_review), q.id
+`
+
+const sessionGeneratedCandidatesSQL = `
+SELECT ` + generatedCardColumns + `
+FROM generated_cards g
+JOIN concepts c ON c.id = g.concept_id
+WHERE g.status IN ('learning', 'reviewing')
+  AND g.next_review IS NOT NULL AND julianday(g.next_review) < julianday(?1)
+  AND (NOT ?2 OR g.topic_id IN (` + activeTopicsSQL + `))
+ORDER BY julianday(g.next_review), g.id
+`
+
+const abandonSessionsSQL = `UPDATE review_sessions SET status = 'abandoned', finished_at = ? WHERE status = 'active'`
+
+const insertSessionSQL = `
+INSERT INTO review_sessions (id, status, active_topics_only, started_at, finished_at) VALUES (?, ?, ?, ?, ?)
+`
+
+const insertSessionCardSQL = `
+INSERT INTO review_session_cards (session_id, position, concept_id, question_id, card_id, is_new) VALUES (?, ?, ?, ?, ?, ?)
+`
+
+func (r *SQLiteReviewRepository) CreateSession(ctx context.Context, dayEnd time.Time, opts models.SessionOptions, arrange ArrangeFunc) (*models.ReviewSession, error) {
+	dayEnd = dayEnd.UTC()
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, abandonSessionsSQL, now); err != nil {
+		return nil, fmt.Errorf("abandon review sessions: %w", err)
+	}
+
+	var newUsed, reviewsUsed int
+	if err := tx.QueryRowContext(ctx, usedTodaySQL,
+		dayEnd.AddDate(0, 0, -1).Format(time.RFC3339), dayEnd.Format(time.RFC3339),
+	).Scan(&newUsed, &reviewsUsed); err != nil {
+		return nil, fmt.Errorf("count today's reviews: %w", err)
+	}
+
+	concepts, err := queryReviewCards(ctx, tx, sessionCandidatesSQL, dayEnd.Format(time.RFC3339), opts.ActiveTopicsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := queryReviewCards(ctx, tx, sessionQuestionCandidatesSQL, dayEnd.Format(time.RFC3339), opts.ActiveTopicsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	generated, err := queryReviewCards(ctx, tx, sessionGeneratedCandidatesSQL, dayEnd.Format(time.RFC3339), opts.ActiveTopicsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews, newCards []models.ReviewCard
+
+	for _, c := range mergeDue(concepts, questions, generated) {
+		switch {
+		case c.ReviewCount == 0 && newUsed+len(newCards) < opts.NewLimit:
+			newCards = append(newCards, c)
+		case c.ReviewCount > 0 && reviewsUsed+len(reviews) < opts.ReviewLimit:
+			reviews = append(reviews, c)
+		}
+	}
+
+	cards := arrange(reviews, newCards)
+
+	session := &models.ReviewSession{
+		ID:               uuid.New().String(),
+		Status:           models.SessionStatusActive,
+		ActiveTopicsOnly: opts.ActiveTopicsOnly,
+		Total:            len(cards),
+		StartedAt:        now,
+		Summary:          newSessionSummary(),
+	}
+
+	// A session with nothing to review is over as soon as it starts.
+	if len(cards) == 0 {
+		session.Status = models.SessionStatusCompleted
+		session.FinishedAt = now
+	}
+
+	if _, err := tx.ExecContext(ctx, insertSessionSQL,
+		session.ID, session.Status, session.ActiveTopicsOnly, now, nullIfEmpty(session.FinishedAt),
+	); err != nil {
+		return nil, fmt.Errorf("insert review session: %w", err)
+	}
+
+	for i, c := range cards {
+		if _, err := tx.ExecContext(ctx, insertSessionCardSQL,
+			session.ID, i, nullIfEmpty(c.ConceptID), nullIfEmpty(c.QuestionID), nullIfEmpty(c.CardID), c.ReviewCount == 0,
+		); err != nil {
+			return nil, classifyError(err, "add card "+c.ConceptID+c.QuestionID+c.CardID+" to review session")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return session, nil
+}
+
+const getSessionSQL = `
+SELECT s.id, s.status, s.active_topics_only, s.started_at, COALESCE(s.finished_at, ''),
+       (SELECT COUNT(*) FROM review_session_cards WHERE session_id = s.id),
+       (SELECT COUNT(*) FROM review_session_cards WHERE session_id = s.id AND rating IS NOT NULL)
+FROM review_sessions s
+WHERE s.id = ?
+`
+
+func (r *SQLiteReviewRepository) GetSession(ctx context.Context, id string) (*models.ReviewSession, error) {
+	s := &models.ReviewSession{}
+
+	err := r.readDB.QueryRowContext(ctx, getSessionSQL, id).Scan(
+		&s.ID, &s.Status, &s.ActiveTopicsOnly, &s.StartedAt, &s.FinishedAt, &s.Total, &s.Answered)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("review session %s: %w", id, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get review session %s: %w", id, err)
+	}
+
+	summary, err := loadSessionSummary(ctx, r.readDB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Summary = *summary
+
+	return s, nil
+}
+
+const getSessionStatusSQL = `SELECT status FROM review_sessions WHERE id = ?`
+
+// sessionStatus returns the session's status, or ErrNotFound.
+func sessionStatus(ctx context.Context, q queryer, id string) (string, error) {
+	var status string
+
+	err := q.QueryRowContext(ctx, getSessionStatusSQL, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("review session %s: %w", id, ErrNotFound)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("get review session %s: %w", id, err)
+	}
+
+	return status, nil
+}
+
+const nextSessionConceptSQL = `
+SELECT ` + reviewCardColumns + `, sc.position, sc.is_new,
+       (SELECT COUNT(*) FROM review_session_cards WHERE session_id = ?1 AND rating IS NULL)
+FROM review_session_cards sc
+JOIN concepts c ON c.id = sc.concept_id
+JOIN concept_retention cr ON cr.concept_id = sc.concept_id
+WHERE sc.session_id = ?1 AND sc.position = ?2
+`
+
+const nextSessionQuestionSQL = `
+SELECT ` + questionCardColumns + `, sc.position, sc.is_new,
+       (SELECT COUNT(*) FROM review_session_cards WHERE session_id = ?1 AND rating IS NULL)
+FROM review_session_cards sc
+JOIN question_cards q ON q.id = sc.question_id
+JOIN modules m ON m.id = q.module_id
+WHERE sc.session_id = ?1 AND sc.position = ?2
+`
+
+const nextSessionGeneratedSQL = `
+SELECT ` + generatedCardColumns + `, sc.position, sc.is_new,
+       (SELECT COUNT(*) FROM review_session_cards WHERE session_id = ?1 AND rating IS NULL)
+FROM review_session_cards sc
+JOIN generated_cards g ON g.id = sc.card_id
+JOIN concepts c ON c.id = g.concept_id
+WHERE sc.session_id = ?1 AND sc.position = ?2
+`
+
+func (r *SQLiteReviewRepository) NextSessionCard(ctx context.Context, id string) (*models.SessionCard, error) {
+	status, err := sessionStatus(ctx, r.readDB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != models.SessionStatusActive {
+		return nil, nil
+	}
+
+	position, next, err := nextSessionPosition(ctx, r.readDB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := nextSessionConceptSQL
+
+	switch {
+	case next.questionID != "":
+		query = nextSessionQuestionSQL
+	case next.cardID != "":
+		query = nextSessionGeneratedSQL
+	}
+
+	c := &models.SessionCard{}
+
+	err = scanReviewCard(r.readDB.QueryRowContext(ctx, query, id, position), &c.ReviewCard, &c.Position, &c.New, &c.Remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get review session %s card: %w", id, err)
+	}
+
+	return c, nil
+}
+
+const nextSessionPositionSQL = `
+SELECT position, COALESCE(concept_id, ''), COALESCE(question_id, ''), COALESCE(card_id, '') FROM review_session_cards
+WHERE session_id = ? AND rating IS NULL
+ORDER BY position
+LIMIT 1
+`
+
+// sessionCardRef names what a session card holds: exactly one of a
+// concept, a question card, or a generated card.
+type sessionCardRef struct {
+	conceptID, questionID, cardID string
+}
+
+// id returns the ID of whatever the card holds.
+func (ref sessionCardRef) id() string {
+	return ref.conceptID + ref.questionID + ref.cardID
+}
+
+// nextSessionPosition returns the position of the session's first
+// unanswered card and what it holds, or sql.ErrNoRows when none is left.
+func nextSessionPosition(ctx context.Context, q queryer, id string) (int, sessionCardRef, error) {
+	var position int
+	var ref sessionCardRef
+
+	err := q.QueryRowContext(ctx, nextSessionPositionSQL, id).Scan(&position, &ref.conceptID, &ref.questionID, &ref.cardID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("get review session %s card: %w", id, err)
+	}
+
+	return position, ref, err
+}
+
+const answerSessionCardSQL = `
+UPDATE review_session_cards SET rating = ?, next_review = ?, interval_days = ?, answered_at = ?
+WHERE session_id = ? AND position = ?
+`
+
+const countUnansweredSQL = `SELECT COUNT(*) FROM review_session_cards WHERE session_id = ? AND rating IS NULL`
+
+const completeSessionSQL = `UPDATE review_sessions SET status = 'completed', finished_at = ? WHERE id = ?`
+
+func (r *SQLiteReviewRepository) AnswerSessionCard(ctx context.Context, id, cardID string, responseMS int, schedule func(models.ConceptRetention) (models.ConceptRetention, error)) (*models.SessionAnswer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	status, err := sessionStatus(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != models.SessionStatusActive {
+		return nil, fmt.Errorf("review session %s is %s: %w", id, status, ErrSessionClosed)
+	}
+
+	position, next, err := nextSessionPosition(ctx, tx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("review session %s has no cards left: %w", id, ErrSessionClosed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cardID != "" && cardID != next.id() {
+		return nil, fmt.Errorf("card %s, next is %s: %w", cardID, next.id(), ErrWrongCard)
+	}
+
+	answer := &models.SessionAnswer{}
+
+	switch {
+	case next.questionID != "":
+		qa, err := r.applyQuestionReview(ctx, tx, next.questionID, responseMS, schedule)
+		if err != nil {
+			return nil, err
+		}
+
+		answer.State, answer.QuestionID, answer.Concepts = qa.State, qa.QuestionID, qa.Concepts
+	case next.cardID != "":
+		if answer.State, err = generatedStore.review(ctx, tx, next.cardID, responseMS, schedule); err != nil {
+			return nil, err
+		}
+
+		answer.CardID = next.cardID
+	default:
+		state, err := r.applyReview(ctx, tx, next.conceptID, responseMS, "", schedule)
+		if err != nil {
+			return nil, err
+		}
+
+		answer.State = *state
+	}
+
+	state := answer.State
+
+	if _, err := tx.ExecContext(ctx, answerSessionCardSQL,
+		state.LastRating, state.NextReview, state.IntervalDays, state.LastReviewed, id, position,
+	); err != nil {
+		return nil, fmt.Errorf("record review session %s answer: %w", id, err)
+	}
+
+	if err := tx.QueryRowContext(ctx, countUnansweredSQL, id).Scan(&answer.Remaining); err != nil {
+		return nil, fmt.Errorf("count review session %s cards: %w", id, err)
+	}
+
+	if answer.Remaining == 0 {
+		if _, err := tx.ExecContext(ctx, completeSessionSQL, state.LastReviewed, id); err != nil {
+			return nil, fmt.Errorf("complete review session %s: %w", id, err)
+		}
+
+		if answer.Summary, err = loadSessionSummary(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return answer, nil
+}
+
+const sessionResultsSQL = `
+SELECT COALESCE(sc.concept_id, g.concept_id, ''), COALESCE(sc.question_id, ''), COALESCE(sc.card_id, ''),
+       COALESCE(c.name, q.question, ''),
+       sc.rating, COALESCE(sc.next_review, ''), COALESCE(sc.interval_days, 0), sc.is_new,
+       COALESCE(c.defined_in_lesson, q.lesson_id, ''), COALESCE(l.title, ''), COALESCE(c.defined_in_topic, m.topic_id, '')
+FROM review_session_cards sc
+LEFT JOIN generated_cards g ON g.id = sc.card_id
+LEFT JOIN concepts c ON c.id = COALESCE(sc.concept_id, g.concept_id)
+LEFT JOIN question_cards q ON q.id = sc.question_id
+LEFT JOIN modules m ON m.id = q.module_id
+LEFT JOIN lessons l ON l.id = COALESCE(c.defined_in_lesson, q.lesson_id)
+WHERE sc.session_id = ? AND sc.rating IS NOT NULL
+ORDER BY sc.position
+`
+
+func newSessionSummary() models.SessionSummary {
+	return models.SessionSummary{
+		Ratings: map[string]int{
+			models.RatingForgot: 0, models.RatingHard: 0, models.RatingGood: 0, models.RatingEasy: 0,
+		},
+		Cards:   []models.SessionResult{},
+		Restudy: []models.RestudyLink{},
+	}
+}
+
+// loadSessionSummary summarizes the session's answered cards. Forgotten
+// concepts defined in a lesson, forgotten generated cards of such concepts,
+// and forgotten lesson questions get a link back to it.
+func loadSessionSummary(ctx context.Context, q queryer, id string) (*models.SessionSummary, error) {
+	rows, err := q.QueryContext(ctx, sessionResultsSQL, id)
+	if err != nil {
+		return nil, fmt.Errorf("query review session %s results: %w", id, err)
+	}
+	defer rows.Close()
+
+	summary := newSessionSummary()
+
+	for rows.Next() {
+		var res models.SessionResult
+		var isNew bool
+		var link models.RestudyLink
+		if err := rows.Scan(&res.ConceptID, &res.QuestionID, &res.CardID, &res.Name, &res.Rating, &res.NextReview, &res.IntervalDays, &isNew,
+			&link.LessonID, &link.LessonTitle, &link.TopicID); err != nil {
+			return nil, fmt.Errorf("scan review session %s result: %w", id, err)
+		}
+
+		summary.Reviewed++
+		summary.Ratings[res.Rating]++
+		summary.Cards = append(summary.Cards, res)
+
+		if isNew {
+			summary.New++
+		}
+
+		if summary.NextDue == "" || (res.NextReview != "" && res.NextReview < summary.NextDue) {
+			summary.NextDue = res.NextReview
+		}
+
+		if res.Rating == models.RatingForgot && link.LessonID != "" {
+			link.ConceptID, link.QuestionID, link.CardID, link.Name = res.ConceptID, res.QuestionID, res.CardID, res.Name
+			summary.Restudy = append(summary.Restudy, link)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate review session %s results: %w", id, err)
+	}
+
+	return &summary, nil
+}
@@ -0,0 +1,152 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestReviewSessionFlow(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedTopic(t, db, "t2", "Other", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Module", 1)
+	seedLesson(t, db, "l1", "m1", "Channels", 1)
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	seedConcept(t, db, "c2", "Select", "Waits on channels", "t1")
+	seedConcept(t, db, "c3", "Mutex", "A lock", "t2")
+	seedConcept(t, db, "c4", "Once", "Runs a function once", "t2")
+	seedConcept(t, db, "c5", "Buffer", "Channel capacity", "t1")
+	seedConcept(t, db, "c6", "WaitGroup", "Counts goroutines", "t2")
+
+	mustExec(t, db, `UPDATE concepts SET defined_in_lesson = 'l1' WHERE id = 'c1'`)
+	mustExec(t, db, `INSERT INTO learning_progress (lesson_id, status) VALUES ('l1', 'in_progress')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, review_count, interval_days) VALUES
+		('c1', 'reviewing', '2026-01-01T09:00:00Z', 2, 3),
+		('c2', 'learning',  '2026-01-02T09:00:00Z', 0, 0),
+		('c3', 'reviewing', '2026-01-03T09:00:00Z', 1, 1),
+		('c4', 'learning',  '2026-01-04T09:00:00Z', 0, 0),
+		('c5', 'reviewing', '2999-01-01T09:00:00Z', 4, 30),
+		('c6', 'learning',  '2026-01-05T09:00:00Z', 0, 0)`)
+	// A first review already made today uses one of the two new-card slots.
+	mustExec(t, db, `INSERT INTO review_log (concept_id, rating, reviewed_at, previous_interval) VALUES ('c5', 'good', ?, 0)`,
+		now.Format(time.RFC3339))
+
+	dayEnd := review.DueCutoff(now)
+	opts := models.SessionOptions{NewLimit: 2, ReviewLimit: 5}
+
+	focused, err := repo.CreateSession(ctx, dayEnd, models.SessionOptions{ActiveTopicsOnly: true, NewLimit: 2, ReviewLimit: 5}, review.Interleave)
+	if err != nil || focused.Total != 2 {
+		t.Fatalf("CreateSession(active topics) = %+v, %v; want c1 and c2", focused, err)
+	}
+
+	session, err := repo.CreateSession(ctx, dayEnd, opts, review.Interleave)
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if session.Status != models.SessionStatusActive || session.Total != 3 {
+		t.Fatalf("CreateSession() = %+v, want 3 active cards", session)
+	}
+
+	if got, _ := repo.GetSession(ctx, focused.ID); got.Status != models.SessionStatusAbandoned {
+		t.Fatalf("earlier session status = %q, want abandoned", got.Status)
+	}
+
+	// Reviews alternate topics; the one new card goes between them.
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	wantOrder := []string{"c1", "c2", "c3"}
+	ratings := []string{models.RatingForgot, models.RatingGood, models.RatingGood}
+
+	for i, want := range wantOrder {
+		card, err := repo.NextSessionCard(ctx, session.ID)
+		if err != nil || card == nil || card.ConceptID != want || card.Position != i || card.Remaining != 3-i || card.New != (want == "c2") {
+			t.Fatalf("NextSessionCard() = %+v, %v; want %s at %d", card, err, want, i)
+		}
+
+		if i == 0 {
+			if _, err := repo.AnswerSessionCard(ctx, session.ID, "c3", 0, nil); !errors.Is(err, repository.ErrWrongCard) {
+				t.Fatalf("answering the wrong card error = %v, want ErrWrongCard", err)
+			}
+		}
+
+		answer, err := repo.AnswerSessionCard(ctx, session.ID, want, 1000, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, ratings[i], now)
+		})
+		if err != nil {
+			t.Fatalf("AnswerSessionCard(%s) error = %v", want, err)
+		}
+
+		if answer.Remaining != 2-i || (answer.Summary != nil) != (i == 2) {
+			t.Fatalf("AnswerSessionCard(%s) = %+v", want, answer)
+		}
+	}
+
+	if card, err := repo.NextSessionCard(ctx, session.ID); err != nil || card != nil {
+		t.Fatalf("NextSessionCard() after last = %+v, %v; want none", card, err)
+	}
+
+	if _, err := repo.AnswerSessionCard(ctx, session.ID, "", 0, nil); !errors.Is(err, repository.ErrSessionClosed) {
+		t.Fatalf("answering a completed session error = %v, want ErrSessionClosed", err)
+	}
+
+	got, err := repo.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+
+	s := got.Summary
+	if got.Status != models.SessionStatusCompleted || got.Answered != 3 || s.Reviewed != 3 || s.New != 1 ||
+		s.Ratings[models.RatingForgot] != 1 || s.Ratings[models.RatingGood] != 2 || len(s.Cards) != 3 {
+		t.Fatalf("GetSession() = %+v", got)
+	}
+
+	// The forgotten card is due tomorrow, the soonest of the three.
+	if s.NextDue != s.Cards[0].NextReview {
+		t.Fatalf("NextDue = %q, want %q", s.NextDue, s.Cards[0].NextReview)
+	}
+
+	wantLink := models.RestudyLink{ConceptID: "c1", Name: "Channel", LessonID: "l1", LessonTitle: "Channels", TopicID: "t1"}
+	if len(s.Restudy) != 1 || s.Restudy[0] != wantLink {
+		t.Fatalf("Restudy = %+v, want %+v", s.Restudy, wantLink)
+	}
+
+	if _, err := repo.GetSession(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetSession(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateSessionRespectsReviewCap(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+	now := time.Now().UTC()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	seedConcept(t, db, "c2", "Select", "Waits on channels", "t1")
+
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, review_count, interval_days) VALUES
+		('c1', 'reviewing', '2026-01-01T09:00:00Z', 2, 3),
+		('c2', 'reviewing', '2026-01-02T09:00:00Z', 2, 3)`)
+	mustExec(t, db, `INSERT INTO review_log (concept_id, rating, reviewed_at, previous_interval) VALUES ('c2', 'good', ?, 3)`,
+		now.Format(time.RFC3339))
+
+	session, err := repo.CreateSession(context.Background(), review.DueCutoff(now), models.SessionOptions{ReviewLimit: 1}, review.Interleave)
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	// Today's earlier review used the only slot.
+	if session.Total != 0 || session.Status != models.SessionStatusCompleted {
+		t.Fatalf("CreateSession() = %+v, want an empty completed session", session)
+	}
+}
@@ -0,0 +1,343 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestListDueSkipsNewAndMastered(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	for _, id := range []string{"c1", "c2", "c3", "c4", "c5"} {
+		seedConcept(t, db, id, "Concept "+id, "def "+id, "t1")
+	}
+
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c1', 'reviewing', '2026-03-10T18:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c2', 'learning', '2026-03-09T08:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c3', 'reviewing', '2026-03-11T00:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c4', 'mastered', '2026-03-01T00:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c5', 'new', '2026-03-01T00:00:00Z')`)
+
+	cards, err := repo.ListDue(context.Background(), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ListDue() error = %v", err)
+	}
+
+	if len(cards) != 2 || cards[0].ConceptID != "c2" || cards[1].ConceptID != "c1" {
+		t.Fatalf("ListDue() = %+v, want c2 then c1", cards)
+	}
+
+	if cards[0].Name != "Concept c2" || cards[0].Definition != "def c2" || cards[0].TopicID != "t1" {
+		t.Fatalf("card missing concept fields: %+v", cards[0])
+	}
+}
+
+func TestRecordReviewStartsAndUpdatesState(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	rate := func(rating string) *models.ConceptRetention {
+		t.Helper()
+
+		state, err := repo.RecordReview(ctx, "c1", 0, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, rating, now)
+		})
+		if err != nil {
+			t.Fatalf("RecordReview(%s) error = %v", rating, err)
+		}
+
+		return state
+	}
+
+	first := rate(models.RatingGood)
+	if first.ReviewCount != 1 || first.IntervalDays != 1 || first.Status != models.RetentionStatusReviewing {
+		t.Fatalf("first review = %+v", first)
+	}
+
+	second := rate(models.RatingEasy)
+	if second.ReviewCount != 2 || second.IntervalDays != 3 || second.EaseFactor != 2.65 {
+		t.Fatalf("second review = %+v", second)
+	}
+
+	var status, rating, next string
+	var count int
+	if err := db.QueryRow(`SELECT status, last_rating, next_review, review_count FROM concept_retention WHERE concept_id = 'c1'`).
+		Scan(&status, &rating, &next, &count); err != nil {
+		t.Fatalf("load retention: %v", err)
+	}
+
+	if status != models.RetentionStatusReviewing || rating != models.RatingEasy || next != "2026-03-13T09:00:00Z" || count != 2 {
+		t.Fatalf("stored state = %s %s %s %d", status, rating, next, count)
+	}
+}
+
+func TestRecordReviewUnknownConcept(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+
+	_, err := repo.RecordReview(context.Background(), "missing", 0, func(s models.ConceptRetention) (models.ConceptRetention, error) {
+		return s, nil
+	})
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("RecordReview() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRecordReviewKeepsStateWhenScheduleFails(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+
+	wantErr := errors.New("boom")
+
+	_, err := repo.RecordReview(context.Background(), "c1", 0, func(models.ConceptRetention) (models.ConceptRetention, error) {
+		return models.ConceptRetention{}, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("RecordReview() error = %v, want %v", err, wantErr)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM concept_retention`); n != 0 {
+		t.Fatalf("failed review stored %d rows", n)
+	}
+}
+
+func TestGetReviewStats(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	for _, id := range []string{"c1", "c2", "c3", "c4", "c5"} {
+		seedConcept(t, db, id, id, "def", "t1")
+	}
+
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, last_reviewed)
+		VALUES ('c1', 'learning', '2026-03-10T12:00:00Z', '2026-03-10T08:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, last_reviewed)
+		VALUES ('c2', 'reviewing', '2026-03-13T12:00:00Z', '2026-03-09T08:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review)
+		VALUES ('c3', 'reviewing', '2026-04-30T12:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, last_reviewed)
+		VALUES ('c4', 'mastered', '2026-09-01T00:00:00Z', '2026-03-10T07:00:00Z')`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id) VALUES ('c5')`)
+
+	stats, err := repo.GetStats(context.Background(), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+
+	want := models.ReviewStats{DueToday: 1, Upcoming: 1, ReviewedToday: 2, New: 1, Learning: 1, Reviewing: 2, Mastered: 1}
+	if *stats != want {
+		t.Fatalf("GetStats() = %+v, want %+v", *stats, want)
+	}
+}
+
+func TestRecordReviewLogsRating(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+
+	fsrs := review.FSRS{MasteryDays: review.DefaultMasteryDays}
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	state, err := repo.RecordReview(ctx, "c1", 0, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+		return fsrs.Review(current, models.RatingGood, now)
+	})
+	if err != nil {
+		t.Fatalf("RecordReview() error = %v", err)
+	}
+
+	states, err := repo.ListRetention(ctx)
+	if err != nil {
+		t.Fatalf("ListRetention() error = %v", err)
+	}
+
+	if len(states) != 1 || states[0] != *state || states[0].Scheduler != review.NameFSRS || states[0].Stability == 0 {
+		t.Fatalf("stored state = %+v, want %+v", states, *state)
+	}
+
+	history, err := repo.ListHistory(ctx)
+	if err != nil {
+		t.Fatalf("ListHistory() error = %v", err)
+	}
+
+	want := []models.ReviewEvent{{Rating: models.RatingGood, ReviewedAt: "2026-03-10T09:00:00Z"}}
+	if len(history) != 1 || len(history["c1"]) != 1 || history["c1"][0] != want[0] {
+		t.Fatalf("ListHistory() = %+v, want c1: %+v", history, want)
+	}
+}
+
+func TestSwitchSchedulerRederivesFromHistory(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	seedConcept(t, db, "c2", "Select", "Waits on channels", "t1")
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	for i, rating := range []string{models.RatingGood, models.RatingGood} {
+		at := time.Date(2026, 3, 1+i, 9, 0, 0, 0, time.UTC)
+		if _, err := repo.RecordReview(ctx, "c1", 0, func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, rating, at)
+		}); err != nil {
+			t.Fatalf("RecordReview() error = %v", err)
+		}
+	}
+
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review) VALUES ('c2', 'learning', '2026-03-02T09:00:00Z')`)
+
+	if name, err := repo.GetSchedulerPreference(ctx); err != nil || name != "" {
+		t.Fatalf("GetSchedulerPreference() = %q, %v; want none", name, err)
+	}
+
+	fsrs := review.FSRS{MasteryDays: review.DefaultMasteryDays}
+	replay := func(current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error) {
+		return review.Replay(fsrs, current, history)
+	}
+
+	n, err := repo.SwitchScheduler(ctx, review.NameFSRS, replay)
+	if err != nil || n != 2 {
+		t.Fatalf("SwitchScheduler() = %d, %v; want 2", n, err)
+	}
+
+	if name, _ := repo.GetSchedulerPreference(ctx); name != review.NameFSRS {
+		t.Fatalf("preference = %q, want fsrs", name)
+	}
+
+	var stability float64
+	var scheduler, status string
+	if err := db.QueryRow(`SELECT stability, scheduler FROM concept_retention WHERE concept_id = 'c1'`).Scan(&stability, &scheduler); err != nil {
+		t.Fatalf("load c1: %v", err)
+	}
+
+	// Good then good a day later under FSRS.
+	if scheduler != review.NameFSRS || stability < 3.7145 {
+		t.Fatalf("c1 = %s stability %v, want re-derived FSRS state", scheduler, stability)
+	}
+
+	if err := db.QueryRow(`SELECT status, scheduler FROM concept_retention WHERE concept_id = 'c2'`).Scan(&status, &scheduler); err != nil {
+		t.Fatalf("load c2: %v", err)
+	}
+
+	if status != models.RetentionStatusLearning || scheduler != review.NameFSRS {
+		t.Fatalf("c2 = %s %s, want enrolled concept adopted by fsrs", status, scheduler)
+	}
+
+	if n, err := repo.Rederive(ctx, review.NameFSRS, replay); err != nil || n != 0 {
+		t.Fatalf("Rederive() = %d, %v; want nothing left to re-derive", n, err)
+	}
+}
+
+func TestRecordReviewLogsIntervalsAndResponseTime(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+
+	sm2 := review.SM2{MasteryDays: review.DefaultMasteryDays}
+	for i, rating := range []string{models.RatingGood, models.RatingHard} {
+		at := time.Date(2026, 3, 1+i, 9, 0, 0, 0, time.UTC)
+		if _, err := repo.RecordReview(ctx, "c1", 1500*(i+1), func(current models.ConceptRetention) (models.ConceptRetention, error) {
+			return sm2.Review(current, rating, at)
+		}); err != nil {
+			t.Fatalf("RecordReview() error = %v", err)
+		}
+	}
+
+	var prev, next, responseMS int
+	var ease float64
+	if err := db.QueryRow(`SELECT previous_interval, new_interval, ease_factor, response_ms FROM review_log ORDER BY id DESC LIMIT 1`).
+		Scan(&prev, &next, &ease, &responseMS); err != nil {
+		t.Fatalf("query review_log: %v", err)
+	}
+
+	if prev != 1 || next != 1 || ease != 2.35 || responseMS != 3000 {
+		t.Fatalf("logged interval %d -> %d, ease %v, response %dms; want 1 -> 1, 2.35, 3000ms", prev, next, ease, responseMS)
+	}
+}
+
+func TestGetAnalytics(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewReviewRepository(db, db)
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedTopic(t, db, "t2", "Other", "foundational", "published")
+	seedConcept(t, db, "c1", "Channel", "A typed pipe", "t1")
+	seedConcept(t, db, "c2", "Select", "Waits on channels", "t1")
+	seedConcept(t, db, "c3", "Mutex", "A lock", "t2")
+
+	mustExec(t, db, `INSERT INTO review_log (concept_id, rating, reviewed_at, previous_interval, new_interval, ease_factor) VALUES
+		('c1', 'good',   '2026-03-08T09:00:00Z', 0, 1, 2.5),
+		('c1', 'forgot', '2026-03-09T09:00:00Z', 1, 1, 2.3),
+		('c1', 'good',   '2026-03-10T09:00:00Z', 1, 3, 2.3),
+		('c2', 'good',   '2026-03-10T10:00:00Z', 3, 8, 2.5),
+		('c3', 'hard',   '2026-03-10T11:00:00Z', 2, 2, 2.35),
+		('c3', 'forgot', '2026-03-01T09:00:00Z', 5, 1, 2.5)`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, next_review, review_count, ease_factor, interval_days) VALUES
+		('c1', 'reviewing', '2026-03-09T09:00:00Z', 3, 2.3, 3),
+		('c2', 'reviewing', '2026-03-11T09:00:00Z', 1, 2.5, 8),
+		('c3', 'learning',  '2026-03-12T09:00:00Z', 2, 2.35, 2)`)
+
+	a, err := repo.GetAnalytics(context.Background(), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), 3)
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+
+	if a.Days != 3 || a.Reviews != 5 || a.RetentionRate != 0.75 {
+		t.Fatalf("summary = %d days, %d reviews, %v retention; want 3, 5, 0.75", a.Days, a.Reviews, a.RetentionRate)
+	}
+
+	wantDaily := []models.DailyReviews{
+		{Date: "2026-03-08", Reviews: 1},
+		{Date: "2026-03-09", Reviews: 1, Recall: 1},
+		{Date: "2026-03-10", Reviews: 3, Recall: 3, Recalled: 3, RetentionRate: 1},
+	}
+	if !slices.Equal(a.Daily, wantDaily) {
+		t.Fatalf("Daily = %+v, want %+v", a.Daily, wantDaily)
+	}
+
+	// c1 is overdue, so it counts as due today.
+	wantForecast := []models.DueForecast{{Date: "2026-03-10", Due: 1}, {Date: "2026-03-11", Due: 1}, {Date: "2026-03-12", Due: 1}}
+	if !slices.Equal(a.Forecast, wantForecast) {
+		t.Fatalf("Forecast = %+v, want %+v", a.Forecast, wantForecast)
+	}
+
+	// c3's lapse falls before the window.
+	wantHardest := []models.HardConcept{{ConceptID: "c1", Name: "Channel", TopicID: "t1", Reviews: 3, Lapses: 1, LapseRate: 0.3333, EaseFactor: 2.3}}
+	if !slices.Equal(a.Hardest, wantHardest) {
+		t.Fatalf("Hardest = %+v, want %+v", a.Hardest, wantHardest)
+	}
+
+	wantTopics := []models.TopicRetention{
+		{TopicID: "t1", Title: "Topic", Concepts: 2, Reviews: 4, Recall: 3, Recalled: 2, RetentionRate: 0.6667},
+		{TopicID: "t2", Title: "Other", Concepts: 1, Reviews: 1, Recall: 1, Recalled: 1, RetentionRate: 1},
+	}
+	if !slices.Equal(a.Topics, wantTopics) {
+		t.Fatalf("Topics = %+v, want %+v", a.Topics, wantTopics)
+	}
+}
@@ -15,7 +15,7 @@ var ErrInvalidQuery = errors.New("invalid search query")
 
 // SearchRepository defines search operations.
 type SearchRepository interface {
-	Search(ctx context.Context, query string, params models.PaginationParams) (*models.PaginatedResponse[models.SearchResult], error)
+	Search(ctx context.Context, query string, params models.PaginationParams, opts models.ReadOptions) (*models.PaginatedResponse[models.SearchResult], error)
 }
 
 // SQLiteSearchRepository implements SearchRepository using FTS5.
@@ -28,25 +28,34 @@ func NewSearchRepository(db *sql.DB) *SQLiteSearchRepository {
 	return &SQLiteSearchRepository{db: db}
 }
 
+// searchVisibleSQL drops lesson hits whose lesson or module is archived,
+// unless the bound include-archived flag is set.
+const searchVisibleSQL = `
+  AND (? OR NOT EXISTS (
+    SELECT 1 FROM lessons l JOIN modules m ON m.id = l.module_id
+    WHERE search_index.entity_type = 'lesson' AND l.id = search_index.entity_id
+      AND (l.archived_at IS NOT NULL OR m.archived_at IS NOT NULL)
+  ))`
+
 // searchSQL: snippet column index 3 = body column in search_index(entity_type, entity_id, title, body).
 const searchSQL = `
 SELECT entity_type, entity_id, title,
        snippet(search_index, 3, '<mark>', '</mark>', '...', 30)
 FROM search_index
-WHERE search_index MATCH ?
+WHERE search_index MATCH ?` + searchVisibleSQL + `
 ORDER BY rank
 LIMIT ? OFFSET ?
 `
 
-const countSearchSQL = `SELECT COUNT(*) FROM search_index WHERE search_index MATCH ?`
+const countSearchSQL = `SELECT COUNT(*) FROM search_index WHERE search_index MATCH ?` + searchVisibleSQL
 
-func (r *SQLiteSearchRepository) Search(ctx context.Context, query string, params models.PaginationParams) (*models.PaginatedResponse[models.SearchResult], error) {
+func (r *SQLiteSearchRepository) Search(ctx context.Context, query string, params models.PaginationParams, opts models.ReadOptions) (*models.PaginatedResponse[models.SearchResult], error) {
 	var total int
-	if err := r.db.QueryRowContext(ctx, countSearchSQL, query).Scan(&total); err != nil {
+	if err := r.db.QueryRowContext(ctx, countSearchSQL, query, opts.IncludeArchived).Scan(&total); err != nil {
 		return nil, classifySearchError(err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, searchSQL, query, params.PerPage, params.Offset())
+	rows, err := r.db.QueryContext(ctx, searchSQL, query, opts.IncludeArchived, params.PerPage, params.Offset())
 	if err != nil {
 		return nil, classifySearchError(err)
 	}
@@ -23,7 +23,7 @@ func TestSearch(t *testing.T) {
 
 	params := models.PaginationParams{Page: 1, PerPage: 20}
 
-	result, err := repo.Search(context.Background(), "Go", params)
+	result, err := repo.Search(context.Background(), "Go", params, models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("search: %v", err)
 	}
@@ -64,7 +64,7 @@ func TestSearchPagination(t *testing.T) {
 
 	params := models.PaginationParams{Page: 1, PerPage: 3}
 
-	result, err := repo.Search(context.Background(), "Go", params)
+	result, err := repo.Search(context.Background(), "Go", params, models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("search page 1: %v", err)
 	}
@@ -79,7 +79,7 @@ func TestSearchPagination(t *testing.T) {
 
 	params2 := models.PaginationParams{Page: 4, PerPage: 3}
 
-	result2, err := repo.Search(context.Background(), "Go", params2)
+	result2, err := repo.Search(context.Background(), "Go", params2, models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("search page 4: %v", err)
 	}
@@ -98,7 +98,7 @@ func TestSearchNoResults(t *testing.T) {
 
 	params := models.PaginationParams{Page: 1, PerPage: 20}
 
-	result, err := repo.Search(context.Background(), "xyznonexistent", params)
+	result, err := repo.Search(context.Background(), "xyznonexistent", params, models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("search: %v", err)
 	}
@@ -121,7 +121,7 @@ func TestSearchInvalidFTS5Syntax(t *testing.T) {
 
 	params := models.PaginationParams{Page: 1, PerPage: 20}
 
-	_, err := repo.Search(context.Background(), "\"unclosed", params)
+	_, err := repo.Search(context.Background(), "\"unclosed", params, models.ReadOptions{})
 	if err == nil {
 		t.Fatal("expected error for invalid FTS5 syntax")
 	}
@@ -140,7 +140,7 @@ func TestSearchSnippet(t *testing.T) {
 
 	params := models.PaginationParams{Page: 1, PerPage: 20}
 
-	result, err := repo.Search(context.Background(), "fundamentals", params)
+	result, err := repo.Search(context.Background(), "fundamentals", params, models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("search: %v", err)
 	}
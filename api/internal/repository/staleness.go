@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// StalenessRepository defines operations for the curriculum staleness sweeper.
+type StalenessRepository interface {
+	MarkOutdatedTopics(ctx context.Context, cutoff time.Time) (int, error)
+	ListRefreshCandidates(ctx context.Context, limit int) ([]models.StaleTopic, error)
+	CountRefreshesSince(ctx context.Context, since time.Time) (int, error)
+	RecordSweep(ctx context.Context, sweep *models.StalenessSweep) error
+	ListSweeps(ctx context.Context, params models.PaginationParams) (*models.PaginatedResponse[models.StalenessSweep], error)
+}
+
+// SQLiteStalenessRepository implements StalenessRepository using SQLite.
+// Reads use the read pool; status changes and sweep records use the write handle.
+type SQLiteStalenessRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewStalenessRepository creates a new SQLiteStalenessRepository.
+func NewStalenessRepository(readDB, writeDB *sql.DB) *SQLiteStalenessRepository {
+	return &SQLiteStalenessRepository{readDB: readDB, db: writeDB}
+}
+
+// julianday() accepts both the RFC 3339 timestamps the ingester writes and
+// bare dates, so the comparison does not depend on string formatting.
+const markOutdatedTopicsSQL = `
+UPDATE topics
+SET status = 'outdated', revision = revision + 1, updated_at = CURRENT_TIMESTAMP
+WHERE status = 'published'
+  AND generated_at IS NOT NULL
+  AND julianday(generated_at) < julianday(?)
+`
+
+// MarkOutdatedTopics flags published topics generated before cutoff as
+// outdated and returns how many changed.
+func (r *SQLiteStalenessRepository) MarkOutdatedTopics(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, markOutdatedTopicsSQL, cutoff.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("mark outdated topics: %w", err)
+	}
+
+	flagged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("mark outdated topics rows affected: %w", err)
+	}
+
+	return int(flagged), nil
+}
+
+const listRefreshCandidatesSQL = `
+SELECT t.id, t.title, COALESCE(t.generated_at, '')
+FROM topics t
+WHERE t.status = 'outdated'
+  AND NOT EXISTS (
+    SELECT 1 FROM research_jobs j
+    WHERE j.kind = 'curriculum' AND j.root_topic IN (t.id, t.title)
+      AND j.status IN ('queued', 'researching', 'resolving')
+  )
+ORDER BY julianday(t.generated_at), t.id
+LIMIT ?
+`
+
+// ListRefreshCandidates returns outdated topics, oldest first, that have no
+// research job queued or in flight.
+func (r *SQLiteStalenessRepository) ListRefreshCandidates(ctx context.Context, limit int) ([]models.StaleTopic, error) {
+	rows, err := r.readDB.QueryContext(ctx, listRefreshCandidatesSQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query refresh candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []models.StaleTopic
+
+	for rows.Next() {
+		var st models.StaleTopic
+		if err := rows.Scan(&st.ID, &st.Title, &st.GeneratedAt); err != nil {
+			return nil, fmt.Errorf("scan refresh candidate: %w", err)
+		}
+
+		topics = append(topics, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate refresh candidates: %w", err)
+	}
+
+	return topics, nil
+}
+
+const countRefreshesSinceSQL = `
+SELECT COALESCE(SUM(jobs_queued), 0) FROM staleness_sweeps WHERE started_at >= ?
+`
+
+// CountRefreshesSince returns how many refresh jobs sweeps have queued since
+// the given time.
+func (r *SQLiteStalenessRepository) CountRefreshesSince(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	if err := r.readDB.QueryRowContext(ctx, countRefreshesSinceSQL, since.UTC().Format(time.RFC3339)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count refreshes: %w", err)
+	}
+
+	return count, nil
+}
+
+const insertSweepSQL = `
+INSERT INTO staleness_sweeps (started_at, completed_at, threshold_days, topics_flagged, jobs_queued, error)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+// RecordSweep stores a completed sweep and sets its ID.
+func (r *SQLiteStalenessRepository) RecordSweep(ctx context.Context, sweep *models.StalenessSweep) error {
+	result, err := r.db.ExecContext(ctx, insertSweepSQL,
+		sweep.StartedAt, sweep.CompletedAt, sweep.ThresholdDays,
+		sweep.TopicsFlagged, sweep.JobsQueued, nullIfEmpty(sweep.Error),
+	)
+	if err != nil {
+		return fmt.Errorf("record staleness sweep: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("staleness sweep id: %w", err)
+	}
+
+	sweep.ID = id
+
+	return nil
+}
+
+const countSweepsSQL = `SELECT COUNT(*) FROM staleness_sweeps`
+
+const listSweepsSQL = `
+SELECT id, started_at, completed_at, threshold_days, topics_flagged, jobs_queued, COALESCE(error, '')
+FROM staleness_sweeps
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+// ListSweeps returns recorded sweeps, most recent first.
+func (r *SQLiteStalenessRepository) ListSweeps(ctx context.Context, params models.PaginationParams) (*models.PaginatedResponse[models.StalenessSweep], error) {
+	var total int
+	if err := r.readDB.QueryRowContext(ctx, countSweepsSQL).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count staleness sweeps: %w", err)
+	}
+
+	rows, err := r.readDB.QueryContext(ctx, listSweepsSQL, params.PerPage, params.Offset())
+	if err != nil {
+		return nil, fmt.Errorf("list staleness sweeps: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.StalenessSweep
+
+	for rows.Next() {
+		var s models.StalenessSweep
+		if err := rows.Scan(&s.ID, &s.StartedAt, &s.CompletedAt, &s.ThresholdDays, &s.TopicsFlagged, &s.JobsQueued, &s.Error); err != nil {
+			return nil, fmt.Errorf("scan staleness sweep: %w", err)
+		}
+
+		items = append(items, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate staleness sweeps: %w", err)
+	}
+
+	if items == nil {
+		items = []models.StalenessSweep{}
+	}
+
+	return &models.PaginatedResponse[models.StalenessSweep]{
+		Items:   items,
+		Total:   total,
+		Page:    params.Page,
+		PerPage: params.PerPage,
+	}, nil
+}
+
+// Verify interface compliance at compile time.
+var _ StalenessRepository = (*SQLiteStalenessRepository)(nil)
@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func TestMarkOutdatedTopicsComparesGeneratedAt(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewStalenessRepository(db, db)
+
+	mustExec(t, db, `INSERT INTO topics (id, title, status, generated_at) VALUES ('dated', 'Dated', 'published', '2020-01-15')`)
+	mustExec(t, db, `INSERT INTO topics (id, title, status, generated_at) VALUES ('stamped', 'Stamped', 'published', '2020-06-01T12:00:00Z')`)
+	mustExec(t, db, `INSERT INTO topics (id, title, status, generated_at) VALUES ('recent', 'Recent', 'published', '2024-06-01T12:00:00Z')`)
+	mustExec(t, db, `INSERT INTO topics (id, title, status) VALUES ('manual', 'Manual', 'published')`)
+
+	flagged, err := repo.MarkOutdatedTopics(context.Background(), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("mark outdated: %v", err)
+	}
+
+	if flagged != 2 {
+		t.Fatalf("expected 2 flagged topics, got %d", flagged)
+	}
+
+	topics, err := repository.NewTopicRepository(db).ListTopics(context.Background(), models.TopicStatusOutdated)
+	if err != nil {
+		t.Fatalf("list outdated: %v", err)
+	}
+
+	if len(topics) != 2 || topics[0].ID != "dated" || topics[1].ID != "stamped" {
+		t.Fatalf("unexpected outdated topics %+v", topics)
+	}
+}
+
+func TestListRefreshCandidatesSkipsActiveJobs(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewStalenessRepository(db, db)
+
+	mustExec(t, db, `INSERT INTO topics (id, title, status, generated_at) VALUES ('a', 'Alpha', 'outdated', '2020-01-01')`)
+	mustExec(t, db, `INSERT INTO topics (id, title, status, generated_at) VALUES ('b', 'Beta', 'outdated', '2021-01-01')`)
+	mustExec(t, db, `INSERT INTO research_jobs (id, root_topic, status) VALUES ('j1', 'Alpha', 'researching')`)
+	mustExec(t, db, `INSERT INTO research_jobs (id, root_topic, status) VALUES ('j2', 'Beta', 'failed')`)
+
+	candidates, err := repo.ListRefreshCandidates(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("list candidates: %v", err)
+	}
+
+	if len(candidates) != 1 || candidates[0].ID != "b" {
+		t.Fatalf("expected only b, got %+v", candidates)
+	}
+}
+
+func TestRecordAndListSweeps(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewStalenessRepository(db, db)
+	ctx := context.Background()
+
+	for i, queued := range []int{1, 2} {
+		sweep := &models.StalenessSweep{
+			StartedAt:     time.Date(2026, 3, 1+i, 6, 0, 0, 0, time.UTC).Format(time.RFC3339),
+			CompletedAt:   time.Date(2026, 3, 1+i, 6, 0, 1, 0, time.UTC).Format(time.RFC3339),
+			ThresholdDays: 180,
+			JobsQueued:    queued,
+		}
+		if err := repo.RecordSweep(ctx, sweep); err != nil {
+			t.Fatalf("record sweep: %v", err)
+		}
+	}
+
+	count, err := repo.CountRefreshesSince(ctx, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("count refreshes: %v", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 refreshes since Mar 2, got %d", count)
+	}
+
+	list, err := repo.ListSweeps(ctx, models.PaginationParams{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("list sweeps: %v", err)
+	}
+
+	if list.Total != 2 || list.Items[0].JobsQueued != 2 {
+		t.Fatalf("expected most recent sweep first, got %+v", list)
+	}
+}
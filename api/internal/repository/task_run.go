@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// TaskRunRepository defines persistence for scheduler run history.
+type TaskRunRepository interface {
+	StartRun(ctx context.Context, task, trigger string) (*models.TaskRun, error)
+	FinishRun(ctx context.Context, id int64, status, summary, errorMsg string) error
+	RecordSkippedRun(ctx context.Context, task, trigger, reason string) (*models.TaskRun, error)
+	FailInterruptedRuns(ctx context.Context) (int, error)
+	LatestRuns(ctx context.Context) (map[string]models.TaskRun, error)
+	ListRuns(ctx context.Context, task string, params models.PaginationParams) (*models.PaginatedResponse[models.TaskRun], error)
+}
+
+// SQLiteTaskRunRepository implements TaskRunRepository using SQLite.
+// Reads use the read pool; run records use the write handle.
+type SQLiteTaskRunRepository struct {
+	readDB *sql.DB
+	db     *sql.DB
+}
+
+// NewTaskRunRepository creates a new SQLiteTaskRunRepository.
+func NewTaskRunRepository(readDB, writeDB *sql.DB) *SQLiteTaskRunRepository {
+	return &SQLiteTaskRunRepository{readDB: readDB, db: writeDB}
+}
+
+const insertTaskRunSQL = `
+INSERT INTO task_runs (task, trigger, status, started_at, finished_at, error)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+// StartRun records a run as running and returns it with its ID.
+func (r *SQLiteTaskRunRepository) StartRun(ctx context.Context, task, trigger string) (*models.TaskRun, error) {
+	run := &models.TaskRun{
+		Task:      task,
+		Trigger:   trigger,
+		Status:    models.TaskRunStatusRunning,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if err := r.insert(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// RecordSkippedRun records a run that did not start, with the reason as its error.
+func (r *SQLiteTaskRunRepository) RecordSkippedRun(ctx context.Context, task, trigger, reason string) (*models.TaskRun, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	run := &models.TaskRun{
+		Task:       task,
+		Trigger:    trigger,
+		Status:     models.TaskRunStatusSkipped,
+		StartedAt:  now,
+		FinishedAt: now,
+		Error:      reason,
+	}
+
+	if err := r.insert(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (r *SQLiteTaskRunRepository) insert(ctx context.Context, run *models.TaskRun) error {
+	result, err := r.db.ExecContext(ctx, insertTaskRunSQL,
+		run.Task, run.Trigger, run.Status, run.StartedAt,
+		nullIfEmpty(run.FinishedAt), nullIfEmpty(run.Error),
+	)
+	if err != nil {
+		return classifyError(err, "record task run")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("task run id: %w", err)
+	}
+
+	run.ID = id
+
+	return nil
+}
+
+const finishTaskRunSQL = `
+UPDATE task_runs SET status = ?, finished_at = ?, summary = ?, error = ?
+WHERE id = ?
+`
+
+// FinishRun stores the outcome of a running task.
+func (r *SQLiteTaskRunRepository) FinishRun(ctx context.Context, id int64, status, summary, errorMsg string) error {
+	result, err := r.db.ExecContext(ctx, finishTaskRunSQL,
+		status, time.Now().UTC().Format(time.RFC3339),
+		nullIfEmpty(summary), nullIfEmpty(errorMsg), id,
+	)
+	if err != nil {
+		return classifyError(err, "finish task run")
+	}
+
+	return requireAffected(result, "task run", fmt.Sprint(id))
+}
+
+const failInterruptedRunsSQL = `
+UPDATE task_runs SET status = 'failed', finished_at = ?, error = 'interrupted by shutdown'
+WHERE status = 'running'
+`
+
+// FailInterruptedRuns marks runs left running by a previous process as
+// failed. Called once at scheduler startup.
+func (r *SQLiteTaskRunRepository) FailInterruptedRuns(ctx context.Context) (int, error) {
+	result, err := r.db.ExecContext(ctx, failInterruptedRunsSQL, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("fail interrupted task runs: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("fail interrupted task runs rows affected: %w", err)
+	}
+
+	return int(n), nil
+}
+
+const taskRunColumns = `
+id, task, trigger, status, started_at, COALESCE(finished_at, ''),
+COALESCE(summary, ''), COALESCE(error, '')
+`
+
+const latestTaskRunsSQL = `
+SELECT ` + taskRunColumns + `
+FROM task_runs
+WHERE id IN (SELECT MAX(id) FROM task_runs GROUP BY task)
+`
+
+// LatestRuns returns the most recent run of each task, keyed by task name.
+func (r *SQLiteTaskRunRepository) LatestRuns(ctx context.Context) (map[string]models.TaskRun, error) {
+	rows, err := r.readDB.QueryContext(ctx, latestTaskRunsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query latest task runs: %w", err)
+	}
+	defer rows.Close()
+
+	latest := make(map[string]models.TaskRun)
+
+	for rows.Next() {
+		run, err := scanTaskRun(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		latest[run.Task] = run
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate latest task runs: %w", err)
+	}
+
+	return latest, nil
+}
+
+const countTaskRunsSQL = `SELECT COUNT(*) FROM task_runs WHERE task = ?`
+
+const listTaskRunsSQL = `
+SELECT ` + taskRunColumns + `
+FROM task_runs
+WHERE task = ?
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+// ListRuns returns a task's runs, most recent first.
+func (r *SQLiteTaskRunRepository) ListRuns(ctx context.Context, task string, params models.PaginationParams) (*models.PaginatedResponse[models.TaskRun], error) {
+	var total int
+	if err := r.readDB.QueryRowContext(ctx, countTaskRunsSQL, task).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count task runs: %w", err)
+	}
+
+	rows, err := r.readDB.QueryContext(ctx, listTaskRunsSQL, task, params.PerPage, params.Offset())
+	if err != nil {
+		return nil, fmt.Errorf("list task runs: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.TaskRun
+
+	for rows.Next() {
+		run, err := scanTaskRun(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate task runs: %w", err)
+	}
+
+	if items == nil {
+		items = []models.TaskRun{}
+	}
+
+	return &models.PaginatedResponse[models.TaskRun]{
+		Items:   items,
+		Total:   total,
+		Page:    params.Page,
+		PerPage: params.PerPage,
+	}, nil
+}
+
+func scanTaskRun(rows *sql.Rows) (models.TaskRun, error) {
+	var run models.TaskRun
+	if err := rows.Scan(
+		&run.ID, &run.Task, &run.Trigger, &run.Status, &run.StartedAt,
+		&run.FinishedAt, &run.Summary, &run.Error,
+	); err != nil {
+		return run, fmt.Errorf("scan task run: %w", err)
+	}
+
+	return run, nil
+}
+
+// Verify interface compliance at compile time.
+var _ TaskRunRepository = (*SQLiteTaskRunRepository)(nil)
@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func TestTaskRunLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewTaskRunRepository(db, db)
+	ctx := context.Background()
+
+	first, err := repo.StartRun(ctx, "backup", models.TaskTriggerSchedule)
+	if err != nil {
+		t.Fatalf("start run: %v", err)
+	}
+
+	if err := repo.FinishRun(ctx, first.ID, models.TaskRunStatusSucceeded, "wrote backup", ""); err != nil {
+		t.Fatalf("finish run: %v", err)
+	}
+
+	if _, err := repo.StartRun(ctx, "backup", models.TaskTriggerManual); err != nil {
+		t.Fatalf("start second run: %v", err)
+	}
+
+	if _, err := repo.RecordSkippedRun(ctx, "fts-optimize", models.TaskTriggerManual, "task already running"); err != nil {
+		t.Fatalf("record skipped: %v", err)
+	}
+
+	latest, err := repo.LatestRuns(ctx)
+	if err != nil {
+		t.Fatalf("latest runs: %v", err)
+	}
+
+	if len(latest) != 2 || latest["backup"].Status != models.TaskRunStatusRunning || latest["fts-optimize"].Status != models.TaskRunStatusSkipped {
+		t.Fatalf("unexpected latest runs %+v", latest)
+	}
+
+	interrupted, err := repo.FailInterruptedRuns(ctx)
+	if err != nil {
+		t.Fatalf("fail interrupted: %v", err)
+	}
+
+	if interrupted != 1 {
+		t.Fatalf("expected 1 interrupted run, got %d", interrupted)
+	}
+
+	runs, err := repo.ListRuns(ctx, "backup", models.PaginationParams{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+
+	if runs.Total != 2 || runs.Items[0].Status != models.TaskRunStatusFailed || runs.Items[1].Summary != "wrote backup" {
+		t.Fatalf("unexpected runs %+v", runs.Items)
+	}
+}
+
+func TestFinishRunMissing(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewTaskRunRepository(db, db)
+
+	err := repo.FinishRun(context.Background(), 42, models.TaskRunStatusSucceeded, "", "")
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
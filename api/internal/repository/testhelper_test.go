@@ -15,17 +15,23 @@ import (
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
 
-	dbPath := filepath.Join(t.TempDir(), "test.db")
+	return setupTestHandle(t).DB
+}
+
+func setupTestHandle(tb testing.TB) *database.Handle {
+	tb.Helper()
+
+	dbPath := filepath.Join(tb.TempDir(), "test.db")
 	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
 
 	handle, err := database.Open(context.Background(), dbPath, logger)
 	if err != nil {
-		t.Fatalf("open test database: %v", err)
+		tb.Fatalf("open test database: %v", err)
 	}
 
-	t.Cleanup(func() { _ = handle.Close() })
+	tb.Cleanup(func() { _ = handle.Close() })
 
-	return handle.DB
+	return handle
 }
 
 func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
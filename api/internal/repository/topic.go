@@ -11,9 +11,9 @@ import (
 
 // TopicRepository defines read operations for topics.
 type TopicRepository interface {
-	ListTopics(ctx context.Context) ([]models.TopicSummary, error)
-	GetTopicByID(ctx context.Context, id string) (*models.TopicDetail, error)
-	GetTopicFull(ctx context.Context, id string) (*models.TopicFull, error)
+	ListTopics(ctx context.Context, status string) ([]models.TopicSummary, error)
+	GetTopicByID(ctx context.Context, id string, opts models.ReadOptions) (*models.TopicDetail, error)
+	GetTopicFull(ctx context.Context, id string, opts models.ReadOptions) (*models.TopicFull, error)
 }
 
 // SQLiteTopicRepository implements TopicRepository using SQLite.
@@ -29,13 +29,16 @@ func NewTopicRepository(db *sql.DB) *SQLiteTopicRepository {
 const listTopicsSQL = `
 SELECT t.id, t.title, COALESCE(t.description, ''), COALESCE(t.difficulty, ''),
        COALESCE(t.estimated_hours, 0), t.tags, t.status,
-       (SELECT COUNT(*) FROM modules m WHERE m.topic_id = t.id) AS module_count
+       (SELECT COUNT(*) FROM modules m WHERE m.topic_id = t.id AND m.archived_at IS NULL) AS module_count
 FROM topics t
+WHERE ? = '' OR t.status = ?
 ORDER BY t.title
 `
 
-func (r *SQLiteTopicRepository) ListTopics(ctx context.Context) ([]models.TopicSummary, error) {
-	rows, err := r.db.QueryContext(ctx, listTopicsSQL)
+// ListTopics returns topic summaries ordered by title. A non-empty status
+// restricts the list to topics in that status.
+func (r *SQLiteTopicRepository) ListTopics(ctx context.Context, status string) ([]models.TopicSummary, error) {
+	rows, err := r.db.QueryContext(ctx, listTopicsSQL, status, status)
 	if err != nil {
 		return nil, fmt.Errorf("query topics: %w", err)
 	}
@@ -73,27 +76,35 @@ const getTopicSQL = `
 SELECT id, title, COALESCE(description, ''), COALESCE(difficulty, ''),
        COALESCE(estimated_hours, 0), tags, status, version,
        source_urls, COALESCE(generated_at, ''), COALESCE(generated_by, ''),
-       COALESCE(parent_topic_id, ''), created_at, updated_at
+       COALESCE(parent_topic_id, ''), aliases, created_at, updated_at, revision
 FROM topics
 WHERE id = ?
 `
 
 const getModulesForTopicSQL = `
-SELECT id, title, COALESCE(description, ''), COALESCE(estimated_minutes, 0), sort_order
+SELECT id, title, COALESCE(description, ''), COALESCE(estimated_minutes, 0), sort_order,
+       COALESCE(archived_at, '')
 FROM modules
-WHERE topic_id = ?
+WHERE topic_id = ? AND (? OR archived_at IS NULL)
 ORDER BY sort_order
 `
 
-func (r *SQLiteTopicRepository) GetTopicByID(ctx context.Context, id string) (*models.TopicDetail, error) {
+// GetTopicByID returns the topic with the given ID or alias, with its module
+// summaries. Archived modules are omitted unless opts.IncludeArchived is set.
+func (r *SQLiteTopicRepository) GetTopicByID(ctx context.Context, id string, opts models.ReadOptions) (*models.TopicDetail, error) {
+	id, err := ResolveTopicID(ctx, r.db, id)
+	if err != nil || id == "" {
+		return nil, err
+	}
+
 	td := &models.TopicDetail{}
-	var tagsRaw, sourceURLsRaw *string
+	var tagsRaw, sourceURLsRaw, aliasesRaw *string
 
-	err := r.db.QueryRowContext(ctx, getTopicSQL, id).Scan(
+	err = r.db.QueryRowContext(ctx, getTopicSQL, id).Scan(
 		&td.ID, &td.Title, &td.Description, &td.Difficulty,
 		&td.EstimatedHours, &tagsRaw, &td.Status, &td.Version,
 		&sourceURLsRaw, &td.GeneratedAt, &td.GeneratedBy,
-		&td.ParentTopicID, &td.CreatedAt, &td.UpdatedAt,
+		&td.ParentTopicID, &aliasesRaw, &td.CreatedAt, &td.UpdatedAt, &td.Revision,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -105,8 +116,9 @@ func (r *SQLiteTopicRepository) GetTopicByID(ctx context.Context, id string) (*m
 
 	td.Tags = models.ParseJSONStringSlice(tagsRaw)
 	td.SourceURLs = models.ParseJSONStringSlice(sourceURLsRaw)
+	td.Aliases = models.ParseJSONStringSlice(aliasesRaw)
 
-	modules, err := r.queryModuleSummaries(ctx, id)
+	modules, err := r.queryModuleSummaries(ctx, id, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -116,8 +128,8 @@ func (r *SQLiteTopicRepository) GetTopicByID(ctx context.Context, id string) (*m
 	return td, nil
 }
 
-func (r *SQLiteTopicRepository) queryModuleSummaries(ctx context.Context, topicID string) ([]models.ModuleSummary, error) {
-	rows, err := r.db.QueryContext(ctx, getModulesForTopicSQL, topicID)
+func (r *SQLiteTopicRepository) queryModuleSummaries(ctx context.Context, topicID string, opts models.ReadOptions) ([]models.ModuleSummary, error) {
+	rows, err := r.db.QueryContext(ctx, getModulesForTopicSQL, topicID, opts.IncludeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("query modules for topic %s: %w", topicID, err)
 	}
@@ -127,7 +139,7 @@ func (r *SQLiteTopicRepository) queryModuleSummaries(ctx context.Context, topicI
 
 	for rows.Next() {
 		var ms models.ModuleSummary
-		if err := rows.Scan(&ms.ID, &ms.Title, &ms.Description, &ms.EstimatedMinutes, &ms.SortOrder); err != nil {
+		if err := rows.Scan(&ms.ID, &ms.Title, &ms.Description, &ms.EstimatedMinutes, &ms.SortOrder, &ms.ArchivedAt); err != nil {
 			return nil, fmt.Errorf("scan module: %w", err)
 		}
 
@@ -147,37 +159,52 @@ func (r *SQLiteTopicRepository) queryModuleSummaries(ctx context.Context, topicI
 
 const getModulesFullSQL = `
 SELECT id, topic_id, title, COALESCE(description, ''), learning_objectives,
-       COALESCE(estimated_minutes, 0), sort_order, assessment
+       COALESCE(estimated_minutes, 0), sort_order, assessment, revision,
+       COALESCE(archived_at, '')
 FROM modules
-WHERE topic_id = ?
+WHERE topic_id = ? AND (? OR archived_at IS NULL)
 ORDER BY sort_order
 `
 
-const getLessonsForModuleSQL = `
-SELECT id, module_id, title, sort_order, COALESCE(estimated_minutes, 0),
-       content, examples, exercises, review_questions
-FROM lessons
-WHERE module_id = ?
-ORDER BY sort_order
+const getLessonsForTopicSQL = `
+SELECT l.id, l.module_id, l.title, l.sort_order, COALESCE(l.estimated_minutes, 0),
+       l.content, l.examples, l.exercises, l.review_questions, l.revision,
+       COALESCE(l.archived_at, '')
+FROM lessons l
+JOIN modules m ON m.id = l.module_id
+WHERE m.topic_id = ? AND (? OR l.archived_at IS NULL)
+ORDER BY m.sort_order, l.sort_order
 `
 
-const getConceptsForLessonSQL = `
-SELECT c.id, c.name, c.definition, COALESCE(c.difficulty, ''), c.status,
+const getConceptsForTopicLessonsSQL = `
+SELECT cr.lesson_id, c.id, c.name, c.definition, COALESCE(c.difficulty, ''), c.status,
        COALESCE(c.defined_in_topic, ''), c.aliases
 FROM concept_references cr
 JOIN concepts c ON c.id = cr.concept_id
-WHERE cr.lesson_id = ?
+JOIN lessons l ON l.id = cr.lesson_id
+JOIN modules m ON m.id = l.module_id
+WHERE m.topic_id = ? AND (? OR l.archived_at IS NULL)
+ORDER BY cr.rowid
 `
 
-func (r *SQLiteTopicRepository) GetTopicFull(ctx context.Context, id string) (*models.TopicFull, error) {
+// GetTopicFull loads the whole topic tree with one query per level (topic,
+// modules, lessons, concept references) and assembles it in memory, so the
+// query count does not grow with the size of the topic. Archived modules and
+// lessons are omitted unless opts.IncludeArchived is set.
+func (r *SQLiteTopicRepository) GetTopicFull(ctx context.Context, id string, opts models.ReadOptions) (*models.TopicFull, error) {
+	id, err := ResolveTopicID(ctx, r.db, id)
+	if err != nil || id == "" {
+		return nil, err
+	}
+
 	tf := &models.TopicFull{}
-	var tagsRaw, sourceURLsRaw *string
+	var tagsRaw, sourceURLsRaw, aliasesRaw *string
 
-	err := r.db.QueryRowContext(ctx, getTopicSQL, id).Scan(
+	err = r.db.QueryRowContext(ctx, getTopicSQL, id).Scan(
 		&tf.ID, &tf.Title, &tf.Description, &tf.Difficulty,
 		&tf.EstimatedHours, &tagsRaw, &tf.Status, &tf.Version,
 		&sourceURLsRaw, &tf.GeneratedAt, &tf.GeneratedBy,
-		&tf.ParentTopicID, &tf.CreatedAt, &tf.UpdatedAt,
+		&tf.ParentTopicID, &aliasesRaw, &tf.CreatedAt, &tf.UpdatedAt, &tf.Revision,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -189,8 +216,9 @@ func (r *SQLiteTopicRepository) GetTopicFull(ctx context.Context, id string) (*m
 
 	tf.Tags = models.ParseJSONStringSlice(tagsRaw)
 	tf.SourceURLs = models.ParseJSONStringSlice(sourceURLsRaw)
+	tf.Aliases = models.ParseJSONStringSlice(aliasesRaw)
 
-	modules, err := r.queryModulesFull(ctx, id)
+	modules, err := r.queryModulesFull(ctx, id, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -200,11 +228,12 @@ func (r *SQLiteTopicRepository) GetTopicFull(ctx context.Context, id string) (*m
 	return tf, nil
 }
 
-func (r *SQLiteTopicRepository) queryModulesFull(ctx context.Context, topicID string) ([]models.ModuleFull, error) {
-	rows, err := r.db.QueryContext(ctx, getModulesFullSQL, topicID)
+func (r *SQLiteTopicRepository) queryModulesFull(ctx context.Context, topicID string, opts models.ReadOptions) ([]models.ModuleFull, error) {
+	rows, err := r.db.QueryContext(ctx, getModulesFullSQL, topicID, opts.IncludeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("query modules full for topic %s: %w", topicID, err)
 	}
+	defer rows.Close()
 
 	var modules []models.ModuleFull
 
@@ -214,46 +243,57 @@ func (r *SQLiteTopicRepository) queryModulesFull(ctx context.Context, topicID st
 
 		if err := rows.Scan(
 			&mf.ID, &mf.TopicID, &mf.Title, &mf.Description, &loRaw,
-			&mf.EstimatedMinutes, &mf.SortOrder, &assessRaw,
+			&mf.EstimatedMinutes, &mf.SortOrder, &assessRaw, &mf.Revision, &mf.ArchivedAt,
 		); err != nil {
-			rows.Close()
 			return nil, fmt.Errorf("scan module full: %w", err)
 		}
 
 		mf.LearningObjectives = models.ParseJSONStringSlice(loRaw)
 		mf.Assessment = models.ParseJSONRaw(assessRaw)
+		mf.Lessons = []models.LessonFull{}
 		modules = append(modules, mf)
 	}
 
 	if err := rows.Err(); err != nil {
-		rows.Close()
 		return nil, fmt.Errorf("iterate modules full: %w", err)
 	}
 
-	rows.Close()
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("close modules full: %w", err)
+	}
 
-	// Connection is now released — safe to make nested queries.
-	for i := range modules {
-		lessons, err := r.queryLessonsFull(ctx, modules[i].ID)
-		if err != nil {
-			return nil, err
-		}
+	if modules == nil {
+		return []models.ModuleFull{}, nil
+	}
 
-		modules[i].Lessons = lessons
+	lessons, err := r.queryLessonsFull(ctx, topicID, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	if modules == nil {
-		modules = []models.ModuleFull{}
+	moduleIndex := make(map[string]int, len(modules))
+	for i := range modules {
+		moduleIndex[modules[i].ID] = i
+	}
+
+	for _, lf := range lessons {
+		if i, ok := moduleIndex[lf.ModuleID]; ok {
+			modules[i].Lessons = append(modules[i].Lessons, lf)
+		}
 	}
 
 	return modules, nil
 }
 
-func (r *SQLiteTopicRepository) queryLessonsFull(ctx context.Context, moduleID string) ([]models.LessonFull, error) {
-	rows, err := r.db.QueryContext(ctx, getLessonsForModuleSQL, moduleID)
+// queryLessonsFull returns every lesson in the topic, ordered by module and
+// lesson sort order, with concepts attached. Lessons of omitted modules are
+// dropped when they are attached to their module.
+func (r *SQLiteTopicRepository) queryLessonsFull(ctx context.Context, topicID string, opts models.ReadOptions) ([]models.LessonFull, error) {
+	rows, err := r.db.QueryContext(ctx, getLessonsForTopicSQL, topicID, opts.IncludeArchived)
 	if err != nil {
-		return nil, fmt.Errorf("query lessons for module %s: %w", moduleID, err)
+		return nil, fmt.Errorf("query lessons for topic %s: %w", topicID, err)
 	}
+	defer rows.Close()
 
 	var lessons []models.LessonFull
 
@@ -263,9 +303,8 @@ func (r *SQLiteTopicRepository) queryLessonsFull(ctx context.Context, moduleID s
 
 		if err := rows.Scan(
 			&lf.ID, &lf.ModuleID, &lf.Title, &lf.SortOrder, &lf.EstimatedMinutes,
-			&contentRaw, &examplesRaw, &exercisesRaw, &reviewRaw,
+			&contentRaw, &examplesRaw, &exercisesRaw, &reviewRaw, &lf.Revision, &lf.ArchivedAt,
 		); err != nil {
-			rows.Close()
 			return nil, fmt.Errorf("scan lesson full: %w", err)
 		}
 
@@ -277,48 +316,51 @@ func (r *SQLiteTopicRepository) queryLessonsFull(ctx context.Context, moduleID s
 	}
 
 	if err := rows.Err(); err != nil {
-		rows.Close()
 		return nil, fmt.Errorf("iterate lessons full: %w", err)
 	}
 
-	rows.Close()
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("close lessons full: %w", err)
+	}
 
-	// Connection is now released — safe to make nested queries.
-	for i := range lessons {
-		concepts, err := r.queryConceptsForLesson(ctx, lessons[i].ID)
-		if err != nil {
-			return nil, err
-		}
+	if lessons == nil {
+		return nil, nil
+	}
 
-		lessons[i].Concepts = concepts
+	concepts, err := r.queryConceptsForTopicLessons(ctx, topicID, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	if lessons == nil {
-		lessons = []models.LessonFull{}
+	for i := range lessons {
+		lessons[i].Concepts = concepts[lessons[i].ID]
 	}
 
 	return lessons, nil
 }
 
-func (r *SQLiteTopicRepository) queryConceptsForLesson(ctx context.Context, lessonID string) ([]models.ConceptSummary, error) {
-	rows, err := r.db.QueryContext(ctx, getConceptsForLessonSQL, lessonID)
+// queryConceptsForTopicLessons returns the concepts referenced by each lesson
+// in the topic, keyed by lesson ID.
+func (r *SQLiteTopicRepository) queryConceptsForTopicLessons(ctx context.Context, topicID string, opts models.ReadOptions) (map[string][]models.ConceptSummary, error) {
+	rows, err := r.db.QueryContext(ctx, getConceptsForTopicLessonsSQL, topicID, opts.IncludeArchived)
 	if err != nil {
-		return nil, fmt.Errorf("query concepts for lesson %s: %w", lessonID, err)
+		return nil, fmt.Errorf("query concepts for topic %s: %w", topicID, err)
 	}
 	defer rows.Close()
 
-	var concepts []models.ConceptSummary
+	concepts := make(map[string][]models.ConceptSummary)
 
 	for rows.Next() {
+		var lessonID string
 		var cs models.ConceptSummary
 		var aliasesRaw *string
 
-		if err := rows.Scan(&cs.ID, &cs.Name, &cs.Definition, &cs.Difficulty, &cs.Status, &cs.DefinedInTopic, &aliasesRaw); err != nil {
+		if err := rows.Scan(&lessonID, &cs.ID, &cs.Name, &cs.Definition, &cs.Difficulty, &cs.Status, &cs.DefinedInTopic, &aliasesRaw); err != nil {
 			return nil, fmt.Errorf("scan concept: %w", err)
 		}
 
 		cs.Aliases = models.ParseJSONStringSlice(aliasesRaw)
-		concepts = append(concepts, cs)
+		concepts[lessonID] = append(concepts[lessonID], cs)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -0,0 +1,243 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+const benchLessonsPerModule = 5
+
+func seedBenchTopic(b *testing.B, db *sql.DB, topicID string, modules int) {
+	b.Helper()
+
+	exec := func(query string, args ...any) {
+		if _, err := db.Exec(query, args...); err != nil {
+			b.Fatalf("exec %q: %v", query, err)
+		}
+	}
+
+	exec(`INSERT INTO topics (id, title, status) VALUES (?, ?, 'published')`, topicID, topicID)
+
+	for m := 0; m < modules; m++ {
+		moduleID := fmt.Sprintf("%s-m%d", topicID, m)
+		exec(`INSERT INTO modules (id, topic_id, title, sort_order) VALUES (?, ?, ?, ?)`, moduleID, topicID, moduleID, m)
+
+		for l := 0; l < benchLessonsPerModule; l++ {
+			lessonID := fmt.Sprintf("%s-l%d", moduleID, l)
+			exec(`INSERT INTO lessons (id, module_id, title, sort_order, content) VALUES (?, ?, ?, ?, ?)`,
+				lessonID, moduleID, lessonID, l, `[{"type":"text","body":"Hello"}]`)
+		}
+	}
+}
+
+// runIngestLoad simulates the research ingester by repeatedly holding write
+// transactions on the write handle until stop is closed.
+func runIngestLoad(b *testing.B, db *sql.DB, stop <-chan struct{}) {
+	b.Helper()
+
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			b.Errorf("begin ingest transaction: %v", err)
+			return
+		}
+
+		topicID := fmt.Sprintf("ingest-%d", i)
+		if _, err := tx.Exec(`INSERT INTO topics (id, title, status) VALUES (?, ?, 'draft')`, topicID, topicID); err != nil {
+			_ = tx.Rollback()
+			b.Errorf("ingest topic: %v", err)
+			return
+		}
+
+		// Ingest transactions spend most of their time doing work between
+		// statements; hold the transaction open to model that.
+		time.Sleep(2 * time.Millisecond)
+
+		if err := tx.Commit(); err != nil {
+			b.Errorf("commit ingest transaction: %v", err)
+			return
+		}
+	}
+}
+
+// BenchmarkGetTopicFullDuringIngest compares read throughput when readers
+// share the single write connection with an active ingest against readers
+// served from the dedicated read pool.
+func BenchmarkGetTopicFullDuringIngest(b *testing.B) {
+	for _, tc := range []struct {
+		name   string
+		readDB func(h *database.Handle) *sql.DB
+	}{
+		{name: "write-handle", readDB: func(h *database.Handle) *sql.DB { return h.DB }},
+		{name: "read-pool", readDB: func(h *database.Handle) *sql.DB { return h.ReadDB }},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			handle := setupTestHandle(b)
+
+			seedBenchTopic(b, handle.DB, "bench", 10)
+
+			repo := repository.NewTopicRepository(tc.readDB(handle))
+			ctx := context.Background()
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runIngestLoad(b, handle.DB, stop)
+			}()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					topic, err := repo.GetTopicFull(ctx, "bench", models.ReadOptions{})
+					if err != nil {
+						b.Errorf("GetTopicFull() returned error: %v", err)
+						return
+					}
+
+					if topic == nil {
+						b.Error("GetTopicFull() returned nil topic")
+						return
+					}
+				}
+			})
+			b.StopTimer()
+
+			close(stop)
+			wg.Wait()
+
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "reads/s")
+		})
+	}
+}
+
+const (
+	largePoolTopics            = 300
+	largePoolModulesPerTopic   = 8
+	largePoolLessonsPerModule  = 5
+	largePoolConceptsPerLesson = 2
+)
+
+// seedLargePool fills db with a knowledge pool of largePoolTopics topics,
+// each with a full module/lesson/concept tree. Concepts are also referenced
+// from the next lesson so concept joins fan out as they do in real data.
+func seedLargePool(b *testing.B, db *sql.DB) {
+	b.Helper()
+
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatalf("begin seed transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	exec := func(query string, args ...any) {
+		if _, err := tx.Exec(query, args...); err != nil {
+			b.Fatalf("exec %q: %v", query, err)
+		}
+	}
+
+	for t := 0; t < largePoolTopics; t++ {
+		topicID := fmt.Sprintf("topic-%03d", t)
+		exec(`INSERT INTO topics (id, title, status, tags) VALUES (?, ?, 'published', '["bench"]')`, topicID, topicID)
+
+		var prevLessonConcepts []string
+
+		for m := 0; m < largePoolModulesPerTopic; m++ {
+			moduleID := fmt.Sprintf("%s-m%d", topicID, m)
+			exec(`INSERT INTO modules (id, topic_id, title, sort_order, learning_objectives) VALUES (?, ?, ?, ?, '["learn"]')`,
+				moduleID, topicID, moduleID, m)
+
+			for l := 0; l < largePoolLessonsPerModule; l++ {
+				lessonID := fmt.Sprintf("%s-l%d", moduleID, l)
+				exec(`INSERT INTO lessons (id, module_id, title, sort_order, content) VALUES (?, ?, ?, ?, ?)`,
+					lessonID, moduleID, lessonID, l, `[{"type":"text","body":"Hello"}]`)
+
+				for _, conceptID := range prevLessonConcepts {
+					exec(`INSERT INTO concept_references (concept_id, lesson_id) VALUES (?, ?)`, conceptID, lessonID)
+				}
+
+				prevLessonConcepts = prevLessonConcepts[:0]
+
+				for c := 0; c < largePoolConceptsPerLesson; c++ {
+					conceptID := fmt.Sprintf("%s-c%d", lessonID, c)
+					exec(`INSERT INTO concepts (id, name, definition, defined_in_topic, defined_in_lesson, status) VALUES (?, ?, 'definition', ?, ?, 'active')`,
+						conceptID, conceptID, topicID, lessonID)
+					exec(`INSERT INTO concept_references (concept_id, lesson_id) VALUES (?, ?)`, conceptID, lessonID)
+					prevLessonConcepts = append(prevLessonConcepts, conceptID)
+				}
+
+				if l%2 == 0 {
+					exec(`INSERT INTO learning_progress (lesson_id, status) VALUES (?, 'completed')`, lessonID)
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("commit seed transaction: %v", err)
+	}
+}
+
+func BenchmarkLargePool(b *testing.B) {
+	handle := setupTestHandle(b)
+	seedLargePool(b, handle.DB)
+
+	ctx := context.Background()
+	topicID := fmt.Sprintf("topic-%03d", largePoolTopics/2)
+
+	b.Run("GetTopicFull", func(b *testing.B) {
+		repo := repository.NewTopicRepository(handle.ReadDB)
+
+		for b.Loop() {
+			topic, err := repo.GetTopicFull(ctx, topicID, models.ReadOptions{})
+			if err != nil || topic == nil {
+				b.Fatalf("GetTopicFull() = %v, %v", topic, err)
+			}
+		}
+	})
+
+	b.Run("ListTopics", func(b *testing.B) {
+		repo := repository.NewTopicRepository(handle.ReadDB)
+
+		for b.Loop() {
+			if _, err := repo.ListTopics(ctx, ""); err != nil {
+				b.Fatalf("ListTopics() returned error: %v", err)
+			}
+		}
+	})
+
+	b.Run("GetTopicProgress", func(b *testing.B) {
+		repo := repository.NewProgressRepository(handle.ReadDB, handle.DB)
+
+		for b.Loop() {
+			if _, err := repo.GetTopicProgress(ctx, topicID, models.ReadOptions{}); err != nil {
+				b.Fatalf("GetTopicProgress() returned error: %v", err)
+			}
+		}
+	})
+
+	b.Run("GetProgressSummary", func(b *testing.B) {
+		repo := repository.NewProgressRepository(handle.ReadDB, handle.DB)
+
+		for b.Loop() {
+			if _, err := repo.GetProgressSummary(ctx, models.ReadOptions{}); err != nil {
+				b.Fatalf("GetProgressSummary() returned error: %v", err)
+			}
+		}
+	})
+}
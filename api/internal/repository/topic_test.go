@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
 )
 
@@ -11,7 +12,7 @@ func TestListTopicsEmpty(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewTopicRepository(db)
 
-	topics, err := repo.ListTopics(context.Background())
+	topics, err := repo.ListTopics(context.Background(), "")
 	if err != nil {
 		t.Fatalf("list topics: %v", err)
 	}
@@ -29,7 +30,7 @@ func TestListTopicsOrderedByTitle(t *testing.T) {
 	seedTopic(t, db, "a-topic", "Algorithms", "intermediate", "published")
 	seedTopic(t, db, "b-topic", "Bash Scripting", "foundational", "published")
 
-	topics, err := repo.ListTopics(context.Background())
+	topics, err := repo.ListTopics(context.Background(), "")
 	if err != nil {
 		t.Fatalf("list topics: %v", err)
 	}
@@ -55,7 +56,7 @@ func TestListTopicsWithModuleCount(t *testing.T) {
 	seedModule(t, db, "mod-1", "go-topic", "Intro", 1)
 	seedModule(t, db, "mod-2", "go-topic", "Types", 2)
 
-	topics, err := repo.ListTopics(context.Background())
+	topics, err := repo.ListTopics(context.Background(), "")
 	if err != nil {
 		t.Fatalf("list topics: %v", err)
 	}
@@ -71,7 +72,7 @@ func TestListTopicsJSONTags(t *testing.T) {
 
 	seedTopic(t, db, "go-topic", "Go Basics", "foundational", "published")
 
-	topics, err := repo.ListTopics(context.Background())
+	topics, err := repo.ListTopics(context.Background(), "")
 	if err != nil {
 		t.Fatalf("list topics: %v", err)
 	}
@@ -89,7 +90,7 @@ func TestGetTopicByID(t *testing.T) {
 	seedModule(t, db, "mod-1", "go-topic", "Introduction", 1)
 	seedModule(t, db, "mod-2", "go-topic", "Types", 2)
 
-	topic, err := repo.GetTopicByID(context.Background(), "go-topic")
+	topic, err := repo.GetTopicByID(context.Background(), "go-topic", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("get topic: %v", err)
 	}
@@ -115,7 +116,7 @@ func TestGetTopicByIDNotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewTopicRepository(db)
 
-	topic, err := repo.GetTopicByID(context.Background(), "nonexistent")
+	topic, err := repo.GetTopicByID(context.Background(), "nonexistent", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -135,7 +136,7 @@ func TestGetTopicFull(t *testing.T) {
 	seedConcept(t, db, "concept-1", "Variable", "A named storage", "go-topic")
 	seedConceptReference(t, db, "concept-1", "lesson-1")
 
-	topic, err := repo.GetTopicFull(context.Background(), "go-topic")
+	topic, err := repo.GetTopicFull(context.Background(), "go-topic", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("get topic full: %v", err)
 	}
@@ -171,11 +172,68 @@ func TestGetTopicFull(t *testing.T) {
 	}
 }
 
+func TestGetTopicFullAssemblesMultipleModules(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewTopicRepository(db)
+
+	seedTopic(t, db, "go-topic", "Go Basics", "foundational", "published")
+	seedTopic(t, db, "other-topic", "Other", "foundational", "published")
+	seedModule(t, db, "mod-2", "go-topic", "Second", 2)
+	seedModule(t, db, "mod-1", "go-topic", "First", 1)
+	seedModule(t, db, "mod-empty", "go-topic", "Empty", 3)
+	seedModule(t, db, "other-mod", "other-topic", "Other", 1)
+	seedLesson(t, db, "lesson-2b", "mod-2", "2B", 2)
+	seedLesson(t, db, "lesson-2a", "mod-2", "2A", 1)
+	seedLesson(t, db, "lesson-1a", "mod-1", "1A", 1)
+	seedLesson(t, db, "other-lesson", "other-mod", "Other", 1)
+	seedConcept(t, db, "concept-1", "Variable", "A named storage", "go-topic")
+	seedConcept(t, db, "concept-2", "Pointer", "An address", "go-topic")
+	seedConceptReference(t, db, "concept-1", "lesson-1a")
+	seedConceptReference(t, db, "concept-1", "lesson-2b")
+	seedConceptReference(t, db, "concept-2", "lesson-2b")
+	seedConceptReference(t, db, "concept-2", "other-lesson")
+
+	topic, err := repo.GetTopicFull(context.Background(), "go-topic", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get topic full: %v", err)
+	}
+
+	if len(topic.Modules) != 3 {
+		t.Fatalf("expected 3 modules, got %d", len(topic.Modules))
+	}
+
+	if topic.Modules[0].ID != "mod-1" || topic.Modules[1].ID != "mod-2" || topic.Modules[2].ID != "mod-empty" {
+		t.Fatalf("expected modules ordered by sort_order, got %s, %s, %s",
+			topic.Modules[0].ID, topic.Modules[1].ID, topic.Modules[2].ID)
+	}
+
+	if topic.Modules[2].Lessons == nil || len(topic.Modules[2].Lessons) != 0 {
+		t.Fatalf("expected empty non-nil lessons for empty module, got %v", topic.Modules[2].Lessons)
+	}
+
+	second := topic.Modules[1].Lessons
+	if len(second) != 2 || second[0].ID != "lesson-2a" || second[1].ID != "lesson-2b" {
+		t.Fatalf("expected lessons [lesson-2a lesson-2b], got %+v", second)
+	}
+
+	if len(second[0].Concepts) != 0 {
+		t.Fatalf("expected no concepts on lesson-2a, got %d", len(second[0].Concepts))
+	}
+
+	if len(second[1].Concepts) != 2 {
+		t.Fatalf("expected 2 concepts on lesson-2b, got %d", len(second[1].Concepts))
+	}
+
+	if len(topic.Modules[0].Lessons[0].Concepts) != 1 {
+		t.Fatalf("expected 1 concept on lesson-1a, got %d", len(topic.Modules[0].Lessons[0].Concepts))
+	}
+}
+
 func TestGetTopicFullNotFound(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewTopicRepository(db)
 
-	topic, err := repo.GetTopicFull(context.Background(), "nonexistent")
+	topic, err := repo.GetTopicFull(context.Background(), "nonexistent", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -184,3 +242,28 @@ func TestGetTopicFullNotFound(t *testing.T) {
 		t.Fatal("expected nil topic for nonexistent ID")
 	}
 }
+
+func TestGetTopicByIDResolvesAlias(t *testing.T) {
+	db := setupTestDB(t)
+	seedTopic(t, db, "linux-administration", "Linux Administration", "foundational", "published")
+	mustExec(t, db, `UPDATE topics SET aliases = '["linux-admin"]' WHERE id = 'linux-administration'`)
+	repo := repository.NewTopicRepository(db)
+
+	td, err := repo.GetTopicByID(context.Background(), "linux-admin", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get topic: %v", err)
+	}
+
+	if td == nil || td.ID != "linux-administration" || len(td.Aliases) != 1 {
+		t.Fatalf("expected alias to resolve to linux-administration, got %+v", td)
+	}
+
+	tf, err := repo.GetTopicFull(context.Background(), "Linux Administration", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get topic full: %v", err)
+	}
+
+	if tf == nil || tf.ID != "linux-administration" {
+		t.Fatalf("expected normalized slug to resolve, got %+v", tf)
+	}
+}
@@ -12,19 +12,68 @@ import (
 
 // WriteRepository defines write operations for curriculum data.
 type WriteRepository interface {
-	CreateTopic(ctx context.Context, input models.TopicInput) error
-	UpdateTopic(ctx context.Context, id string, input models.TopicInput) error
-	CreateModule(ctx context.Context, input models.ModuleInput) error
-	CreateLesson(ctx context.Context, input models.LessonInput) error
-	CreateConcept(ctx context.Context, input models.ConceptInput) error
+	CreateTopic(ctx context.Context, input models.TopicInput) (int, error)
+	UpdateTopic(ctx context.Context, id string, ifRevision int, input models.TopicInput) (int, error)
+	CreateModule(ctx context.Context, input models.ModuleInput) (int, error)
+	CreateLesson(ctx context.Context, input models.LessonInput) (int, error)
+	CreateConcept(ctx context.Context, input models.ConceptInput) (int, error)
 	CreateConceptReference(ctx context.Context, conceptID string, input models.ConceptReferenceInput) error
 	CreatePrerequisite(ctx context.Context, input models.PrerequisiteInput) error
 	CreateRelation(ctx context.Context, input models.RelationInput) error
+
+	UpdateModule(ctx context.Context, id string, ifRevision int, input models.ModuleInput) (int, error)
+	UpdateLesson(ctx context.Context, id string, ifRevision int, input models.LessonInput) (int, error)
+	UpdateConcept(ctx context.Context, id string, ifRevision int, input models.ConceptInput) (int, error)
+	UpdateConceptReference(ctx context.Context, conceptID, lessonID string, input models.ConceptReferenceInput) error
+	UpdatePrerequisite(ctx context.Context, input models.PrerequisiteInput) error
+	UpdateRelation(ctx context.Context, input models.RelationInput) error
+
+	PatchTopic(ctx context.Context, id string, ifRevision int, apply func(*models.TopicInput) error) (*models.TopicInput, int, error)
+	PatchModule(ctx context.Context, id string, ifRevision int, apply func(*models.ModuleInput) error) (*models.ModuleInput, int, error)
+	PatchLesson(ctx context.Context, id string, ifRevision int, apply func(*models.LessonInput) error) (*models.LessonInput, int, error)
+	PatchConcept(ctx context.Context, id string, ifRevision int, apply func(*models.ConceptInput) error) (*models.ConceptInput, int, error)
+	PatchConceptReference(ctx context.Context, conceptID, lessonID string, apply func(*models.ConceptReferenceInput) error) (*models.ConceptReferenceInput, error)
+	PatchPrerequisite(ctx context.Context, topicID, prerequisiteID string, apply func(*models.PrerequisiteInput) error) (*models.PrerequisiteInput, error)
+	PatchRelation(ctx context.Context, topicA, topicB string, apply func(*models.RelationInput) error) (*models.RelationInput, error)
+
+	DeleteTopic(ctx context.Context, id string, force bool) error
+	DeleteModule(ctx context.Context, id string, force bool) error
+	DeleteLesson(ctx context.Context, id string, force bool) error
+	DeleteConcept(ctx context.Context, id string, force bool) error
+	DeleteConceptReference(ctx context.Context, conceptID, lessonID string) error
+	DeletePrerequisite(ctx context.Context, topicID, prerequisiteID string) error
+	DeleteRelation(ctx context.Context, topicA, topicB string) error
+
+	ReorderModules(ctx context.Context, topicID string, moduleIDs []string) error
+	ReorderLessons(ctx context.Context, moduleID string, lessonIDs []string) error
+	MoveLesson(ctx context.Context, lessonID, moduleID string, position int) error
+
+	MergeConcepts(ctx context.Context, targetID, sourceID string) (*models.ConceptMerge, error)
+
+	ArchiveModule(ctx context.Context, id string) error
+	UnarchiveModule(ctx context.Context, id string) error
+	ArchiveLesson(ctx context.Context, id string) error
+	UnarchiveLesson(ctx context.Context, id string) error
+
+	// Transaction runs fn against a repository bound to a single transaction.
+	// Every write fn makes commits together, or none do if fn returns an error.
+	Transaction(ctx context.Context, fn func(tx WriteRepository) error) error
+}
+
+// queryer is the subset of *sql.DB and *sql.Tx used by write operations, so
+// the same statements can run standalone or inside a transaction.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
-// SQLiteWriteRepository implements WriteRepository using SQLite.
+// SQLiteWriteRepository implements WriteRepository using SQLite. When tx is
+// set, the repository was handed out by Transaction and every statement runs
+// on that transaction instead of opening its own.
 type SQLiteWriteRepository struct {
 	db *sql.DB
+	tx *sql.Tx
 }
 
 // NewWriteRepository creates a new SQLiteWriteRepository.
@@ -32,13 +81,28 @@ func NewWriteRepository(db *sql.DB) *SQLiteWriteRepository {
 	return &SQLiteWriteRepository{db: db}
 }
 
+// conn returns the transaction when bound to one, otherwise the database.
+func (r *SQLiteWriteRepository) conn() queryer {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.db
+}
+
+func (r *SQLiteWriteRepository) Transaction(ctx context.Context, fn func(tx WriteRepository) error) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return fn(&SQLiteWriteRepository{db: r.db, tx: tx})
+	})
+}
+
 const createTopicSQL = `
 INSERT INTO topics (id, title, description, difficulty, estimated_hours, tags, status, version,
-                    source_urls, generated_at, generated_by, parent_topic_id)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                    source_urls, generated_at, generated_by, parent_topic_id, aliases)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
-func (r *SQLiteWriteRepository) CreateTopic(ctx context.Context, input models.TopicInput) error {
+func (r *SQLiteWriteRepository) CreateTopic(ctx context.Context, input models.TopicInput) (int, error) {
 	tags := marshalJSONOrNil(input.Tags)
 	sourceURLs := marshalJSONOrNil(input.SourceURLs)
 	version := input.Version
@@ -46,55 +110,62 @@ func (r *SQLiteWriteRepository) CreateTopic(ctx context.Context, input models.To
 		version = 1
 	}
 
-	_, err := r.db.ExecContext(ctx, createTopicSQL,
-		input.ID, input.Title, nullIfEmpty(input.Description), nullIfEmpty(input.Difficulty),
-		nullIfZeroFloat(input.EstimatedHours), tags, input.Status, version,
-		sourceURLs, nullIfEmpty(input.GeneratedAt), nullIfEmpty(input.GeneratedBy),
-		nullIfEmpty(input.ParentTopicID),
-	)
-	if err != nil {
-		return classifyError(err, "create topic")
-	}
+	var revision int
+
+	err := r.tracked(ctx, models.AuditEntityTopic, input.ID, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createTopicSQL,
+			input.ID, input.Title, nullIfEmpty(input.Description), nullIfEmpty(input.Difficulty),
+			nullIfZeroFloat(input.EstimatedHours), tags, input.Status, version,
+			sourceURLs, nullIfEmpty(input.GeneratedAt), nullIfEmpty(input.GeneratedBy),
+			nullIfEmpty(input.ParentTopicID), marshalJSONOrNil(input.Aliases),
+		)
+		if err != nil {
+			return classifyError(err, "create topic")
+		}
+
+		if err := upsertSearchIndex(ctx, q, "topic", input.ID, input.Title, input.Description); err != nil {
+			return err
+		}
+
+		revision, err = rowRevision(ctx, q, "topics", input.ID)
+
+		return err
+	})
 
-	return r.upsertSearchIndex(ctx, "topic", input.ID, input.Title, input.Description)
+	return revision, err
 }
 
 const updateTopicSQL = `
 UPDATE topics SET title = ?, description = ?, difficulty = ?, estimated_hours = ?,
-                  tags = ?, status = ?, version = ?,
+                  tags = ?, status = ?, version = COALESCE(?, version),
                   source_urls = ?, generated_at = ?, generated_by = ?,
-                  parent_topic_id = ?, updated_at = CURRENT_TIMESTAMP
+                  parent_topic_id = ?, aliases = ?, updated_at = CURRENT_TIMESTAMP,
+                  revision = revision + 1
 WHERE id = ?
 `
 
-func (r *SQLiteWriteRepository) UpdateTopic(ctx context.Context, id string, input models.TopicInput) error {
-	tags := marshalJSONOrNil(input.Tags)
-	sourceURLs := marshalJSONOrNil(input.SourceURLs)
-	version := input.Version
-	if version == 0 {
-		version = 1
-	}
+func (r *SQLiteWriteRepository) UpdateTopic(ctx context.Context, id string, ifRevision int, input models.TopicInput) (int, error) {
+	var revision int
 
-	result, err := r.db.ExecContext(ctx, updateTopicSQL,
-		input.Title, nullIfEmpty(input.Description), nullIfEmpty(input.Difficulty),
-		nullIfZeroFloat(input.EstimatedHours), tags, input.Status, version,
-		sourceURLs, nullIfEmpty(input.GeneratedAt), nullIfEmpty(input.GeneratedBy),
-		nullIfEmpty(input.ParentTopicID), id,
-	)
-	if err != nil {
-		return classifyError(err, "update topic")
-	}
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		_, currentRevision, err := loadTopicInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("update topic rows affected: %w", err)
-	}
+		if err := checkRevision("topic", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		revision = currentRevision + 1
 
-	if rows == 0 {
-		return fmt.Errorf("topic %s: %w", id, ErrNotFound)
+		return updateTopicRow(ctx, tx, id, input)
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return r.upsertSearchIndex(ctx, "topic", id, input.Title, input.Description)
+	return revision, nil
 }
 
 const createModuleSQL = `
@@ -102,19 +173,31 @@ INSERT INTO modules (id, topic_id, title, description, learning_objectives, esti
 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 `
 
-func (r *SQLiteWriteRepository) CreateModule(ctx context.Context, input models.ModuleInput) error {
+func (r *SQLiteWriteRepository) CreateModule(ctx context.Context, input models.ModuleInput) (int, error) {
 	lo := marshalJSONOrNil(input.LearningObjectives)
 	assessment := rawJSONOrNil(input.Assessment)
 
-	_, err := r.db.ExecContext(ctx, createModuleSQL,
-		input.ID, input.TopicID, input.Title, nullIfEmpty(input.Description),
-		lo, nullIfZero(input.EstimatedMinutes), input.SortOrder, assessment,
-	)
-	if err != nil {
-		return classifyError(err, "create module")
-	}
+	var revision int
 
-	return nil
+	err := r.tracked(ctx, models.AuditEntityModule, input.ID, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createModuleSQL,
+			input.ID, input.TopicID, input.Title, nullIfEmpty(input.Description),
+			lo, nullIfZero(input.EstimatedMinutes), input.SortOrder, assessment,
+		)
+		if err != nil {
+			return classifyError(err, "create module")
+		}
+
+		if err := syncModuleQuestions(ctx, q, input.ID); err != nil {
+			return err
+		}
+
+		revision, err = rowRevision(ctx, q, "modules", input.ID)
+
+		return err
+	})
+
+	return revision, err
 }
 
 const createLessonSQL = `
@@ -122,18 +205,34 @@ INSERT INTO lessons (id, module_id, title, sort_order, estimated_minutes, conten
 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
-func (r *SQLiteWriteRepository) CreateLesson(ctx context.Context, input models.LessonInput) error {
-	_, err := r.db.ExecContext(ctx, createLessonSQL,
-		input.ID, input.ModuleID, input.Title, input.SortOrder,
-		nullIfZero(input.EstimatedMinutes), string(input.Content),
-		rawJSONOrNil(input.Examples), rawJSONOrNil(input.Exercises),
-		rawJSONOrNil(input.ReviewQuestions),
-	)
-	if err != nil {
-		return classifyError(err, "create lesson")
-	}
+func (r *SQLiteWriteRepository) CreateLesson(ctx context.Context, input models.LessonInput) (int, error) {
+	var revision int
+
+	err := r.tracked(ctx, models.AuditEntityLesson, input.ID, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createLessonSQL,
+			input.ID, input.ModuleID, input.Title, input.SortOrder,
+			nullIfZero(input.EstimatedMinutes), string(input.Content),
+			rawJSONOrNil(input.Examples), rawJSONOrNil(input.Exercises),
+			rawJSONOrNil(input.ReviewQuestions),
+		)
+		if err != nil {
+			return classifyError(err, "create lesson")
+		}
+
+		if err := upsertSearchIndex(ctx, q, "lesson", input.ID, input.Title, ""); err != nil {
+			return err
+		}
 
-	return r.upsertSearchIndex(ctx, "lesson", input.ID, input.Title, "")
+		if err := syncLessonQuestions(ctx, q, input.ID); err != nil {
+			return err
+		}
+
+		revision, err = rowRevision(ctx, q, "lessons", input.ID)
+
+		return err
+	})
+
+	return revision, err
 }
 
 const createConceptSQL = `
@@ -142,24 +241,51 @@ INSERT INTO concepts (id, name, definition, defined_in_lesson, defined_in_topic,
 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
-func (r *SQLiteWriteRepository) CreateConcept(ctx context.Context, input models.ConceptInput) error {
+func (r *SQLiteWriteRepository) CreateConcept(ctx context.Context, input models.ConceptInput) (int, error) {
 	aliases := marshalJSONOrNil(input.Aliases)
 	status := input.Status
 	if status == "" {
 		status = "active"
 	}
 
-	_, err := r.db.ExecContext(ctx, createConceptSQL,
-		input.ID, input.Name, input.Definition,
-		nullIfEmpty(input.DefinedInLesson), nullIfEmpty(input.DefinedInTopic),
-		nullIfEmpty(input.Difficulty), nullIfEmpty(input.FlashcardFront),
-		nullIfEmpty(input.FlashcardBack), status, aliases,
-	)
-	if err != nil {
-		return classifyError(err, "create concept")
+	var revision int
+
+	err := r.tracked(ctx, models.AuditEntityConcept, input.ID, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createConceptSQL,
+			input.ID, input.Name, input.Definition,
+			nullIfEmpty(input.DefinedInLesson), nullIfEmpty(input.DefinedInTopic),
+			nullIfEmpty(input.Difficulty), nullIfEmpty(input.FlashcardFront),
+			nullIfEmpty(input.FlashcardBack), status, aliases,
+		)
+		if err != nil {
+			return classifyError(err, "create concept")
+		}
+
+		if err := upsertSearchIndex(ctx, q, "concept", input.ID, input.Name, input.Definition); err != nil {
+			return err
+		}
+
+		if err := syncTopicCards(ctx, q, input.DefinedInTopic); err != nil {
+			return err
+		}
+
+		revision, err = rowRevision(ctx, q, "concepts", input.ID)
+
+		return err
+	})
+
+	return revision, err
+}
+
+// rowRevision returns the revision of row id in table, for the ETag of a
+// row just created.
+func rowRevision(ctx context.Context, q queryer, table, id string) (int, error) {
+	var revision int
+	if err := q.QueryRowContext(ctx, "SELECT revision FROM "+table+" WHERE id = ?", id).Scan(&revision); err != nil {
+		return 0, fmt.Errorf("read %s %s revision: %w", table, id, err)
 	}
 
-	return r.upsertSearchIndex(ctx, "concept", input.ID, input.Name, input.Definition)
+	return revision, nil
 }
 
 const createConceptRefSQL = `
@@ -168,14 +294,16 @@ VALUES (?, ?, ?)
 `
 
 func (r *SQLiteWriteRepository) CreateConceptReference(ctx context.Context, conceptID string, input models.ConceptReferenceInput) error {
-	_, err := r.db.ExecContext(ctx, createConceptRefSQL,
-		conceptID, input.LessonID, nullIfEmpty(input.Context),
-	)
-	if err != nil {
-		return classifyError(err, "create concept reference")
-	}
+	return r.tracked(ctx, models.AuditEntityConceptReference, conceptID+"/"+input.LessonID, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createConceptRefSQL,
+			conceptID, input.LessonID, nullIfEmpty(input.Context),
+		)
+		if err != nil {
+			return classifyError(err, "create concept reference")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 const createPrerequisiteSQL = `
@@ -184,14 +312,16 @@ VALUES (?, ?, ?, ?)
 `
 
 func (r *SQLiteWriteRepository) CreatePrerequisite(ctx context.Context, input models.PrerequisiteInput) error {
-	_, err := r.db.ExecContext(ctx, createPrerequisiteSQL,
-		input.TopicID, input.PrerequisiteTopicID, input.Priority, nullIfEmpty(input.Reason),
-	)
-	if err != nil {
-		return classifyError(err, "create prerequisite")
-	}
+	return r.tracked(ctx, models.AuditEntityPrerequisite, input.TopicID+"/"+input.PrerequisiteTopicID, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createPrerequisiteSQL,
+			input.TopicID, input.PrerequisiteTopicID, input.Priority, nullIfEmpty(input.Reason),
+		)
+		if err != nil {
+			return classifyError(err, "create prerequisite")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 const createRelationSQL = `
@@ -200,26 +330,28 @@ VALUES (?, ?, ?, ?)
 `
 
 func (r *SQLiteWriteRepository) CreateRelation(ctx context.Context, input models.RelationInput) error {
-	_, err := r.db.ExecContext(ctx, createRelationSQL,
-		input.TopicA, input.TopicB, input.RelationType, nullIfEmpty(input.Description),
-	)
-	if err != nil {
-		return classifyError(err, "create relation")
-	}
+	return r.tracked(ctx, models.AuditEntityRelation, input.TopicA+"/"+input.TopicB, func(q queryer) error {
+		_, err := q.ExecContext(ctx, createRelationSQL,
+			input.TopicA, input.TopicB, input.RelationType, nullIfEmpty(input.Description),
+		)
+		if err != nil {
+			return classifyError(err, "create relation")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // FTS5 virtual tables don't support ON CONFLICT. Use delete + insert instead.
 const deleteSearchSQL = `DELETE FROM search_index WHERE entity_type = ? AND entity_id = ?`
 const insertSearchSQL = `INSERT INTO search_index (entity_type, entity_id, title, body) VALUES (?, ?, ?, ?)`
 
-func (r *SQLiteWriteRepository) upsertSearchIndex(ctx context.Context, entityType, entityID, title, body string) error {
-	if _, err := r.db.ExecContext(ctx, deleteSearchSQL, entityType, entityID); err != nil {
+func upsertSearchIndex(ctx context.Context, q queryer, entityType, entityID, title, body string) error {
+	if _, err := q.ExecContext(ctx, deleteSearchSQL, entityType, entityID); err != nil {
 		return fmt.Errorf("delete search index for %s %s: %w", entityType, entityID, err)
 	}
 
-	_, err := r.db.ExecContext(ctx, insertSearchSQL, entityType, entityID, title, body)
+	_, err := q.ExecContext(ctx, insertSearchSQL, entityType, entityID, title, body)
 	if err != nil {
 		return fmt.Errorf("insert search index for %s %s: %w", entityType, entityID, err)
 	}
@@ -227,6 +359,40 @@ func (r *SQLiteWriteRepository) upsertSearchIndex(ctx context.Context, entityTyp
 	return nil
 }
 
+// withTx runs fn in a transaction, committing on success. A repository
+// already bound to a transaction reuses it and leaves the commit to its owner.
+func (r *SQLiteWriteRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// tracked runs fn in a transaction and records the change it makes to the
+// named row in the audit log.
+func (r *SQLiteWriteRepository) tracked(ctx context.Context, entityType, entityID string, fn func(q queryer) error) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return trackChange(ctx, tx, entityType, entityID, func() error {
+			return fn(tx)
+		})
+	})
+}
+
 func classifyError(err error, op string) error {
 	msg := err.Error()
 
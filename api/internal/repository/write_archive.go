@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const archiveModuleSQL = `
+UPDATE modules SET archived_at = ?, revision = revision + 1
+WHERE id = ? AND archived_at IS NULL
+`
+
+const unarchiveModuleSQL = `
+UPDATE modules SET archived_at = NULL, revision = revision + 1
+WHERE id = ? AND archived_at IS NOT NULL
+`
+
+const archiveLessonSQL = `
+UPDATE lessons SET archived_at = ?, revision = revision + 1
+WHERE id = ? AND archived_at IS NULL
+`
+
+const unarchiveLessonSQL = `
+UPDATE lessons SET archived_at = NULL, revision = revision + 1
+WHERE id = ? AND archived_at IS NOT NULL
+`
+
+// ArchiveModule hides a module from topic trees, search, and progress totals
+// without touching its progress rows or concept references, so an unarchive
+// restores it exactly. Archiving an archived row, or unarchiving a live one,
+// is a no-op that keeps the original timestamp and revision; the lesson
+// methods behave the same way.
+func (r *SQLiteWriteRepository) ArchiveModule(ctx context.Context, id string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	return r.setArchived(ctx, moduleExistsSQL, "module", id, archiveModuleSQL, now, id)
+}
+
+func (r *SQLiteWriteRepository) UnarchiveModule(ctx context.Context, id string) error {
+	return r.setArchived(ctx, moduleExistsSQL, "module", id, unarchiveModuleSQL, id)
+}
+
+func (r *SQLiteWriteRepository) ArchiveLesson(ctx context.Context, id string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	return r.setArchived(ctx, checkLessonExistsSQL, "lesson", id, archiveLessonSQL, now, id)
+}
+
+func (r *SQLiteWriteRepository) UnarchiveLesson(ctx context.Context, id string) error {
+	return r.setArchived(ctx, checkLessonExistsSQL, "lesson", id, unarchiveLessonSQL, id)
+}
+
+// setArchived runs an archive state change. When no row changes, the row is
+// either missing (ErrNotFound) or already in the requested state (no-op).
+func (r *SQLiteWriteRepository) setArchived(ctx context.Context, existsSQL, entity, id, stmt string, args ...any) error {
+	return r.tracked(ctx, entity, id, func(q queryer) error {
+		result, err := q.ExecContext(ctx, stmt, args...)
+		if err != nil {
+			return fmt.Errorf("set archived on %s %s: %w", entity, id, err)
+		}
+
+		changed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s rows affected: %w", entity, err)
+		}
+
+		if changed > 0 {
+			return nil
+		}
+
+		var exists bool
+		if err := q.QueryRowContext(ctx, existsSQL, id).Scan(&exists); err != nil {
+			return fmt.Errorf("check %s %s: %w", entity, id, err)
+		}
+
+		if !exists {
+			return fmt.Errorf("%s %s: %w", entity, id, ErrNotFound)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,150 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func TestArchiveHidesFromReadViews(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedOrderTree(t, db)
+	seedConcept(t, db, "c1", "Goroutine", "A lightweight thread", "t1")
+	seedConceptReference(t, db, "c1", "l1")
+	mustExec(t, db, `INSERT INTO learning_progress (lesson_id, status) VALUES ('l1', 'completed')`)
+	mustExec(t, db, `INSERT INTO search_index (entity_type, entity_id, title, body) VALUES ('lesson', 'l1', 'One', 'archival marker')`)
+
+	write := repository.NewWriteRepository(db)
+	if err := write.ArchiveLesson(ctx, "l1"); err != nil {
+		t.Fatalf("archive lesson: %v", err)
+	}
+
+	if err := write.ArchiveModule(ctx, "m2"); err != nil {
+		t.Fatalf("archive module: %v", err)
+	}
+
+	live := models.ReadOptions{}
+	all := models.ReadOptions{IncludeArchived: true}
+
+	topics := repository.NewTopicRepository(db)
+
+	td, err := topics.GetTopicByID(ctx, "t1", live)
+	if err != nil {
+		t.Fatalf("get topic: %v", err)
+	}
+
+	if len(td.Modules) != 2 {
+		t.Fatalf("expected 2 live modules, got %d", len(td.Modules))
+	}
+
+	tf, err := topics.GetTopicFull(ctx, "t1", live)
+	if err != nil {
+		t.Fatalf("get topic full: %v", err)
+	}
+
+	if got := len(tf.Modules[0].Lessons); got != 2 {
+		t.Fatalf("expected 2 live lessons in m1, got %d", got)
+	}
+
+	tf, err = topics.GetTopicFull(ctx, "t1", all)
+	if err != nil {
+		t.Fatalf("get topic full with archived: %v", err)
+	}
+
+	if len(tf.Modules) != 3 || tf.Modules[1].ArchivedAt == "" {
+		t.Fatalf("expected archived m2 in full tree, got %+v", tf.Modules)
+	}
+
+	first := tf.Modules[0].Lessons[0]
+	if first.ID != "l1" || first.ArchivedAt == "" || len(first.Concepts) != 1 {
+		t.Fatalf("expected archived l1 with its concept, got %+v", first)
+	}
+
+	search := repository.NewSearchRepository(db)
+	params := models.PaginationParams{Page: 1, PerPage: 10}
+
+	result, err := search.Search(ctx, "archival", params, live)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	if result.Total != 0 {
+		t.Fatalf("expected archived lesson hidden from search, got %d hits", result.Total)
+	}
+
+	result, err = search.Search(ctx, "archival", params, all)
+	if err != nil {
+		t.Fatalf("search with archived: %v", err)
+	}
+
+	if result.Total != 1 {
+		t.Fatalf("expected 1 hit with archived, got %d", result.Total)
+	}
+
+	progress := repository.NewProgressRepository(db, db)
+
+	summary, err := progress.GetProgressSummary(ctx, live)
+	if err != nil {
+		t.Fatalf("progress summary: %v", err)
+	}
+
+	if summary.TotalLessons != 2 || summary.CompletedLessons != 0 {
+		t.Fatalf("expected 2 live lessons and 0 completed, got %+v", summary)
+	}
+
+	tp, err := progress.GetTopicProgress(ctx, "t1", all)
+	if err != nil {
+		t.Fatalf("topic progress: %v", err)
+	}
+
+	if len(tp.Lessons) != 4 || !tp.Lessons[0].Archived || tp.Lessons[0].Status != models.ProgressStatusCompleted {
+		t.Fatalf("expected archived l1 to keep its progress, got %+v", tp.Lessons)
+	}
+
+	if err := write.UnarchiveLesson(ctx, "l1"); err != nil {
+		t.Fatalf("unarchive lesson: %v", err)
+	}
+
+	summary, err = progress.GetProgressSummary(ctx, live)
+	if err != nil {
+		t.Fatalf("progress summary after unarchive: %v", err)
+	}
+
+	if summary.TotalLessons != 3 || summary.CompletedLessons != 1 {
+		t.Fatalf("expected restored lesson to count again, got %+v", summary)
+	}
+}
+
+func TestArchiveIsIdempotent(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	seedOrderTree(t, db)
+	repo := repository.NewWriteRepository(db)
+
+	for range 2 {
+		if err := repo.ArchiveModule(ctx, "m1"); err != nil {
+			t.Fatalf("archive module: %v", err)
+		}
+	}
+
+	md, err := repository.NewModuleRepository(db).GetModuleByID(ctx, "m1", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get module: %v", err)
+	}
+
+	if md.ArchivedAt == "" || md.Revision != 2 {
+		t.Fatalf("expected archived module at revision 2, got %q rev %d", md.ArchivedAt, md.Revision)
+	}
+
+	if err := repo.UnarchiveLesson(ctx, "l1"); err != nil {
+		t.Fatalf("unarchive live lesson: %v", err)
+	}
+
+	if err := repo.ArchiveLesson(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
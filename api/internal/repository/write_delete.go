@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+const countTopicProgressSQL = `
+SELECT COUNT(*)
+FROM learning_progress lp
+JOIN lessons l ON l.id = lp.lesson_id
+JOIN modules m ON m.id = l.module_id
+WHERE m.topic_id = ?
+`
+
+const deleteTopicLessonSearchSQL = `
+DELETE FROM search_index
+WHERE entity_type = 'lesson'
+  AND entity_id IN (
+    SELECT l.id FROM lessons l JOIN modules m ON m.id = l.module_id WHERE m.topic_id = ?
+  )
+`
+
+const deleteTopicSQL = `DELETE FROM topics WHERE id = ?`
+
+// DeleteTopic relies on the schema's ON DELETE rules to remove the topic's
+// modules and lessons. search_index is an FTS5 table with no foreign keys,
+// so the rows for every entity removed by the cascade are deleted
+// explicitly in the same transaction, as the other deletes do.
+func (r *SQLiteWriteRepository) DeleteTopic(ctx context.Context, id string, force bool) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if !force {
+			if err := refuseIfProgress(ctx, tx, countTopicProgressSQL, "topic", id); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteTopicLessonSearchSQL, id); err != nil {
+			return fmt.Errorf("delete search index for topic %s lessons: %w", id, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteSearchSQL, "topic", id); err != nil {
+			return fmt.Errorf("delete search index for topic %s: %w", id, err)
+		}
+
+		return deleteRow(ctx, tx, deleteTopicSQL, "topic", id)
+	})
+}
+
+const countModuleProgressSQL = `
+SELECT COUNT(*)
+FROM learning_progress lp
+JOIN lessons l ON l.id = lp.lesson_id
+WHERE l.module_id = ?
+`
+
+const deleteModuleLessonSearchSQL = `
+DELETE FROM search_index
+WHERE entity_type = 'lesson'
+  AND entity_id IN (SELECT id FROM lessons WHERE module_id = ?)
+`
+
+const deleteModuleSQL = `DELETE FROM modules WHERE id = ?`
+
+func (r *SQLiteWriteRepository) DeleteModule(ctx context.Context, id string, force bool) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if !force {
+			if err := refuseIfProgress(ctx, tx, countModuleProgressSQL, "module", id); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteModuleLessonSearchSQL, id); err != nil {
+			return fmt.Errorf("delete search index for module %s lessons: %w", id, err)
+		}
+
+		return deleteRow(ctx, tx, deleteModuleSQL, "module", id)
+	})
+}
+
+const countLessonProgressSQL = `SELECT COUNT(*) FROM learning_progress WHERE lesson_id = ?`
+
+const deleteLessonSQL = `DELETE FROM lessons WHERE id = ?`
+
+func (r *SQLiteWriteRepository) DeleteLesson(ctx context.Context, id string, force bool) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if !force {
+			if err := refuseIfProgress(ctx, tx, countLessonProgressSQL, "lesson", id); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteSearchSQL, "lesson", id); err != nil {
+			return fmt.Errorf("delete search index for lesson %s: %w", id, err)
+		}
+
+		return deleteRow(ctx, tx, deleteLessonSQL, "lesson", id)
+	})
+}
+
+const countConceptRetentionSQL = `SELECT COUNT(*) FROM concept_retention WHERE concept_id = ?`
+
+const deleteConceptSQL = `DELETE FROM concepts WHERE id = ?`
+
+func (r *SQLiteWriteRepository) DeleteConcept(ctx context.Context, id string, force bool) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if !force {
+			if err := refuseIfProgress(ctx, tx, countConceptRetentionSQL, "concept", id); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteSearchSQL, "concept", id); err != nil {
+			return fmt.Errorf("delete search index for concept %s: %w", id, err)
+		}
+
+		topicID, err := conceptTopic(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := deleteRow(ctx, tx, deleteConceptSQL, "concept", id); err != nil {
+			return err
+		}
+
+		// The concept's name no longer makes cloze cards in its topic.
+		return syncTopicCards(ctx, tx, topicID)
+	})
+}
+
+const deleteConceptRefSQL = `DELETE FROM concept_references WHERE concept_id = ? AND lesson_id = ?`
+
+func (r *SQLiteWriteRepository) DeleteConceptReference(ctx context.Context, conceptID, lessonID string) error {
+	return r.tracked(ctx, models.AuditEntityConceptReference, conceptID+"/"+lessonID, func(q queryer) error {
+		result, err := q.ExecContext(ctx, deleteConceptRefSQL, conceptID, lessonID)
+		if err != nil {
+			return classifyError(err, "delete concept reference")
+		}
+
+		return requireAffected(result, "concept reference", conceptID+"/"+lessonID)
+	})
+}
+
+const deletePrerequisiteSQL = `DELETE FROM topic_prerequisites WHERE topic_id = ? AND prerequisite_topic_id = ?`
+
+func (r *SQLiteWriteRepository) DeletePrerequisite(ctx context.Context, topicID, prerequisiteID string) error {
+	return r.tracked(ctx, models.AuditEntityPrerequisite, topicID+"/"+prerequisiteID, func(q queryer) error {
+		result, err := q.ExecContext(ctx, deletePrerequisiteSQL, topicID, prerequisiteID)
+		if err != nil {
+			return classifyError(err, "delete prerequisite")
+		}
+
+		return requireAffected(result, "prerequisite", topicID+"/"+prerequisiteID)
+	})
+}
+
+const deleteRelationSQL = `DELETE FROM topic_relations WHERE topic_a = ? AND topic_b = ?`
+
+func (r *SQLiteWriteRepository) DeleteRelation(ctx context.Context, topicA, topicB string) error {
+	return r.tracked(ctx, models.AuditEntityRelation, topicA+"/"+topicB, func(q queryer) error {
+		result, err := q.ExecContext(ctx, deleteRelationSQL, topicA, topicB)
+		if err != nil {
+			return classifyError(err, "delete relation")
+		}
+
+		return requireAffected(result, "relation", topicA+"/"+topicB)
+	})
+}
+
+// refuseIfProgress returns ErrHasProgress when countSQL reports any progress
+// rows for the entity. Topic, module, lesson, and concept deletes call it
+// unless force is set, so a cascade never silently removes learning
+// progress or review state.
+func refuseIfProgress(ctx context.Context, q queryer, countSQL, entity, id string) error {
+	var count int
+	if err := q.QueryRowContext(ctx, countSQL, id).Scan(&count); err != nil {
+		return fmt.Errorf("count progress for %s %s: %w", entity, id, err)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%s %s has %d progress record(s): %w", entity, id, count, ErrHasProgress)
+	}
+
+	return nil
+}
+
+// deleteRow deletes one row by ID. entity doubles as the audit entity type.
+func deleteRow(ctx context.Context, q queryer, deleteSQL, entity, id string) error {
+	return trackChange(ctx, q, entity, id, func() error {
+		result, err := q.ExecContext(ctx, deleteSQL, id)
+		if err != nil {
+			return classifyError(err, "delete "+entity)
+		}
+
+		return requireAffected(result, entity, id)
+	})
+}
@@ -0,0 +1,191 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func countRows(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("count %q: %v", query, err)
+	}
+
+	return n
+}
+
+// seedIndexedTree creates a topic with one module and two lessons through the
+// write repository so search_index rows exist for each.
+func seedIndexedTree(t *testing.T, db *sql.DB) *repository.SQLiteWriteRepository {
+	t.Helper()
+
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.CreateTopic(ctx, models.TopicInput{ID: "t1", Title: "Topic", Status: "published"}); err != nil {
+		t.Fatalf("create topic: %v", err)
+	}
+
+	if _, err := repo.CreateModule(ctx, models.ModuleInput{ID: "m1", TopicID: "t1", Title: "Module", SortOrder: 1}); err != nil {
+		t.Fatalf("create module: %v", err)
+	}
+
+	for _, id := range []string{"l1", "l2"} {
+		if _, err := repo.CreateLesson(ctx, models.LessonInput{ID: id, ModuleID: "m1", Title: id, Content: []byte(`[]`)}); err != nil {
+			t.Fatalf("create lesson: %v", err)
+		}
+	}
+
+	if _, err := repo.CreateConcept(ctx, models.ConceptInput{ID: "c1", Name: "Concept", Definition: "def", DefinedInTopic: "t1"}); err != nil {
+		t.Fatalf("create concept: %v", err)
+	}
+
+	return repo
+}
+
+func TestDeleteLessonRefusesWithProgress(t *testing.T) {
+	db := setupTestDB(t)
+	repo := seedIndexedTree(t, db)
+	ctx := context.Background()
+
+	mustExec(t, db, `INSERT INTO learning_progress (lesson_id, status) VALUES ('l1', 'completed')`)
+
+	if err := repo.DeleteLesson(ctx, "l1", false); !errors.Is(err, repository.ErrHasProgress) {
+		t.Fatalf("expected ErrHasProgress, got %v", err)
+	}
+
+	if countRows(t, db, `SELECT COUNT(*) FROM lessons WHERE id = 'l1'`) != 1 {
+		t.Fatal("expected lesson to survive refused delete")
+	}
+
+	if err := repo.DeleteLesson(ctx, "l1", true); err != nil {
+		t.Fatalf("force delete lesson: %v", err)
+	}
+
+	if countRows(t, db, `SELECT COUNT(*) FROM learning_progress`) != 0 {
+		t.Fatal("expected progress to cascade on forced delete")
+	}
+
+	if countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type = 'lesson' AND entity_id = 'l1'`) != 0 {
+		t.Fatal("expected lesson search row to be removed")
+	}
+
+	if err := repo.DeleteLesson(ctx, "l1", false); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+	}
+}
+
+func TestDeleteModuleRemovesLessonSearchRows(t *testing.T) {
+	db := setupTestDB(t)
+	repo := seedIndexedTree(t, db)
+
+	if err := repo.DeleteModule(context.Background(), "m1", false); err != nil {
+		t.Fatalf("delete module: %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM lessons`); n != 0 {
+		t.Fatalf("expected lessons to cascade, %d remain", n)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type = 'lesson'`); n != 0 {
+		t.Fatalf("expected lesson search rows to be removed, %d remain", n)
+	}
+}
+
+func TestDeleteTopicCascadesAndCleansSearchIndex(t *testing.T) {
+	db := setupTestDB(t)
+	repo := seedIndexedTree(t, db)
+	ctx := context.Background()
+
+	mustExec(t, db, `INSERT INTO learning_progress (lesson_id, status) VALUES ('l2', 'in_progress')`)
+
+	if err := repo.DeleteTopic(ctx, "t1", false); !errors.Is(err, repository.ErrHasProgress) {
+		t.Fatalf("expected ErrHasProgress, got %v", err)
+	}
+
+	if err := repo.DeleteTopic(ctx, "t1", true); err != nil {
+		t.Fatalf("force delete topic: %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type IN ('topic', 'lesson')`); n != 0 {
+		t.Fatalf("expected topic and lesson search rows to be removed, %d remain", n)
+	}
+
+	// Concepts outlive their defining topic (ON DELETE SET NULL).
+	if n := countRows(t, db, `SELECT COUNT(*) FROM concepts WHERE id = 'c1' AND defined_in_topic IS NULL`); n != 1 {
+		t.Fatal("expected concept to survive with defined_in_topic cleared")
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type = 'concept'`); n != 1 {
+		t.Fatal("expected concept search row to remain")
+	}
+}
+
+func TestDeleteTopicWithChildTopicFails(t *testing.T) {
+	db := setupTestDB(t)
+	repo := seedIndexedTree(t, db)
+
+	mustExec(t, db, `INSERT INTO topics (id, title, status, parent_topic_id) VALUES ('child', 'Child', 'draft', 't1')`)
+
+	if err := repo.DeleteTopic(context.Background(), "t1", true); !errors.Is(err, repository.ErrFKViolation) {
+		t.Fatalf("expected ErrFKViolation, got %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type = 'topic' AND entity_id = 't1'`); n != 1 {
+		t.Fatal("expected search index to be untouched after failed delete")
+	}
+}
+
+func TestDeleteConceptRefusesWithRetention(t *testing.T) {
+	db := setupTestDB(t)
+	repo := seedIndexedTree(t, db)
+	ctx := context.Background()
+
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status) VALUES ('c1', 'reviewing')`)
+
+	if err := repo.DeleteConcept(ctx, "c1", false); !errors.Is(err, repository.ErrHasProgress) {
+		t.Fatalf("expected ErrHasProgress, got %v", err)
+	}
+
+	if err := repo.DeleteConcept(ctx, "c1", true); err != nil {
+		t.Fatalf("force delete concept: %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_index WHERE entity_type = 'concept'`); n != 0 {
+		t.Fatal("expected concept search row to be removed")
+	}
+}
+
+func TestDeleteLinkRows(t *testing.T) {
+	db := setupTestDB(t)
+	repo := seedIndexedTree(t, db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t2", "Topic 2", "foundational", "published")
+	seedConceptReference(t, db, "c1", "l1")
+	mustExec(t, db, `INSERT INTO topic_prerequisites (topic_id, prerequisite_topic_id, priority) VALUES ('t2', 't1', 'helpful')`)
+	mustExec(t, db, `INSERT INTO topic_relations (topic_a, topic_b, relation_type) VALUES ('t1', 't2', 'related')`)
+
+	if err := repo.DeleteConceptReference(ctx, "c1", "l1"); err != nil {
+		t.Fatalf("delete concept reference: %v", err)
+	}
+
+	if err := repo.DeletePrerequisite(ctx, "t2", "t1"); err != nil {
+		t.Fatalf("delete prerequisite: %v", err)
+	}
+
+	if err := repo.DeleteRelation(ctx, "t1", "t2"); err != nil {
+		t.Fatalf("delete relation: %v", err)
+	}
+
+	if err := repo.DeleteRelation(ctx, "t1", "t2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,563 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+const moveConceptRefsSQL = `
+INSERT OR IGNORE INTO concept_references (concept_id, lesson_id, context)
+SELECT ?, lesson_id, context FROM concept_references WHERE concept_id = ?
+`
+
+// moveQuestionConceptsSQL points the question cards that test the source
+// at the target; deleteQuestionConceptSQL drops the links left behind
+// where a question already tested both.
+const moveQuestionConceptsSQL = `UPDATE OR IGNORE question_concepts SET concept_id = ? WHERE concept_id = ?`
+
+const deleteQuestionConceptSQL = `DELETE FROM question_concepts WHERE concept_id = ?`
+
+const getRetentionStrengthSQL = `
+SELECT status, interval_days, review_count FROM concept_retention WHERE concept_id = ?
+`
+
+const deleteRetentionSQL = `DELETE FROM concept_retention WHERE concept_id = ?`
+
+const reassignRetentionSQL = `UPDATE concept_retention SET concept_id = ? WHERE concept_id = ?`
+
+const reassignReviewLogSQL = `UPDATE review_log SET concept_id = ? WHERE concept_id = ?`
+
+// The quoted ID narrows the scan to rows whose JSON could mention it.
+const listLessonsMentioningSQL = `
+SELECT id, COALESCE(content, ''), COALESCE(examples, ''), COALESCE(exercises, ''),
+       COALESCE(review_questions, '')
+FROM lessons
+WHERE instr(content, ?) > 0 OR instr(examples, ?) > 0
+   OR instr(exercises, ?) > 0 OR instr(review_questions, ?) > 0
+`
+
+const updateLessonJSONSQL = `
+UPDATE lessons SET content = ?, examples = ?, exercises = ?, review_questions = ?,
+                   revision = revision + 1
+WHERE id = ?
+`
+
+const listModulesMentioningSQL = `
+SELECT id, COALESCE(assessment, '') FROM modules WHERE instr(assessment, ?) > 0
+`
+
+const updateModuleAssessmentSQL = `
+UPDATE modules SET assessment = ?, revision = revision + 1
+WHERE id = ?
+`
+
+// retentionRank orders review statuses from weakest to strongest.
+var retentionRank = map[string]int{"new": 0, "learning": 1, "reviewing": 2, "mastered": 3}
+
+type retentionStrength struct {
+	status      string
+	interval    int
+	reviewCount int
+}
+
+// stronger reports whether a is further along than b: a higher status
+// first, then a longer interval, then more reviews.
+func (a retentionStrength) stronger(b retentionStrength) bool {
+	if retentionRank[a.status] != retentionRank[b.status] {
+		return retentionRank[a.status] > retentionRank[b.status]
+	}
+
+	if a.interval != b.interval {
+		return a.interval > b.interval
+	}
+
+	return a.reviewCount > b.reviewCount
+}
+
+// MergeConcepts folds a duplicate concept (the source) into the one that
+// survives (the target) inside one transaction: references move over, the
+// stronger review state is kept, both review logs are kept, question cards
+// that test the source test the target, lesson and module JSON that mention
+// the source by ID is rewritten, the source ID and its aliases become
+// aliases of the target, and the source is deleted.
+func (r *SQLiteWriteRepository) MergeConcepts(ctx context.Context, targetID, sourceID string) (*models.ConceptMerge, error) {
+	if targetID == sourceID {
+		return nil, fmt.Errorf("merge concept %s into itself: %w", targetID, ErrCheckViolation)
+	}
+
+	merge := &models.ConceptMerge{TargetID: targetID, SourceID: sourceID}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		target, _, err := loadConceptInput(ctx, tx, targetID)
+		if err != nil {
+			return err
+		}
+
+		source, _, err := loadConceptInput(ctx, tx, sourceID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, moveConceptRefsSQL, targetID, sourceID)
+		if err != nil {
+			return classifyError(err, "move concept references")
+		}
+
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("move concept references rows affected: %w", err)
+		}
+
+		merge.ReferencesMoved = int(moved)
+
+		if _, err := tx.ExecContext(ctx, moveQuestionConceptsSQL, targetID, sourceID); err != nil {
+			return fmt.Errorf("move question concepts: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteQuestionConceptSQL, sourceID); err != nil {
+			return fmt.Errorf("delete question concepts: %w", err)
+		}
+
+		if merge.RetentionFrom, err = reconcileRetention(ctx, tx, targetID, sourceID); err != nil {
+			return err
+		}
+
+		if merge.LessonsRewritten, err = rewriteLessonConceptIDs(ctx, tx, sourceID, targetID); err != nil {
+			return err
+		}
+
+		if merge.ModulesRewritten, err = rewriteModuleConceptIDs(ctx, tx, sourceID, targetID); err != nil {
+			return err
+		}
+
+		target.Aliases = mergeAliases(target.Aliases, targetID, append([]string{source.ID}, source.Aliases...))
+		if target.DefinedInLesson == "" {
+			target.DefinedInLesson = source.DefinedInLesson
+		}
+
+		if target.DefinedInTopic == "" {
+			target.DefinedInTopic = source.DefinedInTopic
+		}
+
+		merge.Aliases = target.Aliases
+
+		if err := updateConceptRow(ctx, tx, targetID, *target); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteSearchSQL, "concept", sourceID); err != nil {
+			return fmt.Errorf("delete search index for concept %s: %w", sourceID, err)
+		}
+
+		if err := deleteRow(ctx, tx, deleteConceptSQL, "concept", sourceID); err != nil {
+			return err
+		}
+
+		return syncTopicCards(ctx, tx, source.DefinedInTopic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+// reconcileRetention leaves the target with the stronger of the two review
+// states and reports which one it kept. Both review logs move to the
+// target, where a replay reads them merged in timestamp order; no review is
+// ever deleted.
+func reconcileRetention(ctx context.Context, tx *sql.Tx, targetID, sourceID string) (string, error) {
+	if _, err := tx.ExecContext(ctx, reassignReviewLogSQL, targetID, sourceID); err != nil {
+		return "", classifyError(err, "move concept review log")
+	}
+
+	target, err := loadRetentionStrength(ctx, tx, targetID)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := loadRetentionStrength(ctx, tx, sourceID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case source == nil && target == nil:
+		return "", nil
+	case source == nil || (target != nil && !source.stronger(*target)):
+		return "target", nil
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteRetentionSQL, targetID); err != nil {
+		return "", fmt.Errorf("drop concept %s retention: %w", targetID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, reassignRetentionSQL, targetID, sourceID); err != nil {
+		return "", classifyError(err, "move concept retention")
+	}
+
+	return "source", nil
+}
+
+func loadRetentionStrength(ctx context.Context, tx *sql.Tx, conceptID string) (*retentionStrength, error) {
+	var s retentionStrength
+
+	err := tx.QueryRowContext(ctx, getRetentionStrengthSQL, conceptID).Scan(&s.status, &s.interval, &s.reviewCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("load concept %s retention: %w", conceptID, err)
+	}
+
+	return &s, nil
+}
+
+func rewriteLessonConceptIDs(ctx context.Context, tx *sql.Tx, from, to string) ([]string, error) {
+	quoted := `"` + from + `"`
+
+	rows, err := tx.QueryContext(ctx, listLessonsMentioningSQL, quoted, quoted, quoted, quoted)
+	if err != nil {
+		return nil, fmt.Errorf("find lessons mentioning concept %s: %w", from, err)
+	}
+
+	type lessonJSON struct {
+		id     string
+		fields [4]string
+	}
+
+	var candidates []lessonJSON
+
+	for rows.Next() {
+		var l lessonJSON
+		if err := rows.Scan(&l.id, &l.fields[0], &l.fields[1], &l.fields[2], &l.fields[3]); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scan lesson JSON: %w", err)
+		}
+
+		candidates = append(candidates, l)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate lessons mentioning concept %s: %w", from, err)
+	}
+
+	rewritten := []string{}
+
+	for _, l := range candidates {
+		changed := false
+		args := make([]any, 0, len(l.fields)+1)
+
+		for _, field := range l.fields {
+			out, ok, err := rewriteConceptIDs(field, from, to)
+			if err != nil {
+				return nil, fmt.Errorf("rewrite lesson %s: %w", l.id, err)
+			}
+
+			changed = changed || ok
+			args = append(args, nullIfEmpty(out))
+		}
+
+		if !changed {
+			continue
+		}
+
+		err := trackChange(ctx, tx, models.AuditEntityLesson, l.id, func() error {
+			if _, err := tx.ExecContext(ctx, updateLessonJSONSQL, append(args, l.id)...); err != nil {
+				return classifyError(err, "rewrite lesson "+l.id)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rewritten = append(rewritten, l.id)
+	}
+
+	return rewritten, nil
+}
+
+func rewriteModuleConceptIDs(ctx context.Context, tx *sql.Tx, from, to string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, listModulesMentioningSQL, `"`+from+`"`)
+	if err != nil {
+		return nil, fmt.Errorf("find modules mentioning concept %s: %w", from, err)
+	}
+
+	assessments := make(map[string]string)
+	var ids []string
+
+	for rows.Next() {
+		var id, assessment string
+		if err := rows.Scan(&id, &assessment); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scan module assessment: %w", err)
+		}
+
+		ids = append(ids, id)
+		assessments[id] = assessment
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate modules mentioning concept %s: %w", from, err)
+	}
+
+	rewritten := []string{}
+
+	for _, id := range ids {
+		out, changed, err := rewriteConceptIDs(assessments[id], from, to)
+		if err != nil {
+			return nil, fmt.Errorf("rewrite module %s: %w", id, err)
+		}
+
+		if !changed {
+			continue
+		}
+
+		err = trackChange(ctx, tx, models.AuditEntityModule, id, func() error {
+			if _, err := tx.ExecContext(ctx, updateModuleAssessmentSQL, out, id); err != nil {
+				return classifyError(err, "rewrite module "+id)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rewritten = append(rewritten, id)
+	}
+
+	return rewritten, nil
+}
+
+// rewriteConceptIDs replaces from with to in every "concept_ref" value and
+// "concepts_tested" list of a JSON document, dropping duplicates the rename
+// creates. Only those string values are edited in place, so key order,
+// spacing, escapes and numbers elsewhere are kept byte for byte. Documents
+// that do not mention from are returned unchanged.
+func rewriteConceptIDs(raw, from, to string) (string, bool, error) {
+	if raw == "" {
+		return raw, false, nil
+	}
+
+	w := &conceptIDWalker{dec: json.NewDecoder(strings.NewReader(raw)), raw: raw, from: from, to: to}
+
+	tok, start, err := w.next()
+	if err == nil {
+		err = w.value("", tok, start)
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("decode JSON: %w", err)
+	}
+
+	if len(w.edits) == 0 {
+		return raw, false, nil
+	}
+
+	slices.SortFunc(w.edits, func(a, b jsonEdit) int { return a.start - b.start })
+
+	var b strings.Builder
+
+	last := 0
+	for _, e := range w.edits {
+		b.WriteString(raw[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+
+	b.WriteString(raw[last:])
+
+	return b.String(), true, nil
+}
+
+// jsonEdit replaces raw[start:end] with text.
+type jsonEdit struct {
+	start, end int
+	text       string
+}
+
+// conceptIDWalker streams a JSON document's tokens and records the edits
+// that rename a concept ID.
+type conceptIDWalker struct {
+	dec      *json.Decoder
+	raw      string
+	from, to string
+	edits    []jsonEdit
+}
+
+// next returns the next token and the offset it starts at. Only
+// whitespace, commas and colons separate tokens.
+func (w *conceptIDWalker) next() (json.Token, int, error) {
+	start := int(w.dec.InputOffset())
+	for start < len(w.raw) && strings.IndexByte(" \t\r\n,:", w.raw[start]) >= 0 {
+		start++
+	}
+
+	tok, err := w.dec.Token()
+
+	return tok, start, err
+}
+
+// value walks the value starting with tok, which is the value of key in its
+// enclosing object, or of "" otherwise.
+func (w *conceptIDWalker) value(key string, tok json.Token, start int) error {
+	switch v := tok.(type) {
+	case json.Delim:
+		switch {
+		case v == '{':
+			return w.object()
+		case v == '[' && key == "concepts_tested":
+			return w.testedList()
+		case v == '[':
+			return w.array()
+		}
+	case string:
+		if key == "concept_ref" && v == w.from {
+			w.edits = append(w.edits, jsonEdit{start: start, end: int(w.dec.InputOffset()), text: jsonString(w.to)})
+		}
+	}
+
+	return nil
+}
+
+func (w *conceptIDWalker) object() error {
+	for {
+		tok, _, err := w.next()
+		if err != nil {
+			return err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return nil
+		}
+
+		tok, start, err := w.next()
+		if err != nil {
+			return err
+		}
+
+		if err := w.value(key, tok, start); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *conceptIDWalker) array() error {
+	for {
+		tok, start, err := w.next()
+		if err != nil {
+			return err
+		}
+
+		if tok == json.Delim(']') {
+			return nil
+		}
+
+		if err := w.value("", tok, start); err != nil {
+			return err
+		}
+	}
+}
+
+// testedList renames from in a concepts_tested list and drops the strings
+// that then repeat an earlier one, each with the comma before it. Lists
+// without from are left alone.
+func (w *conceptIDWalker) testedList() error {
+	type element struct {
+		start, end int
+		id         string
+		isID       bool
+	}
+
+	var elems []element
+
+	for {
+		tok, start, err := w.next()
+		if err != nil {
+			return err
+		}
+
+		if tok == json.Delim(']') {
+			break
+		}
+
+		if err := w.value("", tok, start); err != nil {
+			return err
+		}
+
+		id, isID := tok.(string)
+		elems = append(elems, element{start: start, end: int(w.dec.InputOffset()), id: id, isID: isID})
+	}
+
+	if !slices.ContainsFunc(elems, func(e element) bool { return e.isID && e.id == w.from }) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+
+	for i, e := range elems {
+		if !e.isID {
+			continue
+		}
+
+		id := e.id
+		if id == w.from {
+			id = w.to
+		}
+
+		switch {
+		case seen[id]:
+			w.edits = append(w.edits, jsonEdit{start: elems[i-1].end, end: e.end})
+		case e.id == w.from:
+			w.edits = append(w.edits, jsonEdit{start: e.start, end: e.end, text: jsonString(w.to)})
+		}
+
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// jsonString encodes s as a JSON string without escaping HTML characters.
+func jsonString(s string) string {
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// mergeAliases appends added to existing, skipping the concept's own ID and
+// anything already present.
+func mergeAliases(existing []string, ownID string, added []string) []string {
+	out := slices.Clone(existing)
+
+	for _, alias := range added {
+		if alias != "" && alias != ownID && !slices.Contains(out, alias) {
+			out = append(out, alias)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,226 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func seedMergeTree(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	seedOrderTree(t, db)
+	seedConcept(t, db, "linux-bridge", "Linux bridge", "A software switch", "t1")
+	seedConcept(t, db, "linux-bridge-interface", "Linux bridge interface", "A virtual switch device", "t1")
+	mustExec(t, db, `UPDATE concepts SET aliases = '["br0"]', defined_in_lesson = 'l2' WHERE id = 'linux-bridge-interface'`)
+	mustExec(t, db, `INSERT INTO search_index (entity_type, entity_id, title, body) VALUES ('concept', 'linux-bridge-interface', 'Linux bridge interface', 'def')`)
+
+	seedConceptReference(t, db, "linux-bridge", "l1")
+	seedConceptReference(t, db, "linux-bridge-interface", "l1")
+	seedConceptReference(t, db, "linux-bridge-interface", "l2")
+
+	mustExec(t, db, `UPDATE lessons SET
+		content = '{"sections":[{"type":"callout","variant":"tip","body":"See bridges","concept_ref":"linux-bridge-interface"}]}',
+		review_questions = '[{"question":"Q","answer":"A","concepts_tested":["linux-bridge","linux-bridge-interface"]}]'
+		WHERE id = 'l3'`)
+	mustExec(t, db, `UPDATE modules SET
+		assessment = '{"questions":[{"type":"free_response","question":"Q","answer":"A","concepts_tested":["linux-bridge-interface"]}]}'
+		WHERE id = 'm1'`)
+}
+
+func TestMergeConcepts(t *testing.T) {
+	db := setupTestDB(t)
+	seedMergeTree(t, db)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days, review_count) VALUES ('linux-bridge', 'learning', 3, 2)`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days, review_count) VALUES ('linux-bridge-interface', 'reviewing', 12, 5)`)
+
+	repo := repository.NewWriteRepository(db)
+
+	merge, err := repo.MergeConcepts(context.Background(), "linux-bridge", "linux-bridge-interface")
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if merge.ReferencesMoved != 1 || merge.RetentionFrom != "source" {
+		t.Fatalf("unexpected merge result %+v", merge)
+	}
+
+	if !reflect.DeepEqual(merge.LessonsRewritten, []string{"l3"}) || !reflect.DeepEqual(merge.ModulesRewritten, []string{"m1"}) {
+		t.Fatalf("unexpected rewritten rows %+v", merge)
+	}
+
+	concepts := repository.NewConceptRepository(db)
+
+	survivor, err := concepts.GetConceptByID(context.Background(), "linux-bridge-interface")
+	if err != nil {
+		t.Fatalf("get by alias: %v", err)
+	}
+
+	if survivor == nil || survivor.ID != "linux-bridge" || survivor.DefinedInLesson != "l2" {
+		t.Fatalf("expected alias to resolve to linux-bridge, got %+v", survivor)
+	}
+
+	if !reflect.DeepEqual(survivor.Aliases, []string{"linux-bridge-interface", "br0"}) {
+		t.Fatalf("unexpected aliases %v", survivor.Aliases)
+	}
+
+	if len(survivor.References) != 2 {
+		t.Fatalf("expected references to l1 and l2, got %+v", survivor.References)
+	}
+
+	var status string
+	var interval int
+	if err := db.QueryRow(`SELECT status, interval_days FROM concept_retention WHERE concept_id = 'linux-bridge'`).Scan(&status, &interval); err != nil {
+		t.Fatalf("read retention: %v", err)
+	}
+
+	if status != "reviewing" || interval != 12 {
+		t.Fatalf("expected the stronger review state, got %s/%d", status, interval)
+	}
+
+	var content, questions, assessment string
+	if err := db.QueryRow(`SELECT content, review_questions FROM lessons WHERE id = 'l3'`).Scan(&content, &questions); err != nil {
+		t.Fatalf("read lesson: %v", err)
+	}
+
+	if err := db.QueryRow(`SELECT assessment FROM modules WHERE id = 'm1'`).Scan(&assessment); err != nil {
+		t.Fatalf("read module: %v", err)
+	}
+
+	wantContent := `{"sections":[{"type":"callout","variant":"tip","body":"See bridges","concept_ref":"linux-bridge"}]}`
+	wantQuestions := `[{"question":"Q","answer":"A","concepts_tested":["linux-bridge"]}]`
+	wantAssessment := `{"questions":[{"type":"free_response","question":"Q","answer":"A","concepts_tested":["linux-bridge"]}]}`
+
+	if content != wantContent || questions != wantQuestions || assessment != wantAssessment {
+		t.Fatalf("unexpected rewritten JSON:\n%s\n%s\n%s", content, questions, assessment)
+	}
+
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM search_index WHERE entity_id = 'linux-bridge-interface'`).Scan(&remaining); err != nil {
+		t.Fatalf("count search rows: %v", err)
+	}
+
+	if remaining != 0 {
+		t.Fatalf("expected source search row removed, got %d", remaining)
+	}
+}
+
+func TestMergeConceptsKeepsStrongerTargetRetention(t *testing.T) {
+	db := setupTestDB(t)
+	seedMergeTree(t, db)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days) VALUES ('linux-bridge', 'mastered', 120)`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days) VALUES ('linux-bridge-interface', 'reviewing', 30)`)
+
+	merge, err := repository.NewWriteRepository(db).MergeConcepts(context.Background(), "linux-bridge", "linux-bridge-interface")
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if merge.RetentionFrom != "target" {
+		t.Fatalf("expected target retention kept, got %q", merge.RetentionFrom)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM concept_retention`).Scan(&count); err != nil {
+		t.Fatalf("count retention: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected one retention row, got %d", count)
+	}
+}
+
+func TestMergeConceptsKeepsBothReviewLogs(t *testing.T) {
+	db := setupTestDB(t)
+	seedMergeTree(t, db)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days, review_count) VALUES ('linux-bridge', 'learning', 1, 1)`)
+	mustExec(t, db, `INSERT INTO concept_retention (concept_id, status, interval_days, review_count) VALUES ('linux-bridge-interface', 'reviewing', 3, 2)`)
+	mustExec(t, db, `INSERT INTO review_log (concept_id, rating, reviewed_at) VALUES
+		('linux-bridge', 'forgot', '2026-03-01T12:00:00Z'),
+		('linux-bridge-interface', 'good', '2026-03-01T09:00:00Z'),
+		('linux-bridge-interface', 'easy', '2026-03-02T09:00:00Z')`)
+
+	if _, err := repository.NewWriteRepository(db).MergeConcepts(context.Background(), "linux-bridge", "linux-bridge-interface"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM review_log WHERE concept_id = 'linux-bridge'`); n != 3 {
+		t.Fatalf("expected both concepts' reviews on the survivor, got %d", n)
+	}
+
+	history, err := repository.NewReviewRepository(db, db).ListHistory(context.Background())
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+
+	var ratings []string
+	for _, e := range history["linux-bridge"] {
+		ratings = append(ratings, e.Rating)
+	}
+
+	if !reflect.DeepEqual(ratings, []string{"good", "forgot", "easy"}) {
+		t.Fatalf("expected the merged log in timestamp order, got %v", ratings)
+	}
+}
+
+func TestMergeConceptsPreservesJSONFormatting(t *testing.T) {
+	db := setupTestDB(t)
+	seedMergeTree(t, db)
+	mustExec(t, db, `UPDATE lessons SET
+		review_questions = '[ {"question": "Is <b> & <i> markup?", "weight": 1.50,
+		  "concepts_tested": ["linux-bridge", "linux-bridge-interface", "vlan"]} ]'
+		WHERE id = 'l3'`)
+	mustExec(t, db, `UPDATE lessons SET content = '{"title":"linux-bridge-interface"}' WHERE id = 'l2'`)
+
+	merge, err := repository.NewWriteRepository(db).MergeConcepts(context.Background(), "linux-bridge", "linux-bridge-interface")
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if !reflect.DeepEqual(merge.LessonsRewritten, []string{"l3"}) {
+		t.Fatalf("expected only l3 rewritten, got %v", merge.LessonsRewritten)
+	}
+
+	if n := countRows(t, db, `SELECT revision FROM lessons WHERE id = 'l2'`); n != 1 {
+		t.Fatalf("expected l2 left unwritten, got revision %d", n)
+	}
+
+	var questions string
+	if err := db.QueryRow(`SELECT review_questions FROM lessons WHERE id = 'l3'`).Scan(&questions); err != nil {
+		t.Fatalf("read lesson: %v", err)
+	}
+
+	want := `[ {"question": "Is <b> & <i> markup?", "weight": 1.50,
+		  "concepts_tested": ["linux-bridge", "vlan"]} ]`
+	if questions != want {
+		t.Fatalf("expected only the concept ID edited, got:\n%s", questions)
+	}
+}
+
+func TestMergeConceptsErrors(t *testing.T) {
+	db := setupTestDB(t)
+	seedMergeTree(t, db)
+	repo := repository.NewWriteRepository(db)
+
+	if _, err := repo.MergeConcepts(context.Background(), "linux-bridge", "linux-bridge"); !errors.Is(err, repository.ErrCheckViolation) {
+		t.Fatalf("self merge: expected ErrCheckViolation, got %v", err)
+	}
+
+	if _, err := repo.MergeConcepts(context.Background(), "linux-bridge", "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("missing source: expected ErrNotFound, got %v", err)
+	}
+
+	// A failed merge leaves both concepts untouched.
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM concept_references WHERE concept_id = 'linux-bridge-interface'`).Scan(&count); err != nil {
+		t.Fatalf("count references: %v", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("expected source references intact, got %d", count)
+	}
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+const moduleExistsSQL = `SELECT EXISTS(SELECT 1 FROM modules WHERE id = ?)`
+
+const moduleArchivedSQL = `SELECT archived_at IS NOT NULL FROM modules WHERE id = ?`
+
+// listTopicModuleOrderSQL selects a topic's modules with whether each is
+// archived, as listModuleLessonOrderSQL does a module's lessons.
+const listTopicModuleOrderSQL = `
+SELECT id, archived_at IS NOT NULL FROM modules WHERE topic_id = ? ORDER BY sort_order, id
+`
+
+const listModuleLessonOrderSQL = `
+SELECT id, archived_at IS NOT NULL FROM lessons WHERE module_id = ? ORDER BY sort_order, id
+`
+
+const setModuleSortOrderSQL = `
+UPDATE modules SET sort_order = ?, revision = revision + 1
+WHERE id = ? AND sort_order <> ?
+`
+
+const setLessonSortOrderSQL = `
+UPDATE lessons SET sort_order = ?, revision = revision + 1
+WHERE id = ? AND sort_order <> ?
+`
+
+const getLessonModuleSQL = `SELECT module_id FROM lessons WHERE id = ?`
+
+const setLessonModuleSQL = `
+UPDATE lessons SET module_id = ?, revision = revision + 1
+WHERE id = ?
+`
+
+// ReorderModules renumbers the topic's modules' sort_order as 1..n in the
+// given order inside one transaction. The ID list must be a permutation of
+// the topic's modules that are not archived, the ones reads show; anything
+// else returns ErrOrderMismatch, so a client working from a stale list
+// cannot silently drop or duplicate rows. Archived modules keep their
+// relative order after the listed ones. Only modules whose position changes
+// get a new revision.
+func (r *SQLiteWriteRepository) ReorderModules(ctx context.Context, topicID string, moduleIDs []string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		live, archived, err := childIDs(ctx, tx, topicExistsSQL, listTopicModuleOrderSQL, "topic", topicID)
+		if err != nil {
+			return err
+		}
+
+		if err := requirePermutation(live, moduleIDs, "topic", topicID); err != nil {
+			return err
+		}
+
+		return renumber(ctx, tx, setModuleSortOrderSQL, "module", append(slices.Clone(moduleIDs), archived...))
+	})
+}
+
+// ReorderLessons renumbers the module's lessons as ReorderModules does a
+// topic's modules.
+func (r *SQLiteWriteRepository) ReorderLessons(ctx context.Context, moduleID string, lessonIDs []string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		live, archived, err := childIDs(ctx, tx, moduleExistsSQL, listModuleLessonOrderSQL, "module", moduleID)
+		if err != nil {
+			return err
+		}
+
+		if err := requirePermutation(live, lessonIDs, "module", moduleID); err != nil {
+			return err
+		}
+
+		return renumber(ctx, tx, setLessonSortOrderSQL, "lesson", append(slices.Clone(lessonIDs), archived...))
+	})
+}
+
+// MoveLesson moves a lesson into moduleID at the 1-based position among the
+// module's lessons that are not archived, shifting later lessons down;
+// position 0 or past the end appends. Both the source and target modules are
+// renumbered, archived lessons last. Moving into an archived module returns
+// ErrArchived. Progress and concept references are keyed by lesson ID and
+// follow the lesson unchanged.
+func (r *SQLiteWriteRepository) MoveLesson(ctx context.Context, lessonID, moduleID string, position int) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var sourceID string
+
+		err := tx.QueryRowContext(ctx, getLessonModuleSQL, lessonID).Scan(&sourceID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("lesson %s: %w", lessonID, ErrNotFound)
+		}
+
+		if err != nil {
+			return fmt.Errorf("load lesson %s module: %w", lessonID, err)
+		}
+
+		target, archived, err := childIDs(ctx, tx, moduleExistsSQL, listModuleLessonOrderSQL, "module", moduleID)
+		if err != nil {
+			return err
+		}
+
+		var moduleArchived bool
+		if err := tx.QueryRowContext(ctx, moduleArchivedSQL, moduleID).Scan(&moduleArchived); err != nil {
+			return fmt.Errorf("check module %s archived: %w", moduleID, err)
+		}
+
+		if moduleArchived {
+			return fmt.Errorf("move lesson %s into module %s: %w", lessonID, moduleID, ErrArchived)
+		}
+
+		target = removeID(target, lessonID)
+		if position < 1 || position > len(target)+1 {
+			position = len(target) + 1
+		}
+
+		target = slices.Insert(target, position-1, lessonID)
+		target = append(target, removeID(archived, lessonID)...)
+
+		if sourceID != moduleID {
+			err := trackChange(ctx, tx, models.AuditEntityLesson, lessonID, func() error {
+				if _, err := tx.ExecContext(ctx, setLessonModuleSQL, moduleID, lessonID); err != nil {
+					return classifyError(err, "move lesson")
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			source, sourceArchived, err := childIDs(ctx, tx, moduleExistsSQL, listModuleLessonOrderSQL, "module", sourceID)
+			if err != nil {
+				return err
+			}
+
+			if err := renumber(ctx, tx, setLessonSortOrderSQL, "lesson", append(source, sourceArchived...)); err != nil {
+				return err
+			}
+		}
+
+		return renumber(ctx, tx, setLessonSortOrderSQL, "lesson", target)
+	})
+}
+
+// childIDs returns the parent's children that are not archived and those
+// that are, each in their current order, or ErrNotFound when the parent
+// does not exist.
+func childIDs(ctx context.Context, q queryer, existsSQL, listSQL, parent, parentID string) (live, archived []string, err error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, existsSQL, parentID).Scan(&exists); err != nil {
+		return nil, nil, fmt.Errorf("check %s %s: %w", parent, parentID, err)
+	}
+
+	if !exists {
+		return nil, nil, fmt.Errorf("%s %s: %w", parent, parentID, ErrNotFound)
+	}
+
+	rows, err := q.QueryContext(ctx, listSQL, parentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list %s %s children: %w", parent, parentID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var isArchived bool
+		if err := rows.Scan(&id, &isArchived); err != nil {
+			return nil, nil, fmt.Errorf("scan %s %s child: %w", parent, parentID, err)
+		}
+
+		if isArchived {
+			archived = append(archived, id)
+		} else {
+			live = append(live, id)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate %s %s children: %w", parent, parentID, err)
+	}
+
+	return live, archived, nil
+}
+
+func requirePermutation(current, given []string, parent, parentID string) error {
+	want := make(map[string]bool, len(current))
+	for _, id := range current {
+		want[id] = true
+	}
+
+	seen := make(map[string]bool, len(given))
+
+	for _, id := range given {
+		if !want[id] {
+			return fmt.Errorf("%s %s has no child %q: %w", parent, parentID, id, ErrOrderMismatch)
+		}
+
+		if seen[id] {
+			return fmt.Errorf("%s %s: %q listed twice: %w", parent, parentID, id, ErrOrderMismatch)
+		}
+
+		seen[id] = true
+	}
+
+	if len(given) != len(current) {
+		return fmt.Errorf("%s %s has %d children, got %d: %w", parent, parentID, len(current), len(given), ErrOrderMismatch)
+	}
+
+	return nil
+}
+
+func renumber(ctx context.Context, q queryer, updateSQL, entity string, ids []string) error {
+	for i, id := range ids {
+		err := trackChange(ctx, q, entity, id, func() error {
+			if _, err := q.ExecContext(ctx, updateSQL, i+1, id, i+1); err != nil {
+				return classifyError(err, "renumber "+entity+" "+id)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeID(ids []string, id string) []string {
+	out := ids[:0]
+
+	for _, existing := range ids {
+		if existing != id {
+			out = append(out, existing)
+		}
+	}
+
+	return out
+}
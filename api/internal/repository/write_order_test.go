@@ -0,0 +1,223 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func lessonOrder(t *testing.T, db *sql.DB, moduleID string) []string {
+	t.Helper()
+
+	rows, err := db.Query(`SELECT id FROM lessons WHERE module_id = ? ORDER BY sort_order`, moduleID)
+	if err != nil {
+		t.Fatalf("query lesson order: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("scan lesson id: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func seedOrderTree(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	seedTopic(t, db, "t1", "Topic", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "First", 1)
+	seedModule(t, db, "m2", "t1", "Second", 2)
+	seedModule(t, db, "m3", "t1", "Third", 3)
+	seedLesson(t, db, "l1", "m1", "One", 1)
+	seedLesson(t, db, "l2", "m1", "Two", 2)
+	seedLesson(t, db, "l3", "m1", "Three", 3)
+	seedLesson(t, db, "l4", "m2", "Four", 1)
+}
+
+func TestReorderModules(t *testing.T) {
+	db := setupTestDB(t)
+	seedOrderTree(t, db)
+	repo := repository.NewWriteRepository(db)
+
+	if err := repo.ReorderModules(context.Background(), "t1", []string{"m3", "m1", "m2"}); err != nil {
+		t.Fatalf("reorder modules: %v", err)
+	}
+
+	topic, err := repository.NewTopicRepository(db).GetTopicByID(context.Background(), "t1", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get topic: %v", err)
+	}
+
+	var got []string
+	for _, m := range topic.Modules {
+		got = append(got, m.ID)
+	}
+
+	if !reflect.DeepEqual(got, []string{"m3", "m1", "m2"}) {
+		t.Fatalf("unexpected module order %v", got)
+	}
+
+	var revision int
+	if err := db.QueryRow(`SELECT revision FROM modules WHERE id = 'm3'`).Scan(&revision); err != nil {
+		t.Fatalf("query revision: %v", err)
+	}
+
+	if revision != 2 {
+		t.Fatalf("expected moved module at revision 2, got %d", revision)
+	}
+}
+
+func TestReorderRejectsNonPermutation(t *testing.T) {
+	db := setupTestDB(t)
+	seedOrderTree(t, db)
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ids  []string
+	}{
+		{"missing child", []string{"l3", "l1"}},
+		{"unknown child", []string{"l3", "l1", "l4"}},
+		{"duplicate child", []string{"l3", "l1", "l1"}},
+		{"extra child", []string{"l3", "l1", "l2", "l2"}},
+	}
+
+	for _, tt := range tests {
+		if err := repo.ReorderLessons(ctx, "m1", tt.ids); !errors.Is(err, repository.ErrOrderMismatch) {
+			t.Fatalf("%s: expected ErrOrderMismatch, got %v", tt.name, err)
+		}
+	}
+
+	if got := lessonOrder(t, db, "m1"); !reflect.DeepEqual(got, []string{"l1", "l2", "l3"}) {
+		t.Fatalf("expected order unchanged after rejected reorders, got %v", got)
+	}
+
+	if err := repo.ReorderLessons(ctx, "missing", nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing module, got %v", err)
+	}
+}
+
+func TestMoveLessonKeepsProgressAndReferences(t *testing.T) {
+	db := setupTestDB(t)
+	seedOrderTree(t, db)
+	seedConcept(t, db, "c1", "Concept", "Definition", "t1")
+	seedConceptReference(t, db, "c1", "l2")
+	mustExec(t, db, `INSERT INTO learning_progress (lesson_id, status) VALUES ('l2', 'completed')`)
+	repo := repository.NewWriteRepository(db)
+
+	if err := repo.MoveLesson(context.Background(), "l2", "m2", 1); err != nil {
+		t.Fatalf("move lesson: %v", err)
+	}
+
+	if got := lessonOrder(t, db, "m1"); !reflect.DeepEqual(got, []string{"l1", "l3"}) {
+		t.Fatalf("unexpected source order %v", got)
+	}
+
+	if got := lessonOrder(t, db, "m2"); !reflect.DeepEqual(got, []string{"l2", "l4"}) {
+		t.Fatalf("unexpected target order %v", got)
+	}
+
+	var sortOrder int
+	if err := db.QueryRow(`SELECT sort_order FROM lessons WHERE id = 'l3'`).Scan(&sortOrder); err != nil {
+		t.Fatalf("query sort order: %v", err)
+	}
+
+	if sortOrder != 2 {
+		t.Fatalf("expected source module renumbered, l3 at %d", sortOrder)
+	}
+
+	var refs, progress int
+	if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM concept_references WHERE lesson_id = 'l2'),
+	                              (SELECT COUNT(*) FROM learning_progress WHERE lesson_id = 'l2')`).Scan(&refs, &progress); err != nil {
+		t.Fatalf("count dependents: %v", err)
+	}
+
+	if refs != 1 || progress != 1 {
+		t.Fatalf("expected reference and progress kept, got %d refs %d progress", refs, progress)
+	}
+}
+
+func TestMoveLessonWithinModuleAndMissingTarget(t *testing.T) {
+	db := setupTestDB(t)
+	seedOrderTree(t, db)
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.MoveLesson(ctx, "l1", "m1", 0); err != nil {
+		t.Fatalf("move lesson to end: %v", err)
+	}
+
+	if got := lessonOrder(t, db, "m1"); !reflect.DeepEqual(got, []string{"l2", "l3", "l1"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+
+	if err := repo.MoveLesson(ctx, "l1", "missing", 1); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing module, got %v", err)
+	}
+
+	if err := repo.MoveLesson(ctx, "missing", "m1", 1); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing lesson, got %v", err)
+	}
+}
+
+func TestReorderAndMoveSkipArchived(t *testing.T) {
+	db := setupTestDB(t)
+	seedOrderTree(t, db)
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.ArchiveLesson(ctx, "l1"); err != nil {
+		t.Fatalf("archive lesson: %v", err)
+	}
+
+	// The list a client was shown leaves out the archived lesson.
+	if err := repo.ReorderLessons(ctx, "m1", []string{"l3", "l2"}); err != nil {
+		t.Fatalf("reorder live lessons: %v", err)
+	}
+
+	if got := lessonOrder(t, db, "m1"); !reflect.DeepEqual(got, []string{"l3", "l2", "l1"}) {
+		t.Fatalf("expected archived lesson last, got %v", got)
+	}
+
+	if err := repo.ReorderLessons(ctx, "m1", []string{"l3", "l2", "l1"}); !errors.Is(err, repository.ErrOrderMismatch) {
+		t.Fatalf("expected ErrOrderMismatch when listing an archived lesson, got %v", err)
+	}
+
+	if err := repo.MoveLesson(ctx, "l4", "m1", 1); err != nil {
+		t.Fatalf("move lesson: %v", err)
+	}
+
+	if got := lessonOrder(t, db, "m1"); !reflect.DeepEqual(got, []string{"l4", "l3", "l2", "l1"}) {
+		t.Fatalf("unexpected order after move %v", got)
+	}
+
+	if err := repo.ArchiveModule(ctx, "m3"); err != nil {
+		t.Fatalf("archive module: %v", err)
+	}
+
+	if err := repo.ReorderModules(ctx, "t1", []string{"m2", "m1"}); err != nil {
+		t.Fatalf("reorder live modules: %v", err)
+	}
+
+	if err := repo.MoveLesson(ctx, "l2", "m3", 0); !errors.Is(err, repository.ErrArchived) {
+		t.Fatalf("expected ErrArchived moving into an archived module, got %v", err)
+	}
+
+	if got := lessonOrder(t, db, "m3"); len(got) != 0 {
+		t.Fatalf("expected archived module to stay empty, got %v", got)
+	}
+}
@@ -22,11 +22,16 @@ func TestCreateTopic(t *testing.T) {
 		Tags:       []string{"go", "basics"},
 	}
 
-	if err := writeRepo.CreateTopic(context.Background(), input); err != nil {
+	revision, err := writeRepo.CreateTopic(context.Background(), input)
+	if err != nil {
 		t.Fatalf("create topic: %v", err)
 	}
 
-	topics, err := readRepo.ListTopics(context.Background())
+	if revision != 1 {
+		t.Fatalf("expected revision 1, got %d", revision)
+	}
+
+	topics, err := readRepo.ListTopics(context.Background(), "")
 	if err != nil {
 		t.Fatalf("list topics: %v", err)
 	}
@@ -51,7 +56,7 @@ func TestUpdateTopic(t *testing.T) {
 		Status: "draft",
 	}
 
-	if err := writeRepo.CreateTopic(context.Background(), input); err != nil {
+	if _, err := writeRepo.CreateTopic(context.Background(), input); err != nil {
 		t.Fatalf("create topic: %v", err)
 	}
 
@@ -60,11 +65,11 @@ func TestUpdateTopic(t *testing.T) {
 		Status: "published",
 	}
 
-	if err := writeRepo.UpdateTopic(context.Background(), "go-basics", updated); err != nil {
+	if _, err := writeRepo.UpdateTopic(context.Background(), "go-basics", 0, updated); err != nil {
 		t.Fatalf("update topic: %v", err)
 	}
 
-	topics, err := readRepo.ListTopics(context.Background())
+	topics, err := readRepo.ListTopics(context.Background(), "")
 	if err != nil {
 		t.Fatalf("list topics: %v", err)
 	}
@@ -78,7 +83,7 @@ func TestUpdateTopicNotFound(t *testing.T) {
 	db := setupTestDB(t)
 	writeRepo := repository.NewWriteRepository(db)
 
-	err := writeRepo.UpdateTopic(context.Background(), "nonexistent", models.TopicInput{Title: "X", Status: "draft"})
+	_, err := writeRepo.UpdateTopic(context.Background(), "nonexistent", 0, models.TopicInput{Title: "X", Status: "draft"})
 	if !errors.Is(err, repository.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -98,14 +103,14 @@ func TestCreateModule(t *testing.T) {
 		SortOrder: 1,
 	}
 
-	if err := writeRepo.CreateModule(context.Background(), input); err != nil {
+	if _, err := writeRepo.CreateModule(context.Background(), input); err != nil {
 		t.Fatalf("create module: %v", err)
 	}
 
 	// Seed a lesson so GetModuleByID has something to return.
 	seedLesson(t, db, "l1", "m1", "Hello World", 1)
 
-	module, err := readRepo.GetModuleByID(context.Background(), "m1")
+	module, err := readRepo.GetModuleByID(context.Background(), "m1", models.ReadOptions{})
 	if err != nil {
 		t.Fatalf("get module: %v", err)
 	}
@@ -130,7 +135,7 @@ func TestCreateLesson(t *testing.T) {
 		Content:  []byte(`[{"type":"text","body":"Hello"}]`),
 	}
 
-	if err := writeRepo.CreateLesson(context.Background(), input); err != nil {
+	if _, err := writeRepo.CreateLesson(context.Background(), input); err != nil {
 		t.Fatalf("create lesson: %v", err)
 	}
 
@@ -159,7 +164,7 @@ func TestCreateConcept(t *testing.T) {
 		Aliases:        []string{"var"},
 	}
 
-	if err := writeRepo.CreateConcept(context.Background(), input); err != nil {
+	if _, err := writeRepo.CreateConcept(context.Background(), input); err != nil {
 		t.Fatalf("create concept: %v", err)
 	}
 
@@ -254,11 +259,11 @@ func TestCreateTopicDuplicate(t *testing.T) {
 		Status: "draft",
 	}
 
-	if err := writeRepo.CreateTopic(context.Background(), input); err != nil {
+	if _, err := writeRepo.CreateTopic(context.Background(), input); err != nil {
 		t.Fatalf("first create: %v", err)
 	}
 
-	err := writeRepo.CreateTopic(context.Background(), input)
+	_, err := writeRepo.CreateTopic(context.Background(), input)
 	if !errors.Is(err, repository.ErrDuplicate) {
 		t.Fatalf("expected ErrDuplicate, got %v", err)
 	}
@@ -275,7 +280,7 @@ func TestCreateModuleFKViolation(t *testing.T) {
 		SortOrder: 1,
 	}
 
-	err := writeRepo.CreateModule(context.Background(), input)
+	_, err := writeRepo.CreateModule(context.Background(), input)
 	if !errors.Is(err, repository.ErrFKViolation) {
 		t.Fatalf("expected ErrFKViolation, got %v", err)
 	}
@@ -292,7 +297,7 @@ func TestCreateTopicSearchIndex(t *testing.T) {
 		Status:      "published",
 	}
 
-	if err := writeRepo.CreateTopic(context.Background(), input); err != nil {
+	if _, err := writeRepo.CreateTopic(context.Background(), input); err != nil {
 		t.Fatalf("create topic: %v", err)
 	}
 
@@ -308,3 +313,65 @@ func TestCreateTopicSearchIndex(t *testing.T) {
 		t.Fatalf("expected 1 search index entry, got %d", count)
 	}
 }
+
+func TestTransactionRollsBackOnError(t *testing.T) {
+	db := setupTestDB(t)
+	writeRepo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	err := writeRepo.Transaction(ctx, func(tx repository.WriteRepository) error {
+		if _, err := tx.CreateTopic(ctx, models.TopicInput{ID: "t1", Title: "T", Status: "draft"}); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateModule(ctx, models.ModuleInput{ID: "m1", TopicID: "t1", Title: "M"}); err != nil {
+			return err
+		}
+
+		_, err := tx.CreateModule(ctx, models.ModuleInput{ID: "m2", TopicID: "missing", Title: "M"})
+
+		return err
+	})
+	if !errors.Is(err, repository.ErrFKViolation) {
+		t.Fatalf("expected ErrFKViolation, got %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM topics) + (SELECT COUNT(*) FROM modules) + (SELECT COUNT(*) FROM search_index)`).Scan(&count); err != nil {
+		t.Fatalf("count rows: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected rollback to leave no rows, found %d", count)
+	}
+}
+
+func TestTransactionCommitsNestedWrites(t *testing.T) {
+	db := setupTestDB(t)
+	writeRepo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	err := writeRepo.Transaction(ctx, func(tx repository.WriteRepository) error {
+		if _, err := tx.CreateTopic(ctx, models.TopicInput{ID: "t1", Title: "T", Status: "draft"}); err != nil {
+			return err
+		}
+
+		// UpdateTopic opens its own transaction when standalone; here it must
+		// join the outer one.
+		_, err := tx.UpdateTopic(ctx, "t1", 0, models.TopicInput{Title: "Renamed", Status: "published"})
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	topic, err := repository.NewTopicRepository(db).GetTopicByID(ctx, "t1", models.ReadOptions{})
+	if err != nil || topic == nil {
+		t.Fatalf("get topic: %v", err)
+	}
+
+	if topic.Title != "Renamed" || topic.Revision != 2 {
+		t.Fatalf("expected renamed topic at revision 2, got %q rev %d", topic.Title, topic.Revision)
+	}
+}
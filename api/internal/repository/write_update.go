@@ -0,0 +1,673 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// checkRevision fails with ErrStaleRevision when ifRevision is set and is
+// not the row's current revision. Topics, modules, lessons, and concepts
+// carry a revision that every update increments; their Update and Patch
+// methods take the revision the caller last saw, where 0 skips the check,
+// and return the revision written.
+func checkRevision(entity, id string, currentRevision, ifRevision int) error {
+	if ifRevision != 0 && ifRevision != currentRevision {
+		return fmt.Errorf("%s %s at revision %d, expected %d: %w", entity, id, currentRevision, ifRevision, ErrStaleRevision)
+	}
+
+	return nil
+}
+
+const getTopicInputSQL = `
+SELECT id, title, COALESCE(description, ''), COALESCE(difficulty, ''),
+       COALESCE(estimated_hours, 0), tags, status, version,
+       source_urls, COALESCE(generated_at, ''), COALESCE(generated_by, ''),
+       COALESCE(parent_topic_id, ''), aliases, revision
+FROM topics
+WHERE id = ?
+`
+
+func loadTopicInput(ctx context.Context, q queryer, id string) (*models.TopicInput, int, error) {
+	in := &models.TopicInput{}
+	var revision int
+	var tagsRaw, sourceURLsRaw, aliasesRaw *string
+
+	err := q.QueryRowContext(ctx, getTopicInputSQL, id).Scan(
+		&in.ID, &in.Title, &in.Description, &in.Difficulty,
+		&in.EstimatedHours, &tagsRaw, &in.Status, &in.Version,
+		&sourceURLsRaw, &in.GeneratedAt, &in.GeneratedBy, &in.ParentTopicID, &aliasesRaw, &revision,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("topic %s: %w", id, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("load topic %s: %w", id, err)
+	}
+
+	in.Tags = models.ParseJSONStringSlice(tagsRaw)
+	in.SourceURLs = models.ParseJSONStringSlice(sourceURLsRaw)
+	in.Aliases = models.ParseJSONStringSlice(aliasesRaw)
+
+	return in, revision, nil
+}
+
+// updateTopicRow keeps the stored version when input.Version is zero.
+func updateTopicRow(ctx context.Context, q queryer, id string, input models.TopicInput) error {
+	return trackChange(ctx, q, models.AuditEntityTopic, id, func() error {
+		result, err := q.ExecContext(ctx, updateTopicSQL,
+			input.Title, nullIfEmpty(input.Description), nullIfEmpty(input.Difficulty),
+			nullIfZeroFloat(input.EstimatedHours), marshalJSONOrNil(input.Tags), input.Status, nullIfZero(input.Version),
+			marshalJSONOrNil(input.SourceURLs), nullIfEmpty(input.GeneratedAt), nullIfEmpty(input.GeneratedBy),
+			nullIfEmpty(input.ParentTopicID), marshalJSONOrNil(input.Aliases), id,
+		)
+		if err != nil {
+			return classifyError(err, "update topic")
+		}
+
+		if err := requireAffected(result, "topic", id); err != nil {
+			return err
+		}
+
+		if err := upsertSearchIndex(ctx, q, "topic", id, input.Title, input.Description); err != nil {
+			return err
+		}
+
+		return syncTopicCards(ctx, q, id)
+	})
+}
+
+// PatchTopic loads the topic, lets apply modify it, and writes it back in
+// the same transaction (PATCH semantics), where UpdateTopic replaces every
+// mutable column (PUT semantics). Identifying columns are never changed by
+// a patch; the other Patch methods work the same way.
+func (r *SQLiteWriteRepository) PatchTopic(ctx context.Context, id string, ifRevision int, apply func(*models.TopicInput) error) (*models.TopicInput, int, error) {
+	var patched *models.TopicInput
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current, currentRevision, err := loadTopicInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("topic", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.ID = id
+		patched = current
+		revision = currentRevision + 1
+
+		return updateTopicRow(ctx, tx, id, *current)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return patched, revision, nil
+}
+
+const getModuleInputSQL = `
+SELECT id, topic_id, title, COALESCE(description, ''), learning_objectives,
+       COALESCE(estimated_minutes, 0), sort_order, assessment, revision
+FROM modules
+WHERE id = ?
+`
+
+const updateModuleSQL = `
+UPDATE modules SET topic_id = ?, title = ?, description = ?, learning_objectives = ?,
+                   estimated_minutes = ?, sort_order = ?, assessment = ?,
+                   revision = revision + 1
+WHERE id = ?
+`
+
+func loadModuleInput(ctx context.Context, q queryer, id string) (*models.ModuleInput, int, error) {
+	in := &models.ModuleInput{}
+	var revision int
+	var loRaw, assessRaw *string
+
+	err := q.QueryRowContext(ctx, getModuleInputSQL, id).Scan(
+		&in.ID, &in.TopicID, &in.Title, &in.Description, &loRaw,
+		&in.EstimatedMinutes, &in.SortOrder, &assessRaw, &revision,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("module %s: %w", id, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("load module %s: %w", id, err)
+	}
+
+	in.LearningObjectives = models.ParseJSONStringSlice(loRaw)
+	in.Assessment = models.ParseJSONRaw(assessRaw)
+
+	return in, revision, nil
+}
+
+func updateModuleRow(ctx context.Context, q queryer, id string, input models.ModuleInput) error {
+	return trackChange(ctx, q, models.AuditEntityModule, id, func() error {
+		result, err := q.ExecContext(ctx, updateModuleSQL,
+			input.TopicID, input.Title, nullIfEmpty(input.Description),
+			marshalJSONOrNil(input.LearningObjectives), nullIfZero(input.EstimatedMinutes),
+			input.SortOrder, rawJSONOrNil(input.Assessment), id,
+		)
+		if err != nil {
+			return classifyError(err, "update module")
+		}
+
+		if err := requireAffected(result, "module", id); err != nil {
+			return err
+		}
+
+		return syncModuleQuestions(ctx, q, id)
+	})
+}
+
+func (r *SQLiteWriteRepository) UpdateModule(ctx context.Context, id string, ifRevision int, input models.ModuleInput) (int, error) {
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		_, currentRevision, err := loadModuleInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("module", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		revision = currentRevision + 1
+
+		return updateModuleRow(ctx, tx, id, input)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, nil
+}
+
+func (r *SQLiteWriteRepository) PatchModule(ctx context.Context, id string, ifRevision int, apply func(*models.ModuleInput) error) (*models.ModuleInput, int, error) {
+	var patched *models.ModuleInput
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current, currentRevision, err := loadModuleInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("module", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.ID = id
+		patched = current
+		revision = currentRevision + 1
+
+		return updateModuleRow(ctx, tx, id, *current)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return patched, revision, nil
+}
+
+const getLessonInputSQL = `
+SELECT id, module_id, title, sort_order, COALESCE(estimated_minutes, 0),
+       content, examples, exercises, review_questions, revision
+FROM lessons
+WHERE id = ?
+`
+
+const updateLessonSQL = `
+UPDATE lessons SET module_id = ?, title = ?, sort_order = ?, estimated_minutes = ?,
+                   content = ?, examples = ?, exercises = ?, review_questions = ?,
+                   revision = revision + 1
+WHERE id = ?
+`
+
+func loadLessonInput(ctx context.Context, q queryer, id string) (*models.LessonInput, int, error) {
+	in := &models.LessonInput{}
+	var revision int
+	var contentRaw, examplesRaw, exercisesRaw, reviewRaw *string
+
+	err := q.QueryRowContext(ctx, getLessonInputSQL, id).Scan(
+		&in.ID, &in.ModuleID, &in.Title, &in.SortOrder, &in.EstimatedMinutes,
+		&contentRaw, &examplesRaw, &exercisesRaw, &reviewRaw, &revision,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("lesson %s: %w", id, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("load lesson %s: %w", id, err)
+	}
+
+	in.Content = models.ParseJSONRaw(contentRaw)
+	in.Examples = models.ParseJSONRaw(examplesRaw)
+	in.Exercises = models.ParseJSONRaw(exercisesRaw)
+	in.ReviewQuestions = models.ParseJSONRaw(reviewRaw)
+
+	return in, revision, nil
+}
+
+func updateLessonRow(ctx context.Context, q queryer, id string, input models.LessonInput) error {
+	return trackChange(ctx, q, models.AuditEntityLesson, id, func() error {
+		result, err := q.ExecContext(ctx, updateLessonSQL,
+			input.ModuleID, input.Title, input.SortOrder, nullIfZero(input.EstimatedMinutes),
+			string(input.Content), rawJSONOrNil(input.Examples), rawJSONOrNil(input.Exercises),
+			rawJSONOrNil(input.ReviewQuestions), id,
+		)
+		if err != nil {
+			return classifyError(err, "update lesson")
+		}
+
+		if err := requireAffected(result, "lesson", id); err != nil {
+			return err
+		}
+
+		if err := upsertSearchIndex(ctx, q, "lesson", id, input.Title, ""); err != nil {
+			return err
+		}
+
+		return syncLessonQuestions(ctx, q, id)
+	})
+}
+
+func (r *SQLiteWriteRepository) UpdateLesson(ctx context.Context, id string, ifRevision int, input models.LessonInput) (int, error) {
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		_, currentRevision, err := loadLessonInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("lesson", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		revision = currentRevision + 1
+
+		return updateLessonRow(ctx, tx, id, input)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, nil
+}
+
+func (r *SQLiteWriteRepository) PatchLesson(ctx context.Context, id string, ifRevision int, apply func(*models.LessonInput) error) (*models.LessonInput, int, error) {
+	var patched *models.LessonInput
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current, currentRevision, err := loadLessonInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("lesson", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.ID = id
+		patched = current
+		revision = currentRevision + 1
+
+		return updateLessonRow(ctx, tx, id, *current)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return patched, revision, nil
+}
+
+const getConceptInputSQL = `
+SELECT id, name, definition, COALESCE(defined_in_lesson, ''), COALESCE(defined_in_topic, ''),
+       COALESCE(difficulty, ''), COALESCE(flashcard_front, ''), COALESCE(flashcard_back, ''),
+       status, aliases, revision
+FROM concepts
+WHERE id = ?
+`
+
+const updateConceptSQL = `
+UPDATE concepts SET name = ?, definition = ?, defined_in_lesson = ?, defined_in_topic = ?,
+                    difficulty = ?, flashcard_front = ?, flashcard_back = ?, status = ?, aliases = ?,
+                    revision = revision + 1
+WHERE id = ?
+`
+
+func loadConceptInput(ctx context.Context, q queryer, id string) (*models.ConceptInput, int, error) {
+	in := &models.ConceptInput{}
+	var revision int
+	var aliasesRaw *string
+
+	err := q.QueryRowContext(ctx, getConceptInputSQL, id).Scan(
+		&in.ID, &in.Name, &in.Definition, &in.DefinedInLesson, &in.DefinedInTopic,
+		&in.Difficulty, &in.FlashcardFront, &in.FlashcardBack, &in.Status, &aliasesRaw, &revision,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("concept %s: %w", id, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("load concept %s: %w", id, err)
+	}
+
+	in.Aliases = models.ParseJSONStringSlice(aliasesRaw)
+
+	return in, revision, nil
+}
+
+// updateConceptRow regenerates the cards of the topic defining the concept,
+// and of the one that defined it before.
+func updateConceptRow(ctx context.Context, q queryer, id string, input models.ConceptInput) error {
+	return trackChange(ctx, q, models.AuditEntityConcept, id, func() error {
+		status := input.Status
+		if status == "" {
+			status = "active"
+		}
+
+		oldTopic, err := conceptTopic(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		result, err := q.ExecContext(ctx, updateConceptSQL,
+			input.Name, input.Definition,
+			nullIfEmpty(input.DefinedInLesson), nullIfEmpty(input.DefinedInTopic),
+			nullIfEmpty(input.Difficulty), nullIfEmpty(input.FlashcardFront),
+			nullIfEmpty(input.FlashcardBack), status, marshalJSONOrNil(input.Aliases), id,
+		)
+		if err != nil {
+			return classifyError(err, "update concept")
+		}
+
+		if err := requireAffected(result, "concept", id); err != nil {
+			return err
+		}
+
+		if err := upsertSearchIndex(ctx, q, "concept", id, input.Name, input.Definition); err != nil {
+			return err
+		}
+
+		return syncTopicCards(ctx, q, input.DefinedInTopic, oldTopic)
+	})
+}
+
+func (r *SQLiteWriteRepository) UpdateConcept(ctx context.Context, id string, ifRevision int, input models.ConceptInput) (int, error) {
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		_, currentRevision, err := loadConceptInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("concept", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		revision = currentRevision + 1
+
+		return updateConceptRow(ctx, tx, id, input)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, nil
+}
+
+func (r *SQLiteWriteRepository) PatchConcept(ctx context.Context, id string, ifRevision int, apply func(*models.ConceptInput) error) (*models.ConceptInput, int, error) {
+	var patched *models.ConceptInput
+	var revision int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current, currentRevision, err := loadConceptInput(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRevision("concept", id, currentRevision, ifRevision); err != nil {
+			return err
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.ID = id
+		patched = current
+		revision = currentRevision + 1
+
+		return updateConceptRow(ctx, tx, id, *current)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return patched, revision, nil
+}
+
+const getConceptRefInputSQL = `
+SELECT lesson_id, COALESCE(context, '')
+FROM concept_references
+WHERE concept_id = ? AND lesson_id = ?
+`
+
+const updateConceptRefSQL = `
+UPDATE concept_references SET context = ?
+WHERE concept_id = ? AND lesson_id = ?
+`
+
+func updateConceptRefRow(ctx context.Context, q queryer, conceptID, lessonID string, input models.ConceptReferenceInput) error {
+	return trackChange(ctx, q, models.AuditEntityConceptReference, conceptID+"/"+lessonID, func() error {
+		result, err := q.ExecContext(ctx, updateConceptRefSQL, nullIfEmpty(input.Context), conceptID, lessonID)
+		if err != nil {
+			return classifyError(err, "update concept reference")
+		}
+
+		return requireAffected(result, "concept reference", conceptID+"/"+lessonID)
+	})
+}
+
+func (r *SQLiteWriteRepository) UpdateConceptReference(ctx context.Context, conceptID, lessonID string, input models.ConceptReferenceInput) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return updateConceptRefRow(ctx, tx, conceptID, lessonID, input)
+	})
+}
+
+func (r *SQLiteWriteRepository) PatchConceptReference(ctx context.Context, conceptID, lessonID string, apply func(*models.ConceptReferenceInput) error) (*models.ConceptReferenceInput, error) {
+	var patched *models.ConceptReferenceInput
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current := &models.ConceptReferenceInput{}
+
+		err := tx.QueryRowContext(ctx, getConceptRefInputSQL, conceptID, lessonID).Scan(&current.LessonID, &current.Context)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("concept reference %s/%s: %w", conceptID, lessonID, ErrNotFound)
+		}
+
+		if err != nil {
+			return fmt.Errorf("load concept reference %s/%s: %w", conceptID, lessonID, err)
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.LessonID = lessonID
+		patched = current
+
+		return updateConceptRefRow(ctx, tx, conceptID, lessonID, *current)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+const getPrerequisiteInputSQL = `
+SELECT topic_id, prerequisite_topic_id, priority, COALESCE(reason, '')
+FROM topic_prerequisites
+WHERE topic_id = ? AND prerequisite_topic_id = ?
+`
+
+const updatePrerequisiteSQL = `
+UPDATE topic_prerequisites SET priority = ?, reason = ?
+WHERE topic_id = ? AND prerequisite_topic_id = ?
+`
+
+func updatePrerequisiteRow(ctx context.Context, q queryer, input models.PrerequisiteInput) error {
+	return trackChange(ctx, q, models.AuditEntityPrerequisite, input.TopicID+"/"+input.PrerequisiteTopicID, func() error {
+		result, err := q.ExecContext(ctx, updatePrerequisiteSQL,
+			input.Priority, nullIfEmpty(input.Reason), input.TopicID, input.PrerequisiteTopicID,
+		)
+		if err != nil {
+			return classifyError(err, "update prerequisite")
+		}
+
+		return requireAffected(result, "prerequisite", input.TopicID+"/"+input.PrerequisiteTopicID)
+	})
+}
+
+func (r *SQLiteWriteRepository) UpdatePrerequisite(ctx context.Context, input models.PrerequisiteInput) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return updatePrerequisiteRow(ctx, tx, input)
+	})
+}
+
+func (r *SQLiteWriteRepository) PatchPrerequisite(ctx context.Context, topicID, prerequisiteID string, apply func(*models.PrerequisiteInput) error) (*models.PrerequisiteInput, error) {
+	var patched *models.PrerequisiteInput
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current := &models.PrerequisiteInput{}
+
+		err := tx.QueryRowContext(ctx, getPrerequisiteInputSQL, topicID, prerequisiteID).Scan(
+			&current.TopicID, &current.PrerequisiteTopicID, &current.Priority, &current.Reason,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("prerequisite %s/%s: %w", topicID, prerequisiteID, ErrNotFound)
+		}
+
+		if err != nil {
+			return fmt.Errorf("load prerequisite %s/%s: %w", topicID, prerequisiteID, err)
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.TopicID = topicID
+		current.PrerequisiteTopicID = prerequisiteID
+		patched = current
+
+		return updatePrerequisiteRow(ctx, tx, *current)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+const getRelationInputSQL = `
+SELECT topic_a, topic_b, relation_type, COALESCE(description, '')
+FROM topic_relations
+WHERE topic_a = ? AND topic_b = ?
+`
+
+const updateRelationSQL = `
+UPDATE topic_relations SET relation_type = ?, description = ?
+WHERE topic_a = ? AND topic_b = ?
+`
+
+func updateRelationRow(ctx context.Context, q queryer, input models.RelationInput) error {
+	return trackChange(ctx, q, models.AuditEntityRelation, input.TopicA+"/"+input.TopicB, func() error {
+		result, err := q.ExecContext(ctx, updateRelationSQL,
+			input.RelationType, nullIfEmpty(input.Description), input.TopicA, input.TopicB,
+		)
+		if err != nil {
+			return classifyError(err, "update relation")
+		}
+
+		return requireAffected(result, "relation", input.TopicA+"/"+input.TopicB)
+	})
+}
+
+func (r *SQLiteWriteRepository) UpdateRelation(ctx context.Context, input models.RelationInput) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return updateRelationRow(ctx, tx, input)
+	})
+}
+
+func (r *SQLiteWriteRepository) PatchRelation(ctx context.Context, topicA, topicB string, apply func(*models.RelationInput) error) (*models.RelationInput, error) {
+	var patched *models.RelationInput
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		current := &models.RelationInput{}
+
+		err := tx.QueryRowContext(ctx, getRelationInputSQL, topicA, topicB).Scan(
+			&current.TopicA, &current.TopicB, &current.RelationType, &current.Description,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("relation %s/%s: %w", topicA, topicB, ErrNotFound)
+		}
+
+		if err != nil {
+			return fmt.Errorf("load relation %s/%s: %w", topicA, topicB, err)
+		}
+
+		if err := apply(current); err != nil {
+			return err
+		}
+
+		current.TopicA = topicA
+		current.TopicB = topicB
+		patched = current
+
+		return updateRelationRow(ctx, tx, *current)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+func requireAffected(result sql.Result, entity, id string) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s rows affected: %w", entity, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%s %s: %w", entity, id, ErrNotFound)
+	}
+
+	return nil
+}
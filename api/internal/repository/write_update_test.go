@@ -0,0 +1,317 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+func searchIndexTitle(t *testing.T, db *sql.DB, entityType, entityID string) string {
+	t.Helper()
+
+	var title string
+
+	err := db.QueryRow(`SELECT title FROM search_index WHERE entity_type = ? AND entity_id = ?`, entityType, entityID).Scan(&title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ""
+	}
+
+	if err != nil {
+		t.Fatalf("query search index: %v", err)
+	}
+
+	return title
+}
+
+func TestUpdateModule(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+	seedTopic(t, db, "t2", "Topic 2", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Intro", 1)
+
+	_, err := repo.UpdateModule(context.Background(), "m1", 0, models.ModuleInput{
+		TopicID: "t2", Title: "Moved", SortOrder: 3, LearningObjectives: []string{"learn"},
+	})
+	if err != nil {
+		t.Fatalf("update module: %v", err)
+	}
+
+	md, err := repository.NewModuleRepository(db).GetModuleByID(context.Background(), "m1", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get module: %v", err)
+	}
+
+	if md.TopicID != "t2" || md.Title != "Moved" || md.SortOrder != 3 || len(md.LearningObjectives) != 1 {
+		t.Fatalf("unexpected module after update: %+v", md)
+	}
+}
+
+func TestUpdateModuleNotFoundAndFKViolation(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Intro", 1)
+
+	_, err := repo.UpdateModule(context.Background(), "missing", 0, models.ModuleInput{TopicID: "t1", Title: "X"})
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err = repo.UpdateModule(context.Background(), "m1", 0, models.ModuleInput{TopicID: "nope", Title: "X"})
+	if !errors.Is(err, repository.ErrFKViolation) {
+		t.Fatalf("expected ErrFKViolation, got %v", err)
+	}
+}
+
+func TestUpdateLessonRefreshesSearchIndex(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Intro", 1)
+
+	if _, err := repo.CreateLesson(context.Background(), models.LessonInput{
+		ID: "l1", ModuleID: "m1", Title: "Old", Content: []byte(`[]`),
+	}); err != nil {
+		t.Fatalf("create lesson: %v", err)
+	}
+
+	if _, err := repo.UpdateLesson(context.Background(), "l1", 0, models.LessonInput{
+		ModuleID: "m1", Title: "New Title", SortOrder: 2, Content: []byte(`[{"type":"text","body":"x"}]`),
+	}); err != nil {
+		t.Fatalf("update lesson: %v", err)
+	}
+
+	if got := searchIndexTitle(t, db, "lesson", "l1"); got != "New Title" {
+		t.Fatalf("expected search index title 'New Title', got %q", got)
+	}
+}
+
+func TestUpdateConcept(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+
+	if _, err := repo.CreateConcept(context.Background(), models.ConceptInput{
+		ID: "c1", Name: "Var", Definition: "old", DefinedInTopic: "t1",
+	}); err != nil {
+		t.Fatalf("create concept: %v", err)
+	}
+
+	if _, err := repo.UpdateConcept(context.Background(), "c1", 0, models.ConceptInput{
+		Name: "Variable", Definition: "A named value", Aliases: []string{"var"},
+	}); err != nil {
+		t.Fatalf("update concept: %v", err)
+	}
+
+	cd, err := repository.NewConceptRepository(db).GetConceptByID(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("get concept: %v", err)
+	}
+
+	if cd.Name != "Variable" || cd.Status != "active" || len(cd.Aliases) != 1 {
+		t.Fatalf("unexpected concept after update: %+v", cd)
+	}
+
+	if got := searchIndexTitle(t, db, "concept", "c1"); got != "Variable" {
+		t.Fatalf("expected search index title 'Variable', got %q", got)
+	}
+}
+
+func TestUpdateConceptReferencePrerequisiteAndRelation(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+	seedTopic(t, db, "t2", "Topic 2", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Intro", 1)
+	seedLesson(t, db, "l1", "m1", "Lesson", 1)
+	seedConcept(t, db, "c1", "Var", "def", "t1")
+	seedConceptReference(t, db, "c1", "l1")
+	mustExec(t, db, `INSERT INTO topic_prerequisites (topic_id, prerequisite_topic_id, priority) VALUES ('t2', 't1', 'helpful')`)
+	mustExec(t, db, `INSERT INTO topic_relations (topic_a, topic_b, relation_type) VALUES ('t1', 't2', 'related')`)
+
+	if err := repo.UpdateConceptReference(ctx, "c1", "l1", models.ConceptReferenceInput{Context: "updated"}); err != nil {
+		t.Fatalf("update concept reference: %v", err)
+	}
+
+	if err := repo.UpdatePrerequisite(ctx, models.PrerequisiteInput{
+		TopicID: "t2", PrerequisiteTopicID: "t1", Priority: "essential", Reason: "needed",
+	}); err != nil {
+		t.Fatalf("update prerequisite: %v", err)
+	}
+
+	if err := repo.UpdateRelation(ctx, models.RelationInput{TopicA: "t1", TopicB: "t2", RelationType: "builds_on"}); err != nil {
+		t.Fatalf("update relation: %v", err)
+	}
+
+	var refContext, priority, relationType string
+	if err := db.QueryRow(`SELECT context FROM concept_references WHERE concept_id = 'c1' AND lesson_id = 'l1'`).Scan(&refContext); err != nil {
+		t.Fatalf("query reference: %v", err)
+	}
+
+	if err := db.QueryRow(`SELECT priority FROM topic_prerequisites WHERE topic_id = 't2'`).Scan(&priority); err != nil {
+		t.Fatalf("query prerequisite: %v", err)
+	}
+
+	if err := db.QueryRow(`SELECT relation_type FROM topic_relations WHERE topic_a = 't1'`).Scan(&relationType); err != nil {
+		t.Fatalf("query relation: %v", err)
+	}
+
+	if refContext != "updated" || priority != "essential" || relationType != "builds_on" {
+		t.Fatalf("unexpected values: context=%q priority=%q relation=%q", refContext, priority, relationType)
+	}
+
+	err := repo.UpdatePrerequisite(ctx, models.PrerequisiteInput{TopicID: "t2", PrerequisiteTopicID: "t1", Priority: "bogus"})
+	if !errors.Is(err, repository.ErrCheckViolation) {
+		t.Fatalf("expected ErrCheckViolation, got %v", err)
+	}
+
+	err = repo.UpdateRelation(ctx, models.RelationInput{TopicA: "t2", TopicB: "t1", RelationType: "related"})
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for reversed relation, got %v", err)
+	}
+}
+
+func TestPatchLessonKeepsUnsetFields(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Intro", 1)
+	seedLesson(t, db, "l1", "m1", "Lesson", 4)
+
+	patched, revision, err := repo.PatchLesson(context.Background(), "l1", 1, func(in *models.LessonInput) error {
+		in.Title = "Patched"
+		in.ID = "ignored"
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("patch lesson: %v", err)
+	}
+
+	if revision != 2 {
+		t.Fatalf("expected revision 2 after patch, got %d", revision)
+	}
+
+	if patched.ID != "l1" || patched.Title != "Patched" || patched.SortOrder != 4 || len(patched.Content) == 0 {
+		t.Fatalf("unexpected patched lesson: %+v", patched)
+	}
+
+	if got := searchIndexTitle(t, db, "lesson", "l1"); got != "Patched" {
+		t.Fatalf("expected search index title 'Patched', got %q", got)
+	}
+}
+
+func TestPatchRollsBackWhenApplyFails(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+
+	applyErr := errors.New("rejected")
+
+	_, _, err := repo.PatchTopic(context.Background(), "t1", 0, func(in *models.TopicInput) error {
+		in.Title = "Changed"
+
+		return applyErr
+	})
+	if !errors.Is(err, applyErr) {
+		t.Fatalf("expected apply error, got %v", err)
+	}
+
+	td, err := repository.NewTopicRepository(db).GetTopicByID(context.Background(), "t1", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get topic: %v", err)
+	}
+
+	if td.Title != "Topic 1" {
+		t.Fatalf("expected unchanged title, got %q", td.Title)
+	}
+}
+
+func TestPatchNotFound(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+	noop := func(*models.PrerequisiteInput) error { return nil }
+
+	if _, err := repo.PatchPrerequisite(context.Background(), "a", "b", noop); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPatchRejectsStaleRevision(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	seedTopic(t, db, "t1", "Topic 1", "foundational", "published")
+	seedModule(t, db, "m1", "t1", "Intro", 1)
+
+	rename := func(in *models.ModuleInput) error {
+		in.Title = "Renamed"
+
+		return nil
+	}
+
+	if _, _, err := repo.PatchModule(ctx, "m1", 1, rename); err != nil {
+		t.Fatalf("first patch: %v", err)
+	}
+
+	// A second writer still holding revision 1 must not clobber the change.
+	if _, _, err := repo.PatchModule(ctx, "m1", 1, rename); !errors.Is(err, repository.ErrStaleRevision) {
+		t.Fatalf("expected ErrStaleRevision, got %v", err)
+	}
+
+	// PUT checks the revision and bumps it too.
+	put := models.ModuleInput{TopicID: "t1", Title: "Put"}
+	if _, err := repo.UpdateModule(ctx, "m1", 1, put); !errors.Is(err, repository.ErrStaleRevision) {
+		t.Fatalf("expected ErrStaleRevision from stale PUT, got %v", err)
+	}
+
+	if revision, err := repo.UpdateModule(ctx, "m1", 2, put); err != nil || revision != 3 {
+		t.Fatalf("update module: revision %d, %v", revision, err)
+	}
+
+	md, err := repository.NewModuleRepository(db).GetModuleByID(ctx, "m1", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get module: %v", err)
+	}
+
+	if md.Revision != 3 {
+		t.Fatalf("expected revision 3, got %d", md.Revision)
+	}
+}
+
+func TestUpdateTopicKeepsVersionWhenOmitted(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewWriteRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.CreateTopic(ctx, models.TopicInput{ID: "t1", Title: "Topic", Status: "draft", Version: 4}); err != nil {
+		t.Fatalf("create topic: %v", err)
+	}
+
+	if _, err := repo.UpdateTopic(ctx, "t1", 0, models.TopicInput{Title: "Topic", Status: "published"}); err != nil {
+		t.Fatalf("update topic: %v", err)
+	}
+
+	td, err := repository.NewTopicRepository(db).GetTopicByID(ctx, "t1", models.ReadOptions{})
+	if err != nil {
+		t.Fatalf("get topic: %v", err)
+	}
+
+	if td.Version != 4 || td.Revision != 2 {
+		t.Fatalf("expected version 4 and revision 2, got version %d revision %d", td.Version, td.Revision)
+	}
+}
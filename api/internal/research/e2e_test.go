@@ -142,7 +142,7 @@ func setupE2E(t *testing.T, cli *e2eMockCLI) *e2eEnv {
 	t.Helper()
 
 	db := setupTestDB(t)
-	researchRepo := repository.NewResearchJobRepository(db)
+	researchRepo := repository.NewResearchJobRepository(db, db)
 	topicRepo := repository.NewTopicRepository(db)
 	conceptRepo := repository.NewConceptRepository(db)
 	pool := research.NewPoolSummaryBuilder(db)
@@ -10,3 +10,9 @@ import (
 //
 //go:embed prompts/research.md
 var systemPromptContent []byte
+
+// flashcardPromptContent holds the prompt for flashcard jobs, which rewrite
+// a leech's definition and flashcard.
+//
+//go:embed prompts/flashcard.md
+var flashcardPromptContent []byte
@@ -0,0 +1,164 @@
+package research
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/audit"
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// Filenames in a flashcard job's work directory.
+const (
+	flashcardPromptFile = "flashcard.md"
+	flashcardOutputFile = "flashcard.json"
+)
+
+// CardProposer supplies flashcard jobs: the leech whose card a job rewrites
+// and the store for the card it proposes.
+type CardProposer interface {
+	GetLeech(ctx context.Context, conceptID string) (*models.Leech, error)
+	ProposeCard(ctx context.Context, conceptID, jobID string, card models.CardProposal) (*models.CardProposal, error)
+}
+
+// WithCardProposer enables flashcard jobs and returns the orchestrator.
+// Without one, flashcard jobs fail.
+func (o *Orchestrator) WithCardProposer(cards CardProposer) *Orchestrator {
+	o.cards = cards
+
+	return o
+}
+
+// flashcardOut is the file a flashcard job writes.
+type flashcardOut struct {
+	Definition string       `json:"definition"`
+	Flashcard  FlashcardOut `json:"flashcard"`
+}
+
+// runFlashcardJob rewrites a leech's definition and flashcard in a single
+// CLI pass and stores the result as a proposal awaiting approval. jobCtx
+// is cancelled when the job is.
+func (o *Orchestrator) runFlashcardJob(ctx, jobCtx context.Context, job *models.ResearchJob, log zerolog.Logger) error {
+	if o.cards == nil {
+		return o.failJob(ctx, job.ID, errors.New("flashcard jobs are not enabled"))
+	}
+
+	leech, err := o.cards.GetLeech(jobCtx, job.ConceptID)
+	if err != nil {
+		return o.failJob(ctx, job.ID, fmt.Errorf("load leech: %w", err))
+	}
+
+	log.Info().Str("concept_id", leech.ConceptID).Msg("starting flashcard rewrite")
+
+	workDir := filepath.Join(o.cfg.ResearchWorkDir, job.ID)
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return o.failJob(ctx, job.ID, fmt.Errorf("create work dir: %w", err))
+	}
+
+	if err := os.WriteFile(filepath.Join(workDir, flashcardPromptFile), flashcardPromptContent, 0o644); err != nil {
+		return o.failJob(ctx, job.ID, fmt.Errorf("write system prompt: %w", err))
+	}
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			log.Warn().Int("attempt", attempt+1).Msg("retrying flashcard pass")
+		}
+
+		_, err = o.cli.RunInitialPass(jobCtx, InitialPassOpts{
+			Prompt:           buildFlashcardPrompt(leech),
+			WorkDir:          workDir,
+			SystemPromptFile: flashcardPromptFile,
+			Model:            config.DefaultResearchModel,
+			AllowedTools:     config.ResearchAllowedTools(),
+		})
+		if err == nil || jobCtx.Err() != nil {
+			break
+		}
+	}
+
+	if jobCtx.Err() != nil {
+		return o.handleCancellation(job.ID, log)
+	}
+
+	if err != nil {
+		return o.failJob(ctx, job.ID, fmt.Errorf("flashcard pass failed after %d attempts: %w", maxRetries+1, err))
+	}
+
+	if err := o.repo.UpdateJobStatus(ctx, job.ID, models.ResearchStatusResolving, ""); err != nil {
+		return o.failJob(ctx, job.ID, fmt.Errorf("update status to resolving: %w", err))
+	}
+
+	card, err := readFlashcard(workDir)
+	if err != nil {
+		return o.failJob(ctx, job.ID, err)
+	}
+
+	proposeCtx := audit.WithActor(ctx, audit.ResearchAgent(job.ID))
+	if _, err := o.cards.ProposeCard(proposeCtx, job.ConceptID, job.ID, card); err != nil {
+		return o.failJob(ctx, job.ID, fmt.Errorf("propose card: %w", err))
+	}
+
+	if err := o.repo.UpdateJobStatus(ctx, job.ID, models.ResearchStatusPublished, ""); err != nil {
+		return fmt.Errorf("update status to published: %w", err)
+	}
+
+	log.Info().Msg("flashcard rewrite proposed")
+
+	return nil
+}
+
+// buildFlashcardPrompt describes the leech to rewrite.
+func buildFlashcardPrompt(leech *models.Leech) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Rewrite the definition and flashcard of the concept: %s\n\n", leech.Name)
+	fmt.Fprintf(&b, "Current definition: %s\n", leech.Definition)
+	fmt.Fprintf(&b, "Current flashcard front: %s\n", leech.FlashcardFront)
+	fmt.Fprintf(&b, "Current flashcard back: %s\n", leech.FlashcardBack)
+
+	if leech.LessonTitle != "" {
+		fmt.Fprintf(&b, "Taught in lesson: %s\n", leech.LessonTitle)
+	}
+
+	if leech.TopicTitle != "" {
+		fmt.Fprintf(&b, "Topic: %s\n", leech.TopicTitle)
+	}
+
+	fmt.Fprintf(&b, "Forgotten %d times in %d reviews.\n\n", leech.Lapses, leech.ReviewCount)
+	fmt.Fprintf(&b, "Follow %s and write %s.", flashcardPromptFile, flashcardOutputFile)
+
+	return b.String()
+}
+
+// readFlashcard loads and validates the card a flashcard job wrote.
+func readFlashcard(workDir string) (models.CardProposal, error) {
+	data, err := os.ReadFile(filepath.Join(workDir, flashcardOutputFile))
+	if err != nil {
+		return models.CardProposal{}, fmt.Errorf("read %s: %w", flashcardOutputFile, err)
+	}
+
+	var out flashcardOut
+	if err := json.Unmarshal(data, &out); err != nil {
+		return models.CardProposal{}, fmt.Errorf("parse %s: %w", flashcardOutputFile, err)
+	}
+
+	card := models.CardProposal{
+		Definition:     strings.TrimSpace(out.Definition),
+		FlashcardFront: strings.TrimSpace(out.Flashcard.Front),
+		FlashcardBack:  strings.TrimSpace(out.Flashcard.Back),
+	}
+
+	if card.Definition == "" || card.FlashcardFront == "" || card.FlashcardBack == "" {
+		return models.CardProposal{}, fmt.Errorf("%s: definition, flashcard front and back are required", flashcardOutputFile)
+	}
+
+	return card, nil
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
 	"github.com/sean/apollo/api/internal/schema"
 )
 
@@ -22,67 +26,9 @@ func NewCurriculumIngester(db *sql.DB) *CurriculumIngester {
 // Ingest validates rawJSON against the curriculum schema, parses it,
 // and stores all entities in the database within a single transaction.
 func (ing *CurriculumIngester) Ingest(ctx context.Context, rawJSON json.RawMessage) error {
-	if err := schema.Validate(rawJSON); err != nil {
-		return fmt.Errorf("schema validation: %w", err)
-	}
-
-	var curr CurriculumOutput
-	if err := json.Unmarshal(rawJSON, &curr); err != nil {
-		return fmt.Errorf("unmarshal curriculum: %w", err)
-	}
-
-	tx, err := ing.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
-	}
-
-	defer func() { _ = tx.Rollback() }()
-
-	if err := ing.storeTopic(ctx, tx, &curr); err != nil {
-		return err
-	}
-
-	for i, mod := range curr.Modules {
-		if err := ing.storeModule(ctx, tx, curr.ID, &mod, i); err != nil {
-			return err
-		}
-
-		for j, lesson := range mod.Lessons {
-			if err := ing.storeLesson(ctx, tx, mod.ID, &lesson, j); err != nil {
-				return err
-			}
-
-			for _, concept := range lesson.ConceptsTaught {
-				if err := ing.storeConcept(ctx, tx, curr.ID, lesson.ID, &concept); err != nil {
-					return err
-				}
-			}
-
-			for _, ref := range lesson.ConceptsReferenced {
-				if err := ing.storeConceptReference(ctx, tx, ref.ID, lesson.ID); err != nil {
-					return err
-				}
-			}
-		}
-	}
+	_, err := ing.IngestWithResult(ctx, rawJSON)
 
-	if err := ing.storePrerequisites(ctx, tx, curr.ID, &curr.Prerequisites); err != nil {
-		return err
-	}
-
-	if err := ing.storeExpansionQueue(ctx, tx, curr.ID, &curr.Prerequisites); err != nil {
-		return err
-	}
-
-	if err := ing.storeSearchIndex(ctx, tx, &curr); err != nil {
-		return err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
-	}
-
-	return nil
+	return err
 }
 
 const insertTopicSQL = `
@@ -95,16 +41,18 @@ func (ing *CurriculumIngester) storeTopic(ctx context.Context, tx *sql.Tx, curr
 	tags, _ := json.Marshal(curr.Tags)
 	sourceURLs, _ := json.Marshal(curr.SourceURLs)
 
-	_, err := tx.ExecContext(ctx, insertTopicSQL,
-		curr.ID, curr.Title, curr.Description, curr.Difficulty,
-		curr.EstimatedHours, string(tags), curr.Version,
-		string(sourceURLs), curr.GeneratedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("insert topic %s: %w", curr.ID, err)
-	}
+	return repository.TrackChange(ctx, tx, models.AuditEntityTopic, curr.ID, func() error {
+		_, err := tx.ExecContext(ctx, insertTopicSQL,
+			curr.ID, curr.Title, curr.Description, curr.Difficulty,
+			curr.EstimatedHours, string(tags), curr.Version,
+			string(sourceURLs), curr.GeneratedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert topic %s: %w", curr.ID, err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 const insertModuleSQL = `
@@ -124,15 +72,17 @@ func (ing *CurriculumIngester) storeModule(ctx context.Context, tx *sql.Tx, topi
 		assessment = string(mod.Assessment)
 	}
 
-	_, err := tx.ExecContext(ctx, insertModuleSQL,
-		mod.ID, topicID, mod.Title, mod.Description,
-		string(lo), mod.EstimatedMinutes, sortOrder, assessment,
-	)
-	if err != nil {
-		return fmt.Errorf("insert module %s: %w", mod.ID, err)
-	}
+	return repository.TrackChange(ctx, tx, models.AuditEntityModule, mod.ID, func() error {
+		_, err := tx.ExecContext(ctx, insertModuleSQL,
+			mod.ID, topicID, mod.Title, mod.Description,
+			string(lo), mod.EstimatedMinutes, sortOrder, assessment,
+		)
+		if err != nil {
+			return fmt.Errorf("insert module %s: %w", mod.ID, err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 const insertLessonSQL = `
@@ -151,15 +101,17 @@ func (ing *CurriculumIngester) storeLesson(ctx context.Context, tx *sql.Tx, modu
 	exercisesStr := rawOrNil(lesson.Exercises)
 	reviewStr := rawOrNil(lesson.ReviewQuestions)
 
-	_, err := tx.ExecContext(ctx, insertLessonSQL,
-		lesson.ID, moduleID, lesson.Title, sortOrder,
-		lesson.EstimatedMinutes, contentStr, examplesStr, exercisesStr, reviewStr,
-	)
-	if err != nil {
-		return fmt.Errorf("insert lesson %s: %w", lesson.ID, err)
-	}
+	return repository.TrackChange(ctx, tx, models.AuditEntityLesson, lesson.ID, func() error {
+		_, err := tx.ExecContext(ctx, insertLessonSQL,
+			lesson.ID, moduleID, lesson.Title, sortOrder,
+			lesson.EstimatedMinutes, contentStr, examplesStr, exercisesStr, reviewStr,
+		)
+		if err != nil {
+			return fmt.Errorf("insert lesson %s: %w", lesson.ID, err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 const insertConceptSQL = `
@@ -168,22 +120,135 @@ INSERT INTO concepts (id, name, definition, defined_in_lesson, defined_in_topic,
 VALUES (?, ?, ?, ?, ?, ?, ?, 'active')
 `
 
-func (ing *CurriculumIngester) storeConcept(ctx context.Context, tx *sql.Tx, topicID, lessonID string, concept *ConceptTaughtOut) error {
-	_, err := tx.ExecContext(ctx, insertConceptSQL,
-		concept.ID, concept.Name, concept.Definition,
-		lessonID, topicID,
-		concept.Flashcard.Front, concept.Flashcard.Back,
-	)
+// conceptOutcome reports what storeConcept did with a taught concept.
+type conceptOutcome int
+
+const (
+	conceptCreated conceptOutcome = iota
+	conceptExisting
+	conceptConflicted
+)
+
+const getConceptDefinitionSQL = `
+SELECT name, definition, COALESCE(flashcard_front, ''), COALESCE(flashcard_back, ''),
+       defined_in_lesson, defined_in_topic
+FROM concepts
+WHERE id = ?
+`
+
+const countConceptCandidatesSQL = `SELECT COUNT(*) FROM concept_candidates WHERE concept_id = ?`
+
+const candidateExistsSQL = `
+SELECT EXISTS(SELECT 1 FROM concept_candidates WHERE concept_id = ? AND TRIM(definition) = TRIM(?))
+`
+
+const insertConceptCandidateSQL = `
+INSERT INTO concept_candidates (concept_id, name, definition, flashcard_front, flashcard_back,
+                                source_lesson, source_topic)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+const markConceptConflictSQL = `
+UPDATE concepts SET status = 'conflict', revision = revision + 1 WHERE id = ?
+`
+
+// storeConcept inserts a newly taught concept. A concept that already exists,
+// under its ID or an alias, with the same definition only gains a reference;
+// one defined differently is kept as is and both definitions become
+// candidates for review.
+func (ing *CurriculumIngester) storeConcept(ctx context.Context, tx *sql.Tx, topicID, lessonID string, concept *ConceptTaughtOut) (conceptOutcome, error) {
+	conceptID, err := repository.ResolveConceptID(ctx, tx, concept.ID)
 	if err != nil {
-		return fmt.Errorf("insert concept %s: %w", concept.ID, err)
+		return 0, err
+	}
+
+	if conceptID == "" {
+		conceptID = concept.ID
+	}
+
+	var existing struct {
+		name, definition, front, back string
+		lesson, topic                 sql.NullString
+	}
+
+	err = tx.QueryRowContext(ctx, getConceptDefinitionSQL, conceptID).Scan(
+		&existing.name, &existing.definition, &existing.front, &existing.back,
+		&existing.lesson, &existing.topic,
+	)
+
+	outcome := conceptCreated
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err := repository.TrackChange(ctx, tx, models.AuditEntityConcept, concept.ID, func() error {
+			if _, err := tx.ExecContext(ctx, insertConceptSQL,
+				concept.ID, concept.Name, concept.Definition,
+				lessonID, topicID,
+				concept.Flashcard.Front, concept.Flashcard.Back,
+			); err != nil {
+				return fmt.Errorf("insert concept %s: %w", concept.ID, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, fmt.Errorf("load concept %s: %w", conceptID, err)
+	case strings.TrimSpace(existing.definition) == strings.TrimSpace(concept.Definition):
+		outcome = conceptExisting
+	default:
+		outcome = conceptConflicted
+
+		var candidates int
+		if err := tx.QueryRowContext(ctx, countConceptCandidatesSQL, conceptID).Scan(&candidates); err != nil {
+			return 0, fmt.Errorf("count concept %s candidates: %w", conceptID, err)
+		}
+
+		// The first conflict also records the current canonical definition,
+		// so every competing version can be reviewed side by side.
+		if candidates == 0 {
+			if _, err := tx.ExecContext(ctx, insertConceptCandidateSQL,
+				conceptID, existing.name, existing.definition, existing.front, existing.back,
+				existing.lesson, existing.topic,
+			); err != nil {
+				return 0, fmt.Errorf("insert concept %s candidate: %w", conceptID, err)
+			}
+		}
+
+		var known bool
+		if err := tx.QueryRowContext(ctx, candidateExistsSQL, conceptID, concept.Definition).Scan(&known); err != nil {
+			return 0, fmt.Errorf("check concept %s candidate: %w", conceptID, err)
+		}
+
+		if !known {
+			if _, err := tx.ExecContext(ctx, insertConceptCandidateSQL,
+				conceptID, concept.Name, concept.Definition,
+				concept.Flashcard.Front, concept.Flashcard.Back, lessonID, topicID,
+			); err != nil {
+				return 0, fmt.Errorf("insert concept %s candidate: %w", conceptID, err)
+			}
+		}
+
+		err := repository.TrackChange(ctx, tx, models.AuditEntityConcept, conceptID, func() error {
+			if _, err := tx.ExecContext(ctx, markConceptConflictSQL, conceptID); err != nil {
+				return fmt.Errorf("mark concept %s conflict: %w", conceptID, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	// Also create a self-reference for the defining lesson.
-	if err := ing.storeConceptReference(ctx, tx, concept.ID, lessonID); err != nil {
-		return err
+	if err := ing.storeConceptReference(ctx, tx, conceptID, lessonID); err != nil {
+		return 0, err
 	}
 
-	return nil
+	return outcome, nil
 }
 
 const insertConceptRefSQL = `
@@ -191,16 +256,27 @@ INSERT OR IGNORE INTO concept_references (concept_id, lesson_id, context)
 VALUES (?, ?, '')
 `
 
+// storeConceptReference links lessonID to the concept conceptID names,
+// resolving aliases. An unknown ID is stored as given.
 func (ing *CurriculumIngester) storeConceptReference(ctx context.Context, tx *sql.Tx, conceptID, lessonID string) error {
-	_, err := tx.ExecContext(ctx, insertConceptRefSQL, conceptID, lessonID)
+	resolved, err := repository.ResolveConceptID(ctx, tx, conceptID)
 	if err != nil {
-		return fmt.Errorf("insert concept reference %s -> %s: %w", conceptID, lessonID, err)
+		return err
 	}
 
-	return nil
-}
+	if resolved != "" {
+		conceptID = resolved
+	}
 
-const checkTopicExistsSQL = `SELECT EXISTS(SELECT 1 FROM topics WHERE id = ?)`
+	return repository.TrackChange(ctx, tx, models.AuditEntityConceptReference, conceptID+"/"+lessonID, func() error {
+		_, err := tx.ExecContext(ctx, insertConceptRefSQL, conceptID, lessonID)
+		if err != nil {
+			return fmt.Errorf("insert concept reference %s -> %s: %w", conceptID, lessonID, err)
+		}
+
+		return nil
+	})
+}
 
 const insertPrereqSQL = `
 INSERT OR IGNORE INTO topic_prerequisites (topic_id, prerequisite_topic_id, priority, reason)
@@ -210,22 +286,30 @@ VALUES (?, ?, ?, ?)
 func (ing *CurriculumIngester) storePrerequisites(ctx context.Context, tx *sql.Tx, topicID string, prereqs *PrerequisitesOutput) error {
 	store := func(items []PrerequisiteItem, priority string) error {
 		for _, item := range items {
-			// Only store in topic_prerequisites if the prerequisite topic already exists.
-			// Non-existent topics are handled via the expansion queue.
-			var exists bool
-			if err := tx.QueryRowContext(ctx, checkTopicExistsSQL, item.TopicID).Scan(&exists); err != nil {
-				return fmt.Errorf("check prerequisite topic %s: %w", item.TopicID, err)
+			// Only store in topic_prerequisites if the prerequisite topic already
+			// exists, under its ID, normalized slug, or an alias. Non-existent
+			// topics are handled via the expansion queue.
+			prereqID, err := repository.ResolveTopicID(ctx, tx, item.TopicID)
+			if err != nil {
+				return err
 			}
 
-			if !exists {
+			if prereqID == "" {
 				continue
 			}
 
-			_, err := tx.ExecContext(ctx, insertPrereqSQL,
-				topicID, item.TopicID, priority, item.Reason,
-			)
+			err = repository.TrackChange(ctx, tx, models.AuditEntityPrerequisite, topicID+"/"+prereqID, func() error {
+				_, err := tx.ExecContext(ctx, insertPrereqSQL,
+					topicID, prereqID, priority, item.Reason,
+				)
+				if err != nil {
+					return fmt.Errorf("insert prerequisite %s -> %s: %w", topicID, prereqID, err)
+				}
+
+				return nil
+			})
 			if err != nil {
-				return fmt.Errorf("insert prerequisite %s -> %s: %w", topicID, item.TopicID, err)
+				return err
 			}
 		}
 
@@ -248,11 +332,22 @@ INSERT INTO expansion_queue (topic_id, requested_by_topic, priority, reason, sta
 VALUES (?, ?, ?, ?, 'available')
 `
 
+// storeExpansionQueue queues helpful and deep-background prerequisites that
+// are not yet in the pool under any ID or alias, by their normalized slug.
 func (ing *CurriculumIngester) storeExpansionQueue(ctx context.Context, tx *sql.Tx, topicID string, prereqs *PrerequisitesOutput) error {
 	enqueue := func(items []PrerequisiteItem, priority string) error {
 		for _, item := range items {
-			_, err := tx.ExecContext(ctx, insertExpansionQueueSQL,
-				item.TopicID, topicID, priority, item.Reason,
+			existing, err := repository.ResolveTopicID(ctx, tx, item.TopicID)
+			if err != nil {
+				return err
+			}
+
+			if existing != "" {
+				continue
+			}
+
+			_, err = tx.ExecContext(ctx, insertExpansionQueueSQL,
+				models.NormalizeSlug(item.TopicID), topicID, priority, item.Reason,
 			)
 			if err != nil {
 				return fmt.Errorf("insert expansion queue %s: %w", item.TopicID, err)
@@ -314,9 +409,13 @@ type IngestResult struct {
 	ModulesCreated  int
 	LessonsCreated  int
 	ConceptsCreated int
+	// ConceptConflicts counts taught concepts whose ID already existed with a
+	// different definition and now await review.
+	ConceptConflicts int
 }
 
-// IngestWithResult validates, stores, and returns counts.
+// IngestWithResult validates and stores rawJSON like Ingest and returns
+// counts of what it created.
 func (ing *CurriculumIngester) IngestWithResult(ctx context.Context, rawJSON json.RawMessage) (*IngestResult, error) {
 	if err := schema.Validate(rawJSON); err != nil {
 		return nil, fmt.Errorf("schema validation: %w", err)
@@ -355,11 +454,17 @@ func (ing *CurriculumIngester) IngestWithResult(ctx context.Context, rawJSON jso
 			result.LessonsCreated++
 
 			for _, concept := range lesson.ConceptsTaught {
-				if err := ing.storeConcept(ctx, tx, curr.ID, lesson.ID, &concept); err != nil {
+				outcome, err := ing.storeConcept(ctx, tx, curr.ID, lesson.ID, &concept)
+				if err != nil {
 					return nil, err
 				}
 
-				result.ConceptsCreated++
+				switch outcome {
+				case conceptCreated:
+					result.ConceptsCreated++
+				case conceptConflicted:
+					result.ConceptConflicts++
+				}
 			}
 
 			for _, ref := range lesson.ConceptsReferenced {
@@ -382,6 +487,16 @@ func (ing *CurriculumIngester) IngestWithResult(ctx context.Context, rawJSON jso
 		return nil, err
 	}
 
+	// Question cards link to the concepts stored above, and generated cards
+	// derive from them.
+	if err := repository.SyncTopicQuestions(ctx, tx, curr.ID); err != nil {
+		return nil, err
+	}
+
+	if err := repository.SyncTopicCards(ctx, tx, curr.ID); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -3,8 +3,10 @@ package research_test
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/sean/apollo/api/internal/audit"
 	"github.com/sean/apollo/api/internal/research"
 )
 
@@ -267,6 +269,16 @@ func TestIngestWithResult(t *testing.T) {
 	if result.ConceptsCreated != 2 {
 		t.Fatalf("expected 2 concepts, got %d", result.ConceptsCreated)
 	}
+
+	var lessonCards, assessmentCards int
+	if err := db.QueryRow(`SELECT COUNT(*) FILTER (WHERE source = 'lesson'), COUNT(*) FILTER (WHERE source = 'assessment') FROM question_cards`).
+		Scan(&lessonCards, &assessmentCards); err != nil {
+		t.Fatalf("count question cards: %v", err)
+	}
+
+	if lessonCards != 1 || assessmentCards != 1 {
+		t.Fatalf("expected 1 lesson and 1 assessment card, got %d and %d", lessonCards, assessmentCards)
+	}
 }
 
 func TestIngestModuleSortOrder(t *testing.T) {
@@ -314,3 +326,166 @@ func TestIngestModuleSortOrder(t *testing.T) {
 		t.Fatalf("rows err: %v", err)
 	}
 }
+
+func TestIngestConflictingConceptStoresCandidates(t *testing.T) {
+	db := setupTestDB(t)
+	ingester := research.NewCurriculumIngester(db)
+	ctx := context.Background()
+
+	if err := ingester.Ingest(ctx, json.RawMessage(sampleCurriculum)); err != nil {
+		t.Fatalf("first ingest: %v", err)
+	}
+
+	// A second curriculum teaches goroutine differently and waitgroup the same.
+	second := strings.NewReplacer(
+		"go-concurrency", "go-runtime",
+		"A lightweight thread managed by the Go runtime.", "A function running concurrently with others in the same address space.",
+	).Replace(sampleCurriculum)
+
+	result, err := ingester.IngestWithResult(ctx, json.RawMessage(second))
+	if err != nil {
+		t.Fatalf("second ingest: %v", err)
+	}
+
+	if result.ConceptsCreated != 0 || result.ConceptConflicts != 1 {
+		t.Fatalf("expected 0 created and 1 conflict, got %+v", result)
+	}
+
+	var status, definition string
+	if err := db.QueryRow(`SELECT status, definition FROM concepts WHERE id = 'goroutine'`).Scan(&status, &definition); err != nil {
+		t.Fatalf("query goroutine: %v", err)
+	}
+
+	if status != "conflict" || definition != "A lightweight thread managed by the Go runtime." {
+		t.Fatalf("expected canonical definition kept in conflict, got %s %q", status, definition)
+	}
+
+	rows, err := db.Query(`SELECT source_lesson FROM concept_candidates WHERE concept_id = 'goroutine' ORDER BY id`)
+	if err != nil {
+		t.Fatalf("query candidates: %v", err)
+	}
+	defer rows.Close()
+
+	var sources []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			t.Fatalf("scan candidate: %v", err)
+		}
+
+		sources = append(sources, source)
+	}
+
+	if len(sources) != 2 || sources[0] != "go-concurrency/goroutines/intro" || sources[1] != "go-runtime/goroutines/intro" {
+		t.Fatalf("expected candidates from both lessons, got %v", sources)
+	}
+
+	var waitgroupStatus string
+	if err := db.QueryRow(`SELECT status FROM concepts WHERE id = 'waitgroup'`).Scan(&waitgroupStatus); err != nil {
+		t.Fatalf("query waitgroup: %v", err)
+	}
+
+	if waitgroupStatus != "active" {
+		t.Fatalf("expected matching definition to stay active, got %s", waitgroupStatus)
+	}
+
+	var refs int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM concept_references WHERE concept_id = 'waitgroup'`).Scan(&refs); err != nil {
+		t.Fatalf("count waitgroup refs: %v", err)
+	}
+
+	if refs != 2 {
+		t.Fatalf("expected waitgroup referenced from both curricula, got %d", refs)
+	}
+}
+
+func TestIngestResolvesAliases(t *testing.T) {
+	db := setupTestDB(t)
+	mustExec(t, db, `INSERT INTO topics (id, title, status, aliases) VALUES ('golang-fundamentals', 'Go Fundamentals', 'published', '["go-basics"]')`)
+	mustExec(t, db, `INSERT INTO topics (id, title, status, aliases) VALUES ('operating-system-threads', 'OS Threads', 'published', '["os-threads"]')`)
+	mustExec(t, db, `INSERT INTO concepts (id, name, definition, defined_in_topic, status, aliases)
+		VALUES ('go-routine', 'Goroutine', 'A lightweight thread managed by the Go runtime.', 'golang-fundamentals', 'active', '["goroutine"]')`)
+
+	result, err := research.NewCurriculumIngester(db).IngestWithResult(context.Background(), json.RawMessage(sampleCurriculum))
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	if result.ConceptsCreated != 1 || result.ConceptConflicts != 0 {
+		t.Fatalf("expected only waitgroup created, got %+v", result)
+	}
+
+	var prereq string
+	if err := db.QueryRow(`SELECT prerequisite_topic_id FROM topic_prerequisites WHERE topic_id = 'go-concurrency'`).Scan(&prereq); err != nil {
+		t.Fatalf("query prerequisite: %v", err)
+	}
+
+	if prereq != "golang-fundamentals" {
+		t.Fatalf("expected alias go-basics stored as golang-fundamentals, got %s", prereq)
+	}
+
+	var queued []string
+	rows, err := db.Query(`SELECT topic_id FROM expansion_queue ORDER BY topic_id`)
+	if err != nil {
+		t.Fatalf("query expansion queue: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("scan expansion queue: %v", err)
+		}
+
+		queued = append(queued, id)
+	}
+
+	if len(queued) != 1 || queued[0] != "csp-theory" {
+		t.Fatalf("expected only csp-theory queued, got %v", queued)
+	}
+
+	var refs int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM concept_references WHERE concept_id = 'go-routine'`).Scan(&refs); err != nil {
+		t.Fatalf("count references: %v", err)
+	}
+
+	if refs != 2 {
+		t.Fatalf("expected taught and referenced goroutine linked to go-routine, got %d", refs)
+	}
+}
+
+func TestIngestRecordsHistoryForResearchAgent(t *testing.T) {
+	db := setupTestDB(t)
+	ingester := research.NewCurriculumIngester(db)
+	ctx := audit.WithActor(context.Background(), audit.ResearchAgent("job-1"))
+
+	if err := ingester.Ingest(ctx, json.RawMessage(sampleCurriculum)); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT entity_type, COUNT(*) FROM audit_log
+		WHERE actor = 'research-agent:job-1' AND action = 'create' GROUP BY entity_type`)
+	if err != nil {
+		t.Fatalf("query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var entityType string
+		var n int
+		if err := rows.Scan(&entityType, &n); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+
+		counts[entityType] = n
+	}
+
+	want := map[string]int{"topic": 1, "module": 1, "lesson": 2, "concept": 2, "concept_reference": 3}
+	for entityType, n := range want {
+		if counts[entityType] != n {
+			t.Fatalf("expected %d %s creates, got %v", n, entityType, counts)
+		}
+	}
+}
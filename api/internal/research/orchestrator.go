@@ -11,6 +11,7 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/sean/apollo/api/internal/audit"
 	"github.com/sean/apollo/api/internal/config"
 	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
@@ -45,6 +46,7 @@ type Orchestrator struct {
 	repo    repository.ResearchJobRepository
 	logger  zerolog.Logger
 	cfg     config.Config
+	cards   CardProposer
 	mu      sync.Mutex
 	cancels map[string]context.CancelFunc
 }
@@ -119,6 +121,7 @@ func (o *Orchestrator) sleep(ctx context.Context) {
 }
 
 // RunJob executes the full 4-pass research pipeline for a single job.
+// Flashcard jobs run their single pass instead.
 func (o *Orchestrator) RunJob(ctx context.Context, jobID string) error {
 	log := o.logger.With().Str("job_id", jobID).Logger()
 
@@ -145,6 +148,10 @@ func (o *Orchestrator) RunJob(ctx context.Context, jobID string) error {
 		return fmt.Errorf("update status to researching: %w", err)
 	}
 
+	if job.Kind == models.ResearchKindFlashcard {
+		return o.runFlashcardJob(ctx, jobCtx, job, log)
+	}
+
 	log.Info().Str("topic", job.RootTopic).Msg("starting research pipeline")
 
 	// Prepare working directory.
@@ -213,8 +220,9 @@ func (o *Orchestrator) RunJob(ctx context.Context, jobID string) error {
 		return o.failJob(ctx, jobID, fmt.Errorf("marshal assembled curriculum: %w", err))
 	}
 
-	// Ingest the assembled curriculum.
-	if err := o.ingest.Ingest(jobCtx, json.RawMessage(assembledJSON)); err != nil {
+	// Ingest the assembled curriculum, attributing every change to this job.
+	ingestCtx := audit.WithActor(jobCtx, audit.ResearchAgent(jobID))
+	if err := o.ingest.Ingest(ingestCtx, json.RawMessage(assembledJSON)); err != nil {
 		if jobCtx.Err() != nil {
 			return o.handleCancellation(jobID, log)
 		}
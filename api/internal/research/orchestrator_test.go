@@ -211,7 +211,7 @@ func setupOrchestrator(t *testing.T, cli research.CLIRunner) (*research.Orchestr
 	t.Helper()
 
 	db := setupTestDB(t)
-	repo := repository.NewResearchJobRepository(db)
+	repo := repository.NewResearchJobRepository(db, db)
 	pool := research.NewPoolSummaryBuilder(db)
 	ingest := research.NewCurriculumIngester(db)
 	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
@@ -540,3 +540,120 @@ func TestOrchestratorCancelMethod(t *testing.T) {
 	// Cancel a non-existent job should not panic.
 	orch.Cancel("nonexistent-id")
 }
+
+// setupLeech seeds a concept suspended as a leech and queues a flashcard
+// job for it.
+func setupLeech(t *testing.T, db *sql.DB) (*repository.SQLiteLeechRepository, *models.ResearchJob) {
+	t.Helper()
+
+	stmts := []string{
+		`INSERT INTO concepts (id, name, definition, flashcard_front, flashcard_back) VALUES ('goroutine', 'Goroutine', 'A lightweight thread.', 'What is a goroutine?', 'A thread.')`,
+		`INSERT INTO concept_retention (concept_id, status, next_review, review_count, lapses, suspended_at)
+		 VALUES ('goroutine', 'learning', '2026-03-10T00:00:00Z', 12, 8, '2026-03-10T00:00:00Z')`,
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed leech: %v", err)
+		}
+	}
+
+	leeches := repository.NewLeechRepository(db, db)
+
+	job, err := leeches.RegenerateCard(context.Background(), "goroutine")
+	if err != nil {
+		t.Fatalf("regenerate card: %v", err)
+	}
+
+	return leeches, job
+}
+
+func TestOrchestratorFlashcardJob(t *testing.T) {
+	cli := newMockCLI()
+	cli.writeFixtures = func(workDir string) {
+		writeJSON(filepath.Join(workDir, "flashcard.json"), map[string]any{
+			"definition": "A function running concurrently, scheduled by the Go runtime.",
+			"flashcard":  map[string]string{"front": "Who schedules goroutines?", "back": "The Go runtime."},
+		})
+	}
+
+	orch, db, repo := setupOrchestrator(t, cli)
+	leeches, job := setupLeech(t, db)
+	orch.WithCardProposer(leeches)
+
+	if err := orch.RunJob(context.Background(), job.ID); err != nil {
+		t.Fatalf("run job: %v", err)
+	}
+
+	updated, err := repo.GetJobByID(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+
+	if updated.Status != models.ResearchStatusPublished || updated.Kind != models.ResearchKindFlashcard {
+		t.Fatalf("expected published flashcard job, got %q %q", updated.Kind, updated.Status)
+	}
+
+	if cli.callCount() != 1 {
+		t.Fatalf("expected 1 CLI call, got %d", cli.callCount())
+	}
+
+	leech, err := leeches.GetLeech(context.Background(), "goroutine")
+	if err != nil {
+		t.Fatalf("get leech: %v", err)
+	}
+
+	if leech.Proposal == nil || leech.Proposal.FlashcardFront != "Who schedules goroutines?" || leech.Proposal.JobID != job.ID {
+		t.Fatalf("expected proposal from job, got %+v", leech.Proposal)
+	}
+
+	// The concept keeps its card until the proposal is approved.
+	if leech.FlashcardFront != "What is a goroutine?" {
+		t.Fatalf("expected original flashcard, got %q", leech.FlashcardFront)
+	}
+}
+
+func TestOrchestratorFlashcardJobFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		write   func(workDir string)
+		propose bool
+	}{
+		{name: "not enabled", propose: false},
+		{name: "no card written", propose: true},
+		{
+			name:    "incomplete card",
+			propose: true,
+			write: func(workDir string) {
+				writeJSON(filepath.Join(workDir, "flashcard.json"), map[string]any{"definition": "A function."})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := newMockCLI()
+			cli.writeFixtures = tt.write
+
+			orch, db, repo := setupOrchestrator(t, cli)
+			leeches, job := setupLeech(t, db)
+
+			if tt.propose {
+				orch.WithCardProposer(leeches)
+			}
+
+			if err := orch.RunJob(context.Background(), job.ID); err == nil {
+				t.Fatal("expected error")
+			}
+
+			updated, err := repo.GetJobByID(context.Background(), job.ID)
+			if err != nil {
+				t.Fatalf("get job: %v", err)
+			}
+
+			if updated.Status != models.ResearchStatusFailed || updated.Error == "" {
+				t.Fatalf("expected failed job with error, got %q %q", updated.Status, updated.Error)
+			}
+		})
+	}
+}
@@ -8,27 +8,35 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/schema"
 )
 
 const poolSummaryFilename = "knowledge_pool_summary.json"
 
-const listTopicIDsSQL = `SELECT id FROM topics ORDER BY id`
+const listTopicModuleIDsSQL = `
+SELECT t.id, t.aliases, m.id
+FROM topics t
+LEFT JOIN modules m ON m.topic_id = t.id
+ORDER BY t.id, m.sort_order
+`
 
-const listModuleIDsForTopicSQL = `SELECT id FROM modules WHERE topic_id = ? ORDER BY sort_order`
-
-const listConceptIDsSQL = `SELECT id FROM concepts ORDER BY id`
+const listConceptIDsSQL = `SELECT id, aliases FROM concepts ORDER BY id`
 
 // PoolSummaryTopic is one entry in existing_topics.
 type PoolSummaryTopic struct {
 	ID      string   `json:"id"`
+	Aliases []string `json:"aliases,omitempty"`
 	Modules []string `json:"modules"`
 }
 
 // PoolSummary is the JSON structure written to knowledge_pool_summary.json.
+// ConceptAliases maps each concept alias to the concept ID it stands for, so
+// the agent reuses existing concepts instead of minting near-duplicates.
 type PoolSummary struct {
 	ExistingTopics   []PoolSummaryTopic `json:"existing_topics"`
 	ExistingConcepts []string           `json:"existing_concepts"`
+	ConceptAliases   map[string]string  `json:"concept_aliases,omitempty"`
 }
 
 // PoolSummaryBuilder queries the database and produces the knowledge pool summary.
@@ -48,7 +56,7 @@ func (b *PoolSummaryBuilder) Build(ctx context.Context) ([]byte, error) {
 		return nil, err
 	}
 
-	concepts, err := b.queryConcepts(ctx)
+	concepts, aliases, err := b.queryConcepts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +64,7 @@ func (b *PoolSummaryBuilder) Build(ctx context.Context) ([]byte, error) {
 	summary := PoolSummary{
 		ExistingTopics:   topics,
 		ExistingConcepts: concepts,
+		ConceptAliases:   aliases,
 	}
 
 	data, err := json.MarshalIndent(summary, "", "  ")
@@ -86,99 +95,82 @@ func (b *PoolSummaryBuilder) WriteToDir(ctx context.Context, dir string) error {
 	return nil
 }
 
+// queryTopics loads every topic with its module IDs in a single query.
 func (b *PoolSummaryBuilder) queryTopics(ctx context.Context) ([]PoolSummaryTopic, error) {
-	rows, err := b.db.QueryContext(ctx, listTopicIDsSQL)
+	rows, err := b.db.QueryContext(ctx, listTopicModuleIDsSQL)
 	if err != nil {
-		return nil, fmt.Errorf("query topic IDs: %w", err)
+		return nil, fmt.Errorf("query topic module IDs: %w", err)
 	}
 	defer rows.Close()
 
-	var topicIDs []string
+	topics := []PoolSummaryTopic{}
 
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("scan topic ID: %w", err)
-		}
-
-		topicIDs = append(topicIDs, id)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate topic IDs: %w", err)
-	}
+		var topicID string
+		var aliasesRaw *string
+		var moduleID sql.NullString
 
-	topics := make([]PoolSummaryTopic, 0, len(topicIDs))
-
-	for _, topicID := range topicIDs {
-		modules, err := b.queryModules(ctx, topicID)
-		if err != nil {
-			return nil, err
+		if err := rows.Scan(&topicID, &aliasesRaw, &moduleID); err != nil {
+			return nil, fmt.Errorf("scan topic module ID: %w", err)
 		}
 
-		topics = append(topics, PoolSummaryTopic{
-			ID:      topicID,
-			Modules: modules,
-		})
-	}
-
-	return topics, nil
-}
-
-func (b *PoolSummaryBuilder) queryModules(ctx context.Context, topicID string) ([]string, error) {
-	rows, err := b.db.QueryContext(ctx, listModuleIDsForTopicSQL, topicID)
-	if err != nil {
-		return nil, fmt.Errorf("query module IDs for topic %s: %w", topicID, err)
-	}
-	defer rows.Close()
-
-	var modules []string
-
-	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("scan module ID: %w", err)
+		if len(topics) == 0 || topics[len(topics)-1].ID != topicID {
+			topics = append(topics, PoolSummaryTopic{
+				ID:      topicID,
+				Aliases: models.ParseJSONStringSlice(aliasesRaw),
+				Modules: []string{},
+			})
 		}
 
-		modules = append(modules, id)
+		if moduleID.Valid {
+			last := &topics[len(topics)-1]
+			last.Modules = append(last.Modules, moduleID.String)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate module IDs: %w", err)
-	}
-
-	if modules == nil {
-		modules = []string{}
+		return nil, fmt.Errorf("iterate topic module IDs: %w", err)
 	}
 
-	return modules, nil
+	return topics, nil
 }
 
-func (b *PoolSummaryBuilder) queryConcepts(ctx context.Context) ([]string, error) {
+// queryConcepts returns every concept ID and a map from alias to concept ID.
+// The map is nil when no concept has aliases.
+func (b *PoolSummaryBuilder) queryConcepts(ctx context.Context) ([]string, map[string]string, error) {
 	rows, err := b.db.QueryContext(ctx, listConceptIDsSQL)
 	if err != nil {
-		return nil, fmt.Errorf("query concept IDs: %w", err)
+		return nil, nil, fmt.Errorf("query concept IDs: %w", err)
 	}
 	defer rows.Close()
 
-	var concepts []string
+	concepts := []string{}
+	var aliases map[string]string
 
 	for rows.Next() {
 		var id string
-		if err := rows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("scan concept ID: %w", err)
+		var aliasesRaw *string
+
+		if err := rows.Scan(&id, &aliasesRaw); err != nil {
+			return nil, nil, fmt.Errorf("scan concept ID: %w", err)
 		}
 
 		concepts = append(concepts, id)
-	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate concept IDs: %w", err)
+		for _, alias := range models.ParseJSONStringSlice(aliasesRaw) {
+			if aliases == nil {
+				aliases = make(map[string]string)
+			}
+
+			if _, taken := aliases[alias]; !taken {
+				aliases[alias] = id
+			}
+		}
 	}
 
-	if concepts == nil {
-		concepts = []string{}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate concept IDs: %w", err)
 	}
 
-	return concepts, nil
+	return concepts, aliases, nil
 }
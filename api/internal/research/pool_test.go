@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -145,3 +146,79 @@ func TestPoolSummaryWriteToDir(t *testing.T) {
 		t.Fatalf("expected 0 topics, got %d", len(summary.ExistingTopics))
 	}
 }
+
+func BenchmarkPoolSummaryBuildLargePool(b *testing.B) {
+	const topics, modulesPerTopic = 300, 8
+
+	dbPath := filepath.Join(b.TempDir(), "bench.db")
+
+	handle, err := database.Open(context.Background(), dbPath, zerolog.New(os.Stderr).Level(zerolog.Disabled))
+	if err != nil {
+		b.Fatalf("open bench database: %v", err)
+	}
+	b.Cleanup(func() { _ = handle.Close() })
+
+	tx, err := handle.DB.Begin()
+	if err != nil {
+		b.Fatalf("begin seed transaction: %v", err)
+	}
+
+	for t := 0; t < topics; t++ {
+		topicID := fmt.Sprintf("topic-%03d", t)
+		if _, err := tx.Exec(`INSERT INTO topics (id, title, status) VALUES (?, ?, 'published')`, topicID, topicID); err != nil {
+			b.Fatalf("seed topic: %v", err)
+		}
+
+		for m := 0; m < modulesPerTopic; m++ {
+			moduleID := fmt.Sprintf("%s-m%d", topicID, m)
+			if _, err := tx.Exec(`INSERT INTO modules (id, topic_id, title, sort_order) VALUES (?, ?, ?, ?)`, moduleID, topicID, moduleID, m); err != nil {
+				b.Fatalf("seed module: %v", err)
+			}
+		}
+
+		if _, err := tx.Exec(`INSERT INTO concepts (id, name, definition, defined_in_topic, status) VALUES (?, ?, 'definition', ?, 'active')`,
+			topicID+"-concept", topicID+"-concept", topicID); err != nil {
+			b.Fatalf("seed concept: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("commit seed transaction: %v", err)
+	}
+
+	builder := research.NewPoolSummaryBuilder(handle.ReadDB)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := builder.Build(ctx); err != nil {
+			b.Fatalf("build: %v", err)
+		}
+	}
+}
+
+func TestPoolSummaryIncludesAliases(t *testing.T) {
+	db := setupTestDB(t)
+
+	mustExec(t, db, `INSERT INTO topics (id, title, status, aliases) VALUES (?, ?, ?, ?)`,
+		"linux-administration", "Linux Administration", "published", `["linux-admin"]`)
+	mustExec(t, db, `INSERT INTO concepts (id, name, definition, defined_in_topic, status, aliases) VALUES (?, ?, ?, ?, 'active', ?)`,
+		"linux-bridge", "Linux bridge", "A software switch", "linux-administration", `["br0"]`)
+
+	data, err := research.NewPoolSummaryBuilder(db).Build(context.Background())
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	var summary research.PoolSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if aliases := summary.ExistingTopics[0].Aliases; len(aliases) != 1 || aliases[0] != "linux-admin" {
+		t.Fatalf("expected topic alias linux-admin, got %v", aliases)
+	}
+
+	if summary.ConceptAliases["br0"] != "linux-bridge" {
+		t.Fatalf("expected br0 to map to linux-bridge, got %v", summary.ConceptAliases)
+	}
+}
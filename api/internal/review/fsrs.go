@@ -0,0 +1,171 @@
+package review
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// fsrsWeights are the default FSRS-4.5 parameters.
+var fsrsWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// The forgetting curve R(t) = (1 + fsrsFactor*t/S)^fsrsDecay, chosen so
+// that recall probability is 90% after S days.
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+
+	defaultDesiredRetention = 0.9
+	maxIntervalDays         = 36500
+)
+
+// fsrsGrades maps ratings to FSRS grades 1 (again) to 4 (easy).
+var fsrsGrades = map[string]float64{
+	models.RatingForgot: 1,
+	models.RatingHard:   2,
+	models.RatingGood:   3,
+	models.RatingEasy:   4,
+}
+
+// FSRS is the Free Spaced Repetition Scheduler (FSRS-4.5) with its default
+// parameters. It models each concept's memory stability, in days, and
+// difficulty, from 1 to 10, and schedules the next review for when recall
+// probability falls to DesiredRetention (0.9 when zero). As with SM2, an
+// interval past MasteryDays masters the concept.
+type FSRS struct {
+	MasteryDays      int
+	DesiredRetention float64
+}
+
+// Name implements Scheduler.
+func (FSRS) Name() string { return NameFSRS }
+
+// Review returns the state after rating the concept at now. The first
+// rating sets the initial stability and difficulty; later ones update both
+// from how likely recall was after the time elapsed since the last review.
+func (f FSRS) Review(state models.ConceptRetention, rating string, now time.Time) (models.ConceptRetention, error) {
+	grade, ok := fsrsGrades[rating]
+	if !ok {
+		return state, fmt.Errorf("unknown rating %q", rating)
+	}
+
+	state = f.Adopt(state)
+	w := fsrsWeights
+
+	var stability, difficulty float64
+
+	if state.Stability == 0 {
+		stability = w[int(grade)-1]
+		difficulty = initDifficulty(grade)
+	} else {
+		r := retrievability(elapsedDays(state, now), state.Stability)
+		difficulty = clampDifficulty(w[7]*initDifficulty(4) + (1-w[7])*(state.Difficulty-w[6]*(grade-3)))
+
+		if grade == 1 {
+			stability = w[11] * math.Pow(difficulty, -w[12]) * (math.Pow(state.Stability+1, w[13]) - 1) * math.Exp(w[14]*(1-r))
+			stability = math.Min(stability, state.Stability)
+		} else {
+			bonus := 1.0
+
+			switch grade {
+			case 2:
+				bonus = w[15]
+			case 4:
+				bonus = w[16]
+			}
+
+			stability = state.Stability * (1 + math.Exp(w[8])*(11-difficulty)*math.Pow(state.Stability, -w[9])*(math.Exp(w[10]*(1-r))-1)*bonus)
+		}
+	}
+
+	interval := f.interval(stability)
+	now = now.UTC()
+
+	ease := state.EaseFactor
+	if ease == 0 {
+		ease = models.DefaultEaseFactor
+	}
+
+	return models.ConceptRetention{
+		ConceptID:    state.ConceptID,
+		Status:       nextStatus(rating, interval, f.MasteryDays),
+		NextReview:   now.AddDate(0, 0, interval).Format(time.RFC3339),
+		ReviewCount:  state.ReviewCount + 1,
+		EaseFactor:   ease,
+		IntervalDays: interval,
+		LastReviewed: now.Format(time.RFC3339),
+		LastRating:   rating,
+		Stability:    math.Round(stability*10000) / 10000,
+		Difficulty:   math.Round(difficulty*10000) / 10000,
+		Scheduler:    NameFSRS,
+	}, nil
+}
+
+// Adopt treats an SM-2 interval as the stability, since FSRS schedules a
+// review after about S days at 90% retention, and maps the ease factor
+// onto difficulty, 2.5 becoming 5. A concept never reviewed keeps no
+// stability so its first rating initializes it.
+func (FSRS) Adopt(state models.ConceptRetention) models.ConceptRetention {
+	if state.Scheduler == NameFSRS {
+		return state
+	}
+
+	state.Scheduler = NameFSRS
+
+	if state.ReviewCount == 0 {
+		state.Stability, state.Difficulty = 0, 0
+
+		return state
+	}
+
+	ease := state.EaseFactor
+	if ease == 0 {
+		ease = models.DefaultEaseFactor
+	}
+
+	state.Stability = float64(max(1, state.IntervalDays))
+	state.Difficulty = clampDifficulty(5 + (models.DefaultEaseFactor-ease)*5)
+
+	return state
+}
+
+// interval is the number of days until recall probability falls to the
+// desired retention.
+func (f FSRS) interval(stability float64) int {
+	retention := f.DesiredRetention
+	if retention <= 0 || retention >= 1 {
+		retention = defaultDesiredRetention
+	}
+
+	days := stability / fsrsFactor * (math.Pow(retention, 1/fsrsDecay) - 1)
+
+	return min(maxIntervalDays, max(1, roundDays(days)))
+}
+
+func retrievability(elapsed, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsed/stability, fsrsDecay)
+}
+
+func initDifficulty(grade float64) float64 {
+	return clampDifficulty(fsrsWeights[4] - (grade-3)*fsrsWeights[5])
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(10, math.Max(1, d))
+}
+
+// elapsedDays is the time since the last review, or the scheduled interval
+// when the last review time is unknown.
+func elapsedDays(state models.ConceptRetention, now time.Time) float64 {
+	last, err := time.Parse(time.RFC3339, state.LastReviewed)
+	if err != nil {
+		return float64(state.IntervalDays)
+	}
+
+	return math.Max(0, now.Sub(last).Hours()/24)
+}
@@ -0,0 +1,94 @@
+package review_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestFSRSReview(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)
+	fourDaysAgo := now.AddDate(0, 0, -4).Format(time.RFC3339)
+
+	// After a first "good": stability 3.7145, difficulty 5.1618.
+	reviewed := models.ConceptRetention{
+		Status: models.RetentionStatusReviewing, ReviewCount: 1, IntervalDays: 4, EaseFactor: 2.5,
+		LastReviewed: fourDaysAgo, Stability: 3.7145, Difficulty: 5.1618, Scheduler: review.NameFSRS,
+	}
+
+	tests := []struct {
+		name           string
+		state          models.ConceptRetention
+		rating         string
+		wantInterval   int
+		wantStability  float64
+		wantDifficulty float64
+		wantStatus     string
+	}{
+		{"first forgot", models.ConceptRetention{}, models.RatingForgot, 1, 0.4872, 7.6214, models.RetentionStatusLearning},
+		{"first hard", models.ConceptRetention{}, models.RatingHard, 1, 1.4003, 6.3916, models.RetentionStatusReviewing},
+		{"first good", models.ConceptRetention{}, models.RatingGood, 4, 3.7145, 5.1618, models.RetentionStatusReviewing},
+		{"first easy", models.ConceptRetention{}, models.RatingEasy, 14, 13.8206, 3.932, models.RetentionStatusReviewing},
+		{"good on time", reviewed, models.RatingGood, 15, 14.8805, 5.1237, models.RetentionStatusReviewing},
+		{"hard on time", reviewed, models.RatingHard, 6, 5.876, 5.9934, models.RetentionStatusReviewing},
+		{"easy on time", reviewed, models.RatingEasy, 41, 40.5743, 4.254, models.RetentionStatusReviewing},
+		{"forgot on time", reviewed, models.RatingForgot, 1, 1.4012, 6.863, models.RetentionStatusLearning},
+		{
+			"adopted SM-2 state past threshold",
+			models.ConceptRetention{
+				Status: models.RetentionStatusReviewing, ReviewCount: 5, IntervalDays: 30, EaseFactor: 2.5,
+				LastReviewed: now.AddDate(0, 0, -30).Format(time.RFC3339), Scheduler: review.NameSM2,
+			},
+			models.RatingGood, 95, 95.1154, 4.9669, models.RetentionStatusMastered,
+		},
+	}
+
+	sched := review.FSRS{MasteryDays: 90}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.state.ConceptID = "c1"
+
+			got, err := sched.Review(tt.state, tt.rating, now)
+			if err != nil {
+				t.Fatalf("Review() error = %v", err)
+			}
+
+			if got.IntervalDays != tt.wantInterval || got.Stability != tt.wantStability ||
+				got.Difficulty != tt.wantDifficulty || got.Status != tt.wantStatus {
+				t.Fatalf("Review() = interval %d S %v D %v status %s, want %d %v %v %s",
+					got.IntervalDays, got.Stability, got.Difficulty, got.Status,
+					tt.wantInterval, tt.wantStability, tt.wantDifficulty, tt.wantStatus)
+			}
+
+			if got.Scheduler != review.NameFSRS || got.ReviewCount != tt.state.ReviewCount+1 || got.LastRating != tt.rating {
+				t.Fatalf("Review() bookkeeping = %+v", got)
+			}
+
+			if want := now.AddDate(0, 0, tt.wantInterval).Format(time.RFC3339); got.NextReview != want {
+				t.Fatalf("Review() next %s, want %s", got.NextReview, want)
+			}
+		})
+	}
+}
+
+func TestFSRSDesiredRetentionShortensIntervals(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	got, err := review.FSRS{MasteryDays: 90, DesiredRetention: 0.95}.Review(models.ConceptRetention{}, models.RatingEasy, now)
+	if err != nil {
+		t.Fatalf("Review() error = %v", err)
+	}
+
+	if got.IntervalDays >= 14 {
+		t.Fatalf("interval at 95%% retention = %d, want shorter than 14", got.IntervalDays)
+	}
+}
+
+func TestFSRSReviewRejectsUnknownRating(t *testing.T) {
+	if _, err := (review.FSRS{}).Review(models.ConceptRetention{}, "meh", time.Now()); err == nil {
+		t.Fatal("expected error for unknown rating")
+	}
+}
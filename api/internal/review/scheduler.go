@@ -0,0 +1,95 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// Scheduler names stored in concept_retention.scheduler.
+const (
+	NameSM2  = "sm2"
+	NameFSRS = "fsrs"
+)
+
+// ErrUnknownScheduler is returned by New for a name no scheduler has.
+var ErrUnknownScheduler = errors.New("unknown scheduler")
+
+// Scheduler maps a concept's retention state and a rating to its next
+// state. Implementations are pure.
+type Scheduler interface {
+	// Name is stored with every state the scheduler produces.
+	Name() string
+	// Review returns the state after rating the concept at now. State
+	// produced by another scheduler is adopted first.
+	Review(state models.ConceptRetention, rating string, now time.Time) (models.ConceptRetention, error)
+	// Adopt converts state produced by another scheduler into this one's
+	// terms, keeping the interval and due date. It is the fallback when the
+	// review history is too short to replay.
+	Adopt(state models.ConceptRetention) models.ConceptRetention
+}
+
+// Names lists the available schedulers.
+func Names() []string {
+	return []string{NameFSRS, NameSM2}
+}
+
+// New returns the named scheduler. A concept whose interval exceeds
+// masteryDays becomes mastered.
+func New(name string, masteryDays int) (Scheduler, error) {
+	switch name {
+	case NameSM2:
+		return SM2{MasteryDays: masteryDays}, nil
+	case NameFSRS:
+		return FSRS{MasteryDays: masteryDays}, nil
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrUnknownScheduler, name)
+}
+
+// Replay re-derives a concept's state under s from its review history,
+// oldest first. Only the last current.ReviewCount events count, so a review
+// state reset since then stays reset. When the history holds fewer events
+// than that (reviews from before it was kept), the current state is adopted
+// instead.
+func Replay(s Scheduler, current models.ConceptRetention, history []models.ReviewEvent) (models.ConceptRetention, error) {
+	if current.ReviewCount == 0 || len(history) < current.ReviewCount {
+		return s.Adopt(current), nil
+	}
+
+	state := models.ConceptRetention{
+		ConceptID:  current.ConceptID,
+		Status:     models.RetentionStatusNew,
+		EaseFactor: models.DefaultEaseFactor,
+		Scheduler:  s.Name(),
+	}
+
+	for _, event := range history[len(history)-current.ReviewCount:] {
+		at, err := time.Parse(time.RFC3339, event.ReviewedAt)
+		if err != nil {
+			return current, fmt.Errorf("replay %s review at %q: %w", current.ConceptID, event.ReviewedAt, err)
+		}
+
+		if state, err = s.Review(state, event.Rating, at); err != nil {
+			return current, fmt.Errorf("replay %s: %w", current.ConceptID, err)
+		}
+	}
+
+	return state, nil
+}
+
+// nextStatus is the lifecycle step shared by the schedulers: forgetting
+// sends a concept back to learning, and an interval past masteryDays
+// masters it.
+func nextStatus(rating string, interval, masteryDays int) string {
+	switch {
+	case rating == models.RatingForgot:
+		return models.RetentionStatusLearning
+	case interval > masteryDays:
+		return models.RetentionStatusMastered
+	}
+
+	return models.RetentionStatusReviewing
+}
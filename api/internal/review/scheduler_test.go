@@ -0,0 +1,90 @@
+package review_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range review.Names() {
+		s, err := review.New(name, 90)
+		if err != nil || s.Name() != name {
+			t.Fatalf("New(%q) = %v, %v", name, s, err)
+		}
+	}
+
+	if _, err := review.New("leitner", 90); !errors.Is(err, review.ErrUnknownScheduler) {
+		t.Fatalf("New(leitner) error = %v, want ErrUnknownScheduler", err)
+	}
+}
+
+func TestAdoptRoundTrip(t *testing.T) {
+	state := models.ConceptRetention{
+		ConceptID: "c1", Status: models.RetentionStatusReviewing, ReviewCount: 4,
+		IntervalDays: 12, EaseFactor: 2.1, Scheduler: review.NameSM2,
+	}
+
+	fsrs := review.FSRS{}.Adopt(state)
+	if fsrs.Stability != 12 || fsrs.Difficulty != 7 || fsrs.Scheduler != review.NameFSRS {
+		t.Fatalf("FSRS.Adopt() = %+v", fsrs)
+	}
+
+	back := review.SM2{}.Adopt(fsrs)
+	if back.EaseFactor != 2.1 || back.Stability != 0 || back.Scheduler != review.NameSM2 || back.IntervalDays != 12 {
+		t.Fatalf("SM2.Adopt() = %+v", back)
+	}
+
+	if fresh := (review.FSRS{}).Adopt(models.ConceptRetention{Status: models.RetentionStatusLearning}); fresh.Stability != 0 {
+		t.Fatalf("never-reviewed concept got stability %v", fresh.Stability)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	day := func(d int) string {
+		return time.Date(2026, 3, d, 9, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+
+	history := []models.ReviewEvent{
+		{Rating: models.RatingEasy, ReviewedAt: day(1)},
+		{Rating: models.RatingGood, ReviewedAt: day(2)},
+		{Rating: models.RatingGood, ReviewedAt: day(6)},
+	}
+
+	sm2 := review.SM2{MasteryDays: 90}
+	current := models.ConceptRetention{ConceptID: "c1", ReviewCount: 2, IntervalDays: 3, EaseFactor: 2.5, Scheduler: review.NameFSRS}
+
+	// Only the last two reviews count: good then good is 1 then 3 days.
+	got, err := review.Replay(sm2, current, history)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if got.IntervalDays != 3 || got.ReviewCount != 2 || got.LastReviewed != day(6) || got.Scheduler != review.NameSM2 {
+		t.Fatalf("Replay() = %+v", got)
+	}
+
+	// Reviews from before the log was kept cannot be replayed.
+	current.ReviewCount = 5
+
+	got, err = review.Replay(sm2, current, history)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if got.ReviewCount != 5 || got.IntervalDays != 3 || got.Scheduler != review.NameSM2 {
+		t.Fatalf("Replay() with short history = %+v, want adopted state", got)
+	}
+}
+
+func TestReplayRejectsBadTimestamp(t *testing.T) {
+	current := models.ConceptRetention{ConceptID: "c1", ReviewCount: 1}
+	history := []models.ReviewEvent{{Rating: models.RatingGood, ReviewedAt: "yesterday"}}
+
+	if _, err := review.Replay(review.SM2{}, current, history); err == nil {
+		t.Fatal("expected error for unparseable review time")
+	}
+}
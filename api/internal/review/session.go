@@ -0,0 +1,63 @@
+package review
+
+import "github.com/sean/apollo/api/internal/models"
+
+// Default daily caps on the cards review sessions hand out.
+const (
+	DefaultNewPerDay     = 20
+	DefaultReviewsPerDay = 200
+)
+
+// DefaultLeechThreshold is the lapses after which a concept is suspended
+// from review as a leech.
+const DefaultLeechThreshold = 8
+
+// Interleave orders the cards of a review session. Within reviews and new
+// cards alike, topics take turns, each keeping its own due order, so
+// consecutive cards rarely test the same material. New cards are then
+// spread evenly among the reviews.
+func Interleave(reviews, newCards []models.ReviewCard) []models.ReviewCard {
+	reviews = alternateTopics(reviews)
+	newCards = alternateTopics(newCards)
+
+	out := make([]models.ReviewCard, 0, len(reviews)+len(newCards))
+	next := 0
+
+	for i, card := range newCards {
+		for ; next < (i+1)*len(reviews)/(len(newCards)+1); next++ {
+			out = append(out, reviews[next])
+		}
+
+		out = append(out, card)
+	}
+
+	return append(out, reviews[next:]...)
+}
+
+// alternateTopics deals cards round-robin by topic, topics in order of
+// their first card.
+func alternateTopics(cards []models.ReviewCard) []models.ReviewCard {
+	var topics []string
+
+	byTopic := make(map[string][]models.ReviewCard)
+
+	for _, card := range cards {
+		if _, ok := byTopic[card.TopicID]; !ok {
+			topics = append(topics, card.TopicID)
+		}
+
+		byTopic[card.TopicID] = append(byTopic[card.TopicID], card)
+	}
+
+	out := make([]models.ReviewCard, 0, len(cards))
+
+	for round := 0; len(out) < len(cards); round++ {
+		for _, topic := range topics {
+			if round < len(byTopic[topic]) {
+				out = append(out, byTopic[topic][round])
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,55 @@
+package review_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+// cards builds cards from "topic:concept" pairs.
+func cards(specs ...string) []models.ReviewCard {
+	out := make([]models.ReviewCard, 0, len(specs))
+
+	for _, spec := range specs {
+		topic, concept, _ := strings.Cut(spec, ":")
+		out = append(out, models.ReviewCard{ConceptRetention: models.ConceptRetention{ConceptID: concept}, TopicID: topic})
+	}
+
+	return out
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		name     string
+		reviews  []models.ReviewCard
+		newCards []models.ReviewCard
+		want     string
+	}{
+		{name: "empty", want: ""},
+		{name: "topics alternate", reviews: cards("a:r1", "a:r2", "a:r3", "b:r4", "c:r5"), want: "r1 r4 r5 r2 r3"},
+		{name: "only new cards", newCards: cards("a:n1", "b:n2", "a:n3"), want: "n1 n2 n3"},
+		{name: "new cards spread", reviews: cards("a:r1", "a:r2", "a:r3", "a:r4"), newCards: cards("b:n1"), want: "r1 r2 n1 r3 r4"},
+		{name: "one each", reviews: cards("a:r1"), newCards: cards("a:n1"), want: "n1 r1"},
+		{
+			name:     "new cards spread across topics",
+			reviews:  cards("a:r1", "a:r2", "b:r3", "b:r4", "a:r5", "b:r6"),
+			newCards: cards("c:n1", "c:n2"),
+			want:     "r1 r3 n1 r2 r4 n2 r5 r6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, c := range review.Interleave(tt.reviews, tt.newCards) {
+				ids = append(ids, c.ConceptID)
+			}
+
+			if got := strings.Join(ids, " "); got != tt.want {
+				t.Fatalf("Interleave() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package review
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// simulationSeed fixes the simulated ratings so runs are repeatable and
+// every scheduler sees the same sequence.
+const simulationSeed = 42
+
+// RatingMix is the share of reviews given each rating. The shares need not
+// sum to one; they are normalized when drawing.
+type RatingMix struct {
+	Forgot, Hard, Good, Easy float64
+}
+
+// DefaultRatingMix is used when there is no review history to learn from.
+var DefaultRatingMix = RatingMix{Forgot: 0.1, Hard: 0.15, Good: 0.6, Easy: 0.15}
+
+// MixFromHistory returns the rating shares in the given review histories,
+// or DefaultRatingMix when they hold no reviews.
+func MixFromHistory(history map[string][]models.ReviewEvent) RatingMix {
+	var mix RatingMix
+
+	var total int
+
+	for _, events := range history {
+		for _, e := range events {
+			switch e.Rating {
+			case models.RatingForgot:
+				mix.Forgot++
+			case models.RatingHard:
+				mix.Hard++
+			case models.RatingGood:
+				mix.Good++
+			case models.RatingEasy:
+				mix.Easy++
+			default:
+				continue
+			}
+
+			total++
+		}
+	}
+
+	if total == 0 {
+		return DefaultRatingMix
+	}
+
+	return mix
+}
+
+// draw picks a rating for x in [0, 1).
+func (m RatingMix) draw(x float64) string {
+	x *= m.Forgot + m.Hard + m.Good + m.Easy
+
+	switch {
+	case x < m.Forgot:
+		return models.RatingForgot
+	case x < m.Forgot+m.Hard:
+		return models.RatingHard
+	case x < m.Forgot+m.Hard+m.Good:
+		return models.RatingGood
+	}
+
+	return models.RatingEasy
+}
+
+// Simulate projects the review workload of cards under s for the given
+// number of days from start, returning the reviews due each day. Every due
+// learning or reviewing card is reviewed once that day with a rating drawn
+// from mix; mastered cards leave the queue and suspended ones never enter
+// it. The draws use a fixed seed, so
+// two schedulers given the same cards face the same sequence of ratings.
+func Simulate(s Scheduler, cards []models.ConceptRetention, mix RatingMix, start time.Time, days int) []int {
+	rng := rand.New(rand.NewPCG(simulationSeed, simulationSeed))
+	workload := make([]int, days)
+
+	queue := make([]models.ConceptRetention, len(cards))
+	copy(queue, cards)
+
+	cutoff := DueCutoff(start)
+
+	for day := range days {
+		dayEnd := cutoff.AddDate(0, 0, day)
+		reviewAt := dayEnd.Add(-12 * time.Hour)
+
+		for i, card := range queue {
+			if card.Status != models.RetentionStatusLearning && card.Status != models.RetentionStatusReviewing ||
+				card.SuspendedAt != "" {
+				continue
+			}
+
+			due, err := time.Parse(time.RFC3339, card.NextReview)
+			if err != nil || !due.Before(dayEnd) {
+				continue
+			}
+
+			next, err := s.Review(card, mix.draw(rng.Float64()), reviewAt)
+			if err != nil {
+				continue
+			}
+
+			queue[i] = next
+			workload[day]++
+		}
+	}
+
+	return workload
+}
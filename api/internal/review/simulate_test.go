@@ -0,0 +1,56 @@
+package review_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestSimulate(t *testing.T) {
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	cards := []models.ConceptRetention{
+		{ConceptID: "due", Status: models.RetentionStatusLearning, NextReview: start.Format(time.RFC3339), EaseFactor: 2.5},
+		{ConceptID: "later", Status: models.RetentionStatusReviewing, NextReview: start.AddDate(0, 0, 3).Format(time.RFC3339), EaseFactor: 2.5, ReviewCount: 2, IntervalDays: 3},
+		{ConceptID: "mastered", Status: models.RetentionStatusMastered, NextReview: start.Format(time.RFC3339)},
+		{ConceptID: "new", Status: models.RetentionStatusNew},
+		{ConceptID: "leech", Status: models.RetentionStatusLearning, NextReview: start.Format(time.RFC3339), EaseFactor: 1.3, Lapses: 8, SuspendedAt: start.Format(time.RFC3339)},
+	}
+
+	alwaysGood := review.RatingMix{Good: 1}
+
+	got := review.Simulate(review.SM2{MasteryDays: 90}, cards, alwaysGood, start, 8)
+
+	// "due" is reviewed on days 0, 1 and 4; "later" on day 3 (then in 8 days).
+	want := []int{1, 1, 0, 1, 1, 0, 0, 0}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Simulate() = %v, want %v", got, want)
+	}
+
+	if cards[0].ReviewCount != 0 {
+		t.Fatal("Simulate() modified its input")
+	}
+
+	again := review.Simulate(review.FSRS{MasteryDays: 90}, cards, review.DefaultRatingMix, start, 30)
+	if !slices.Equal(again, review.Simulate(review.FSRS{MasteryDays: 90}, cards, review.DefaultRatingMix, start, 30)) {
+		t.Fatal("Simulate() is not repeatable")
+	}
+}
+
+func TestMixFromHistory(t *testing.T) {
+	if got := review.MixFromHistory(nil); got != review.DefaultRatingMix {
+		t.Fatalf("empty history mix = %+v, want default", got)
+	}
+
+	history := map[string][]models.ReviewEvent{
+		"c1": {{Rating: models.RatingGood}, {Rating: models.RatingForgot}},
+		"c2": {{Rating: models.RatingGood}},
+	}
+
+	if got := review.MixFromHistory(history); got != (review.RatingMix{Forgot: 1, Good: 2}) {
+		t.Fatalf("MixFromHistory() = %+v", got)
+	}
+}
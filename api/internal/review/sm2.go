@@ -0,0 +1,115 @@
+// Package review schedules spaced repetition of concepts. Schedulers are
+// pure: they map a concept's retention state, a rating, and the current time
+// to the next state, leaving storage to the repository.
+package review
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+)
+
+// minEaseFactor is the floor SM-2 keeps the ease factor above, so a card
+// that is often forgotten still spaces out eventually.
+const minEaseFactor = 1.3
+
+// DefaultMasteryDays is the PRD's mastery threshold: a concept reviewed at
+// intervals longer than 90 days is considered mastered.
+const DefaultMasteryDays = 90
+
+// SM2 is the SM-2 variant from PRD §11.1. A concept whose interval exceeds
+// MasteryDays becomes mastered and leaves the due queue.
+type SM2 struct {
+	MasteryDays int
+}
+
+// Name implements Scheduler.
+func (SM2) Name() string { return NameSM2 }
+
+// Review returns the state after rating the concept at now. Forgetting
+// sends the concept back to learning; any other rating moves it to
+// reviewing, or mastered once the interval passes the threshold.
+func (s SM2) Review(state models.ConceptRetention, rating string, now time.Time) (models.ConceptRetention, error) {
+	state = s.Adopt(state)
+	ease := state.EaseFactor
+	if ease == 0 {
+		ease = models.DefaultEaseFactor
+	}
+
+	interval := state.IntervalDays
+
+	switch rating {
+	case models.RatingForgot:
+		interval = 1
+		ease = math.Max(minEaseFactor, ease-0.2)
+	case models.RatingHard:
+		interval = max(1, roundDays(float64(interval)*1.2))
+		ease = math.Max(minEaseFactor, ease-0.15)
+	case models.RatingGood:
+		switch state.ReviewCount {
+		case 0:
+			interval = 1
+		case 1:
+			interval = 3
+		default:
+			interval = roundDays(float64(interval) * ease)
+		}
+	case models.RatingEasy:
+		if state.ReviewCount == 0 {
+			interval = 4
+		} else {
+			interval = roundDays(float64(interval) * ease * 1.3)
+		}
+
+		ease += 0.15
+	default:
+		return state, fmt.Errorf("unknown rating %q", rating)
+	}
+
+	now = now.UTC()
+
+	return models.ConceptRetention{
+		ConceptID:    state.ConceptID,
+		Status:       nextStatus(rating, interval, s.MasteryDays),
+		NextReview:   now.AddDate(0, 0, interval).Format(time.RFC3339),
+		ReviewCount:  state.ReviewCount + 1,
+		EaseFactor:   math.Round(ease*100) / 100,
+		IntervalDays: interval,
+		LastReviewed: now.Format(time.RFC3339),
+		LastRating:   rating,
+		Scheduler:    NameSM2,
+	}, nil
+}
+
+// Adopt derives the ease factor from FSRS difficulty, the inverse of
+// FSRS.Adopt: the default difficulty of 5 maps to the default ease of 2.5.
+func (SM2) Adopt(state models.ConceptRetention) models.ConceptRetention {
+	if state.Scheduler == NameSM2 || state.Scheduler == "" {
+		state.Scheduler = NameSM2
+
+		return state
+	}
+
+	if state.Difficulty > 0 {
+		state.EaseFactor = math.Max(minEaseFactor, math.Round((2.5-(state.Difficulty-5)*0.2)*100)/100)
+	}
+
+	state.Stability, state.Difficulty = 0, 0
+	state.Scheduler = NameSM2
+
+	return state
+}
+
+func roundDays(days float64) int {
+	return int(math.Round(days))
+}
+
+// DueCutoff returns the end of now's UTC day. A review is due today when its
+// next_review falls before the cutoff, whatever the time of day.
+func DueCutoff(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
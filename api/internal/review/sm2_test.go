@@ -0,0 +1,77 @@
+package review_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/review"
+)
+
+func TestSM2Review(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		state        models.ConceptRetention
+		rating       string
+		wantInterval int
+		wantEase     float64
+		wantStatus   string
+	}{
+		{"first good", models.ConceptRetention{EaseFactor: 2.5}, models.RatingGood, 1, 2.5, models.RetentionStatusReviewing},
+		{"second good", models.ConceptRetention{EaseFactor: 2.5, ReviewCount: 1, IntervalDays: 1}, models.RatingGood, 3, 2.5, models.RetentionStatusReviewing},
+		{"later good multiplies by ease", models.ConceptRetention{EaseFactor: 2.5, ReviewCount: 2, IntervalDays: 3}, models.RatingGood, 8, 2.5, models.RetentionStatusReviewing},
+		{"first easy", models.ConceptRetention{EaseFactor: 2.5}, models.RatingEasy, 4, 2.65, models.RetentionStatusReviewing},
+		{"later easy adds bonus", models.ConceptRetention{EaseFactor: 2.5, ReviewCount: 3, IntervalDays: 10}, models.RatingEasy, 33, 2.65, models.RetentionStatusReviewing},
+		{"hard grows slowly", models.ConceptRetention{EaseFactor: 2.5, ReviewCount: 3, IntervalDays: 10}, models.RatingHard, 12, 2.35, models.RetentionStatusReviewing},
+		{"hard never below a day", models.ConceptRetention{EaseFactor: 2.5}, models.RatingHard, 1, 2.35, models.RetentionStatusReviewing},
+		{"forgot resets to learning", models.ConceptRetention{Status: models.RetentionStatusReviewing, EaseFactor: 2.5, ReviewCount: 5, IntervalDays: 40}, models.RatingForgot, 1, 2.3, models.RetentionStatusLearning},
+		{"ease floor", models.ConceptRetention{EaseFactor: 1.4, ReviewCount: 5, IntervalDays: 4}, models.RatingForgot, 1, 1.3, models.RetentionStatusLearning},
+		{"unset ease uses default", models.ConceptRetention{ReviewCount: 2, IntervalDays: 4}, models.RatingGood, 10, 2.5, models.RetentionStatusReviewing},
+		{"past threshold is mastered", models.ConceptRetention{EaseFactor: 2.5, ReviewCount: 6, IntervalDays: 40}, models.RatingGood, 100, 2.5, models.RetentionStatusMastered},
+		{"at threshold is not mastered", models.ConceptRetention{EaseFactor: 2.5, ReviewCount: 6, IntervalDays: 36}, models.RatingGood, 90, 2.5, models.RetentionStatusReviewing},
+		{"mastered forgot relearns", models.ConceptRetention{Status: models.RetentionStatusMastered, EaseFactor: 2.5, ReviewCount: 8, IntervalDays: 120}, models.RatingForgot, 1, 2.3, models.RetentionStatusLearning},
+	}
+
+	sched := review.SM2{MasteryDays: 90}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.state.ConceptID = "c1"
+
+			got, err := sched.Review(tt.state, tt.rating, now)
+			if err != nil {
+				t.Fatalf("Review() error = %v", err)
+			}
+
+			if got.IntervalDays != tt.wantInterval || got.EaseFactor != tt.wantEase || got.Status != tt.wantStatus {
+				t.Fatalf("Review() = interval %d ease %v status %s, want %d %v %s",
+					got.IntervalDays, got.EaseFactor, got.Status, tt.wantInterval, tt.wantEase, tt.wantStatus)
+			}
+
+			if got.ReviewCount != tt.state.ReviewCount+1 || got.LastRating != tt.rating || got.ConceptID != "c1" {
+				t.Fatalf("Review() bookkeeping = %+v", got)
+			}
+
+			wantNext := now.AddDate(0, 0, tt.wantInterval).Format(time.RFC3339)
+			if got.NextReview != wantNext || got.LastReviewed != now.Format(time.RFC3339) {
+				t.Fatalf("Review() next %s last %s, want %s %s", got.NextReview, got.LastReviewed, wantNext, now.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestSM2ReviewRejectsUnknownRating(t *testing.T) {
+	if _, err := (review.SM2{MasteryDays: 90}).Review(models.ConceptRetention{}, "meh", time.Now()); err == nil {
+		t.Fatal("expected error for unknown rating")
+	}
+}
+
+func TestDueCutoff(t *testing.T) {
+	got := review.DueCutoff(time.Date(2026, 3, 10, 23, 59, 0, 0, time.FixedZone("east", 2*3600)))
+
+	if want := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("DueCutoff() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,306 @@
+// Package scheduler runs periodic maintenance tasks on cron-like schedules.
+// Each task runs under a named lock so two runs of the same task never
+// overlap, and every run is recorded in the task_runs table.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+var (
+	// ErrUnknownTask is returned when triggering a task that is not registered.
+	ErrUnknownTask = errors.New("unknown task")
+	// ErrTaskRunning is returned when a task's lock is held by another run.
+	ErrTaskRunning = errors.New("task already running")
+)
+
+// DisabledSpec registers a task that only runs when triggered manually.
+const DisabledSpec = "off"
+
+// Task is a named unit of periodic work. Run returns a short summary that is
+// stored with the run record.
+type Task struct {
+	Name string
+	Spec string
+	Run  func(ctx context.Context) (string, error)
+}
+
+type entry struct {
+	task     Task
+	schedule Schedule // nil when the task is manual-only
+	next     time.Time
+}
+
+// Scheduler owns the registered tasks and their locks.
+type Scheduler struct {
+	repo   repository.TaskRunRepository
+	logger zerolog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	locks   map[string]bool
+	baseCtx context.Context
+	wg      sync.WaitGroup
+}
+
+// New creates an empty Scheduler.
+func New(repo repository.TaskRunRepository, logger zerolog.Logger) *Scheduler {
+	return &Scheduler{
+		repo:    repo,
+		logger:  logger,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+		locks:   make(map[string]bool),
+		baseCtx: context.Background(),
+	}
+}
+
+// Register adds a task. An empty or "off" spec registers it for manual
+// triggers only. Register fails on a duplicate name or an invalid spec.
+func (s *Scheduler) Register(task Task) error {
+	e := &entry{task: task}
+
+	if task.Spec != "" && task.Spec != DisabledSpec {
+		schedule, err := ParseSpec(task.Spec)
+		if err != nil {
+			return fmt.Errorf("register task %s: %w", task.Name, err)
+		}
+
+		e.schedule = schedule
+		e.next = schedule.Next(s.now().UTC())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[task.Name]; ok {
+		return fmt.Errorf("register task %s: duplicate name", task.Name)
+	}
+
+	s.entries[task.Name] = e
+
+	return nil
+}
+
+// Start fires due tasks until ctx is cancelled, then waits for running tasks
+// to return. Runs left "running" by a previous process are marked failed.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.baseCtx = ctx
+	count := len(s.entries)
+	s.mu.Unlock()
+
+	if n, err := s.repo.FailInterruptedRuns(ctx); err != nil {
+		s.logger.Error().Err(err).Msg("mark interrupted task runs failed")
+	} else if n > 0 {
+		s.logger.Warn().Int("runs", n).Msg("marked interrupted task runs failed")
+	}
+
+	s.logger.Info().Int("tasks", count).Msg("scheduler started")
+
+	for {
+		var timer *time.Timer
+		var fire <-chan time.Time
+
+		if wait, ok := s.untilNext(); ok {
+			timer = time.NewTimer(wait)
+			fire = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+
+			s.logger.Info().Msg("scheduler stopping")
+			s.wg.Wait()
+
+			return
+		case <-fire:
+			s.fireDue()
+		}
+	}
+}
+
+// untilNext returns the delay before the earliest scheduled run, or false
+// when no task is scheduled.
+func (s *Scheduler) untilNext() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var earliest time.Time
+	for _, e := range s.entries {
+		if e.schedule == nil || e.next.IsZero() {
+			continue
+		}
+
+		if earliest.IsZero() || e.next.Before(earliest) {
+			earliest = e.next
+		}
+	}
+
+	if earliest.IsZero() {
+		return 0, false
+	}
+
+	return max(earliest.Sub(s.now().UTC()), 0), true
+}
+
+func (s *Scheduler) fireDue() {
+	now := s.now().UTC()
+
+	s.mu.Lock()
+	var due []string
+	for name, e := range s.entries {
+		if e.schedule != nil && !e.next.IsZero() && !e.next.After(now) {
+			due = append(due, name)
+			e.next = e.schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Strings(due)
+
+	for _, name := range due {
+		if _, err := s.launch(name, models.TaskTriggerSchedule); err != nil && !errors.Is(err, ErrTaskRunning) {
+			s.logger.Error().Err(err).Str("task", name).Msg("start scheduled task failed")
+		}
+	}
+}
+
+// Trigger starts a task now, outside its schedule. The returned run is in the
+// running state; the task continues in the background.
+func (s *Scheduler) Trigger(name string) (*models.TaskRun, error) {
+	return s.launch(name, models.TaskTriggerManual)
+}
+
+// launch takes the task's named lock, records the run, and runs the task in
+// its own goroutine. When the lock is held, a skipped run is recorded and
+// ErrTaskRunning is returned.
+func (s *Scheduler) launch(name, trigger string) (*models.TaskRun, error) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	if !ok {
+		s.mu.Unlock()
+
+		return nil, fmt.Errorf("task %s: %w", name, ErrUnknownTask)
+	}
+
+	ctx := s.baseCtx
+	held := s.locks[name]
+	if !held {
+		s.locks[name] = true
+	}
+	s.mu.Unlock()
+
+	if held {
+		if _, err := s.repo.RecordSkippedRun(ctx, name, trigger, ErrTaskRunning.Error()); err != nil {
+			s.logger.Error().Err(err).Str("task", name).Msg("record skipped task run")
+		}
+
+		return nil, fmt.Errorf("task %s: %w", name, ErrTaskRunning)
+	}
+
+	run, err := s.repo.StartRun(ctx, name, trigger)
+	if err != nil {
+		s.unlock(name)
+
+		return nil, err
+	}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer s.unlock(name)
+
+		s.execute(ctx, e.task, run)
+	}()
+
+	return run, nil
+}
+
+func (s *Scheduler) execute(ctx context.Context, task Task, run *models.TaskRun) {
+	log := s.logger.With().Str("task", task.Name).Int64("run_id", run.ID).Logger()
+	log.Info().Str("trigger", run.Trigger).Msg("task started")
+
+	summary, err := runRecovered(ctx, task)
+
+	status, errMsg := models.TaskRunStatusSucceeded, ""
+	if err != nil {
+		status, errMsg = models.TaskRunStatusFailed, err.Error()
+		log.Error().Err(err).Msg("task failed")
+	} else {
+		log.Info().Str("summary", summary).Msg("task succeeded")
+	}
+
+	// Record the outcome even when shutdown cancelled the task's context.
+	if err := s.repo.FinishRun(context.WithoutCancel(ctx), run.ID, status, summary, errMsg); err != nil {
+		log.Error().Err(err).Msg("record task run outcome")
+	}
+}
+
+// runRecovered runs the task, turning a panic into an error so one broken
+// task cannot take the scheduler down.
+func runRecovered(ctx context.Context, task Task) (summary string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("task panicked: %v", p)
+		}
+	}()
+
+	return task.Run(ctx)
+}
+
+func (s *Scheduler) unlock(name string) {
+	s.mu.Lock()
+	delete(s.locks, name)
+	s.mu.Unlock()
+}
+
+// Tasks reports every registered task with its schedule, lock state, and
+// latest recorded run, ordered by name.
+func (s *Scheduler) Tasks(ctx context.Context) ([]models.TaskStatus, error) {
+	latest, err := s.repo.LatestRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]models.TaskStatus, 0, len(s.entries))
+
+	for name, e := range s.entries {
+		ts := models.TaskStatus{Name: name, Running: s.locks[name]}
+
+		if e.schedule != nil {
+			ts.Spec = e.task.Spec
+			if !e.next.IsZero() {
+				ts.NextRun = e.next.Format(time.RFC3339)
+			}
+		}
+
+		if run, ok := latest[name]; ok {
+			ts.LastRun = &run
+		}
+
+		tasks = append(tasks, ts)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+
+	return tasks, nil
+}
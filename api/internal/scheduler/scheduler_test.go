@@ -0,0 +1,236 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/scheduler"
+)
+
+func setupScheduler(t *testing.T) (*scheduler.Scheduler, *repository.SQLiteTaskRunRepository) {
+	t.Helper()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	handle, err := database.Open(context.Background(), filepath.Join(t.TempDir(), "test.db"), logger)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+
+	t.Cleanup(func() { _ = handle.Close() })
+
+	repo := repository.NewTaskRunRepository(handle.ReadDB, handle.DB)
+
+	return scheduler.New(repo, logger), repo
+}
+
+// waitForRun polls until none of the task's runs are still running and
+// returns the most recent one.
+func waitForRun(t *testing.T, repo repository.TaskRunRepository, task string) models.TaskRun {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		runs, err := repo.ListRuns(context.Background(), task, models.PaginationParams{Page: 1, PerPage: 50})
+		if err != nil {
+			t.Fatalf("list runs: %v", err)
+		}
+
+		running := false
+		for _, run := range runs.Items {
+			if run.Status == models.TaskRunStatusRunning {
+				running = true
+			}
+		}
+
+		if len(runs.Items) > 0 && !running {
+			return runs.Items[0]
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("task %s did not finish", task)
+
+	return models.TaskRun{}
+}
+
+func TestRegisterRejectsInvalidAndDuplicate(t *testing.T) {
+	sched, _ := setupScheduler(t)
+	noop := func(context.Context) (string, error) { return "", nil }
+
+	if err := sched.Register(scheduler.Task{Name: "bad", Spec: "not a spec", Run: noop}); err == nil {
+		t.Fatal("expected invalid spec error")
+	}
+
+	if err := sched.Register(scheduler.Task{Name: "a", Spec: "@daily", Run: noop}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	if err := sched.Register(scheduler.Task{Name: "a", Spec: scheduler.DisabledSpec, Run: noop}); err == nil {
+		t.Fatal("expected duplicate name error")
+	}
+}
+
+func TestTriggerRecordsOutcome(t *testing.T) {
+	sched, repo := setupScheduler(t)
+
+	tasks := []scheduler.Task{
+		{Name: "ok", Spec: "@daily", Run: func(context.Context) (string, error) { return "did it", nil }},
+		{Name: "fails", Spec: scheduler.DisabledSpec, Run: func(context.Context) (string, error) { return "", errors.New("boom") }},
+		{Name: "panics", Spec: scheduler.DisabledSpec, Run: func(context.Context) (string, error) { panic("oops") }},
+	}
+	for _, task := range tasks {
+		if err := sched.Register(task); err != nil {
+			t.Fatalf("register %s: %v", task.Name, err)
+		}
+	}
+
+	for _, task := range tasks {
+		run, err := sched.Trigger(task.Name)
+		if err != nil {
+			t.Fatalf("trigger %s: %v", task.Name, err)
+		}
+
+		if run.Trigger != models.TaskTriggerManual || run.Status != models.TaskRunStatusRunning {
+			t.Fatalf("unexpected started run %+v", run)
+		}
+	}
+
+	if run := waitForRun(t, repo, "ok"); run.Status != models.TaskRunStatusSucceeded || run.Summary != "did it" || run.FinishedAt == "" {
+		t.Fatalf("unexpected ok run %+v", run)
+	}
+
+	if run := waitForRun(t, repo, "fails"); run.Status != models.TaskRunStatusFailed || run.Error != "boom" {
+		t.Fatalf("unexpected fails run %+v", run)
+	}
+
+	if run := waitForRun(t, repo, "panics"); run.Status != models.TaskRunStatusFailed || run.Error == "" {
+		t.Fatalf("unexpected panics run %+v", run)
+	}
+
+	if _, err := sched.Trigger("missing"); !errors.Is(err, scheduler.ErrUnknownTask) {
+		t.Fatalf("expected ErrUnknownTask, got %v", err)
+	}
+
+	statuses, err := sched.Tasks(context.Background())
+	if err != nil {
+		t.Fatalf("tasks: %v", err)
+	}
+
+	if len(statuses) != 3 || statuses[0].Name != "fails" || statuses[1].Name != "ok" {
+		t.Fatalf("unexpected task statuses %+v", statuses)
+	}
+
+	if statuses[0].Spec != "" || statuses[0].NextRun != "" {
+		t.Fatalf("manual-only task should have no schedule: %+v", statuses[0])
+	}
+
+	if statuses[1].Spec != "@daily" || statuses[1].NextRun == "" || statuses[1].LastRun == nil {
+		t.Fatalf("unexpected scheduled task status %+v", statuses[1])
+	}
+}
+
+func TestTriggerWhileRunningIsSkipped(t *testing.T) {
+	sched, repo := setupScheduler(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	err := sched.Register(scheduler.Task{
+		Name: "slow",
+		Run: func(context.Context) (string, error) {
+			close(started)
+			<-release
+
+			return "done", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	if _, err := sched.Trigger("slow"); err != nil {
+		t.Fatalf("first trigger: %v", err)
+	}
+
+	<-started
+
+	if _, err := sched.Trigger("slow"); !errors.Is(err, scheduler.ErrTaskRunning) {
+		t.Fatalf("expected ErrTaskRunning, got %v", err)
+	}
+
+	close(release)
+	waitForRun(t, repo, "slow")
+
+	runs, err := repo.ListRuns(context.Background(), "slow", models.PaginationParams{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+
+	statuses := map[string]int{}
+	for _, run := range runs.Items {
+		statuses[run.Status]++
+	}
+
+	if runs.Total != 2 || statuses[models.TaskRunStatusSkipped] != 1 || statuses[models.TaskRunStatusSucceeded] != 1 {
+		t.Fatalf("expected one skipped and one succeeded run, got %+v", runs.Items)
+	}
+}
+
+func TestStartFiresScheduledTasksAndWaitsOnShutdown(t *testing.T) {
+	sched, repo := setupScheduler(t)
+
+	fired := make(chan struct{}, 1)
+
+	err := sched.Register(scheduler.Task{
+		Name: "tick",
+		Spec: "@every 1s",
+		Run: func(context.Context) (string, error) {
+			select {
+			case fired <- struct{}{}:
+			default:
+			}
+
+			return "tick", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		sched.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduled task did not fire")
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+
+	if run := waitForRun(t, repo, "tick"); run.Trigger != models.TaskTriggerSchedule {
+		t.Fatalf("expected scheduled trigger, got %+v", run)
+	}
+}
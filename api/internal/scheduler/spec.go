@@ -0,0 +1,224 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule reports the next activation time strictly after a given time.
+// A zero time means the schedule never fires again.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// descriptors are the @-shorthands accepted in place of five cron fields.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSpec parses a cron-like schedule: five fields (minute, hour, day of
+// month, month, day of week) supporting *, lists, ranges, and steps; an
+// @-descriptor such as @daily; or "@every <duration>". Times are evaluated
+// in the location of the time passed to Next.
+func ParseSpec(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("parse spec %q: %w", spec, err)
+		}
+
+		if d < time.Second {
+			return nil, fmt.Errorf("parse spec %q: interval must be at least 1s", spec)
+		}
+
+		return everySchedule(d), nil
+	}
+
+	if expanded, ok := descriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("parse spec %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var c cronSchedule
+	bounds := []struct {
+		set      *bitset
+		star     *bool
+		min, max int
+	}{
+		{&c.minute, nil, 0, 59},
+		{&c.hour, nil, 0, 23},
+		{&c.dom, &c.domStar, 1, 31},
+		{&c.month, nil, 1, 12},
+		{&c.dow, &c.dowStar, 0, 7},
+	}
+
+	for i, b := range bounds {
+		set, star, err := parseField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("parse spec %q: field %d: %w", spec, i+1, err)
+		}
+
+		*b.set = set
+		if b.star != nil {
+			*b.star = star
+		}
+	}
+
+	// Day of week 7 is Sunday, like 0.
+	if c.dow.has(7) {
+		c.dow |= 1
+	}
+
+	return &c, nil
+}
+
+type bitset uint64
+
+func (b bitset) has(n int) bool { return b&(1<<uint(n)) != 0 }
+
+// parseField parses one comma-separated cron field. star reports whether the
+// field was an unrestricted "*", which matters for day matching.
+func parseField(field string, lo, hi int) (bitset, bool, error) {
+	var set bitset
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, false, fmt.Errorf("invalid step %q", stepPart)
+			}
+
+			step = n
+		}
+
+		start, end := lo, hi
+
+		switch {
+		case rangePart == "*":
+			if field == "*" {
+				return rangeBits(lo, hi, 1), true, nil
+			}
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = parseBound(a, lo, hi); err != nil {
+				return 0, false, err
+			}
+
+			if end, err = parseBound(b, lo, hi); err != nil {
+				return 0, false, err
+			}
+
+			if start > end {
+				return 0, false, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			n, err := parseBound(rangePart, lo, hi)
+			if err != nil {
+				return 0, false, err
+			}
+
+			start, end = n, n
+			if hasStep {
+				end = hi
+			}
+		}
+
+		set |= rangeBits(start, end, step)
+	}
+
+	return set, false, nil
+}
+
+func parseBound(s string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, lo, hi)
+	}
+
+	return n, nil
+}
+
+func rangeBits(start, end, step int) bitset {
+	var set bitset
+	for n := start; n <= end; n += step {
+		set |= 1 << uint(n)
+	}
+
+	return set
+}
+
+// cronSchedule is a parsed five-field spec.
+type cronSchedule struct {
+	minute, hour, dom, month, dow bitset
+	domStar, dowStar              bool
+}
+
+// maxSearch bounds Next for specs that can never match, such as 30 February.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		y, m, d := t.Date()
+
+		switch {
+		case !c.month.has(int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !c.hour.has(t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches follows cron's rule: when both day fields are restricted, a day
+// matching either one fires.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom.has(t.Day())
+	dow := c.dow.has(int(t.Weekday()))
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+type everySchedule time.Duration
+
+func (e everySchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
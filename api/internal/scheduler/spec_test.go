@@ -0,0 +1,82 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sean/apollo/api/internal/scheduler"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	tm, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("parse time %q: %v", s, err)
+	}
+
+	return tm
+}
+
+func TestParseSpecNext(t *testing.T) {
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		{"0 3 * * *", "2026-03-10T02:59:00Z", "2026-03-10T03:00:00Z"},
+		{"0 3 * * *", "2026-03-10T03:00:00Z", "2026-03-11T03:00:00Z"},
+		{"*/15 * * * *", "2026-03-10T10:07:30Z", "2026-03-10T10:15:00Z"},
+		{"30 4 * * 0", "2026-03-10T00:00:00Z", "2026-03-15T04:30:00Z"},
+		{"30 4 * * 7", "2026-03-10T00:00:00Z", "2026-03-15T04:30:00Z"},
+		{"0 9-17/4 * * 1-5", "2026-03-13T14:00:00Z", "2026-03-13T17:00:00Z"},
+		{"0 9-17/4 * * 1-5", "2026-03-13T17:00:00Z", "2026-03-16T09:00:00Z"},
+		{"0 0 1,15 * *", "2026-03-02T00:00:00Z", "2026-03-15T00:00:00Z"},
+		{"0 0 31 * *", "2026-04-01T00:00:00Z", "2026-05-31T00:00:00Z"},
+		{"0 0 29 2 *", "2026-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		// Restricted day-of-month and day-of-week match if either does.
+		{"0 0 13 * 5", "2026-03-01T00:00:00Z", "2026-03-06T00:00:00Z"},
+		{"@daily", "2026-03-10T12:00:00Z", "2026-03-11T00:00:00Z"},
+		{"@hourly", "2026-03-10T12:00:00Z", "2026-03-10T13:00:00Z"},
+		{"@monthly", "2026-12-05T00:00:00Z", "2027-01-01T00:00:00Z"},
+		{"@every 90m", "2026-03-10T12:00:00Z", "2026-03-10T13:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec+"/"+tt.after, func(t *testing.T) {
+			schedule, err := scheduler.ParseSpec(tt.spec)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			got := schedule.Next(mustTime(t, tt.after))
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.after, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpecRejectsInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@fortnightly",
+		"@every soon",
+		"@every 10ms",
+	}
+
+	for _, spec := range specs {
+		if _, err := scheduler.ParseSpec(spec); err == nil {
+			t.Errorf("expected error for spec %q", spec)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Curriculum schema definitions that can be validated on their own, for
+// payloads that arrive through the write API rather than a full curriculum.
+const (
+	DefLessonContent  = "LessonContent"
+	DefContentSection = "ContentSection"
+	DefExample        = "Example"
+	DefExercise       = "Exercise"
+	DefReviewQuestion = "ReviewQuestion"
+	DefAssessment     = "Assessment"
+)
+
+// Violation is one schema failure. Path is a JSON pointer into the
+// validated document.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ViolationsError carries every violation found, unlike Validate which
+// reports only the first.
+type ViolationsError struct {
+	Violations []Violation
+}
+
+func (e *ViolationsError) Error() string {
+	if len(e.Violations) == 0 {
+		return "schema validation failed"
+	}
+
+	first := e.Violations[0]
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("schema validation failed: %s: %s", first.Path, first.Message)
+	}
+
+	return fmt.Sprintf("schema validation failed (%d errors, first): %s: %s", len(e.Violations), first.Path, first.Message)
+}
+
+// definitionCache compiles curriculum $defs on first use.
+type definitionCache struct {
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+var definitions definitionCache
+
+func (dc *definitionCache) get(def string) (*jsonschema.Schema, error) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if sch, ok := dc.schemas[def]; ok {
+		return sch, nil
+	}
+
+	if dc.compiler == nil {
+		raw, err := schemaFS.ReadFile(curriculumSchemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("read embedded schema %s: %w", curriculumSchemaFile, err)
+		}
+
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal schema %s: %w", curriculumSchemaFile, err)
+		}
+
+		c := jsonschema.NewCompiler()
+		if err := c.AddResource(curriculumSchemaFile, doc); err != nil {
+			return nil, fmt.Errorf("add schema resource %s: %w", curriculumSchemaFile, err)
+		}
+
+		dc.compiler = c
+		dc.schemas = make(map[string]*jsonschema.Schema)
+	}
+
+	sch, err := dc.compiler.Compile(curriculumSchemaFile + "#/$defs/" + def)
+	if err != nil {
+		return nil, fmt.Errorf("compile definition %s: %w", def, err)
+	}
+
+	dc.schemas[def] = sch
+
+	return sch, nil
+}
+
+// ValidateDefinition checks jsonData against a single curriculum definition
+// such as DefLessonContent. On failure it returns a *ViolationsError listing
+// every violation.
+func ValidateDefinition(def string, jsonData []byte) error {
+	sch, err := definitions.get(def)
+	if err != nil {
+		return fmt.Errorf("load schema: %w", err)
+	}
+
+	data, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("invalid JSON input: %w", err)
+	}
+
+	var violations []Violation
+	validateValue(sch, data, "", &violations)
+
+	if len(violations) > 0 {
+		return &ViolationsError{Violations: violations}
+	}
+
+	return nil
+}
+
+// ValidateDefinitionList checks that jsonData is an array whose items each
+// satisfy def, as lesson examples, exercises, and review questions are
+// stored.
+func ValidateDefinitionList(def string, jsonData []byte) error {
+	sch, err := definitions.get(def)
+	if err != nil {
+		return fmt.Errorf("load schema: %w", err)
+	}
+
+	data, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("invalid JSON input: %w", err)
+	}
+
+	items, ok := data.([]any)
+	if !ok {
+		return &ViolationsError{Violations: []Violation{{Path: "", Message: "must be an array"}}}
+	}
+
+	var violations []Violation
+	for i, item := range items {
+		validateValue(sch, item, "/"+strconv.Itoa(i), &violations)
+	}
+
+	if len(violations) > 0 {
+		return &ViolationsError{Violations: violations}
+	}
+
+	return nil
+}
+
+func validateValue(sch *jsonschema.Schema, value any, prefix string, violations *[]Violation) {
+	err := sch.Validate(value)
+	if err == nil {
+		return
+	}
+
+	vErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		*violations = append(*violations, Violation{Path: prefix, Message: err.Error()})
+		return
+	}
+
+	collectViolations(vErr, prefix, violations)
+}
+
+var printer = message.NewPrinter(language.English)
+
+// collectViolations flattens the error tree into leaf violations. oneOf
+// failures are narrowed to the branch whose discriminating const matched
+// (a section's "type"), so an invalid text section reports its own missing
+// fields rather than one failure per section kind.
+func collectViolations(e *jsonschema.ValidationError, prefix string, out *[]Violation) {
+	if oneOf, ok := e.ErrorKind.(*kind.OneOf); ok && oneOf.Subschemas == nil {
+		collectOneOf(e, prefix, out)
+		return
+	}
+
+	if len(e.Causes) == 0 {
+		*out = append(*out, Violation{
+			Path:    prefix + instancePointer(e.InstanceLocation),
+			Message: e.ErrorKind.LocalizedString(printer),
+		})
+
+		return
+	}
+
+	for _, cause := range e.Causes {
+		collectViolations(cause, prefix, out)
+	}
+}
+
+func collectOneOf(e *jsonschema.ValidationError, prefix string, out *[]Violation) {
+	var matched []*jsonschema.ValidationError
+	var discriminator []string
+	var allowed []string
+
+	for _, branch := range e.Causes {
+		c := constFailure(branch, len(e.InstanceLocation)+1)
+		if c == nil {
+			matched = append(matched, branch)
+			continue
+		}
+
+		discriminator = c.InstanceLocation
+		allowed = append(allowed, fmt.Sprintf("%q", fmt.Sprint(c.ErrorKind.(*kind.Const).Want)))
+	}
+
+	if len(matched) == 1 {
+		collectViolations(matched[0], prefix, out)
+		return
+	}
+
+	if len(matched) == 0 && discriminator != nil {
+		*out = append(*out, Violation{
+			Path:    prefix + instancePointer(discriminator),
+			Message: "value must be one of " + strings.Join(allowed, ", "),
+		})
+
+		return
+	}
+
+	*out = append(*out, Violation{
+		Path:    prefix + instancePointer(e.InstanceLocation),
+		Message: e.ErrorKind.LocalizedString(printer),
+	})
+}
+
+// constFailure finds a const failure on a direct property of the oneOf
+// instance, which marks the branch as the wrong variant.
+func constFailure(e *jsonschema.ValidationError, depth int) *jsonschema.ValidationError {
+	if _, ok := e.ErrorKind.(*kind.Const); ok && len(e.InstanceLocation) == depth {
+		return e
+	}
+
+	for _, cause := range e.Causes {
+		if c := constFailure(cause, depth); c != nil {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// instancePointer renders an instance location as a JSON pointer.
+func instancePointer(location []string) string {
+	var b strings.Builder
+
+	for _, token := range location {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		b.WriteString("/")
+		b.WriteString(token)
+	}
+
+	return b.String()
+}
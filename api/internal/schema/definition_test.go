@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+func violationsOf(t *testing.T, err error) []Violation {
+	t.Helper()
+
+	var vErr *ViolationsError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ViolationsError, got %v", err)
+	}
+
+	return vErr.Violations
+}
+
+func TestValidateDefinitionAcceptsValidContent(t *testing.T) {
+	content := `{"sections":[
+		{"type":"text","body":"Hello"},
+		{"type":"code","language":"go","code":"fmt.Println()","explanation":"Prints"},
+		{"type":"callout","variant":"tip","body":"Remember"}
+	]}`
+
+	if err := ValidateDefinition(DefLessonContent, []byte(content)); err != nil {
+		t.Fatalf("valid content rejected: %v", err)
+	}
+}
+
+func TestValidateDefinitionReportsEveryViolation(t *testing.T) {
+	content := `{"sections":[
+		{"type":"video","url":"x"},
+		{"type":"text"},
+		{"type":"callout","variant":"nope","body":"b"}
+	]}`
+
+	got := violationsOf(t, ValidateDefinition(DefLessonContent, []byte(content)))
+
+	want := []string{"/sections/0/type", "/sections/1", "/sections/2/variant"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d violations, got %d: %+v", len(want), len(got), got)
+	}
+
+	for i, path := range want {
+		if got[i].Path != path {
+			t.Fatalf("violation %d: expected path %s, got %s (%s)", i, path, got[i].Path, got[i].Message)
+		}
+	}
+}
+
+func TestValidateDefinitionRejectsBareSectionArray(t *testing.T) {
+	got := violationsOf(t, ValidateDefinition(DefLessonContent, []byte(`[{"type":"text","body":"Hello"}]`)))
+
+	if len(got) != 1 || got[0].Path != "" {
+		t.Fatalf("expected one root violation, got %+v", got)
+	}
+}
+
+func TestValidateDefinitionListPrefixesItemIndex(t *testing.T) {
+	exercises := `[
+		{"type":"command","title":"T","instructions":"I","success_criteria":["ok"],"hints":[],"environment":"linux"},
+		{"type":"hands_on","title":"T","instructions":"I","success_criteria":["ok"],"hints":[],"environment":"linux"}
+	]`
+
+	got := violationsOf(t, ValidateDefinitionList(DefExercise, []byte(exercises)))
+
+	if len(got) != 1 || got[0].Path != "/1/type" {
+		t.Fatalf("expected violation at /1/type, got %+v", got)
+	}
+
+	if err := ValidateDefinitionList(DefReviewQuestion, []byte(`{}`)); err == nil {
+		t.Fatal("expected non-array to be rejected")
+	}
+}
+
+func TestValidateDefinitionAssessment(t *testing.T) {
+	valid := `{"questions":[{"type":"conceptual","question":"Q","answer":"A","concepts_tested":["c"]}]}`
+	if err := ValidateDefinition(DefAssessment, []byte(valid)); err != nil {
+		t.Fatalf("valid assessment rejected: %v", err)
+	}
+
+	got := violationsOf(t, ValidateDefinition(DefAssessment, []byte(`{"questions":[]}`)))
+	if len(got) != 1 || got[0].Path != "/questions" {
+		t.Fatalf("expected minItems violation at /questions, got %+v", got)
+	}
+}
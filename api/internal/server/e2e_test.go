@@ -1,11 +1,28 @@
 package server_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/maintenance"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/review"
+	"github.com/sean/apollo/api/internal/scheduler"
+	"github.com/sean/apollo/api/internal/server"
 )
 
 // e2eEnv holds the test server and provides HTTP helper methods.
@@ -38,11 +55,11 @@ func setupE2E(t *testing.T) *e2eEnv {
 	env.postJSON("/api/modules", `{"id":"mod-3","topic_id":"go-advanced","title":"Concurrency","sort_order":1,"description":"Goroutines and channels"}`)
 
 	// Lessons.
-	env.postJSON("/api/lessons", `{"id":"les-1","module_id":"mod-1","title":"Hello World","sort_order":1,"content":[{"type":"text","body":"Hello"}]}`)
-	env.postJSON("/api/lessons", `{"id":"les-2","module_id":"mod-1","title":"Variables","sort_order":2,"content":[{"type":"text","body":"Variables in Go"}]}`)
-	env.postJSON("/api/lessons", `{"id":"les-3","module_id":"mod-2","title":"Integers","sort_order":1,"content":[{"type":"text","body":"Integer types"}]}`)
-	env.postJSON("/api/lessons", `{"id":"les-4","module_id":"mod-2","title":"Strings","sort_order":2,"content":[{"type":"text","body":"String handling"}]}`)
-	env.postJSON("/api/lessons", `{"id":"les-5","module_id":"mod-3","title":"Goroutines","sort_order":1,"content":[{"type":"text","body":"Lightweight threads"}]}`)
+	env.postJSON("/api/lessons", `{"id":"les-1","module_id":"mod-1","title":"Hello World","sort_order":1,"content":{"sections":[{"type":"text","body":"Hello"}]}}`)
+	env.postJSON("/api/lessons", `{"id":"les-2","module_id":"mod-1","title":"Variables","sort_order":2,"content":{"sections":[{"type":"text","body":"Variables in Go"}]}}`)
+	env.postJSON("/api/lessons", `{"id":"les-3","module_id":"mod-2","title":"Integers","sort_order":1,"content":{"sections":[{"type":"text","body":"Integer types"}]}}`)
+	env.postJSON("/api/lessons", `{"id":"les-4","module_id":"mod-2","title":"Strings","sort_order":2,"content":{"sections":[{"type":"text","body":"String handling"}]}}`)
+	env.postJSON("/api/lessons", `{"id":"les-5","module_id":"mod-3","title":"Goroutines","sort_order":1,"content":{"sections":[{"type":"text","body":"Lightweight threads"}]}}`)
 
 	// Concepts.
 	env.postJSON("/api/concepts", `{"id":"con-1","name":"Variable","definition":"A named storage location","defined_in_topic":"go-basics","difficulty":"foundational"}`)
@@ -100,6 +117,31 @@ func (e *e2eEnv) putJSON(path, body string) *httptest.ResponseRecorder {
 	return rec
 }
 
+func (e *e2eEnv) do(method, path, body string) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (e *e2eEnv) patchJSON(path, ifMatch, body string) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+
+	return rec
+}
+
 func decodeMap(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
 	t.Helper()
 
@@ -401,12 +443,33 @@ func TestE2E_AC7_WriteAndReadBack(t *testing.T) {
 		t.Fatalf("expected title 'New Topic', got %v", result["title"])
 	}
 
-	// Update via PUT.
-	putRec := env.putJSON("/api/topics/new-topic", `{"title":"Updated Topic","status":"published"}`)
+	// Update via PUT, conditional on the revision read.
+	etag := rec.Header().Get("ETag")
+	body := `{"title":"Updated Topic","status":"published"}`
+
+	if putRec := env.putJSON("/api/topics/new-topic", body); putRec.Code != http.StatusPreconditionRequired {
+		t.Fatalf("expected 428 for update without If-Match, got %d", putRec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/topics/new-topic", strings.NewReader(body))
+	req.Header.Set("If-Match", etag)
+	putRec := httptest.NewRecorder()
+	env.router.ServeHTTP(putRec, req)
+
 	if putRec.Code != http.StatusOK {
 		t.Fatalf("expected 200 for update, got %d: %s", putRec.Code, putRec.Body.String())
 	}
 
+	// A second writer still holding the old revision gets 412.
+	req = httptest.NewRequest(http.MethodPut, "/api/topics/new-topic", strings.NewReader(body))
+	req.Header.Set("If-Match", etag)
+	staleRec := httptest.NewRecorder()
+	env.router.ServeHTTP(staleRec, req)
+
+	if staleRec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for stale update, got %d", staleRec.Code)
+	}
+
 	// Read back updated.
 	rec2 := env.get("/api/topics/new-topic")
 	result2 := decodeMap(t, rec2)
@@ -426,7 +489,7 @@ func TestE2E_AC7_WriteAndReadBack(t *testing.T) {
 
 	// FK violation: lesson with nonexistent module_id.
 	fkReq2 := httptest.NewRequest(http.MethodPost, "/api/lessons",
-		strings.NewReader(`{"id":"bad-les","module_id":"nonexistent","title":"Bad","sort_order":1,"content":[{"type":"text"}]}`))
+		strings.NewReader(`{"id":"bad-les","module_id":"nonexistent","title":"Bad","sort_order":1,"content":{"sections":[{"type":"text","body":"Hello"}]}}`))
 	fkRec2 := httptest.NewRecorder()
 	env.router.ServeHTTP(fkRec2, fkReq2)
 
@@ -538,3 +601,736 @@ func TestE2E_ConceptTopicFilter(t *testing.T) {
 		t.Fatalf("expected 3 concepts for go-basics, got %d", total)
 	}
 }
+
+func TestE2E_UpdateAndDeleteCurriculum(t *testing.T) {
+	env := setupE2E(t)
+
+	// PATCH keeps fields that are not in the body.
+	etag := env.get("/api/lessons/les-1").Header().Get("ETag")
+
+	rec := env.patchJSON("/api/lessons/les-1", etag, `{"title":"Hello, World"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH lesson: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	lesson := decodeMap(t, env.get("/api/lessons/les-1"))
+	if lesson["title"] != "Hello, World" || lesson["module_id"] != "mod-1" {
+		t.Fatalf("unexpected lesson after patch: %v", lesson)
+	}
+
+	before := decodeMap(t, env.get("/api/search?q=Hello"))
+	if items, _ := before["items"].([]any); len(items) != 1 {
+		t.Fatalf("expected patched lesson in search, got %v", before["items"])
+	}
+
+	// Deleting a lesson with progress conflicts unless forced.
+	if rec := env.putJSON("/api/progress/lessons/les-1", `{"status":"completed"}`); rec.Code != http.StatusOK {
+		t.Fatalf("update progress: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := env.do(http.MethodDelete, "/api/lessons/les-1", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("DELETE lesson with progress: expected 409, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodDelete, "/api/lessons/les-1?force=true", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("forced DELETE lesson: expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := env.get("/api/lessons/les-1"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected deleted lesson to 404, got %d", rec.Code)
+	}
+
+	results := decodeMap(t, env.get("/api/search?q=Hello"))
+	if items, _ := results["items"].([]any); len(items) != 0 {
+		t.Fatalf("expected deleted lesson to drop out of search, got %v", items)
+	}
+
+	// Link rows.
+	if rec := env.do(http.MethodPut, "/api/prerequisites/go-advanced/go-basics", `{"priority":"helpful"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT prerequisite: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := env.do(http.MethodDelete, "/api/relations/go-basics/go-advanced", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE relation: expected 204, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodDelete, "/api/concepts/con-2/references/les-3", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE concept reference: expected 204, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodDelete, "/api/modules/missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE missing module: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_MergePatchWithIfMatch(t *testing.T) {
+	env := setupE2E(t)
+
+	get := env.get("/api/topics/go-basics")
+	etag := get.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag on topic detail")
+	}
+
+	if rec := env.patchJSON("/api/topics/go-basics", "", `{"title":"Go"}`); rec.Code != http.StatusPreconditionRequired {
+		t.Fatalf("PATCH without If-Match: expected 428, got %d", rec.Code)
+	}
+
+	// The UI patches first; a script holding the same ETag then conflicts.
+	ui := env.patchJSON("/api/topics/go-basics", etag, `{"description":null,"tags":["go"]}`)
+	if ui.Code != http.StatusOK {
+		t.Fatalf("PATCH topic: expected 200, got %d: %s", ui.Code, ui.Body.String())
+	}
+
+	newETag := ui.Header().Get("ETag")
+	if newETag == "" || newETag == etag {
+		t.Fatalf("expected a new ETag after PATCH, got %q (was %q)", newETag, etag)
+	}
+
+	if rec := env.patchJSON("/api/topics/go-basics", etag, `{"title":"Clobbered"}`); rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale PATCH: expected 412, got %d", rec.Code)
+	}
+
+	topic := decodeMap(t, env.get("/api/topics/go-basics"))
+	if topic["title"] != "Go Basics" || topic["difficulty"] != "foundational" {
+		t.Fatalf("expected untouched fields to survive, got %v", topic)
+	}
+
+	if _, ok := topic["description"]; ok {
+		t.Fatalf("expected null to clear description, got %v", topic["description"])
+	}
+
+	if tags, _ := topic["tags"].([]any); len(tags) != 1 {
+		t.Fatalf("expected tags array to be replaced, got %v", topic["tags"])
+	}
+
+	if env.get("/api/topics/go-basics").Header().Get("ETag") != newETag {
+		t.Fatal("expected GET to return the ETag issued by PATCH")
+	}
+}
+
+func TestE2E_BatchWritesAtomically(t *testing.T) {
+	env := setupE2E(t)
+
+	failing := `{"operations":[
+		{"op":"create","entity":"topic","data":{"id":"rust","title":"Rust","status":"draft"}},
+		{"op":"create","entity":"module","data":{"id":"rust-1","topic_id":"rust","title":"Ownership","sort_order":1}},
+		{"op":"create","entity":"lesson","data":{"id":"rust-1-1","module_id":"no-such-module","title":"Borrowing","sort_order":1,"content":{"sections":[{"type":"text","body":"Body"}]}}}
+	]}`
+
+	rec := env.do(http.MethodPost, "/api/batch", failing)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("failing batch: expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if body := decodeMap(t, rec); body["index"] != float64(2) {
+		t.Fatalf("expected failing index 2, got %v", body["index"])
+	}
+
+	if rec := env.get("/api/topics/rust"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected rolled-back topic to 404, got %d", rec.Code)
+	}
+
+	ok := `{"operations":[
+		{"op":"create","entity":"topic","data":{"id":"rust","title":"Rust","status":"draft"}},
+		{"op":"create","entity":"module","data":{"id":"rust-1","topic_id":"rust","title":"Ownership","sort_order":1}},
+		{"op":"create","entity":"lesson","data":{"id":"rust-1-1","module_id":"rust-1","title":"Borrowing","sort_order":1,"content":{"sections":[{"type":"text","body":"Body"}]}}},
+		{"op":"create","entity":"concept","data":{"id":"borrow","name":"Borrow","definition":"A reference","defined_in_topic":"rust"}},
+		{"op":"create","entity":"concept_reference","id":"borrow","data":{"lesson_id":"rust-1-1"}},
+		{"op":"update","entity":"topic","id":"rust","data":{"title":"Rust Basics","status":"published"}}
+	]}`
+
+	rec = env.do(http.MethodPost, "/api/batch", ok)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("batch: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if results, _ := decodeMap(t, rec)["results"].([]any); len(results) != 6 {
+		t.Fatalf("expected 6 results, got %v", results)
+	}
+
+	topic := decodeMap(t, env.get("/api/topics/rust/full"))
+	if topic["title"] != "Rust Basics" {
+		t.Fatalf("expected updated title, got %v", topic["title"])
+	}
+
+	search := decodeMap(t, env.get("/api/search?q=Borrowing"))
+	if items, _ := search["items"].([]any); len(items) != 1 {
+		t.Fatalf("expected batch-created lesson in search, got %v", search["items"])
+	}
+}
+
+func TestE2E_ReorderAndMoveLessons(t *testing.T) {
+	env := setupE2E(t)
+
+	if rec := env.putJSON("/api/modules/mod-1/lesson-order", `{"lesson_ids":["les-2","les-1"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("reorder lessons: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	module := decodeMap(t, env.get("/api/modules/mod-1"))
+	lessons, _ := module["lessons"].([]any)
+	if len(lessons) != 2 || lessons[0].(map[string]any)["id"] != "les-2" {
+		t.Fatalf("expected les-2 first after reorder, got %v", lessons)
+	}
+
+	if rec := env.putJSON("/api/modules/mod-1/lesson-order", `{"lesson_ids":["les-2"]}`); rec.Code != http.StatusConflict {
+		t.Fatalf("partial order: expected 409, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/lessons/les-1/move", `{"module_id":"mod-2","position":1}`); rec.Code != http.StatusOK {
+		t.Fatalf("move lesson: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	lesson := decodeMap(t, env.get("/api/lessons/les-1"))
+	if lesson["module_id"] != "mod-2" || lesson["sort_order"] != float64(1) {
+		t.Fatalf("unexpected moved lesson: %v", lesson)
+	}
+}
+
+func TestE2E_ArchiveAndUnarchiveLesson(t *testing.T) {
+	env := setupE2E(t)
+
+	if rec := env.putJSON("/api/progress/lessons/les-1", `{"status":"completed"}`); rec.Code != http.StatusOK {
+		t.Fatalf("complete lesson: expected 200, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/lessons/les-1/archive", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("archive lesson: expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	module := decodeMap(t, env.get("/api/modules/mod-1"))
+	if lessons, _ := module["lessons"].([]any); len(lessons) != 1 {
+		t.Fatalf("expected archived lesson hidden, got %v", lessons)
+	}
+
+	module = decodeMap(t, env.get("/api/modules/mod-1?include_archived=true"))
+	if lessons, _ := module["lessons"].([]any); len(lessons) != 2 {
+		t.Fatalf("expected archived lesson with include_archived, got %v", lessons)
+	}
+
+	lesson := decodeMap(t, env.get("/api/lessons/les-1"))
+	if lesson["archived_at"] == nil {
+		t.Fatalf("expected archived_at on direct lesson read, got %v", lesson)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/lessons/les-1/unarchive", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("unarchive lesson: expected 204, got %d", rec.Code)
+	}
+
+	progress := decodeMap(t, env.get("/api/progress/topics/go-basics"))
+	lessons, _ := progress["lessons"].([]any)
+	if len(lessons) == 0 || lessons[0].(map[string]any)["status"] != "completed" {
+		t.Fatalf("expected progress kept across archive, got %v", lessons)
+	}
+}
+
+func TestE2E_ListTopicsByStatusAndSweeps(t *testing.T) {
+	env := setupE2E(t)
+
+	etag := env.get("/api/topics/go-advanced").Header().Get("ETag")
+	if rec := env.patchJSON("/api/topics/go-advanced", etag, `{"status":"outdated"}`); rec.Code != http.StatusOK {
+		t.Fatalf("mark outdated: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	topics := decodeSlice(t, env.get("/api/topics?status=outdated"))
+	if len(topics) != 1 || topics[0].(map[string]any)["id"] != "go-advanced" {
+		t.Fatalf("expected only go-advanced, got %v", topics)
+	}
+
+	sweeps := decodeMap(t, env.get("/api/staleness/sweeps"))
+	if sweeps["total"] != float64(0) {
+		t.Fatalf("expected no sweeps recorded yet, got %v", sweeps)
+	}
+}
+
+func TestE2E_AdminTriggerMaintenanceTask(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	handle, err := database.Open(context.Background(), filepath.Join(t.TempDir(), "test.db"), logger)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+
+	t.Cleanup(func() { _ = handle.Close() })
+
+	runs := repository.NewTaskRunRepository(handle.ReadDB, handle.DB)
+	sched := scheduler.New(runs, logger)
+
+	err = sched.Register(scheduler.Task{
+		Name: maintenance.TaskFTSOptimize,
+		Spec: "30 4 * * 0",
+		Run: func(ctx context.Context) (string, error) {
+			if err := repository.NewMaintenanceRepository(handle.ReadDB, handle.DB).OptimizeSearchIndex(ctx); err != nil {
+				return "", err
+			}
+
+			return "search index optimized", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	srv := server.New(handle, logger)
+	srv.SetTaskScheduler(sched)
+	env := &e2eEnv{t: t, router: srv.Router()}
+
+	tasks := decodeSlice(t, env.get("/api/admin/tasks"))
+	if len(tasks) != 1 || tasks[0].(map[string]any)["next_run"] == nil {
+		t.Fatalf("expected one scheduled task, got %v", tasks)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/admin/tasks/nope/run", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown task: expected 404, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/admin/tasks/fts-optimize/run", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("trigger: expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		list := decodeMap(t, env.get("/api/admin/tasks/fts-optimize/runs"))
+		items := list["items"].([]any)
+
+		if len(items) == 1 && items[0].(map[string]any)["status"] == "succeeded" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("task run did not succeed: %v", list)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestE2E_MergeConceptsResolvesAlias(t *testing.T) {
+	env := setupE2E(t)
+
+	rec := env.do(http.MethodPost, "/api/concepts/con-1/merge", `{"source_id":"con-2"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("merge: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	merge := decodeMap(t, rec)
+	if merge["references_moved"] != float64(1) {
+		t.Fatalf("expected one moved reference, got %v", merge)
+	}
+
+	rec = env.get("/api/concepts/con-2")
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/api/concepts/con-1" {
+		t.Fatalf("expected con-2 to redirect to con-1, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	refs := decodeSlice(t, env.get("/api/concepts/con-1/references"))
+	if len(refs) != 3 {
+		t.Fatalf("expected 3 references after merge, got %d", len(refs))
+	}
+
+	if rec := env.do(http.MethodPost, "/api/concepts/con-1/merge", `{"source_id":"con-2"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("repeat merge: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_ConceptConflictRoutes(t *testing.T) {
+	env := setupE2E(t)
+
+	rec := env.get("/api/concepts/conflicts")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list conflicts: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if conflicts := decodeSlice(t, rec); len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/concepts/con-1/resolve", `{"definition":"x"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("resolve active concept: expected 409, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/concepts/nope/resolve", `{"definition":"x"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("resolve unknown concept: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_TopicAliasLookup(t *testing.T) {
+	env := setupE2E(t)
+
+	etag := env.get("/api/topics/go-basics").Header().Get("ETag")
+	if rec := env.patchJSON("/api/topics/go-basics", etag, `{"aliases":["golang-basics"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("patch aliases: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := env.get("/api/topics/golang-basics")
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/api/topics/go-basics" {
+		t.Fatalf("alias: expected 301 to /api/topics/go-basics, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	if rec := env.get("/api/topics/Go_Basics/full"); rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/api/topics/go-basics/full" {
+		t.Fatalf("slug: expected 301 to /api/topics/go-basics/full, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	topic := decodeMap(t, env.get("/api/topics/go-basics"))
+	if aliases, _ := topic["aliases"].([]any); len(aliases) != 1 || aliases[0] != "golang-basics" {
+		t.Fatalf("expected aliases [golang-basics], got %v", topic["aliases"])
+	}
+}
+
+func TestE2E_HistoryAndRevert(t *testing.T) {
+	env := setupE2E(t)
+
+	etag := env.get("/api/topics/go-basics").Header().Get("ETag")
+	if rec := env.patchJSON("/api/topics/go-basics", etag, `{"title":"Go Basics, Revised"}`); rec.Code != http.StatusOK {
+		t.Fatalf("patch title: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := env.get("/api/history?entity_type=topic&entity_id=go-basics")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("history: expected 200, got %d", rec.Code)
+	}
+
+	history := decodeMap(t, rec)
+	items, _ := history["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("expected create and update records, got %v", history)
+	}
+
+	update, _ := items[0].(map[string]any)
+	if update["action"] != "update" || update["actor"] != "api" {
+		t.Fatalf("expected update by api, got %v", update)
+	}
+
+	id := int(update["id"].(float64))
+	rec = env.do(http.MethodPost, "/api/history/"+strconv.Itoa(id)+"/revert", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revert: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if topic := decodeMap(t, env.get("/api/topics/go-basics")); topic["title"] != "Go Basics" {
+		t.Fatalf("expected title restored, got %v", topic["title"])
+	}
+
+	if rec := env.do(http.MethodPost, "/api/history/"+strconv.Itoa(id)+"/revert", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("repeat revert: expected 204, got %d", rec.Code)
+	}
+}
+
+func TestE2E_ReviewFlow(t *testing.T) {
+	env := setupE2E(t)
+
+	rec := env.do(http.MethodPost, "/api/review/con-1", `{"rating":"good"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("review: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	state := decodeMap(t, rec)
+	if state["status"] != "reviewing" || state["interval_days"] != float64(1) || state["review_count"] != float64(1) {
+		t.Fatalf("unexpected state after first review: %v", state)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/con-2", `{"rating":"forgot"}`); rec.Code != http.StatusOK {
+		t.Fatalf("forgot review: expected 200, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/con-9", `{"rating":"good"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown concept: expected 404, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/con-1", `{"rating":"perfect"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad rating: expected 400, got %d", rec.Code)
+	}
+
+	// Both concepts are next due tomorrow, so nothing is due today.
+	if due := decodeSlice(t, env.get("/api/review/due")); len(due) != 0 {
+		t.Fatalf("expected no reviews due today, got %v", due)
+	}
+
+	stats := decodeMap(t, env.get("/api/review/stats"))
+	if stats["reviewed_today"] != float64(2) || stats["learning"] != float64(1) || stats["reviewing"] != float64(1) || stats["upcoming"] != float64(2) {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+}
+
+func TestE2E_LessonCompletionEnrollsConcepts(t *testing.T) {
+	env := setupE2E(t)
+
+	env.postJSON("/api/concepts", `{"id":"con-6","name":"Select","definition":"Waits on several channel operations",
+		"defined_in_topic":"go-advanced","defined_in_lesson":"les-5"}`)
+
+	rec := env.putJSON("/api/progress/lessons/les-5", `{"status":"completed"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("complete lesson: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if progress := decodeMap(t, rec); progress["enrolled"] != float64(1) {
+		t.Fatalf("expected one enrolled concept, got %v", progress)
+	}
+
+	// The first review is due tomorrow, so the concept is upcoming, not due.
+	stats := decodeMap(t, env.get("/api/review/stats"))
+	if stats["learning"] != float64(1) || stats["upcoming"] != float64(1) || stats["due_today"] != float64(0) {
+		t.Fatalf("unexpected stats after enrollment: %v", stats)
+	}
+}
+
+func TestE2E_ReviewSchedulerSwitch(t *testing.T) {
+	env := setupE2E(t)
+
+	if info := decodeMap(t, env.get("/api/review/scheduler")); info["scheduler"] != "sm2" {
+		t.Fatalf("expected sm2 by default, got %v", info)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/con-1", `{"rating":"good"}`); rec.Code != http.StatusOK {
+		t.Fatalf("review: expected 200, got %d", rec.Code)
+	}
+
+	rec := env.putJSON("/api/review/scheduler", `{"scheduler":"fsrs"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("switch: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if info := decodeMap(t, rec); info["scheduler"] != "fsrs" || info["rederived"] != float64(1) {
+		t.Fatalf("unexpected switch result: %v", info)
+	}
+
+	if rec := env.putJSON("/api/review/scheduler", `{"scheduler":"leitner"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown scheduler: expected 400, got %d", rec.Code)
+	}
+
+	rec = env.do(http.MethodPost, "/api/review/con-1", `{"rating":"good"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("review after switch: expected 200, got %d", rec.Code)
+	}
+
+	state := decodeMap(t, rec)
+	if state["scheduler"] != "fsrs" || state["review_count"] != float64(2) || state["stability"] == nil {
+		t.Fatalf("expected FSRS state, got %v", state)
+	}
+}
+
+func TestE2E_ReviewAnalytics(t *testing.T) {
+	env := setupE2E(t)
+
+	for _, body := range []string{`{"rating":"good","response_ms":2100}`, `{"rating":"forgot"}`} {
+		if rec := env.do(http.MethodPost, "/api/review/con-1", body); rec.Code != http.StatusOK {
+			t.Fatalf("review: expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	a := decodeMap(t, env.get("/api/review/analytics?days=7"))
+
+	// The first review is not a recall, so only the lapse counts.
+	if a["days"] != float64(7) || a["reviews"] != float64(2) || a["retention_rate"] != float64(0) {
+		t.Fatalf("unexpected summary: %v", a)
+	}
+
+	daily, _ := a["daily"].([]any)
+	forecast, _ := a["forecast"].([]any)
+	hardest, _ := a["hardest"].([]any)
+	topics, _ := a["topics"].([]any)
+
+	if len(daily) != 7 || len(forecast) != 7 || len(hardest) != 1 || len(topics) != 1 {
+		t.Fatalf("unexpected analytics: %v", a)
+	}
+}
+
+func TestE2E_ReviewSessionWithNothingDue(t *testing.T) {
+	env := setupE2E(t)
+
+	// Reviewed concepts are next due tomorrow, so today's session is empty.
+	if rec := env.do(http.MethodPost, "/api/review/con-1", `{"rating":"good"}`); rec.Code != http.StatusOK {
+		t.Fatalf("review: expected 200, got %d", rec.Code)
+	}
+
+	session := decodeMap(t, env.postJSON("/api/review/sessions", `{"active_topics_only":false}`))
+	if session["status"] != "completed" || session["total"] != float64(0) {
+		t.Fatalf("unexpected session: %v", session)
+	}
+
+	id, _ := session["id"].(string)
+
+	if rec := env.get("/api/review/sessions/" + id + "/next"); rec.Code != http.StatusNoContent {
+		t.Fatalf("next: expected 204, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/sessions/"+id+"/answer", `{"rating":"good"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("answer: expected 409, got %d", rec.Code)
+	}
+
+	if rec := env.get("/api/review/sessions/" + id); rec.Code != http.StatusOK {
+		t.Fatalf("get session: expected 200, got %d", rec.Code)
+	}
+
+	if rec := env.get("/api/review/sessions/missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown session: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_Leeches(t *testing.T) {
+	env := setupE2E(t)
+
+	// The default threshold suspends a concept on its eighth lapse.
+	for i := range review.DefaultLeechThreshold {
+		rec := env.do(http.MethodPost, "/api/review/con-1", `{"rating":"forgot"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("review %d: expected 200, got %d", i+1, rec.Code)
+		}
+
+		suspended := decodeMap(t, rec)["suspended_at"] != nil
+		if suspended != (i+1 == review.DefaultLeechThreshold) {
+			t.Fatalf("review %d: suspended = %v", i+1, suspended)
+		}
+	}
+
+	leeches := decodeSlice(t, env.get("/api/review/leeches"))
+	if len(leeches) != 1 {
+		t.Fatalf("expected 1 leech, got %d", len(leeches))
+	}
+
+	leech, _ := leeches[0].(map[string]any)
+	if leech["concept_id"] != "con-1" || leech["lapses"] != float64(review.DefaultLeechThreshold) || leech["topic_title"] != "Go Basics" {
+		t.Fatalf("unexpected leech: %v", leech)
+	}
+
+	if due := decodeSlice(t, env.get("/api/review/due")); len(due) != 0 {
+		t.Fatalf("expected the leech out of the queue, got %d due", len(due))
+	}
+
+	job := decodeMap(t, env.postJSON("/api/review/leeches/con-1/regenerate", ""))
+	if job["kind"] != "flashcard" || job["concept_id"] != "con-1" || job["status"] != "queued" {
+		t.Fatalf("unexpected job: %v", job)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/leeches/con-1/regenerate", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("second regenerate: expected 409, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/leeches/con-1/proposal/approve", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("approve without proposal: expected 404, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/leeches/con-1/unsuspend", ""); rec.Code != http.StatusOK {
+		t.Fatalf("unsuspend: expected 200, got %d", rec.Code)
+	}
+
+	if due := decodeSlice(t, env.get("/api/review/due")); len(due) != 1 {
+		t.Fatalf("expected the concept due again, got %d due", len(due))
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/leeches/con-1/unsuspend", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unsuspend twice: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_QuestionCards(t *testing.T) {
+	env := setupE2E(t)
+
+	env.postJSON("/api/lessons", `{"id":"les-6","module_id":"mod-3","title":"Select","sort_order":2,
+		"content":{"sections":[{"type":"text","body":"Select"}]},
+		"review_questions":[{"question":"What does select wait on?","answer":"Several channel operations","concepts_tested":["con-6"]}]}`)
+	env.postJSON("/api/concepts", `{"id":"con-6","name":"Select","definition":"Waits on several channel operations",
+		"defined_in_topic":"go-advanced","defined_in_lesson":"les-6"}`)
+
+	rec := env.putJSON("/api/progress/lessons/les-6", `{"status":"completed"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("complete lesson: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if progress := decodeMap(t, rec); progress["enrolled"] != float64(1) || progress["enrolled_questions"] != float64(1) {
+		t.Fatalf("expected one concept and one question enrolled, got %v", progress)
+	}
+
+	// The concept and its question are both first due tomorrow.
+	stats := decodeMap(t, env.get("/api/review/stats"))
+	if stats["upcoming"] != float64(2) || stats["due_today"] != float64(0) {
+		t.Fatalf("unexpected stats after enrollment: %v", stats)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/questions/q-unknown", `{"rating":"good"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown question: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_GeneratedCards(t *testing.T) {
+	env := setupE2E(t)
+
+	rec := env.putJSON("/api/topics/go-advanced/card-kinds", `{"kinds":["reverse","topic"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set card kinds: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A reverse and a topic card for each of the topic's two concepts.
+	if kinds := decodeMap(t, rec); kinds["cards"] != float64(4) {
+		t.Fatalf("expected 4 generated cards, got %v", kinds)
+	}
+
+	if kinds := decodeMap(t, env.get("/api/topics/go-advanced/card-kinds")); len(kinds["kinds"].([]any)) != 2 {
+		t.Fatalf("expected 2 enabled kinds, got %v", kinds)
+	}
+
+	if rec := env.putJSON("/api/topics/go-advanced/card-kinds", `{"kinds":["flashcard"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown kind: expected 400, got %d", rec.Code)
+	}
+
+	if rec := env.get("/api/topics/missing/card-kinds"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown topic: expected 404, got %d", rec.Code)
+	}
+
+	if rec := env.do(http.MethodPost, "/api/review/cards/g-unknown", `{"rating":"good"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown card: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_AnkiExport(t *testing.T) {
+	env := setupE2E(t)
+
+	rec := env.get("/api/topics/go-advanced/anki.apkg")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export topic deck: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if _, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len())); err != nil {
+		t.Fatalf("topic deck is not a zip: %v", err)
+	}
+
+	// A header row and one row for each of the topic's two concepts.
+	rec = env.get("/api/export/flashcards.csv?topic=go-advanced")
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if rec.Code != http.StatusOK || err != nil || len(records) != 3 {
+		t.Fatalf("export CSV: expected 3 rows, got %d with %d rows: %v", rec.Code, len(records), err)
+	}
+
+	if rec := env.get("/api/topics/missing/anki.apkg"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown topic: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestE2E_AnkiImport(t *testing.T) {
+	env := setupE2E(t)
+
+	deck := env.get("/api/topics/go-advanced/anki.apkg").Body.String()
+
+	// An exported deck has no reviews yet, but each of its notes names a
+	// concept by GUID.
+	rec := env.do(http.MethodPost, "/api/anki/import?dry_run=true", deck)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import deck: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	report := decodeMap(t, rec)
+
+	matched, _ := report["matched"].([]any)
+	if report["dry_run"] != true || report["cards"] != float64(2) || len(matched) != 2 {
+		t.Fatalf("import report: %v", report)
+	}
+
+	if m, _ := matched[0].(map[string]any); m["matched_by"] != "guid" {
+		t.Fatalf("import matched %v, want by guid", matched[0])
+	}
+
+	if rec := env.do(http.MethodPost, "/api/anki/import", "not a package"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid package: expected 400, got %d", rec.Code)
+	}
+}
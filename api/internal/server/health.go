@@ -12,7 +12,12 @@ type healthResponse struct {
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	if err := database.HealthCheck(r.Context(), s.db.DB); err != nil {
+	err := database.HealthCheck(r.Context(), s.db.DB)
+	if err == nil {
+		err = database.HealthCheck(r.Context(), s.db.ReadDB)
+	}
+
+	if err != nil {
 		s.logger.Error().Err(err).Msg("health check failed")
 		w.WriteHeader(http.StatusServiceUnavailable)
 		_ = json.NewEncoder(w).Encode(healthResponse{Status: "error"})
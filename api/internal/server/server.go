@@ -2,15 +2,20 @@ package server
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
 
+	"github.com/sean/apollo/api/internal/audit"
 	"github.com/sean/apollo/api/internal/database"
 	"github.com/sean/apollo/api/internal/handler"
+	"github.com/sean/apollo/api/internal/models"
 	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/respond"
+	"github.com/sean/apollo/api/internal/review"
 )
 
 // Server holds dependencies for the HTTP API.
@@ -18,13 +23,27 @@ type Server struct {
 	db               *database.Handle
 	logger           zerolog.Logger
 	cancelResearchFn handler.CancelFunc
+	taskScheduler    handler.TaskScheduler
+	apiTokens        map[string]string
+	masteryDays      int
+	reviewScheduler  string
+	enrollment       models.EnrollmentOptions
+	sessionLimits    models.SessionLimits
+	leechThreshold   int
 }
 
 // New creates a Server with the given dependencies.
 func New(db *database.Handle, logger zerolog.Logger) *Server {
 	return &Server{
-		db:     db,
-		logger: logger,
+		db:              db,
+		logger:          logger,
+		masteryDays:     review.DefaultMasteryDays,
+		reviewScheduler: review.NameSM2,
+		sessionLimits: models.SessionLimits{
+			NewPerDay:     review.DefaultNewPerDay,
+			ReviewsPerDay: review.DefaultReviewsPerDay,
+		},
+		leechThreshold: review.DefaultLeechThreshold,
 	}
 }
 
@@ -34,46 +53,126 @@ func (s *Server) SetCancelResearchFunc(fn handler.CancelFunc) {
 	s.cancelResearchFn = fn
 }
 
+// SetTaskScheduler sets the scheduler behind the admin task endpoints.
+// Called during startup once maintenance tasks are registered.
+func (s *Server) SetTaskScheduler(sched handler.TaskScheduler) {
+	s.taskScheduler = sched
+}
+
+// SetAPITokens sets the bearer tokens that identify callers, keyed by token
+// with the caller's name as the value. The name is recorded as the actor of
+// every change made with that token.
+func (s *Server) SetAPITokens(tokens map[string]string) {
+	s.apiTokens = tokens
+}
+
+// SetMasteryThreshold sets the review interval, in days, beyond which a
+// concept counts as mastered. Zero keeps the default.
+func (s *Server) SetMasteryThreshold(days int) {
+	if days > 0 {
+		s.masteryDays = days
+	}
+}
+
+// SetReviewScheduler sets the scheduler reviews use until one is chosen
+// through the API.
+func (s *Server) SetReviewScheduler(name string) {
+	s.reviewScheduler = name
+}
+
+// SetEnrollment sets which concepts lesson progress enrolls in and drops
+// from review.
+func (s *Server) SetEnrollment(opts models.EnrollmentOptions) {
+	s.enrollment = opts
+}
+
+// SetSessionLimits sets the daily caps on new cards and reviews that review
+// sessions hand out.
+func (s *Server) SetSessionLimits(limits models.SessionLimits) {
+	s.sessionLimits = limits
+}
+
+// SetLeechThreshold sets the lapses after which a review suspends a
+// concept as a leech. Zero never suspends.
+func (s *Server) SetLeechThreshold(lapses int) {
+	s.leechThreshold = lapses
+}
+
 // Router builds and returns the configured chi router with all middleware and routes.
 func (s *Server) Router() chi.Router {
 	r := chi.NewRouter()
 
 	r.Use(middleware.Recoverer)
 	r.Use(s.requestLogger)
+	r.Use(s.identifyActor)
 	r.Use(middleware.SetHeader("Content-Type", "application/json"))
 
 	r.Get("/api/health", s.handleHealth)
 
-	topicHandler := handler.NewTopicHandler(repository.NewTopicRepository(s.db.DB))
+	// Read-only repositories use the read pool; anything that writes uses the
+	// single-connection write handle so writers stay serialized.
+
+	topicHandler := handler.NewTopicHandler(repository.NewTopicRepository(s.db.ReadDB))
 	topicHandler.RegisterRoutes(r)
 
-	moduleHandler := handler.NewModuleHandler(repository.NewModuleRepository(s.db.DB))
+	moduleHandler := handler.NewModuleHandler(repository.NewModuleRepository(s.db.ReadDB))
 	moduleHandler.RegisterRoutes(r)
 
-	lessonHandler := handler.NewLessonHandler(repository.NewLessonRepository(s.db.DB))
+	lessonHandler := handler.NewLessonHandler(repository.NewLessonRepository(s.db.ReadDB))
 	lessonHandler.RegisterRoutes(r)
 
-	conceptHandler := handler.NewConceptHandler(repository.NewConceptRepository(s.db.DB))
+	conceptHandler := handler.NewConceptHandler(repository.NewConceptRepository(s.db.ReadDB))
 	conceptHandler.RegisterRoutes(r)
 
 	writeHandler := handler.NewWriteHandler(repository.NewWriteRepository(s.db.DB))
 	writeHandler.RegisterRoutes(r)
 
-	searchHandler := handler.NewSearchHandler(repository.NewSearchRepository(s.db.DB))
+	conflictHandler := handler.NewConflictHandler(repository.NewConceptConflictRepository(s.db.ReadDB, s.db.DB))
+	conflictHandler.RegisterRoutes(r)
+
+	historyHandler := handler.NewHistoryHandler(repository.NewAuditRepository(s.db.ReadDB, s.db.DB))
+	historyHandler.RegisterRoutes(r)
+
+	reviewHandler := handler.NewReviewHandler(
+		repository.NewReviewRepository(s.db.ReadDB, s.db.DB).WithLeechThreshold(s.leechThreshold),
+		s.reviewScheduler, s.masteryDays,
+	).WithSessionLimits(s.sessionLimits)
+	reviewHandler.RegisterRoutes(r)
+
+	leechHandler := handler.NewLeechHandler(repository.NewLeechRepository(s.db.ReadDB, s.db.DB))
+	leechHandler.RegisterRoutes(r)
+
+	ankiHandler := handler.NewAnkiHandler(
+		repository.NewAnkiRepository(s.db.ReadDB, s.db.DB), s.reviewScheduler, s.masteryDays,
+	)
+	ankiHandler.RegisterRoutes(r)
+
+	searchHandler := handler.NewSearchHandler(repository.NewSearchRepository(s.db.ReadDB))
 	searchHandler.RegisterRoutes(r)
 
-	graphHandler := handler.NewGraphHandler(repository.NewGraphRepository(s.db.DB))
+	graphHandler := handler.NewGraphHandler(repository.NewGraphRepository(s.db.ReadDB))
 	graphHandler.RegisterRoutes(r)
 
-	progressHandler := handler.NewProgressHandler(repository.NewProgressRepository(s.db.DB))
+	progressHandler := handler.NewProgressHandler(
+		repository.NewProgressRepository(s.db.ReadDB, s.db.DB).WithEnrollment(s.enrollment),
+	)
 	progressHandler.RegisterRoutes(r)
 
 	researchHandler := handler.NewResearchHandler(
-		repository.NewResearchJobRepository(s.db.DB),
+		repository.NewResearchJobRepository(s.db.ReadDB, s.db.DB),
 		s.cancelResearchFn,
 	)
 	researchHandler.RegisterRoutes(r)
 
+	stalenessHandler := handler.NewStalenessHandler(repository.NewStalenessRepository(s.db.ReadDB, s.db.DB))
+	stalenessHandler.RegisterRoutes(r)
+
+	adminHandler := handler.NewAdminHandler(
+		s.taskScheduler,
+		repository.NewTaskRunRepository(s.db.ReadDB, s.db.DB),
+	)
+	adminHandler.RegisterRoutes(r)
+
 	return r
 }
 
@@ -94,3 +193,27 @@ func (s *Server) requestLogger(next http.Handler) http.Handler {
 			Msg("request completed")
 	})
 }
+
+// identifyActor attributes the request's changes to the name of its bearer
+// token, or to audit.ActorAPI when it has none. An unknown token is refused
+// rather than silently recorded as anonymous.
+func (s *Server) identifyActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := audit.ActorAPI
+
+		if header := r.Header.Get("Authorization"); header != "" {
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			name, known := s.apiTokens[token]
+
+			if !ok || !known {
+				respond.Error(w, http.StatusUnauthorized, "unknown API token")
+
+				return
+			}
+
+			actor = name
+		}
+
+		next.ServeHTTP(w, r.WithContext(audit.WithActor(r.Context(), actor)))
+	})
+}
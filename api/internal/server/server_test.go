@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -135,3 +136,49 @@ func TestHealthEndpointWithClosedDB(t *testing.T) {
 		t.Fatalf("expected status 'error', got %q", resp["status"])
 	}
 }
+
+func TestAPITokenNamesActor(t *testing.T) {
+	srv := setupTestServer(t)
+	srv.SetAPITokens(map[string]string{"s3cret": "alice"})
+	router := srv.Router()
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	if rec := send(http.MethodPost, "/api/topics", "s3cret", `{"id":"t1","title":"T1","status":"draft"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create with token: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := send(http.MethodPost, "/api/topics", "", `{"id":"t2","title":"T2","status":"draft"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create without token: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := send(http.MethodPost, "/api/topics", "wrong", `{"id":"t3","title":"T3","status":"draft"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown token: expected 401, got %d", rec.Code)
+	}
+
+	var page struct {
+		Items []struct {
+			EntityID string `json:"entity_id"`
+			Actor    string `json:"actor"`
+		} `json:"items"`
+	}
+
+	rec := send(http.MethodGet, "/api/history?entity_type=topic", "", "")
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+
+	if len(page.Items) != 2 || page.Items[0].Actor != "api" || page.Items[1].Actor != "alice" {
+		t.Fatalf("unexpected history %+v", page.Items)
+	}
+}
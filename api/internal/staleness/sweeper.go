@@ -0,0 +1,119 @@
+// Package staleness flags published curricula that have aged past the
+// configured threshold and optionally queues research jobs to refresh them.
+package staleness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+)
+
+// Sweeper marks stale topics outdated and records each sweep. The scheduler
+// runs Sweep on the SCHEDULE_STALENESS_SWEEP spec.
+type Sweeper struct {
+	repo         repository.StalenessRepository
+	jobs         repository.ResearchJobRepository
+	logger       zerolog.Logger
+	staleDays    int
+	refreshLimit int
+	now          func() time.Time
+}
+
+// NewSweeper creates a Sweeper from the staleness settings in cfg.
+func NewSweeper(
+	repo repository.StalenessRepository,
+	jobs repository.ResearchJobRepository,
+	logger zerolog.Logger,
+	cfg config.Config,
+) *Sweeper {
+	return &Sweeper{
+		repo:         repo,
+		jobs:         jobs,
+		logger:       logger,
+		staleDays:    cfg.CurriculumStale,
+		refreshLimit: cfg.StaleRefreshLimit,
+		now:          time.Now,
+	}
+}
+
+// Sweep flags published topics generated more than staleDays ago as outdated,
+// queues refresh jobs up to the remaining daily limit, and records the run.
+// A failed sweep is still recorded, with its error.
+func (s *Sweeper) Sweep(ctx context.Context) (*models.StalenessSweep, error) {
+	started := s.now().UTC()
+	sweep := &models.StalenessSweep{
+		StartedAt:     started.Format(time.RFC3339),
+		ThresholdDays: s.staleDays,
+	}
+
+	runErr := s.run(ctx, started, sweep)
+	if runErr != nil {
+		sweep.Error = runErr.Error()
+	}
+
+	sweep.CompletedAt = s.now().UTC().Format(time.RFC3339)
+
+	if err := s.repo.RecordSweep(ctx, sweep); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Int("topics_flagged", sweep.TopicsFlagged).
+		Int("jobs_queued", sweep.JobsQueued).
+		Msg("staleness sweep completed")
+
+	return sweep, runErr
+}
+
+func (s *Sweeper) run(ctx context.Context, started time.Time, sweep *models.StalenessSweep) error {
+	cutoff := started.AddDate(0, 0, -s.staleDays)
+
+	flagged, err := s.repo.MarkOutdatedTopics(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	sweep.TopicsFlagged = flagged
+
+	if s.refreshLimit <= 0 {
+		return nil
+	}
+
+	dayStart := time.Date(started.Year(), started.Month(), started.Day(), 0, 0, 0, 0, time.UTC)
+
+	queuedToday, err := s.repo.CountRefreshesSince(ctx, dayStart)
+	if err != nil {
+		return err
+	}
+
+	remaining := s.refreshLimit - queuedToday
+	if remaining <= 0 {
+		return nil
+	}
+
+	candidates, err := s.repo.ListRefreshCandidates(ctx, remaining)
+	if err != nil {
+		return err
+	}
+
+	for _, topic := range candidates {
+		input := models.CreateResearchJobInput{
+			Topic: topic.Title,
+			Brief: fmt.Sprintf("Refresh the outdated curriculum for topic %s.", topic.ID),
+		}
+
+		if _, err := s.jobs.CreateJob(ctx, input); err != nil {
+			return fmt.Errorf("queue refresh for topic %s: %w", topic.ID, err)
+		}
+
+		sweep.JobsQueued++
+	}
+
+	return nil
+}
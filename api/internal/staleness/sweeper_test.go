@@ -0,0 +1,135 @@
+package staleness_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sean/apollo/api/internal/config"
+	"github.com/sean/apollo/api/internal/database"
+	"github.com/sean/apollo/api/internal/models"
+	"github.com/sean/apollo/api/internal/repository"
+	"github.com/sean/apollo/api/internal/staleness"
+)
+
+func setupSweeper(t *testing.T, refreshLimit int) (*staleness.Sweeper, *sql.DB) {
+	t.Helper()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	handle, err := database.Open(context.Background(), filepath.Join(t.TempDir(), "test.db"), logger)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+
+	t.Cleanup(func() { _ = handle.Close() })
+
+	cfg := config.Config{CurriculumStale: 180, StaleRefreshLimit: refreshLimit}
+	sweeper := staleness.NewSweeper(
+		repository.NewStalenessRepository(handle.ReadDB, handle.DB),
+		repository.NewResearchJobRepository(handle.ReadDB, handle.DB),
+		logger, cfg,
+	)
+
+	return sweeper, handle.DB
+}
+
+func seedGeneratedTopic(t *testing.T, db *sql.DB, id, status string, age time.Duration) {
+	t.Helper()
+
+	generatedAt := time.Now().UTC().Add(-age).Format(time.RFC3339)
+	if _, err := db.Exec(`INSERT INTO topics (id, title, status, generated_at) VALUES (?, ?, ?, ?)`, id, "Title "+id, status, generatedAt); err != nil {
+		t.Fatalf("seed topic %s: %v", id, err)
+	}
+}
+
+func topicStatus(t *testing.T, db *sql.DB, id string) string {
+	t.Helper()
+
+	var status string
+	if err := db.QueryRow(`SELECT status FROM topics WHERE id = ?`, id).Scan(&status); err != nil {
+		t.Fatalf("read topic %s status: %v", id, err)
+	}
+
+	return status
+}
+
+const day = 24 * time.Hour
+
+func TestSweepFlagsOnlyStalePublishedTopics(t *testing.T) {
+	sweeper, db := setupSweeper(t, 0)
+	seedGeneratedTopic(t, db, "old", models.TopicStatusPublished, 200*day)
+	seedGeneratedTopic(t, db, "fresh", models.TopicStatusPublished, 10*day)
+	seedGeneratedTopic(t, db, "old-draft", models.TopicStatusDraft, 400*day)
+
+	sweep, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+
+	if sweep.ID == 0 || sweep.TopicsFlagged != 1 || sweep.JobsQueued != 0 || sweep.ThresholdDays != 180 {
+		t.Fatalf("unexpected sweep record %+v", sweep)
+	}
+
+	want := map[string]string{
+		"old":       models.TopicStatusOutdated,
+		"fresh":     models.TopicStatusPublished,
+		"old-draft": models.TopicStatusDraft,
+	}
+	for id, status := range want {
+		if got := topicStatus(t, db, id); got != status {
+			t.Fatalf("topic %s: expected %s, got %s", id, status, got)
+		}
+	}
+}
+
+func TestSweepQueuesRefreshJobsUpToDailyLimit(t *testing.T) {
+	sweeper, db := setupSweeper(t, 2)
+	ctx := context.Background()
+	seedGeneratedTopic(t, db, "a", models.TopicStatusPublished, 300*day)
+	seedGeneratedTopic(t, db, "b", models.TopicStatusPublished, 250*day)
+	seedGeneratedTopic(t, db, "c", models.TopicStatusPublished, 200*day)
+
+	first, err := sweeper.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("first sweep: %v", err)
+	}
+
+	if first.TopicsFlagged != 3 || first.JobsQueued != 2 {
+		t.Fatalf("expected 3 flagged and 2 queued, got %+v", first)
+	}
+
+	var roots []string
+	rows, err := db.Query(`SELECT root_topic FROM research_jobs ORDER BY rowid`)
+	if err != nil {
+		t.Fatalf("query jobs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var root string
+		if err := rows.Scan(&root); err != nil {
+			t.Fatalf("scan job: %v", err)
+		}
+
+		roots = append(roots, root)
+	}
+
+	if len(roots) != 2 || roots[0] != "Title a" || roots[1] != "Title b" {
+		t.Fatalf("expected refreshes for the two oldest topics, got %v", roots)
+	}
+
+	second, err := sweeper.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("second sweep: %v", err)
+	}
+
+	if second.JobsQueued != 0 {
+		t.Fatalf("expected daily limit to stop further refreshes, got %d", second.JobsQueued)
+	}
+}
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS search_index;
+DROP TABLE IF EXISTS concept_retention;
+DROP TABLE IF EXISTS learning_progress;
+DROP TABLE IF EXISTS research_jobs;
+DROP TABLE IF EXISTS expansion_queue;
+DROP TABLE IF EXISTS topic_relations;
+DROP TABLE IF EXISTS topic_prerequisites;
+DROP TABLE IF EXISTS concept_references;
+DROP TABLE IF EXISTS concepts;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS modules;
+DROP TABLE IF EXISTS topics;
@@ -0,0 +1,4 @@
+ALTER TABLE concepts DROP COLUMN revision;
+ALTER TABLE lessons DROP COLUMN revision;
+ALTER TABLE modules DROP COLUMN revision;
+ALTER TABLE topics DROP COLUMN revision;
@@ -0,0 +1,6 @@
+-- Row revisions back the ETag / If-Match optimistic concurrency on PATCH.
+-- Every update to one of these rows increments its revision.
+ALTER TABLE topics ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE modules ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE lessons ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE concepts ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
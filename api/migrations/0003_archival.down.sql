@@ -0,0 +1,2 @@
+ALTER TABLE lessons DROP COLUMN archived_at;
+ALTER TABLE modules DROP COLUMN archived_at;
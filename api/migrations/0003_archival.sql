@@ -0,0 +1,4 @@
+-- Archived modules and lessons are hidden from topic trees, search, and
+-- progress totals but keep their progress rows and concept references.
+ALTER TABLE modules ADD COLUMN archived_at TEXT;
+ALTER TABLE lessons ADD COLUMN archived_at TEXT;
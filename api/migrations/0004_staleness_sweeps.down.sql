@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_staleness_sweeps_started_at;
+DROP TABLE IF EXISTS staleness_sweeps;
@@ -0,0 +1,13 @@
+-- One row per staleness sweep, so the dashboard can show when curricula were
+-- last checked and the sweeper can enforce its daily refresh-job limit.
+CREATE TABLE IF NOT EXISTS staleness_sweeps (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  started_at TEXT NOT NULL,
+  completed_at TEXT NOT NULL,
+  threshold_days INTEGER NOT NULL,
+  topics_flagged INTEGER NOT NULL DEFAULT 0,
+  jobs_queued INTEGER NOT NULL DEFAULT 0,
+  error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_staleness_sweeps_started_at ON staleness_sweeps(started_at);
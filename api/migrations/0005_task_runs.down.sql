@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_runs_task;
+DROP TABLE IF EXISTS task_runs;
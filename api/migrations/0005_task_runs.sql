@@ -0,0 +1,14 @@
+-- Run history for the maintenance scheduler. One row per scheduled or
+-- manual run, including runs skipped because the task was still running.
+CREATE TABLE IF NOT EXISTS task_runs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  task TEXT NOT NULL,
+  trigger TEXT NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+  status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed', 'skipped')),
+  started_at TEXT NOT NULL,
+  finished_at TEXT,
+  summary TEXT,
+  error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_runs_task ON task_runs(task, id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_concept_candidates_concept_id;
+DROP TABLE IF EXISTS concept_candidates;
@@ -0,0 +1,16 @@
+-- Competing definitions for a concept taught by more than one curriculum.
+-- A concept with candidates has status 'conflict' until it is resolved; the
+-- candidates are removed on resolution.
+CREATE TABLE IF NOT EXISTS concept_candidates (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  concept_id TEXT NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  definition TEXT NOT NULL,
+  flashcard_front TEXT,
+  flashcard_back TEXT,
+  source_lesson TEXT REFERENCES lessons(id) ON DELETE SET NULL,
+  source_topic TEXT REFERENCES topics(id) ON DELETE SET NULL,
+  created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_concept_candidates_concept_id ON concept_candidates(concept_id);
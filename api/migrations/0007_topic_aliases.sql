@@ -0,0 +1,3 @@
+-- Alternative IDs a topic answers to, such as the slugs research agents use
+-- for it in prerequisites. Same JSON array shape as concepts.aliases.
+ALTER TABLE topics ADD COLUMN aliases TEXT CHECK (aliases IS NULL OR json_valid(aliases));
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS settings;
+DROP INDEX IF EXISTS idx_review_log_concept;
+DROP TABLE IF EXISTS review_log;
+ALTER TABLE concept_retention DROP COLUMN scheduler;
+ALTER TABLE concept_retention DROP COLUMN difficulty;
+ALTER TABLE concept_retention DROP COLUMN stability;
@@ -0,0 +1,22 @@
+-- Review state for pluggable schedulers. scheduler names the algorithm whose
+-- state the row holds: SM-2 uses ease_factor, FSRS uses stability and
+-- difficulty. review_log keeps every rating so state can be re-derived when
+-- the scheduler changes. settings holds runtime preferences by key.
+ALTER TABLE concept_retention ADD COLUMN stability REAL;
+ALTER TABLE concept_retention ADD COLUMN difficulty REAL;
+ALTER TABLE concept_retention ADD COLUMN scheduler TEXT NOT NULL DEFAULT 'sm2';
+
+CREATE TABLE IF NOT EXISTS review_log (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  concept_id TEXT NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+  rating TEXT NOT NULL CHECK (rating IN ('forgot', 'hard', 'good', 'easy')),
+  reviewed_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_log_concept ON review_log(concept_id, id);
+
+CREATE TABLE IF NOT EXISTS settings (
+  key TEXT PRIMARY KEY,
+  value TEXT NOT NULL,
+  updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_review_log_reviewed_at;
+ALTER TABLE review_log DROP COLUMN response_ms;
+ALTER TABLE review_log DROP COLUMN ease_factor;
+ALTER TABLE review_log DROP COLUMN new_interval;
+ALTER TABLE review_log DROP COLUMN previous_interval;
@@ -0,0 +1,11 @@
+-- Per-review detail for analytics. previous_interval is the interval the
+-- concept had when reviewed, 0 on its first review; new_interval and
+-- ease_factor are the state the review produced. The columns are NULL for
+-- reviews logged before this migration, and response_ms whenever the
+-- client did not time the answer.
+ALTER TABLE review_log ADD COLUMN previous_interval INTEGER;
+ALTER TABLE review_log ADD COLUMN new_interval INTEGER;
+ALTER TABLE review_log ADD COLUMN ease_factor REAL;
+ALTER TABLE review_log ADD COLUMN response_ms INTEGER CHECK (response_ms IS NULL OR response_ms >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_review_log_reviewed_at ON review_log(reviewed_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_review_sessions_status;
+DROP TABLE IF EXISTS review_session_cards;
+DROP TABLE IF EXISTS review_sessions;
@@ -0,0 +1,24 @@
+-- Server-side review sessions. A session fixes its cards and their order
+-- when it starts; each card records the rating it was given and the state
+-- the review produced. Starting a session abandons the active one.
+CREATE TABLE IF NOT EXISTS review_sessions (
+  id TEXT PRIMARY KEY,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'abandoned')),
+  active_topics_only INTEGER NOT NULL DEFAULT 0,
+  started_at TEXT NOT NULL,
+  finished_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS review_session_cards (
+  session_id TEXT NOT NULL REFERENCES review_sessions(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  concept_id TEXT NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+  is_new INTEGER NOT NULL DEFAULT 0,
+  rating TEXT CHECK (rating IS NULL OR rating IN ('forgot', 'hard', 'good', 'easy')),
+  next_review TEXT,
+  interval_days INTEGER,
+  answered_at TEXT,
+  PRIMARY KEY (session_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_sessions_status ON review_sessions(status);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_research_jobs_concept;
+DROP INDEX IF EXISTS idx_card_proposals_concept;
+DROP TABLE IF EXISTS card_proposals;
+ALTER TABLE research_jobs DROP COLUMN concept_id;
+ALTER TABLE research_jobs DROP COLUMN kind;
+ALTER TABLE concept_retention DROP COLUMN suspended_at;
+ALTER TABLE concept_retention DROP COLUMN lapses;
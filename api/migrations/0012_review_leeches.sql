@@ -0,0 +1,33 @@
+-- Leech detection. lapses counts the times a concept was forgotten since
+-- its state was last reset; suspended_at is set when lapses reach the leech
+-- threshold and keeps the concept out of the review queue until it is
+-- unsuspended or its card is rewritten. Existing rows take their lapses
+-- from review_log.
+ALTER TABLE concept_retention ADD COLUMN lapses INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE concept_retention ADD COLUMN suspended_at TEXT;
+
+UPDATE concept_retention SET lapses = (
+  SELECT COUNT(*) FROM review_log l WHERE l.concept_id = concept_retention.concept_id AND l.rating = 'forgot'
+);
+
+-- Research jobs come in two kinds: curriculum jobs research a topic,
+-- flashcard jobs rewrite the definition and flashcard of one concept.
+ALTER TABLE research_jobs ADD COLUMN kind TEXT NOT NULL DEFAULT 'curriculum' CHECK (kind IN ('curriculum', 'flashcard'));
+ALTER TABLE research_jobs ADD COLUMN concept_id TEXT REFERENCES concepts(id) ON DELETE SET NULL;
+
+-- The definition and flashcard a flashcard job proposes for a concept,
+-- applied only once approved.
+CREATE TABLE IF NOT EXISTS card_proposals (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  concept_id TEXT NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+  job_id TEXT REFERENCES research_jobs(id) ON DELETE SET NULL,
+  status TEXT NOT NULL DEFAULT 'proposed' CHECK (status IN ('proposed', 'approved', 'rejected')),
+  definition TEXT NOT NULL,
+  flashcard_front TEXT NOT NULL,
+  flashcard_back TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  resolved_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_proposals_concept ON card_proposals(concept_id, status);
+CREATE INDEX IF NOT EXISTS idx_research_jobs_concept ON research_jobs(concept_id);
@@ -0,0 +1,28 @@
+CREATE TABLE review_session_cards_old (
+  session_id TEXT NOT NULL REFERENCES review_sessions(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  concept_id TEXT NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+  is_new INTEGER NOT NULL DEFAULT 0,
+  rating TEXT CHECK (rating IS NULL OR rating IN ('forgot', 'hard', 'good', 'easy')),
+  next_review TEXT,
+  interval_days INTEGER,
+  answered_at TEXT,
+  PRIMARY KEY (session_id, position)
+);
+
+INSERT INTO review_session_cards_old (session_id, position, concept_id, is_new, rating, next_review, interval_days, answered_at)
+SELECT session_id, position, concept_id, is_new, rating, next_review, interval_days, answered_at
+FROM review_session_cards WHERE concept_id IS NOT NULL;
+
+DROP TABLE review_session_cards;
+ALTER TABLE review_session_cards_old RENAME TO review_session_cards;
+
+DROP INDEX IF EXISTS idx_question_review_log_question;
+DROP INDEX IF EXISTS idx_question_concepts_concept;
+DROP INDEX IF EXISTS idx_question_cards_next_review;
+DROP INDEX IF EXISTS idx_question_cards_lesson;
+DROP INDEX IF EXISTS idx_question_cards_module;
+ALTER TABLE review_log DROP COLUMN question_id;
+DROP TABLE IF EXISTS question_review_log;
+DROP TABLE IF EXISTS question_concepts;
+DROP TABLE IF EXISTS question_cards;
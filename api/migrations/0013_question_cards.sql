@@ -0,0 +1,77 @@
+-- Question cards: the review_questions of each lesson and the assessment
+-- questions of each module, reviewed alongside concept cards with their own
+-- scheduling state. They are synced from the JSON columns whenever a lesson
+-- or module is written; id is derived from the source and the question
+-- text, so a card keeps its state while its question is unchanged.
+CREATE TABLE IF NOT EXISTS question_cards (
+  id TEXT PRIMARY KEY,
+  source TEXT NOT NULL CHECK (source IN ('lesson', 'assessment')),
+  module_id TEXT NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+  lesson_id TEXT REFERENCES lessons(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  question TEXT NOT NULL,
+  answer TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'learning', 'reviewing', 'mastered')),
+  next_review TEXT,
+  review_count INTEGER NOT NULL DEFAULT 0,
+  ease_factor REAL NOT NULL DEFAULT 2.5,
+  interval_days INTEGER NOT NULL DEFAULT 0,
+  last_reviewed TEXT,
+  last_rating TEXT CHECK (last_rating IS NULL OR last_rating IN ('forgot', 'hard', 'good', 'easy')),
+  stability REAL,
+  difficulty REAL,
+  scheduler TEXT NOT NULL DEFAULT 'sm2',
+  lapses INTEGER NOT NULL DEFAULT 0,
+  CHECK ((source = 'lesson') = (lesson_id IS NOT NULL))
+);
+
+-- The concepts a question tests, as listed in its concepts_tested. IDs are
+-- resolved through aliases when the card is synced; a concept that does
+-- not exist yet is kept and simply matches nothing.
+CREATE TABLE IF NOT EXISTS question_concepts (
+  question_id TEXT NOT NULL REFERENCES question_cards(id) ON DELETE CASCADE,
+  concept_id TEXT NOT NULL,
+  PRIMARY KEY (question_id, concept_id)
+);
+
+CREATE TABLE IF NOT EXISTS question_review_log (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  question_id TEXT NOT NULL REFERENCES question_cards(id) ON DELETE CASCADE,
+  rating TEXT NOT NULL CHECK (rating IN ('forgot', 'hard', 'good', 'easy')),
+  reviewed_at TEXT NOT NULL,
+  previous_interval INTEGER,
+  new_interval INTEGER,
+  ease_factor REAL,
+  response_ms INTEGER CHECK (response_ms IS NULL OR response_ms >= 0)
+);
+
+-- Answering a question also reviews the concepts it tests; those reviews
+-- name the question they came from.
+ALTER TABLE review_log ADD COLUMN question_id TEXT REFERENCES question_cards(id) ON DELETE SET NULL;
+
+-- A session card is a concept or a question.
+CREATE TABLE review_session_cards_new (
+  session_id TEXT NOT NULL REFERENCES review_sessions(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  concept_id TEXT REFERENCES concepts(id) ON DELETE CASCADE,
+  question_id TEXT REFERENCES question_cards(id) ON DELETE CASCADE,
+  is_new INTEGER NOT NULL DEFAULT 0,
+  rating TEXT CHECK (rating IS NULL OR rating IN ('forgot', 'hard', 'good', 'easy')),
+  next_review TEXT,
+  interval_days INTEGER,
+  answered_at TEXT,
+  PRIMARY KEY (session_id, position),
+  CHECK ((concept_id IS NULL) <> (question_id IS NULL))
+);
+
+INSERT INTO review_session_cards_new (session_id, position, concept_id, is_new, rating, next_review, interval_days, answered_at)
+SELECT session_id, position, concept_id, is_new, rating, next_review, interval_days, answered_at FROM review_session_cards;
+
+DROP TABLE review_session_cards;
+ALTER TABLE review_session_cards_new RENAME TO review_session_cards;
+
+CREATE INDEX IF NOT EXISTS idx_question_cards_module ON question_cards(module_id);
+CREATE INDEX IF NOT EXISTS idx_question_cards_lesson ON question_cards(lesson_id);
+CREATE INDEX IF NOT EXISTS idx_question_cards_next_review ON question_cards(next_review);
+CREATE INDEX IF NOT EXISTS idx_question_concepts_concept ON question_concepts(concept_id);
+CREATE INDEX IF NOT EXISTS idx_question_review_log_question ON question_review_log(question_id, id);
@@ -0,0 +1,28 @@
+CREATE TABLE review_session_cards_old (
+  session_id TEXT NOT NULL REFERENCES review_sessions(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  concept_id TEXT REFERENCES concepts(id) ON DELETE CASCADE,
+  question_id TEXT REFERENCES question_cards(id) ON DELETE CASCADE,
+  is_new INTEGER NOT NULL DEFAULT 0,
+  rating TEXT CHECK (rating IS NULL OR rating IN ('forgot', 'hard', 'good', 'easy')),
+  next_review TEXT,
+  interval_days INTEGER,
+  answered_at TEXT,
+  PRIMARY KEY (session_id, position),
+  CHECK ((concept_id IS NULL) <> (question_id IS NULL))
+);
+
+INSERT INTO review_session_cards_old (session_id, position, concept_id, question_id, is_new, rating, next_review, interval_days, answered_at)
+SELECT session_id, position, concept_id, question_id, is_new, rating, next_review, interval_days, answered_at
+FROM review_session_cards WHERE card_id IS NULL;
+
+DROP TABLE review_session_cards;
+ALTER TABLE review_session_cards_old RENAME TO review_session_cards;
+
+DROP INDEX IF EXISTS idx_generated_card_review_log_card;
+DROP INDEX IF EXISTS idx_generated_cards_next_review;
+DROP INDEX IF EXISTS idx_generated_cards_topic;
+DROP INDEX IF EXISTS idx_generated_cards_concept;
+DROP TABLE IF EXISTS generated_card_review_log;
+DROP TABLE IF EXISTS generated_cards;
+DROP TABLE IF EXISTS topic_card_kinds;
@@ -0,0 +1,71 @@
+-- Generated cards: reverse (definition to name), cloze (a key term blanked
+-- out of the definition) and topic ("which topic defines X") cards derived
+-- from each concept's stored data, reviewed with their own scheduling
+-- state. A topic opts into each kind through topic_card_kinds. Cards are
+-- regenerated whenever the topic or its concepts are written; id is derived
+-- from the kind, the concept and, for cloze cards, the term, so a card keeps
+-- its state while its text changes.
+CREATE TABLE IF NOT EXISTS topic_card_kinds (
+  topic_id TEXT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL CHECK (kind IN ('reverse', 'cloze', 'topic')),
+  PRIMARY KEY (topic_id, kind)
+);
+
+CREATE TABLE IF NOT EXISTS generated_cards (
+  id TEXT PRIMARY KEY,
+  kind TEXT NOT NULL CHECK (kind IN ('reverse', 'cloze', 'topic')),
+  concept_id TEXT NOT NULL REFERENCES concepts(id) ON DELETE CASCADE,
+  topic_id TEXT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  front TEXT NOT NULL,
+  back TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'learning', 'reviewing', 'mastered')),
+  next_review TEXT,
+  review_count INTEGER NOT NULL DEFAULT 0,
+  ease_factor REAL NOT NULL DEFAULT 2.5,
+  interval_days INTEGER NOT NULL DEFAULT 0,
+  last_reviewed TEXT,
+  last_rating TEXT CHECK (last_rating IS NULL OR last_rating IN ('forgot', 'hard', 'good', 'easy')),
+  stability REAL,
+  difficulty REAL,
+  scheduler TEXT NOT NULL DEFAULT 'sm2',
+  lapses INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS generated_card_review_log (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  card_id TEXT NOT NULL REFERENCES generated_cards(id) ON DELETE CASCADE,
+  rating TEXT NOT NULL CHECK (rating IN ('forgot', 'hard', 'good', 'easy')),
+  reviewed_at TEXT NOT NULL,
+  previous_interval INTEGER,
+  new_interval INTEGER,
+  ease_factor REAL,
+  response_ms INTEGER CHECK (response_ms IS NULL OR response_ms >= 0)
+);
+
+-- A session card is a concept, a question or a generated card.
+CREATE TABLE review_session_cards_new (
+  session_id TEXT NOT NULL REFERENCES review_sessions(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  concept_id TEXT REFERENCES concepts(id) ON DELETE CASCADE,
+  question_id TEXT REFERENCES question_cards(id) ON DELETE CASCADE,
+  card_id TEXT REFERENCES generated_cards(id) ON DELETE CASCADE,
+  is_new INTEGER NOT NULL DEFAULT 0,
+  rating TEXT CHECK (rating IS NULL OR rating IN ('forgot', 'hard', 'good', 'easy')),
+  next_review TEXT,
+  interval_days INTEGER,
+  answered_at TEXT,
+  PRIMARY KEY (session_id, position),
+  CHECK ((concept_id IS NOT NULL) + (question_id IS NOT NULL) + (card_id IS NOT NULL) = 1)
+);
+
+INSERT INTO review_session_cards_new (session_id, position, concept_id, question_id, is_new, rating, next_review, interval_days, answered_at)
+SELECT session_id, position, concept_id, question_id, is_new, rating, next_review, interval_days, answered_at FROM review_session_cards;
+
+DROP TABLE review_session_cards;
+ALTER TABLE review_session_cards_new RENAME TO review_session_cards;
+
+CREATE INDEX IF NOT EXISTS idx_generated_cards_concept ON generated_cards(concept_id);
+CREATE INDEX IF NOT EXISTS idx_generated_cards_topic ON generated_cards(topic_id);
+CREATE INDEX IF NOT EXISTS idx_generated_cards_next_review ON generated_cards(next_review);
+CREATE INDEX IF NOT EXISTS idx_generated_card_review_log_card ON generated_card_review_log(card_id, id);
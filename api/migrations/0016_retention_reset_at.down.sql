@@ -0,0 +1 @@
+ALTER TABLE concept_retention DROP COLUMN reset_at;
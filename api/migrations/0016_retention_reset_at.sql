@@ -0,0 +1,5 @@
+-- When a concept's review state was last reset, by a changed canonical
+-- definition or an approved card proposal. Re-deriving the state replays
+-- only the reviews logged after it, so a scheduler switch does not undo the
+-- reset. NULL means the state was never reset.
+ALTER TABLE concept_retention ADD COLUMN reset_at TEXT;